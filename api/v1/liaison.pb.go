@@ -1508,18 +1508,22 @@ func (x *DeleteDeviceResponse) GetMessage() string {
 
 // 应用
 type Application struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Id              uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                            // 应用ID
-	EdgeId          uint64                 `protobuf:"varint,2,opt,name=edge_id,proto3" json:"edge_id,omitempty"`                  // 边缘ID
-	Device          *Device                `protobuf:"bytes,3,opt,name=device,proto3" json:"device,omitempty"`                     // 所属设备
-	Proxy           *Proxy                 `protobuf:"bytes,4,opt,name=proxy,proto3" json:"proxy,omitempty"`                       // 关联Proxy
-	Name            string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`                         // 名称
-	Description     string                 `protobuf:"bytes,11,opt,name=description,proto3" json:"description,omitempty"`          // 描述
-	Ip              string                 `protobuf:"bytes,6,opt,name=ip,proto3" json:"ip,omitempty"`                             // 内网IP地址
-	Port            int32                  `protobuf:"varint,7,opt,name=port,proto3" json:"port,omitempty"`                        // 端口
-	ApplicationType string                 `protobuf:"bytes,8,opt,name=application_type,proto3" json:"application_type,omitempty"` // 应用类型
-	CreatedAt       string                 `protobuf:"bytes,9,opt,name=created_at,proto3" json:"created_at,omitempty"`             // 创建时间
-	UpdatedAt       string                 `protobuf:"bytes,10,opt,name=updated_at,proto3" json:"updated_at,omitempty"`            // 更新时间
+	state           protoimpl.MessageState  `protogen:"open.v1"`
+	Id              uint64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                            // 应用ID
+	EdgeId          uint64                  `protobuf:"varint,2,opt,name=edge_id,proto3" json:"edge_id,omitempty"`                  // 边缘ID
+	Device          *Device                 `protobuf:"bytes,3,opt,name=device,proto3" json:"device,omitempty"`                     // 所属设备
+	Proxy           *Proxy                  `protobuf:"bytes,4,opt,name=proxy,proto3" json:"proxy,omitempty"`                       // 关联Proxy
+	Name            string                  `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`                         // 名称
+	Description     string                  `protobuf:"bytes,11,opt,name=description,proto3" json:"description,omitempty"`          // 描述
+	Ip              string                  `protobuf:"bytes,6,opt,name=ip,proto3" json:"ip,omitempty"`                             // 内网IP地址
+	Port            int32                   `protobuf:"varint,7,opt,name=port,proto3" json:"port,omitempty"`                        // 端口
+	ApplicationType string                  `protobuf:"bytes,8,opt,name=application_type,proto3" json:"application_type,omitempty"` // 应用类型
+	CreatedAt       string                  `protobuf:"bytes,9,opt,name=created_at,proto3" json:"created_at,omitempty"`             // 创建时间
+	UpdatedAt       string                  `protobuf:"bytes,10,opt,name=updated_at,proto3" json:"updated_at,omitempty"`            // 更新时间
+	EdgeIds         []uint64                `protobuf:"varint,12,rep,packed,name=edge_ids,proto3" json:"edge_ids,omitempty"`        // 关联的全部边缘ID，按优先级排列，edge_id 为第一个
+	EdgePolicy      string                  `protobuf:"bytes,13,opt,name=edge_policy,proto3" json:"edge_policy,omitempty"`          // 多个边缘的选择策略：failover（默认）或 round_robin
+	Targets         []*ApplicationTarget    `protobuf:"bytes,14,rep,name=targets,proto3" json:"targets,omitempty"`                  // 全部后端，只有 ip:port 一个后端时也会列出
+	HealthCheck     *ApplicationHealthCheck `protobuf:"bytes,15,opt,name=health_check,proto3" json:"health_check,omitempty"`        // 健康检查，type 为空表示不检查
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -1631,6 +1635,180 @@ func (x *Application) GetUpdatedAt() string {
 	return ""
 }
 
+func (x *Application) GetEdgeIds() []uint64 {
+	if x != nil {
+		return x.EdgeIds
+	}
+	return nil
+}
+
+func (x *Application) GetEdgePolicy() string {
+	if x != nil {
+		return x.EdgePolicy
+	}
+	return ""
+}
+
+func (x *Application) GetTargets() []*ApplicationTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *Application) GetHealthCheck() *ApplicationHealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
+// 应用的一个后端
+type ApplicationTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Weight        int32                  `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"` // 权重，0 按 1 处理
+	Health        string                 `protobuf:"bytes,4,opt,name=health,proto3" json:"health,omitempty"`  // 只读：healthy、unhealthy、unknown（未开启检查或没有检查结果）
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`    // 只读：最近一次检查失败的原因
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplicationTarget) Reset() {
+	*x = ApplicationTarget{}
+	mi := &file_liaison_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplicationTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplicationTarget) ProtoMessage() {}
+
+func (x *ApplicationTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_liaison_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplicationTarget.ProtoReflect.Descriptor instead.
+func (*ApplicationTarget) Descriptor() ([]byte, []int) {
+	return file_liaison_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ApplicationTarget) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *ApplicationTarget) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ApplicationTarget) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ApplicationTarget) GetHealth() string {
+	if x != nil {
+		return x.Health
+	}
+	return ""
+}
+
+func (x *ApplicationTarget) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 由 edge 执行的主动健康检查
+type ApplicationHealthCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                  // tcp 或 http，为空不检查
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                  // http 检查的路径，默认 /
+	IntervalSec   int32                  `protobuf:"varint,3,opt,name=interval_sec,proto3" json:"interval_sec,omitempty"` // 检查间隔，默认 10 秒
+	TimeoutSec    int32                  `protobuf:"varint,4,opt,name=timeout_sec,proto3" json:"timeout_sec,omitempty"`   // 单次检查超时，默认 3 秒
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplicationHealthCheck) Reset() {
+	*x = ApplicationHealthCheck{}
+	mi := &file_liaison_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplicationHealthCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplicationHealthCheck) ProtoMessage() {}
+
+func (x *ApplicationHealthCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_liaison_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplicationHealthCheck.ProtoReflect.Descriptor instead.
+func (*ApplicationHealthCheck) Descriptor() ([]byte, []int) {
+	return file_liaison_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ApplicationHealthCheck) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ApplicationHealthCheck) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ApplicationHealthCheck) GetIntervalSec() int32 {
+	if x != nil {
+		return x.IntervalSec
+	}
+	return 0
+}
+
+func (x *ApplicationHealthCheck) GetTimeoutSec() int32 {
+	if x != nil {
+		return x.TimeoutSec
+	}
+	return 0
+}
+
 type Applications struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
@@ -1641,7 +1819,7 @@ type Applications struct {
 
 func (x *Applications) Reset() {
 	*x = Applications{}
-	mi := &file_liaison_proto_msgTypes[25]
+	mi := &file_liaison_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1653,7 +1831,7 @@ func (x *Applications) String() string {
 func (*Applications) ProtoMessage() {}
 
 func (x *Applications) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[25]
+	mi := &file_liaison_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1666,7 +1844,7 @@ func (x *Applications) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Applications.ProtoReflect.Descriptor instead.
 func (*Applications) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{25}
+	return file_liaison_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *Applications) GetTotal() int32 {
@@ -1685,21 +1863,25 @@ func (x *Applications) GetApplications() []*Application {
 
 // 添加应用请求
 type CreateApplicationRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description     string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"` // 描述
-	Ip              string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
-	Port            int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
-	ApplicationType string                 `protobuf:"bytes,4,opt,name=application_type,proto3" json:"application_type,omitempty"`
-	EdgeId          uint64                 `protobuf:"varint,5,opt,name=edge_id,proto3" json:"edge_id,omitempty"`
-	DeviceId        *uint64                `protobuf:"varint,6,opt,name=device_id,proto3,oneof" json:"device_id,omitempty"`
+	state           protoimpl.MessageState  `protogen:"open.v1"`
+	Name            string                  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description     string                  `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"` // 描述
+	Ip              string                  `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port            int32                   `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	ApplicationType string                  `protobuf:"bytes,4,opt,name=application_type,proto3" json:"application_type,omitempty"`
+	EdgeId          uint64                  `protobuf:"varint,5,opt,name=edge_id,proto3" json:"edge_id,omitempty"`
+	DeviceId        *uint64                 `protobuf:"varint,6,opt,name=device_id,proto3,oneof" json:"device_id,omitempty"`
+	EdgeIds         []uint64                `protobuf:"varint,8,rep,packed,name=edge_ids,proto3" json:"edge_ids,omitempty"` // 多个边缘，按优先级排列；非空时忽略 edge_id
+	EdgePolicy      string                  `protobuf:"bytes,9,opt,name=edge_policy,proto3" json:"edge_policy,omitempty"`   // failover（默认）或 round_robin
+	Targets         []*ApplicationTarget    `protobuf:"bytes,10,rep,name=targets,proto3" json:"targets,omitempty"`          // 多个后端，非空时 ip/port 取第一个
+	HealthCheck     *ApplicationHealthCheck `protobuf:"bytes,11,opt,name=health_check,proto3" json:"health_check,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
 
 func (x *CreateApplicationRequest) Reset() {
 	*x = CreateApplicationRequest{}
-	mi := &file_liaison_proto_msgTypes[26]
+	mi := &file_liaison_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1711,7 +1893,7 @@ func (x *CreateApplicationRequest) String() string {
 func (*CreateApplicationRequest) ProtoMessage() {}
 
 func (x *CreateApplicationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[26]
+	mi := &file_liaison_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1724,7 +1906,7 @@ func (x *CreateApplicationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateApplicationRequest.ProtoReflect.Descriptor instead.
 func (*CreateApplicationRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{26}
+	return file_liaison_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *CreateApplicationRequest) GetName() string {
@@ -1776,6 +1958,34 @@ func (x *CreateApplicationRequest) GetDeviceId() uint64 {
 	return 0
 }
 
+func (x *CreateApplicationRequest) GetEdgeIds() []uint64 {
+	if x != nil {
+		return x.EdgeIds
+	}
+	return nil
+}
+
+func (x *CreateApplicationRequest) GetEdgePolicy() string {
+	if x != nil {
+		return x.EdgePolicy
+	}
+	return ""
+}
+
+func (x *CreateApplicationRequest) GetTargets() []*ApplicationTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *CreateApplicationRequest) GetHealthCheck() *ApplicationHealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
 type CreateApplicationResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
@@ -1787,7 +1997,7 @@ type CreateApplicationResponse struct {
 
 func (x *CreateApplicationResponse) Reset() {
 	*x = CreateApplicationResponse{}
-	mi := &file_liaison_proto_msgTypes[27]
+	mi := &file_liaison_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1799,7 +2009,7 @@ func (x *CreateApplicationResponse) String() string {
 func (*CreateApplicationResponse) ProtoMessage() {}
 
 func (x *CreateApplicationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[27]
+	mi := &file_liaison_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1812,7 +2022,7 @@ func (x *CreateApplicationResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateApplicationResponse.ProtoReflect.Descriptor instead.
 func (*CreateApplicationResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{27}
+	return file_liaison_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *CreateApplicationResponse) GetCode() int32 {
@@ -1851,7 +2061,7 @@ type ListApplicationsRequest struct {
 
 func (x *ListApplicationsRequest) Reset() {
 	*x = ListApplicationsRequest{}
-	mi := &file_liaison_proto_msgTypes[28]
+	mi := &file_liaison_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1863,7 +2073,7 @@ func (x *ListApplicationsRequest) String() string {
 func (*ListApplicationsRequest) ProtoMessage() {}
 
 func (x *ListApplicationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[28]
+	mi := &file_liaison_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1876,7 +2086,7 @@ func (x *ListApplicationsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListApplicationsRequest.ProtoReflect.Descriptor instead.
 func (*ListApplicationsRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{28}
+	return file_liaison_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *ListApplicationsRequest) GetPage() int32 {
@@ -1932,7 +2142,7 @@ type ListApplicationsResponse struct {
 
 func (x *ListApplicationsResponse) Reset() {
 	*x = ListApplicationsResponse{}
-	mi := &file_liaison_proto_msgTypes[29]
+	mi := &file_liaison_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1944,7 +2154,7 @@ func (x *ListApplicationsResponse) String() string {
 func (*ListApplicationsResponse) ProtoMessage() {}
 
 func (x *ListApplicationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[29]
+	mi := &file_liaison_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1957,7 +2167,7 @@ func (x *ListApplicationsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListApplicationsResponse.ProtoReflect.Descriptor instead.
 func (*ListApplicationsResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{29}
+	return file_liaison_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *ListApplicationsResponse) GetCode() int32 {
@@ -1983,17 +2193,21 @@ func (x *ListApplicationsResponse) GetData() *Applications {
 
 // 更新应用请求
 type UpdateApplicationRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"` // 描述
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Id            uint64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                  `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`       // 描述
+	EdgeIds       []uint64                `protobuf:"varint,4,rep,packed,name=edge_ids,proto3" json:"edge_ids,omitempty"`     // 不传表示不修改，运行中的代理立即生效
+	EdgePolicy    *string                 `protobuf:"bytes,5,opt,name=edge_policy,proto3,oneof" json:"edge_policy,omitempty"` // 不传表示不修改
+	Targets       []*ApplicationTarget    `protobuf:"bytes,6,rep,name=targets,proto3" json:"targets,omitempty"`               // 不传表示不修改，运行中的代理立即生效
+	HealthCheck   *ApplicationHealthCheck `protobuf:"bytes,7,opt,name=health_check,proto3" json:"health_check,omitempty"`     // 不传表示不修改
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateApplicationRequest) Reset() {
 	*x = UpdateApplicationRequest{}
-	mi := &file_liaison_proto_msgTypes[30]
+	mi := &file_liaison_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2005,7 +2219,7 @@ func (x *UpdateApplicationRequest) String() string {
 func (*UpdateApplicationRequest) ProtoMessage() {}
 
 func (x *UpdateApplicationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[30]
+	mi := &file_liaison_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2018,7 +2232,7 @@ func (x *UpdateApplicationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateApplicationRequest.ProtoReflect.Descriptor instead.
 func (*UpdateApplicationRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{30}
+	return file_liaison_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *UpdateApplicationRequest) GetId() uint64 {
@@ -2042,6 +2256,34 @@ func (x *UpdateApplicationRequest) GetDescription() string {
 	return ""
 }
 
+func (x *UpdateApplicationRequest) GetEdgeIds() []uint64 {
+	if x != nil {
+		return x.EdgeIds
+	}
+	return nil
+}
+
+func (x *UpdateApplicationRequest) GetEdgePolicy() string {
+	if x != nil && x.EdgePolicy != nil {
+		return *x.EdgePolicy
+	}
+	return ""
+}
+
+func (x *UpdateApplicationRequest) GetTargets() []*ApplicationTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *UpdateApplicationRequest) GetHealthCheck() *ApplicationHealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
 type UpdateApplicationResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
@@ -2053,7 +2295,7 @@ type UpdateApplicationResponse struct {
 
 func (x *UpdateApplicationResponse) Reset() {
 	*x = UpdateApplicationResponse{}
-	mi := &file_liaison_proto_msgTypes[31]
+	mi := &file_liaison_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2065,7 +2307,7 @@ func (x *UpdateApplicationResponse) String() string {
 func (*UpdateApplicationResponse) ProtoMessage() {}
 
 func (x *UpdateApplicationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[31]
+	mi := &file_liaison_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2078,7 +2320,7 @@ func (x *UpdateApplicationResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateApplicationResponse.ProtoReflect.Descriptor instead.
 func (*UpdateApplicationResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{31}
+	return file_liaison_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *UpdateApplicationResponse) GetCode() int32 {
@@ -2112,7 +2354,7 @@ type DeleteApplicationRequest struct {
 
 func (x *DeleteApplicationRequest) Reset() {
 	*x = DeleteApplicationRequest{}
-	mi := &file_liaison_proto_msgTypes[32]
+	mi := &file_liaison_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2124,7 +2366,7 @@ func (x *DeleteApplicationRequest) String() string {
 func (*DeleteApplicationRequest) ProtoMessage() {}
 
 func (x *DeleteApplicationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[32]
+	mi := &file_liaison_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2137,7 +2379,7 @@ func (x *DeleteApplicationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteApplicationRequest.ProtoReflect.Descriptor instead.
 func (*DeleteApplicationRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{32}
+	return file_liaison_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *DeleteApplicationRequest) GetId() uint64 {
@@ -2157,7 +2399,7 @@ type DeleteApplicationResponse struct {
 
 func (x *DeleteApplicationResponse) Reset() {
 	*x = DeleteApplicationResponse{}
-	mi := &file_liaison_proto_msgTypes[33]
+	mi := &file_liaison_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2169,7 +2411,7 @@ func (x *DeleteApplicationResponse) String() string {
 func (*DeleteApplicationResponse) ProtoMessage() {}
 
 func (x *DeleteApplicationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[33]
+	mi := &file_liaison_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2182,7 +2424,7 @@ func (x *DeleteApplicationResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteApplicationResponse.ProtoReflect.Descriptor instead.
 func (*DeleteApplicationResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{33}
+	return file_liaison_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *DeleteApplicationResponse) GetCode() int32 {
@@ -2211,13 +2453,16 @@ type Proxy struct {
 	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,proto3" json:"created_at,omitempty"`
 	UpdatedAt     string                 `protobuf:"bytes,8,opt,name=updated_at,proto3" json:"updated_at,omitempty"`
 	AccessUrl     string                 `protobuf:"bytes,9,opt,name=access_url,proto3" json:"access_url,omitempty"`
+	RateLimit     *RateLimit             `protobuf:"bytes,10,opt,name=rate_limit,proto3" json:"rate_limit,omitempty"`          // 带宽限制
+	Hostname      string                 `protobuf:"bytes,11,opt,name=hostname,proto3" json:"hostname,omitempty"`              // 虚拟主机名，为空只通过端口访问
+	CertificateId uint64                 `protobuf:"varint,12,opt,name=certificate_id,proto3" json:"certificate_id,omitempty"` // 绑定的证书，0 表示按 SNI 选择
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Proxy) Reset() {
 	*x = Proxy{}
-	mi := &file_liaison_proto_msgTypes[34]
+	mi := &file_liaison_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2229,7 +2474,7 @@ func (x *Proxy) String() string {
 func (*Proxy) ProtoMessage() {}
 
 func (x *Proxy) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[34]
+	mi := &file_liaison_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2242,7 +2487,7 @@ func (x *Proxy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Proxy.ProtoReflect.Descriptor instead.
 func (*Proxy) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{34}
+	return file_liaison_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *Proxy) GetId() uint64 {
@@ -2273,39 +2518,129 @@ func (x *Proxy) GetStatus() string {
 	return ""
 }
 
-func (x *Proxy) GetApplication() *Application {
-	if x != nil {
-		return x.Application
-	}
-	return nil
+func (x *Proxy) GetApplication() *Application {
+	if x != nil {
+		return x.Application
+	}
+	return nil
+}
+
+func (x *Proxy) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Proxy) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Proxy) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Proxy) GetAccessUrl() string {
+	if x != nil {
+		return x.AccessUrl
+	}
+	return ""
+}
+
+func (x *Proxy) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *Proxy) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *Proxy) GetCertificateId() uint64 {
+	if x != nil {
+		return x.CertificateId
+	}
+	return 0
+}
+
+// 代理带宽限制，单位字节/秒，0 表示不限
+type RateLimit struct {
+	state                        protoimpl.MessageState `protogen:"open.v1"`
+	UploadBytesPerSec            int64                  `protobuf:"varint,1,opt,name=upload_bytes_per_sec,proto3" json:"upload_bytes_per_sec,omitempty"`                           // 整个代理的上行（客户端 -> 后端）
+	DownloadBytesPerSec          int64                  `protobuf:"varint,2,opt,name=download_bytes_per_sec,proto3" json:"download_bytes_per_sec,omitempty"`                       // 整个代理的下行（后端 -> 客户端）
+	PerClientUploadBytesPerSec   int64                  `protobuf:"varint,3,opt,name=per_client_upload_bytes_per_sec,proto3" json:"per_client_upload_bytes_per_sec,omitempty"`     // 单个客户端 IP 的上行
+	PerClientDownloadBytesPerSec int64                  `protobuf:"varint,4,opt,name=per_client_download_bytes_per_sec,proto3" json:"per_client_download_bytes_per_sec,omitempty"` // 单个客户端 IP 的下行
+	unknownFields                protoimpl.UnknownFields
+	sizeCache                    protoimpl.SizeCache
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	mi := &file_liaison_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_liaison_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_liaison_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *Proxy) GetDescription() string {
+func (x *RateLimit) GetUploadBytesPerSec() int64 {
 	if x != nil {
-		return x.Description
+		return x.UploadBytesPerSec
 	}
-	return ""
+	return 0
 }
 
-func (x *Proxy) GetCreatedAt() string {
+func (x *RateLimit) GetDownloadBytesPerSec() int64 {
 	if x != nil {
-		return x.CreatedAt
+		return x.DownloadBytesPerSec
 	}
-	return ""
+	return 0
 }
 
-func (x *Proxy) GetUpdatedAt() string {
+func (x *RateLimit) GetPerClientUploadBytesPerSec() int64 {
 	if x != nil {
-		return x.UpdatedAt
+		return x.PerClientUploadBytesPerSec
 	}
-	return ""
+	return 0
 }
 
-func (x *Proxy) GetAccessUrl() string {
+func (x *RateLimit) GetPerClientDownloadBytesPerSec() int64 {
 	if x != nil {
-		return x.AccessUrl
+		return x.PerClientDownloadBytesPerSec
 	}
-	return ""
+	return 0
 }
 
 type Proxies struct {
@@ -2318,7 +2653,7 @@ type Proxies struct {
 
 func (x *Proxies) Reset() {
 	*x = Proxies{}
-	mi := &file_liaison_proto_msgTypes[35]
+	mi := &file_liaison_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2330,7 +2665,7 @@ func (x *Proxies) String() string {
 func (*Proxies) ProtoMessage() {}
 
 func (x *Proxies) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[35]
+	mi := &file_liaison_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2343,7 +2678,7 @@ func (x *Proxies) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Proxies.ProtoReflect.Descriptor instead.
 func (*Proxies) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{35}
+	return file_liaison_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *Proxies) GetTotal() int32 {
@@ -2372,7 +2707,7 @@ type ListProxiesRequest struct {
 
 func (x *ListProxiesRequest) Reset() {
 	*x = ListProxiesRequest{}
-	mi := &file_liaison_proto_msgTypes[36]
+	mi := &file_liaison_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2384,7 +2719,7 @@ func (x *ListProxiesRequest) String() string {
 func (*ListProxiesRequest) ProtoMessage() {}
 
 func (x *ListProxiesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[36]
+	mi := &file_liaison_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2397,7 +2732,7 @@ func (x *ListProxiesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListProxiesRequest.ProtoReflect.Descriptor instead.
 func (*ListProxiesRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{36}
+	return file_liaison_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *ListProxiesRequest) GetPage() int32 {
@@ -2432,7 +2767,7 @@ type ListProxiesResponse struct {
 
 func (x *ListProxiesResponse) Reset() {
 	*x = ListProxiesResponse{}
-	mi := &file_liaison_proto_msgTypes[37]
+	mi := &file_liaison_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2444,7 +2779,7 @@ func (x *ListProxiesResponse) String() string {
 func (*ListProxiesResponse) ProtoMessage() {}
 
 func (x *ListProxiesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[37]
+	mi := &file_liaison_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2457,7 +2792,7 @@ func (x *ListProxiesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListProxiesResponse.ProtoReflect.Descriptor instead.
 func (*ListProxiesResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{37}
+	return file_liaison_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *ListProxiesResponse) GetCode() int32 {
@@ -2488,13 +2823,15 @@ type CreateProxyRequest struct {
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Port          int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
 	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	RateLimit     *RateLimit             `protobuf:"bytes,5,opt,name=rate_limit,proto3" json:"rate_limit,omitempty"` // 带宽限制，可选
+	Hostname      string                 `protobuf:"bytes,6,opt,name=hostname,proto3" json:"hostname,omitempty"`     // 虚拟主机名（仅 HTTP 应用），可以是完整域名或基础域名下的一级名字；设置后不指定端口则只通过共享监听访问
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateProxyRequest) Reset() {
 	*x = CreateProxyRequest{}
-	mi := &file_liaison_proto_msgTypes[38]
+	mi := &file_liaison_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2506,7 +2843,7 @@ func (x *CreateProxyRequest) String() string {
 func (*CreateProxyRequest) ProtoMessage() {}
 
 func (x *CreateProxyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[38]
+	mi := &file_liaison_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2519,7 +2856,7 @@ func (x *CreateProxyRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateProxyRequest.ProtoReflect.Descriptor instead.
 func (*CreateProxyRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{38}
+	return file_liaison_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *CreateProxyRequest) GetApplicationId() uint64 {
@@ -2550,6 +2887,20 @@ func (x *CreateProxyRequest) GetDescription() string {
 	return ""
 }
 
+func (x *CreateProxyRequest) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *CreateProxyRequest) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
 type CreateProxyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
@@ -2561,7 +2912,7 @@ type CreateProxyResponse struct {
 
 func (x *CreateProxyResponse) Reset() {
 	*x = CreateProxyResponse{}
-	mi := &file_liaison_proto_msgTypes[39]
+	mi := &file_liaison_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2573,7 +2924,7 @@ func (x *CreateProxyResponse) String() string {
 func (*CreateProxyResponse) ProtoMessage() {}
 
 func (x *CreateProxyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[39]
+	mi := &file_liaison_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2586,7 +2937,7 @@ func (x *CreateProxyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateProxyResponse.ProtoReflect.Descriptor instead.
 func (*CreateProxyResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{39}
+	return file_liaison_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *CreateProxyResponse) GetCode() int32 {
@@ -2618,13 +2969,15 @@ type UpdateProxyRequest struct {
 	Port          int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
 	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
 	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	RateLimit     *RateLimit             `protobuf:"bytes,6,opt,name=rate_limit,proto3" json:"rate_limit,omitempty"`   // 带宽限制，不传表示不修改，运行中的代理立即生效
+	Hostname      *string                `protobuf:"bytes,7,opt,name=hostname,proto3,oneof" json:"hostname,omitempty"` // 虚拟主机名，不传表示不修改，空字符串表示清除
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateProxyRequest) Reset() {
 	*x = UpdateProxyRequest{}
-	mi := &file_liaison_proto_msgTypes[40]
+	mi := &file_liaison_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2636,7 +2989,7 @@ func (x *UpdateProxyRequest) String() string {
 func (*UpdateProxyRequest) ProtoMessage() {}
 
 func (x *UpdateProxyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[40]
+	mi := &file_liaison_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2649,7 +3002,7 @@ func (x *UpdateProxyRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateProxyRequest.ProtoReflect.Descriptor instead.
 func (*UpdateProxyRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{40}
+	return file_liaison_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *UpdateProxyRequest) GetId() uint64 {
@@ -2687,6 +3040,20 @@ func (x *UpdateProxyRequest) GetDescription() string {
 	return ""
 }
 
+func (x *UpdateProxyRequest) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *UpdateProxyRequest) GetHostname() string {
+	if x != nil && x.Hostname != nil {
+		return *x.Hostname
+	}
+	return ""
+}
+
 type UpdateProxyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
@@ -2698,7 +3065,7 @@ type UpdateProxyResponse struct {
 
 func (x *UpdateProxyResponse) Reset() {
 	*x = UpdateProxyResponse{}
-	mi := &file_liaison_proto_msgTypes[41]
+	mi := &file_liaison_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2710,7 +3077,7 @@ func (x *UpdateProxyResponse) String() string {
 func (*UpdateProxyResponse) ProtoMessage() {}
 
 func (x *UpdateProxyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[41]
+	mi := &file_liaison_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2723,7 +3090,7 @@ func (x *UpdateProxyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateProxyResponse.ProtoReflect.Descriptor instead.
 func (*UpdateProxyResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{41}
+	return file_liaison_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *UpdateProxyResponse) GetCode() int32 {
@@ -2757,7 +3124,7 @@ type DeleteProxyRequest struct {
 
 func (x *DeleteProxyRequest) Reset() {
 	*x = DeleteProxyRequest{}
-	mi := &file_liaison_proto_msgTypes[42]
+	mi := &file_liaison_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2769,7 +3136,7 @@ func (x *DeleteProxyRequest) String() string {
 func (*DeleteProxyRequest) ProtoMessage() {}
 
 func (x *DeleteProxyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[42]
+	mi := &file_liaison_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2782,7 +3149,7 @@ func (x *DeleteProxyRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteProxyRequest.ProtoReflect.Descriptor instead.
 func (*DeleteProxyRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{42}
+	return file_liaison_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *DeleteProxyRequest) GetId() uint64 {
@@ -2802,7 +3169,7 @@ type DeleteProxyResponse struct {
 
 func (x *DeleteProxyResponse) Reset() {
 	*x = DeleteProxyResponse{}
-	mi := &file_liaison_proto_msgTypes[43]
+	mi := &file_liaison_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2814,7 +3181,7 @@ func (x *DeleteProxyResponse) String() string {
 func (*DeleteProxyResponse) ProtoMessage() {}
 
 func (x *DeleteProxyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[43]
+	mi := &file_liaison_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2827,7 +3194,7 @@ func (x *DeleteProxyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteProxyResponse.ProtoReflect.Descriptor instead.
 func (*DeleteProxyResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{43}
+	return file_liaison_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *DeleteProxyResponse) GetCode() int32 {
@@ -2860,7 +3227,7 @@ type EdgeScanApplicationTask struct {
 
 func (x *EdgeScanApplicationTask) Reset() {
 	*x = EdgeScanApplicationTask{}
-	mi := &file_liaison_proto_msgTypes[44]
+	mi := &file_liaison_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2872,7 +3239,7 @@ func (x *EdgeScanApplicationTask) String() string {
 func (*EdgeScanApplicationTask) ProtoMessage() {}
 
 func (x *EdgeScanApplicationTask) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[44]
+	mi := &file_liaison_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2885,7 +3252,7 @@ func (x *EdgeScanApplicationTask) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EdgeScanApplicationTask.ProtoReflect.Descriptor instead.
 func (*EdgeScanApplicationTask) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{44}
+	return file_liaison_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *EdgeScanApplicationTask) GetId() uint64 {
@@ -2949,7 +3316,7 @@ type CreateEdgeScanApplicationTaskRequest struct {
 
 func (x *CreateEdgeScanApplicationTaskRequest) Reset() {
 	*x = CreateEdgeScanApplicationTaskRequest{}
-	mi := &file_liaison_proto_msgTypes[45]
+	mi := &file_liaison_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2961,7 +3328,7 @@ func (x *CreateEdgeScanApplicationTaskRequest) String() string {
 func (*CreateEdgeScanApplicationTaskRequest) ProtoMessage() {}
 
 func (x *CreateEdgeScanApplicationTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[45]
+	mi := &file_liaison_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2974,7 +3341,7 @@ func (x *CreateEdgeScanApplicationTaskRequest) ProtoReflect() protoreflect.Messa
 
 // Deprecated: Use CreateEdgeScanApplicationTaskRequest.ProtoReflect.Descriptor instead.
 func (*CreateEdgeScanApplicationTaskRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{45}
+	return file_liaison_proto_rawDescGZIP(), []int{48}
 }
 
 func (x *CreateEdgeScanApplicationTaskRequest) GetEdgeId() uint64 {
@@ -3008,7 +3375,7 @@ type CreateEdgeScanApplicationTaskResponse struct {
 
 func (x *CreateEdgeScanApplicationTaskResponse) Reset() {
 	*x = CreateEdgeScanApplicationTaskResponse{}
-	mi := &file_liaison_proto_msgTypes[46]
+	mi := &file_liaison_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3020,7 +3387,7 @@ func (x *CreateEdgeScanApplicationTaskResponse) String() string {
 func (*CreateEdgeScanApplicationTaskResponse) ProtoMessage() {}
 
 func (x *CreateEdgeScanApplicationTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[46]
+	mi := &file_liaison_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3033,7 +3400,7 @@ func (x *CreateEdgeScanApplicationTaskResponse) ProtoReflect() protoreflect.Mess
 
 // Deprecated: Use CreateEdgeScanApplicationTaskResponse.ProtoReflect.Descriptor instead.
 func (*CreateEdgeScanApplicationTaskResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{46}
+	return file_liaison_proto_rawDescGZIP(), []int{49}
 }
 
 func (x *CreateEdgeScanApplicationTaskResponse) GetCode() int32 {
@@ -3060,7 +3427,7 @@ type GetEdgeScanApplicationTaskRequest struct {
 
 func (x *GetEdgeScanApplicationTaskRequest) Reset() {
 	*x = GetEdgeScanApplicationTaskRequest{}
-	mi := &file_liaison_proto_msgTypes[47]
+	mi := &file_liaison_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3072,7 +3439,7 @@ func (x *GetEdgeScanApplicationTaskRequest) String() string {
 func (*GetEdgeScanApplicationTaskRequest) ProtoMessage() {}
 
 func (x *GetEdgeScanApplicationTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[47]
+	mi := &file_liaison_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3085,7 +3452,7 @@ func (x *GetEdgeScanApplicationTaskRequest) ProtoReflect() protoreflect.Message
 
 // Deprecated: Use GetEdgeScanApplicationTaskRequest.ProtoReflect.Descriptor instead.
 func (*GetEdgeScanApplicationTaskRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{47}
+	return file_liaison_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *GetEdgeScanApplicationTaskRequest) GetEdgeId() uint64 {
@@ -3106,7 +3473,7 @@ type GetEdgeScanApplicationTaskResponse struct {
 
 func (x *GetEdgeScanApplicationTaskResponse) Reset() {
 	*x = GetEdgeScanApplicationTaskResponse{}
-	mi := &file_liaison_proto_msgTypes[48]
+	mi := &file_liaison_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3118,7 +3485,7 @@ func (x *GetEdgeScanApplicationTaskResponse) String() string {
 func (*GetEdgeScanApplicationTaskResponse) ProtoMessage() {}
 
 func (x *GetEdgeScanApplicationTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[48]
+	mi := &file_liaison_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3131,7 +3498,7 @@ func (x *GetEdgeScanApplicationTaskResponse) ProtoReflect() protoreflect.Message
 
 // Deprecated: Use GetEdgeScanApplicationTaskResponse.ProtoReflect.Descriptor instead.
 func (*GetEdgeScanApplicationTaskResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{48}
+	return file_liaison_proto_rawDescGZIP(), []int{51}
 }
 
 func (x *GetEdgeScanApplicationTaskResponse) GetCode() int32 {
@@ -3169,7 +3536,7 @@ type User struct {
 
 func (x *User) Reset() {
 	*x = User{}
-	mi := &file_liaison_proto_msgTypes[49]
+	mi := &file_liaison_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3181,7 +3548,7 @@ func (x *User) String() string {
 func (*User) ProtoMessage() {}
 
 func (x *User) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[49]
+	mi := &file_liaison_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3194,7 +3561,7 @@ func (x *User) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use User.ProtoReflect.Descriptor instead.
 func (*User) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{49}
+	return file_liaison_proto_rawDescGZIP(), []int{52}
 }
 
 func (x *User) GetId() uint64 {
@@ -3243,7 +3610,7 @@ type LoginRequest struct {
 
 func (x *LoginRequest) Reset() {
 	*x = LoginRequest{}
-	mi := &file_liaison_proto_msgTypes[50]
+	mi := &file_liaison_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3255,7 +3622,7 @@ func (x *LoginRequest) String() string {
 func (*LoginRequest) ProtoMessage() {}
 
 func (x *LoginRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[50]
+	mi := &file_liaison_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3268,7 +3635,7 @@ func (x *LoginRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
 func (*LoginRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{50}
+	return file_liaison_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *LoginRequest) GetEmail() string {
@@ -3297,7 +3664,7 @@ type LoginResponse struct {
 
 func (x *LoginResponse) Reset() {
 	*x = LoginResponse{}
-	mi := &file_liaison_proto_msgTypes[51]
+	mi := &file_liaison_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3309,7 +3676,7 @@ func (x *LoginResponse) String() string {
 func (*LoginResponse) ProtoMessage() {}
 
 func (x *LoginResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[51]
+	mi := &file_liaison_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3322,7 +3689,7 @@ func (x *LoginResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
 func (*LoginResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{51}
+	return file_liaison_proto_rawDescGZIP(), []int{54}
 }
 
 func (x *LoginResponse) GetCode() int32 {
@@ -3356,7 +3723,7 @@ type LoginData struct {
 
 func (x *LoginData) Reset() {
 	*x = LoginData{}
-	mi := &file_liaison_proto_msgTypes[52]
+	mi := &file_liaison_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3368,7 +3735,7 @@ func (x *LoginData) String() string {
 func (*LoginData) ProtoMessage() {}
 
 func (x *LoginData) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[52]
+	mi := &file_liaison_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3381,7 +3748,7 @@ func (x *LoginData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoginData.ProtoReflect.Descriptor instead.
 func (*LoginData) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{52}
+	return file_liaison_proto_rawDescGZIP(), []int{55}
 }
 
 func (x *LoginData) GetToken() string {
@@ -3407,7 +3774,7 @@ type GetProfileRequest struct {
 
 func (x *GetProfileRequest) Reset() {
 	*x = GetProfileRequest{}
-	mi := &file_liaison_proto_msgTypes[53]
+	mi := &file_liaison_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3419,7 +3786,7 @@ func (x *GetProfileRequest) String() string {
 func (*GetProfileRequest) ProtoMessage() {}
 
 func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[53]
+	mi := &file_liaison_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3432,7 +3799,7 @@ func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetProfileRequest.ProtoReflect.Descriptor instead.
 func (*GetProfileRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{53}
+	return file_liaison_proto_rawDescGZIP(), []int{56}
 }
 
 // 获取用户信息响应
@@ -3447,7 +3814,7 @@ type GetProfileResponse struct {
 
 func (x *GetProfileResponse) Reset() {
 	*x = GetProfileResponse{}
-	mi := &file_liaison_proto_msgTypes[54]
+	mi := &file_liaison_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3459,7 +3826,7 @@ func (x *GetProfileResponse) String() string {
 func (*GetProfileResponse) ProtoMessage() {}
 
 func (x *GetProfileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[54]
+	mi := &file_liaison_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3472,7 +3839,7 @@ func (x *GetProfileResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetProfileResponse.ProtoReflect.Descriptor instead.
 func (*GetProfileResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{54}
+	return file_liaison_proto_rawDescGZIP(), []int{57}
 }
 
 func (x *GetProfileResponse) GetCode() int32 {
@@ -3505,7 +3872,7 @@ type LogoutRequest struct {
 
 func (x *LogoutRequest) Reset() {
 	*x = LogoutRequest{}
-	mi := &file_liaison_proto_msgTypes[55]
+	mi := &file_liaison_proto_msgTypes[58]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3517,7 +3884,7 @@ func (x *LogoutRequest) String() string {
 func (*LogoutRequest) ProtoMessage() {}
 
 func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[55]
+	mi := &file_liaison_proto_msgTypes[58]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3530,7 +3897,7 @@ func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogoutRequest.ProtoReflect.Descriptor instead.
 func (*LogoutRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{55}
+	return file_liaison_proto_rawDescGZIP(), []int{58}
 }
 
 // 登出响应
@@ -3544,7 +3911,7 @@ type LogoutResponse struct {
 
 func (x *LogoutResponse) Reset() {
 	*x = LogoutResponse{}
-	mi := &file_liaison_proto_msgTypes[56]
+	mi := &file_liaison_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3556,7 +3923,7 @@ func (x *LogoutResponse) String() string {
 func (*LogoutResponse) ProtoMessage() {}
 
 func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[56]
+	mi := &file_liaison_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3569,7 +3936,7 @@ func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogoutResponse.ProtoReflect.Descriptor instead.
 func (*LogoutResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{56}
+	return file_liaison_proto_rawDescGZIP(), []int{59}
 }
 
 func (x *LogoutResponse) GetCode() int32 {
@@ -3597,7 +3964,7 @@ type ChangePasswordRequest struct {
 
 func (x *ChangePasswordRequest) Reset() {
 	*x = ChangePasswordRequest{}
-	mi := &file_liaison_proto_msgTypes[57]
+	mi := &file_liaison_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3609,7 +3976,7 @@ func (x *ChangePasswordRequest) String() string {
 func (*ChangePasswordRequest) ProtoMessage() {}
 
 func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[57]
+	mi := &file_liaison_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3622,7 +3989,7 @@ func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChangePasswordRequest.ProtoReflect.Descriptor instead.
 func (*ChangePasswordRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{57}
+	return file_liaison_proto_rawDescGZIP(), []int{60}
 }
 
 func (x *ChangePasswordRequest) GetOldPassword() string {
@@ -3650,7 +4017,7 @@ type ChangePasswordResponse struct {
 
 func (x *ChangePasswordResponse) Reset() {
 	*x = ChangePasswordResponse{}
-	mi := &file_liaison_proto_msgTypes[58]
+	mi := &file_liaison_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3662,7 +4029,7 @@ func (x *ChangePasswordResponse) String() string {
 func (*ChangePasswordResponse) ProtoMessage() {}
 
 func (x *ChangePasswordResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[58]
+	mi := &file_liaison_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3675,7 +4042,7 @@ func (x *ChangePasswordResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChangePasswordResponse.ProtoReflect.Descriptor instead.
 func (*ChangePasswordResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{58}
+	return file_liaison_proto_rawDescGZIP(), []int{61}
 }
 
 func (x *ChangePasswordResponse) GetCode() int32 {
@@ -3701,7 +4068,7 @@ type HealthRequest struct {
 
 func (x *HealthRequest) Reset() {
 	*x = HealthRequest{}
-	mi := &file_liaison_proto_msgTypes[59]
+	mi := &file_liaison_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3713,7 +4080,7 @@ func (x *HealthRequest) String() string {
 func (*HealthRequest) ProtoMessage() {}
 
 func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[59]
+	mi := &file_liaison_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3726,7 +4093,7 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
 func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{59}
+	return file_liaison_proto_rawDescGZIP(), []int{62}
 }
 
 // 健康检查响应
@@ -3741,7 +4108,7 @@ type HealthResponse struct {
 
 func (x *HealthResponse) Reset() {
 	*x = HealthResponse{}
-	mi := &file_liaison_proto_msgTypes[60]
+	mi := &file_liaison_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3753,7 +4120,7 @@ func (x *HealthResponse) String() string {
 func (*HealthResponse) ProtoMessage() {}
 
 func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[60]
+	mi := &file_liaison_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3766,7 +4133,7 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
 func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{60}
+	return file_liaison_proto_rawDescGZIP(), []int{63}
 }
 
 func (x *HealthResponse) GetCode() int32 {
@@ -3792,20 +4159,27 @@ func (x *HealthResponse) GetStatus() string {
 
 // 流量监控
 type TrafficMetric struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	ApplicationId uint64                 `protobuf:"varint,2,opt,name=application_id,proto3" json:"application_id,omitempty"`
-	ProxyId       uint64                 `protobuf:"varint,3,opt,name=proxy_id,proto3" json:"proxy_id,omitempty"`
-	Timestamp     string                 `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`  // 时间戳（分钟级别）
-	BytesIn       int32                  `protobuf:"varint,5,opt,name=bytes_in,proto3" json:"bytes_in,omitempty"`   // 入站流量（字节，平均每分钟）
-	BytesOut      int32                  `protobuf:"varint,6,opt,name=bytes_out,proto3" json:"bytes_out,omitempty"` // 出站流量（字节，平均每分钟）
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Id                    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ApplicationId         uint64                 `protobuf:"varint,2,opt,name=application_id,proto3" json:"application_id,omitempty"`
+	ProxyId               uint64                 `protobuf:"varint,3,opt,name=proxy_id,proto3" json:"proxy_id,omitempty"`
+	Timestamp             string                 `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                               // 时间戳（分钟级别）
+	BytesIn               int32                  `protobuf:"varint,5,opt,name=bytes_in,proto3" json:"bytes_in,omitempty"`                                // 入站流量（字节，平均每分钟）
+	BytesOut              int32                  `protobuf:"varint,6,opt,name=bytes_out,proto3" json:"bytes_out,omitempty"`                              // 出站流量（字节，平均每分钟）
+	WireBytesIn           int32                  `protobuf:"varint,7,opt,name=wire_bytes_in,proto3" json:"wire_bytes_in,omitempty"`                      // entry 与 edge 之间实际传输的入站流量（压缩后）
+	WireBytesOut          int32                  `protobuf:"varint,8,opt,name=wire_bytes_out,proto3" json:"wire_bytes_out,omitempty"`                    // entry 与 edge 之间实际传输的出站流量（压缩后）
+	CacheHits             int32                  `protobuf:"varint,9,opt,name=cache_hits,proto3" json:"cache_hits,omitempty"`                            // HTTP 响应缓存命中的请求数
+	CacheMisses           int32                  `protobuf:"varint,10,opt,name=cache_misses,proto3" json:"cache_misses,omitempty"`                       // 可以缓存但没有命中的请求数
+	CacheHitRatio         float64                `protobuf:"fixed64,11,opt,name=cache_hit_ratio,proto3" json:"cache_hit_ratio,omitempty"`                // 命中率，没有可缓存的请求时为 0
+	CacheSavedBytes       int32                  `protobuf:"varint,12,opt,name=cache_saved_bytes,proto3" json:"cache_saved_bytes,omitempty"`             // 由缓存提供、不经 edge 传输的字节数
+	CompressionSavedBytes int32                  `protobuf:"varint,13,opt,name=compression_saved_bytes,proto3" json:"compression_saved_bytes,omitempty"` // 响应压缩减少的字节数
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
 }
 
 func (x *TrafficMetric) Reset() {
 	*x = TrafficMetric{}
-	mi := &file_liaison_proto_msgTypes[61]
+	mi := &file_liaison_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3817,7 +4191,7 @@ func (x *TrafficMetric) String() string {
 func (*TrafficMetric) ProtoMessage() {}
 
 func (x *TrafficMetric) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[61]
+	mi := &file_liaison_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3830,7 +4204,7 @@ func (x *TrafficMetric) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TrafficMetric.ProtoReflect.Descriptor instead.
 func (*TrafficMetric) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{61}
+	return file_liaison_proto_rawDescGZIP(), []int{64}
 }
 
 func (x *TrafficMetric) GetId() uint64 {
@@ -3875,6 +4249,55 @@ func (x *TrafficMetric) GetBytesOut() int32 {
 	return 0
 }
 
+func (x *TrafficMetric) GetWireBytesIn() int32 {
+	if x != nil {
+		return x.WireBytesIn
+	}
+	return 0
+}
+
+func (x *TrafficMetric) GetWireBytesOut() int32 {
+	if x != nil {
+		return x.WireBytesOut
+	}
+	return 0
+}
+
+func (x *TrafficMetric) GetCacheHits() int32 {
+	if x != nil {
+		return x.CacheHits
+	}
+	return 0
+}
+
+func (x *TrafficMetric) GetCacheMisses() int32 {
+	if x != nil {
+		return x.CacheMisses
+	}
+	return 0
+}
+
+func (x *TrafficMetric) GetCacheHitRatio() float64 {
+	if x != nil {
+		return x.CacheHitRatio
+	}
+	return 0
+}
+
+func (x *TrafficMetric) GetCacheSavedBytes() int32 {
+	if x != nil {
+		return x.CacheSavedBytes
+	}
+	return 0
+}
+
+func (x *TrafficMetric) GetCompressionSavedBytes() int32 {
+	if x != nil {
+		return x.CompressionSavedBytes
+	}
+	return 0
+}
+
 type TrafficMetrics struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Metrics       []*TrafficMetric       `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
@@ -3884,7 +4307,7 @@ type TrafficMetrics struct {
 
 func (x *TrafficMetrics) Reset() {
 	*x = TrafficMetrics{}
-	mi := &file_liaison_proto_msgTypes[62]
+	mi := &file_liaison_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3896,7 +4319,7 @@ func (x *TrafficMetrics) String() string {
 func (*TrafficMetrics) ProtoMessage() {}
 
 func (x *TrafficMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[62]
+	mi := &file_liaison_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3909,7 +4332,7 @@ func (x *TrafficMetrics) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TrafficMetrics.ProtoReflect.Descriptor instead.
 func (*TrafficMetrics) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{62}
+	return file_liaison_proto_rawDescGZIP(), []int{65}
 }
 
 func (x *TrafficMetrics) GetMetrics() []*TrafficMetric {
@@ -3932,7 +4355,7 @@ type ListTrafficMetricsRequest struct {
 
 func (x *ListTrafficMetricsRequest) Reset() {
 	*x = ListTrafficMetricsRequest{}
-	mi := &file_liaison_proto_msgTypes[63]
+	mi := &file_liaison_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3944,7 +4367,7 @@ func (x *ListTrafficMetricsRequest) String() string {
 func (*ListTrafficMetricsRequest) ProtoMessage() {}
 
 func (x *ListTrafficMetricsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[63]
+	mi := &file_liaison_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3957,7 +4380,7 @@ func (x *ListTrafficMetricsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListTrafficMetricsRequest.ProtoReflect.Descriptor instead.
 func (*ListTrafficMetricsRequest) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{63}
+	return file_liaison_proto_rawDescGZIP(), []int{66}
 }
 
 func (x *ListTrafficMetricsRequest) GetApplicationIds() []uint64 {
@@ -4006,7 +4429,7 @@ type ListTrafficMetricsResponse struct {
 
 func (x *ListTrafficMetricsResponse) Reset() {
 	*x = ListTrafficMetricsResponse{}
-	mi := &file_liaison_proto_msgTypes[64]
+	mi := &file_liaison_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -4018,7 +4441,7 @@ func (x *ListTrafficMetricsResponse) String() string {
 func (*ListTrafficMetricsResponse) ProtoMessage() {}
 
 func (x *ListTrafficMetricsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_liaison_proto_msgTypes[64]
+	mi := &file_liaison_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -4031,7 +4454,7 @@ func (x *ListTrafficMetricsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListTrafficMetricsResponse.ProtoReflect.Descriptor instead.
 func (*ListTrafficMetricsResponse) Descriptor() ([]byte, []int) {
-	return file_liaison_proto_rawDescGZIP(), []int{64}
+	return file_liaison_proto_rawDescGZIP(), []int{67}
 }
 
 func (x *ListTrafficMetricsResponse) GetCode() int32 {
@@ -4175,7 +4598,7 @@ const file_liaison_proto_rawDesc = "" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\"D\n" +
 	"\x14DeleteDeviceResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\xbc\x02\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xe5\x03\n" +
 	"\vApplication\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x18\n" +
 	"\aedge_id\x18\x02 \x01(\x04R\aedge_id\x12\x1f\n" +
@@ -4192,10 +4615,25 @@ const file_liaison_proto_rawDesc = "" +
 	"\n" +
 	"updated_at\x18\n" +
 	" \x01(\tR\n" +
-	"updated_at\"V\n" +
+	"updated_at\x12\x1a\n" +
+	"\bedge_ids\x18\f \x03(\x04R\bedge_ids\x12 \n" +
+	"\vedge_policy\x18\r \x01(\tR\vedge_policy\x12,\n" +
+	"\atargets\x18\x0e \x03(\v2\x12.ApplicationTargetR\atargets\x12;\n" +
+	"\fhealth_check\x18\x0f \x01(\v2\x17.ApplicationHealthCheckR\fhealth_check\"}\n" +
+	"\x11ApplicationTarget\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\x12\x16\n" +
+	"\x06health\x18\x04 \x01(\tR\x06health\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\x86\x01\n" +
+	"\x16ApplicationHealthCheck\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\"\n" +
+	"\finterval_sec\x18\x03 \x01(\x05R\finterval_sec\x12 \n" +
+	"\vtimeout_sec\x18\x04 \x01(\x05R\vtimeout_sec\"V\n" +
 	"\fApplications\x12\x14\n" +
 	"\x05total\x18\x01 \x01(\x05R\x05total\x120\n" +
-	"\fapplications\x18\x02 \x03(\v2\f.ApplicationR\fapplications\"\xeb\x01\n" +
+	"\fapplications\x18\x02 \x03(\v2\f.ApplicationR\fapplications\"\x94\x03\n" +
 	"\x18CreateApplicationRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\a \x01(\tR\vdescription\x12\x0e\n" +
@@ -4203,7 +4641,12 @@ const file_liaison_proto_rawDesc = "" +
 	"\x04port\x18\x03 \x01(\x05R\x04port\x12*\n" +
 	"\x10application_type\x18\x04 \x01(\tR\x10application_type\x12\x18\n" +
 	"\aedge_id\x18\x05 \x01(\x04R\aedge_id\x12!\n" +
-	"\tdevice_id\x18\x06 \x01(\x04H\x00R\tdevice_id\x88\x01\x01B\f\n" +
+	"\tdevice_id\x18\x06 \x01(\x04H\x00R\tdevice_id\x88\x01\x01\x12\x1a\n" +
+	"\bedge_ids\x18\b \x03(\x04R\bedge_ids\x12 \n" +
+	"\vedge_policy\x18\t \x01(\tR\vedge_policy\x12,\n" +
+	"\atargets\x18\n" +
+	" \x03(\v2\x12.ApplicationTargetR\atargets\x12;\n" +
+	"\fhealth_check\x18\v \x01(\v2\x17.ApplicationHealthCheckR\fhealth_checkB\f\n" +
 	"\n" +
 	"_device_id\"k\n" +
 	"\x19CreateApplicationResponse\x12\x12\n" +
@@ -4225,11 +4668,16 @@ const file_liaison_proto_rawDesc = "" +
 	"\x18ListApplicationsResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
-	"\x04data\x18\x03 \x01(\v2\r.ApplicationsR\x04data\"`\n" +
+	"\x04data\x18\x03 \x01(\v2\r.ApplicationsR\x04data\"\x9e\x02\n" +
 	"\x18UpdateApplicationRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\"k\n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bedge_ids\x18\x04 \x03(\x04R\bedge_ids\x12%\n" +
+	"\vedge_policy\x18\x05 \x01(\tH\x00R\vedge_policy\x88\x01\x01\x12,\n" +
+	"\atargets\x18\x06 \x03(\v2\x12.ApplicationTargetR\atargets\x12;\n" +
+	"\fhealth_check\x18\a \x01(\v2\x17.ApplicationHealthCheckR\fhealth_checkB\x0e\n" +
+	"\f_edge_policy\"k\n" +
 	"\x19UpdateApplicationResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12 \n" +
@@ -4238,7 +4686,7 @@ const file_liaison_proto_rawDesc = "" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\"I\n" +
 	"\x19DeleteApplicationResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\x89\x02\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xf9\x02\n" +
 	"\x05Proxy\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
@@ -4254,7 +4702,19 @@ const file_liaison_proto_rawDesc = "" +
 	"updated_at\x12\x1e\n" +
 	"\n" +
 	"access_url\x18\t \x01(\tR\n" +
-	"access_url\"A\n" +
+	"access_url\x12*\n" +
+	"\n" +
+	"rate_limit\x18\n" +
+	" \x01(\v2\n" +
+	".RateLimitR\n" +
+	"rate_limit\x12\x1a\n" +
+	"\bhostname\x18\v \x01(\tR\bhostname\x12&\n" +
+	"\x0ecertificate_id\x18\f \x01(\x04R\x0ecertificate_id\"\x8f\x02\n" +
+	"\tRateLimit\x122\n" +
+	"\x14upload_bytes_per_sec\x18\x01 \x01(\x03R\x14upload_bytes_per_sec\x126\n" +
+	"\x16download_bytes_per_sec\x18\x02 \x01(\x03R\x16download_bytes_per_sec\x12H\n" +
+	"\x1fper_client_upload_bytes_per_sec\x18\x03 \x01(\x03R\x1fper_client_upload_bytes_per_sec\x12L\n" +
+	"!per_client_download_bytes_per_sec\x18\x04 \x01(\x03R!per_client_download_bytes_per_sec\"A\n" +
 	"\aProxies\x12\x14\n" +
 	"\x05total\x18\x01 \x01(\x05R\x05total\x12 \n" +
 	"\aproxies\x18\x02 \x03(\v2\x06.ProxyR\aproxies\"Z\n" +
@@ -4265,22 +4725,33 @@ const file_liaison_proto_rawDesc = "" +
 	"\x13ListProxiesResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
-	"\x04data\x18\x03 \x01(\v2\b.ProxiesR\x04data\"\x86\x01\n" +
+	"\x04data\x18\x03 \x01(\v2\b.ProxiesR\x04data\"\xce\x01\n" +
 	"\x12CreateProxyRequest\x12&\n" +
 	"\x0eapplication_id\x18\x01 \x01(\x04R\x0eapplication_id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
 	"\x04port\x18\x03 \x01(\x05R\x04port\x12 \n" +
-	"\vdescription\x18\x04 \x01(\tR\vdescription\"_\n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12*\n" +
+	"\n" +
+	"rate_limit\x18\x05 \x01(\v2\n" +
+	".RateLimitR\n" +
+	"rate_limit\x12\x1a\n" +
+	"\bhostname\x18\x06 \x01(\tR\bhostname\"_\n" +
 	"\x13CreateProxyResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1a\n" +
-	"\x04data\x18\x03 \x01(\v2\x06.ProxyR\x04data\"\x86\x01\n" +
+	"\x04data\x18\x03 \x01(\v2\x06.ProxyR\x04data\"\xe0\x01\n" +
 	"\x12UpdateProxyRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
 	"\x04port\x18\x03 \x01(\x05R\x04port\x12\x16\n" +
 	"\x06status\x18\x04 \x01(\tR\x06status\x12 \n" +
-	"\vdescription\x18\x05 \x01(\tR\vdescription\"_\n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12*\n" +
+	"\n" +
+	"rate_limit\x18\x06 \x01(\v2\n" +
+	".RateLimitR\n" +
+	"rate_limit\x12\x1f\n" +
+	"\bhostname\x18\a \x01(\tH\x00R\bhostname\x88\x01\x01B\v\n" +
+	"\t_hostname\"_\n" +
 	"\x13UpdateProxyResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1a\n" +
@@ -4355,14 +4826,24 @@ const file_liaison_proto_rawDesc = "" +
 	"\x0eHealthResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\tR\x06status\"\xbb\x01\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"\xdf\x03\n" +
 	"\rTrafficMetric\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12&\n" +
 	"\x0eapplication_id\x18\x02 \x01(\x04R\x0eapplication_id\x12\x1a\n" +
 	"\bproxy_id\x18\x03 \x01(\x04R\bproxy_id\x12\x1c\n" +
 	"\ttimestamp\x18\x04 \x01(\tR\ttimestamp\x12\x1a\n" +
 	"\bbytes_in\x18\x05 \x01(\x05R\bbytes_in\x12\x1c\n" +
-	"\tbytes_out\x18\x06 \x01(\x05R\tbytes_out\":\n" +
+	"\tbytes_out\x18\x06 \x01(\x05R\tbytes_out\x12$\n" +
+	"\rwire_bytes_in\x18\a \x01(\x05R\rwire_bytes_in\x12&\n" +
+	"\x0ewire_bytes_out\x18\b \x01(\x05R\x0ewire_bytes_out\x12\x1e\n" +
+	"\n" +
+	"cache_hits\x18\t \x01(\x05R\n" +
+	"cache_hits\x12\"\n" +
+	"\fcache_misses\x18\n" +
+	" \x01(\x05R\fcache_misses\x12(\n" +
+	"\x0fcache_hit_ratio\x18\v \x01(\x01R\x0fcache_hit_ratio\x12,\n" +
+	"\x11cache_saved_bytes\x18\f \x01(\x05R\x11cache_saved_bytes\x128\n" +
+	"\x17compression_saved_bytes\x18\r \x01(\x05R\x17compression_saved_bytes\":\n" +
 	"\x0eTrafficMetrics\x12(\n" +
 	"\ametrics\x18\x01 \x03(\v2\x0e.TrafficMetricR\ametrics\"\xb5\x01\n" +
 	"\x19ListTrafficMetricsRequest\x12(\n" +
@@ -4420,7 +4901,7 @@ func file_liaison_proto_rawDescGZIP() []byte {
 	return file_liaison_proto_rawDescData
 }
 
-var file_liaison_proto_msgTypes = make([]protoimpl.MessageInfo, 65)
+var file_liaison_proto_msgTypes = make([]protoimpl.MessageInfo, 68)
 var file_liaison_proto_goTypes = []any{
 	(*Edge)(nil),                                  // 0: Edge
 	(*Edges)(nil),                                 // 1: Edges
@@ -4447,46 +4928,49 @@ var file_liaison_proto_goTypes = []any{
 	(*DeleteDeviceRequest)(nil),                   // 22: DeleteDeviceRequest
 	(*DeleteDeviceResponse)(nil),                  // 23: DeleteDeviceResponse
 	(*Application)(nil),                           // 24: Application
-	(*Applications)(nil),                          // 25: Applications
-	(*CreateApplicationRequest)(nil),              // 26: CreateApplicationRequest
-	(*CreateApplicationResponse)(nil),             // 27: CreateApplicationResponse
-	(*ListApplicationsRequest)(nil),               // 28: ListApplicationsRequest
-	(*ListApplicationsResponse)(nil),              // 29: ListApplicationsResponse
-	(*UpdateApplicationRequest)(nil),              // 30: UpdateApplicationRequest
-	(*UpdateApplicationResponse)(nil),             // 31: UpdateApplicationResponse
-	(*DeleteApplicationRequest)(nil),              // 32: DeleteApplicationRequest
-	(*DeleteApplicationResponse)(nil),             // 33: DeleteApplicationResponse
-	(*Proxy)(nil),                                 // 34: Proxy
-	(*Proxies)(nil),                               // 35: Proxies
-	(*ListProxiesRequest)(nil),                    // 36: ListProxiesRequest
-	(*ListProxiesResponse)(nil),                   // 37: ListProxiesResponse
-	(*CreateProxyRequest)(nil),                    // 38: CreateProxyRequest
-	(*CreateProxyResponse)(nil),                   // 39: CreateProxyResponse
-	(*UpdateProxyRequest)(nil),                    // 40: UpdateProxyRequest
-	(*UpdateProxyResponse)(nil),                   // 41: UpdateProxyResponse
-	(*DeleteProxyRequest)(nil),                    // 42: DeleteProxyRequest
-	(*DeleteProxyResponse)(nil),                   // 43: DeleteProxyResponse
-	(*EdgeScanApplicationTask)(nil),               // 44: EdgeScanApplicationTask
-	(*CreateEdgeScanApplicationTaskRequest)(nil),  // 45: CreateEdgeScanApplicationTaskRequest
-	(*CreateEdgeScanApplicationTaskResponse)(nil), // 46: CreateEdgeScanApplicationTaskResponse
-	(*GetEdgeScanApplicationTaskRequest)(nil),     // 47: GetEdgeScanApplicationTaskRequest
-	(*GetEdgeScanApplicationTaskResponse)(nil),    // 48: GetEdgeScanApplicationTaskResponse
-	(*User)(nil),                                  // 49: User
-	(*LoginRequest)(nil),                          // 50: LoginRequest
-	(*LoginResponse)(nil),                         // 51: LoginResponse
-	(*LoginData)(nil),                             // 52: LoginData
-	(*GetProfileRequest)(nil),                     // 53: GetProfileRequest
-	(*GetProfileResponse)(nil),                    // 54: GetProfileResponse
-	(*LogoutRequest)(nil),                         // 55: LogoutRequest
-	(*LogoutResponse)(nil),                        // 56: LogoutResponse
-	(*ChangePasswordRequest)(nil),                 // 57: ChangePasswordRequest
-	(*ChangePasswordResponse)(nil),                // 58: ChangePasswordResponse
-	(*HealthRequest)(nil),                         // 59: HealthRequest
-	(*HealthResponse)(nil),                        // 60: HealthResponse
-	(*TrafficMetric)(nil),                         // 61: TrafficMetric
-	(*TrafficMetrics)(nil),                        // 62: TrafficMetrics
-	(*ListTrafficMetricsRequest)(nil),             // 63: ListTrafficMetricsRequest
-	(*ListTrafficMetricsResponse)(nil),            // 64: ListTrafficMetricsResponse
+	(*ApplicationTarget)(nil),                     // 25: ApplicationTarget
+	(*ApplicationHealthCheck)(nil),                // 26: ApplicationHealthCheck
+	(*Applications)(nil),                          // 27: Applications
+	(*CreateApplicationRequest)(nil),              // 28: CreateApplicationRequest
+	(*CreateApplicationResponse)(nil),             // 29: CreateApplicationResponse
+	(*ListApplicationsRequest)(nil),               // 30: ListApplicationsRequest
+	(*ListApplicationsResponse)(nil),              // 31: ListApplicationsResponse
+	(*UpdateApplicationRequest)(nil),              // 32: UpdateApplicationRequest
+	(*UpdateApplicationResponse)(nil),             // 33: UpdateApplicationResponse
+	(*DeleteApplicationRequest)(nil),              // 34: DeleteApplicationRequest
+	(*DeleteApplicationResponse)(nil),             // 35: DeleteApplicationResponse
+	(*Proxy)(nil),                                 // 36: Proxy
+	(*RateLimit)(nil),                             // 37: RateLimit
+	(*Proxies)(nil),                               // 38: Proxies
+	(*ListProxiesRequest)(nil),                    // 39: ListProxiesRequest
+	(*ListProxiesResponse)(nil),                   // 40: ListProxiesResponse
+	(*CreateProxyRequest)(nil),                    // 41: CreateProxyRequest
+	(*CreateProxyResponse)(nil),                   // 42: CreateProxyResponse
+	(*UpdateProxyRequest)(nil),                    // 43: UpdateProxyRequest
+	(*UpdateProxyResponse)(nil),                   // 44: UpdateProxyResponse
+	(*DeleteProxyRequest)(nil),                    // 45: DeleteProxyRequest
+	(*DeleteProxyResponse)(nil),                   // 46: DeleteProxyResponse
+	(*EdgeScanApplicationTask)(nil),               // 47: EdgeScanApplicationTask
+	(*CreateEdgeScanApplicationTaskRequest)(nil),  // 48: CreateEdgeScanApplicationTaskRequest
+	(*CreateEdgeScanApplicationTaskResponse)(nil), // 49: CreateEdgeScanApplicationTaskResponse
+	(*GetEdgeScanApplicationTaskRequest)(nil),     // 50: GetEdgeScanApplicationTaskRequest
+	(*GetEdgeScanApplicationTaskResponse)(nil),    // 51: GetEdgeScanApplicationTaskResponse
+	(*User)(nil),                                  // 52: User
+	(*LoginRequest)(nil),                          // 53: LoginRequest
+	(*LoginResponse)(nil),                         // 54: LoginResponse
+	(*LoginData)(nil),                             // 55: LoginData
+	(*GetProfileRequest)(nil),                     // 56: GetProfileRequest
+	(*GetProfileResponse)(nil),                    // 57: GetProfileResponse
+	(*LogoutRequest)(nil),                         // 58: LogoutRequest
+	(*LogoutResponse)(nil),                        // 59: LogoutResponse
+	(*ChangePasswordRequest)(nil),                 // 60: ChangePasswordRequest
+	(*ChangePasswordResponse)(nil),                // 61: ChangePasswordResponse
+	(*HealthRequest)(nil),                         // 62: HealthRequest
+	(*HealthResponse)(nil),                        // 63: HealthResponse
+	(*TrafficMetric)(nil),                         // 64: TrafficMetric
+	(*TrafficMetrics)(nil),                        // 65: TrafficMetrics
+	(*ListTrafficMetricsRequest)(nil),             // 66: ListTrafficMetricsRequest
+	(*ListTrafficMetricsResponse)(nil),            // 67: ListTrafficMetricsResponse
 }
 var file_liaison_proto_depIdxs = []int32{
 	13, // 0: Edge.device:type_name -> Device
@@ -4501,77 +4985,86 @@ var file_liaison_proto_depIdxs = []int32{
 	15, // 9: ListDevicesResponse.data:type_name -> Devices
 	13, // 10: UpdateDeviceResponse.data:type_name -> Device
 	13, // 11: Application.device:type_name -> Device
-	34, // 12: Application.proxy:type_name -> Proxy
-	24, // 13: Applications.applications:type_name -> Application
-	24, // 14: CreateApplicationResponse.data:type_name -> Application
-	25, // 15: ListApplicationsResponse.data:type_name -> Applications
-	24, // 16: UpdateApplicationResponse.data:type_name -> Application
-	24, // 17: Proxy.application:type_name -> Application
-	34, // 18: Proxies.proxies:type_name -> Proxy
-	35, // 19: ListProxiesResponse.data:type_name -> Proxies
-	34, // 20: CreateProxyResponse.data:type_name -> Proxy
-	34, // 21: UpdateProxyResponse.data:type_name -> Proxy
-	44, // 22: GetEdgeScanApplicationTaskResponse.data:type_name -> EdgeScanApplicationTask
-	52, // 23: LoginResponse.data:type_name -> LoginData
-	49, // 24: LoginData.user:type_name -> User
-	49, // 25: GetProfileResponse.data:type_name -> User
-	61, // 26: TrafficMetrics.metrics:type_name -> TrafficMetric
-	62, // 27: ListTrafficMetricsResponse.data:type_name -> TrafficMetrics
-	3,  // 28: LiaisonService.CreateEdge:input_type -> CreateEdgeRequest
-	5,  // 29: LiaisonService.GetEdge:input_type -> GetEdgeRequest
-	7,  // 30: LiaisonService.ListEdges:input_type -> ListEdgesRequest
-	9,  // 31: LiaisonService.UpdateEdge:input_type -> UpdateEdgeRequest
-	11, // 32: LiaisonService.DeleteEdge:input_type -> DeleteEdgeRequest
-	18, // 33: LiaisonService.ListDevices:input_type -> ListDevicesRequest
-	20, // 34: LiaisonService.UpdateDevice:input_type -> UpdateDeviceRequest
-	16, // 35: LiaisonService.GetDevice:input_type -> GetDeviceRequest
-	22, // 36: LiaisonService.DeleteDevice:input_type -> DeleteDeviceRequest
-	26, // 37: LiaisonService.CreateApplication:input_type -> CreateApplicationRequest
-	28, // 38: LiaisonService.ListApplications:input_type -> ListApplicationsRequest
-	30, // 39: LiaisonService.UpdateApplication:input_type -> UpdateApplicationRequest
-	32, // 40: LiaisonService.DeleteApplication:input_type -> DeleteApplicationRequest
-	36, // 41: LiaisonService.ListProxies:input_type -> ListProxiesRequest
-	38, // 42: LiaisonService.CreateProxy:input_type -> CreateProxyRequest
-	40, // 43: LiaisonService.UpdateProxy:input_type -> UpdateProxyRequest
-	42, // 44: LiaisonService.DeleteProxy:input_type -> DeleteProxyRequest
-	45, // 45: LiaisonService.CreateEdgeScanApplicationTask:input_type -> CreateEdgeScanApplicationTaskRequest
-	47, // 46: LiaisonService.GetEdgeScanApplicationTask:input_type -> GetEdgeScanApplicationTaskRequest
-	50, // 47: LiaisonService.Login:input_type -> LoginRequest
-	55, // 48: LiaisonService.Logout:input_type -> LogoutRequest
-	53, // 49: LiaisonService.GetProfile:input_type -> GetProfileRequest
-	57, // 50: LiaisonService.ChangePassword:input_type -> ChangePasswordRequest
-	59, // 51: LiaisonService.Health:input_type -> HealthRequest
-	63, // 52: LiaisonService.ListTrafficMetrics:input_type -> ListTrafficMetricsRequest
-	4,  // 53: LiaisonService.CreateEdge:output_type -> CreateEdgeResponse
-	6,  // 54: LiaisonService.GetEdge:output_type -> GetEdgeResponse
-	8,  // 55: LiaisonService.ListEdges:output_type -> ListEdgesResponse
-	10, // 56: LiaisonService.UpdateEdge:output_type -> UpdateEdgeResponse
-	12, // 57: LiaisonService.DeleteEdge:output_type -> DeleteEdgeResponse
-	19, // 58: LiaisonService.ListDevices:output_type -> ListDevicesResponse
-	21, // 59: LiaisonService.UpdateDevice:output_type -> UpdateDeviceResponse
-	17, // 60: LiaisonService.GetDevice:output_type -> GetDeviceResponse
-	23, // 61: LiaisonService.DeleteDevice:output_type -> DeleteDeviceResponse
-	27, // 62: LiaisonService.CreateApplication:output_type -> CreateApplicationResponse
-	29, // 63: LiaisonService.ListApplications:output_type -> ListApplicationsResponse
-	31, // 64: LiaisonService.UpdateApplication:output_type -> UpdateApplicationResponse
-	33, // 65: LiaisonService.DeleteApplication:output_type -> DeleteApplicationResponse
-	37, // 66: LiaisonService.ListProxies:output_type -> ListProxiesResponse
-	39, // 67: LiaisonService.CreateProxy:output_type -> CreateProxyResponse
-	41, // 68: LiaisonService.UpdateProxy:output_type -> UpdateProxyResponse
-	43, // 69: LiaisonService.DeleteProxy:output_type -> DeleteProxyResponse
-	46, // 70: LiaisonService.CreateEdgeScanApplicationTask:output_type -> CreateEdgeScanApplicationTaskResponse
-	48, // 71: LiaisonService.GetEdgeScanApplicationTask:output_type -> GetEdgeScanApplicationTaskResponse
-	51, // 72: LiaisonService.Login:output_type -> LoginResponse
-	56, // 73: LiaisonService.Logout:output_type -> LogoutResponse
-	54, // 74: LiaisonService.GetProfile:output_type -> GetProfileResponse
-	58, // 75: LiaisonService.ChangePassword:output_type -> ChangePasswordResponse
-	60, // 76: LiaisonService.Health:output_type -> HealthResponse
-	64, // 77: LiaisonService.ListTrafficMetrics:output_type -> ListTrafficMetricsResponse
-	53, // [53:78] is the sub-list for method output_type
-	28, // [28:53] is the sub-list for method input_type
-	28, // [28:28] is the sub-list for extension type_name
-	28, // [28:28] is the sub-list for extension extendee
-	0,  // [0:28] is the sub-list for field type_name
+	36, // 12: Application.proxy:type_name -> Proxy
+	25, // 13: Application.targets:type_name -> ApplicationTarget
+	26, // 14: Application.health_check:type_name -> ApplicationHealthCheck
+	24, // 15: Applications.applications:type_name -> Application
+	25, // 16: CreateApplicationRequest.targets:type_name -> ApplicationTarget
+	26, // 17: CreateApplicationRequest.health_check:type_name -> ApplicationHealthCheck
+	24, // 18: CreateApplicationResponse.data:type_name -> Application
+	27, // 19: ListApplicationsResponse.data:type_name -> Applications
+	25, // 20: UpdateApplicationRequest.targets:type_name -> ApplicationTarget
+	26, // 21: UpdateApplicationRequest.health_check:type_name -> ApplicationHealthCheck
+	24, // 22: UpdateApplicationResponse.data:type_name -> Application
+	24, // 23: Proxy.application:type_name -> Application
+	37, // 24: Proxy.rate_limit:type_name -> RateLimit
+	36, // 25: Proxies.proxies:type_name -> Proxy
+	38, // 26: ListProxiesResponse.data:type_name -> Proxies
+	37, // 27: CreateProxyRequest.rate_limit:type_name -> RateLimit
+	36, // 28: CreateProxyResponse.data:type_name -> Proxy
+	37, // 29: UpdateProxyRequest.rate_limit:type_name -> RateLimit
+	36, // 30: UpdateProxyResponse.data:type_name -> Proxy
+	47, // 31: GetEdgeScanApplicationTaskResponse.data:type_name -> EdgeScanApplicationTask
+	55, // 32: LoginResponse.data:type_name -> LoginData
+	52, // 33: LoginData.user:type_name -> User
+	52, // 34: GetProfileResponse.data:type_name -> User
+	64, // 35: TrafficMetrics.metrics:type_name -> TrafficMetric
+	65, // 36: ListTrafficMetricsResponse.data:type_name -> TrafficMetrics
+	3,  // 37: LiaisonService.CreateEdge:input_type -> CreateEdgeRequest
+	5,  // 38: LiaisonService.GetEdge:input_type -> GetEdgeRequest
+	7,  // 39: LiaisonService.ListEdges:input_type -> ListEdgesRequest
+	9,  // 40: LiaisonService.UpdateEdge:input_type -> UpdateEdgeRequest
+	11, // 41: LiaisonService.DeleteEdge:input_type -> DeleteEdgeRequest
+	18, // 42: LiaisonService.ListDevices:input_type -> ListDevicesRequest
+	20, // 43: LiaisonService.UpdateDevice:input_type -> UpdateDeviceRequest
+	16, // 44: LiaisonService.GetDevice:input_type -> GetDeviceRequest
+	22, // 45: LiaisonService.DeleteDevice:input_type -> DeleteDeviceRequest
+	28, // 46: LiaisonService.CreateApplication:input_type -> CreateApplicationRequest
+	30, // 47: LiaisonService.ListApplications:input_type -> ListApplicationsRequest
+	32, // 48: LiaisonService.UpdateApplication:input_type -> UpdateApplicationRequest
+	34, // 49: LiaisonService.DeleteApplication:input_type -> DeleteApplicationRequest
+	39, // 50: LiaisonService.ListProxies:input_type -> ListProxiesRequest
+	41, // 51: LiaisonService.CreateProxy:input_type -> CreateProxyRequest
+	43, // 52: LiaisonService.UpdateProxy:input_type -> UpdateProxyRequest
+	45, // 53: LiaisonService.DeleteProxy:input_type -> DeleteProxyRequest
+	48, // 54: LiaisonService.CreateEdgeScanApplicationTask:input_type -> CreateEdgeScanApplicationTaskRequest
+	50, // 55: LiaisonService.GetEdgeScanApplicationTask:input_type -> GetEdgeScanApplicationTaskRequest
+	53, // 56: LiaisonService.Login:input_type -> LoginRequest
+	58, // 57: LiaisonService.Logout:input_type -> LogoutRequest
+	56, // 58: LiaisonService.GetProfile:input_type -> GetProfileRequest
+	60, // 59: LiaisonService.ChangePassword:input_type -> ChangePasswordRequest
+	62, // 60: LiaisonService.Health:input_type -> HealthRequest
+	66, // 61: LiaisonService.ListTrafficMetrics:input_type -> ListTrafficMetricsRequest
+	4,  // 62: LiaisonService.CreateEdge:output_type -> CreateEdgeResponse
+	6,  // 63: LiaisonService.GetEdge:output_type -> GetEdgeResponse
+	8,  // 64: LiaisonService.ListEdges:output_type -> ListEdgesResponse
+	10, // 65: LiaisonService.UpdateEdge:output_type -> UpdateEdgeResponse
+	12, // 66: LiaisonService.DeleteEdge:output_type -> DeleteEdgeResponse
+	19, // 67: LiaisonService.ListDevices:output_type -> ListDevicesResponse
+	21, // 68: LiaisonService.UpdateDevice:output_type -> UpdateDeviceResponse
+	17, // 69: LiaisonService.GetDevice:output_type -> GetDeviceResponse
+	23, // 70: LiaisonService.DeleteDevice:output_type -> DeleteDeviceResponse
+	29, // 71: LiaisonService.CreateApplication:output_type -> CreateApplicationResponse
+	31, // 72: LiaisonService.ListApplications:output_type -> ListApplicationsResponse
+	33, // 73: LiaisonService.UpdateApplication:output_type -> UpdateApplicationResponse
+	35, // 74: LiaisonService.DeleteApplication:output_type -> DeleteApplicationResponse
+	40, // 75: LiaisonService.ListProxies:output_type -> ListProxiesResponse
+	42, // 76: LiaisonService.CreateProxy:output_type -> CreateProxyResponse
+	44, // 77: LiaisonService.UpdateProxy:output_type -> UpdateProxyResponse
+	46, // 78: LiaisonService.DeleteProxy:output_type -> DeleteProxyResponse
+	49, // 79: LiaisonService.CreateEdgeScanApplicationTask:output_type -> CreateEdgeScanApplicationTaskResponse
+	51, // 80: LiaisonService.GetEdgeScanApplicationTask:output_type -> GetEdgeScanApplicationTaskResponse
+	54, // 81: LiaisonService.Login:output_type -> LoginResponse
+	59, // 82: LiaisonService.Logout:output_type -> LogoutResponse
+	57, // 83: LiaisonService.GetProfile:output_type -> GetProfileResponse
+	61, // 84: LiaisonService.ChangePassword:output_type -> ChangePasswordResponse
+	63, // 85: LiaisonService.Health:output_type -> HealthResponse
+	67, // 86: LiaisonService.ListTrafficMetrics:output_type -> ListTrafficMetricsResponse
+	62, // [62:87] is the sub-list for method output_type
+	37, // [37:62] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_liaison_proto_init() }
@@ -4579,15 +5072,17 @@ func file_liaison_proto_init() {
 	if File_liaison_proto != nil {
 		return
 	}
-	file_liaison_proto_msgTypes[26].OneofWrappers = []any{}
 	file_liaison_proto_msgTypes[28].OneofWrappers = []any{}
+	file_liaison_proto_msgTypes[30].OneofWrappers = []any{}
+	file_liaison_proto_msgTypes[32].OneofWrappers = []any{}
+	file_liaison_proto_msgTypes[43].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_liaison_proto_rawDesc), len(file_liaison_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   65,
+			NumMessages:   68,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
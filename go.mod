@@ -3,9 +3,11 @@ module github.com/liaisonio/liaison
 go 1.24.0
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/go-kratos/kratos/v2 v2.7.2
 	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/jumboframes/armorigo v0.5.0-rc.2
+	github.com/klauspost/compress v1.18.0
 	github.com/mattn/go-sqlite3 v1.14.17
 	github.com/pion/transport/v2 v2.2.10
 	github.com/shirou/gopsutil v3.21.11+incompatible
@@ -15,6 +17,8 @@ require (
 	github.com/swaggo/swag v1.16.5
 	github.com/yusufpapurcu/wmi v1.2.4
 	golang.org/x/crypto v0.45.0
+	golang.org/x/net v0.47.0
+	golang.org/x/time v0.14.0
 	google.golang.org/genproto/googleapis/api v0.0.0-20240304212257-790db918fca8
 	google.golang.org/grpc v1.62.1
 	google.golang.org/protobuf v1.36.9
@@ -56,7 +60,6 @@ require (
 	github.com/tklauser/go-sysconf v0.3.12 // indirect
 	github.com/tklauser/numcpus v0.6.1 // indirect
 	golang.org/x/mod v0.29.0 // indirect
-	golang.org/x/net v0.47.0 // indirect
 	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
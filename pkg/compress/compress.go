@@ -0,0 +1,104 @@
+// Package compress 实现 entry 与 edge 之间 stream 的可选压缩：
+// entry 在 Dst 中请求压缩算法，edge 在 DialResult 中确认后，双方在握手之后的数据上对称地套上压缩层。
+// 每次 Write 都会立即 flush，交互式流量（SSH 等）不会被缓冲延迟。
+package compress
+
+import (
+	"io"
+	"net"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// 算法，与代理配置中的取值一致
+const (
+	Zstd   = "zstd"
+	Snappy = "snappy"
+)
+
+// ValidAlgorithm 检查算法取值是否合法，空字符串表示不压缩
+func ValidAlgorithm(algo string) bool {
+	return algo == "" || algo == Zstd || algo == Snappy
+}
+
+type writeFlusher interface {
+	io.Writer
+	Flush() error
+	Close() error
+}
+
+// Conn 压缩后的连接，Read 解压、Write 压缩，其余方法透传给底层连接
+type Conn struct {
+	net.Conn
+	r io.Reader
+	// 写端不是并发安全的，Write 和 Close 串行
+	mu sync.Mutex
+	w  writeFlusher
+}
+
+// Wrap 按算法包装连接，algo 为空时原样返回
+func Wrap(conn net.Conn, algo string) (net.Conn, error) {
+	switch algo {
+	case "":
+		return conn, nil
+	case Zstd:
+		// 单线程、低内存模式：每个连接一对编解码器，不额外起 goroutine。
+		// 单线程的解码器不持有 goroutine，无需 Close，避免与并发的 Read 竞争
+		w, err := zstd.NewWriter(conn,
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithEncoderLevel(zstd.SpeedFastest),
+			zstd.WithWindowSize(1<<20),
+			zstd.WithLowerEncoderMem(true))
+		if err != nil {
+			return nil, err
+		}
+		r, err := zstd.NewReader(conn,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderLowmem(true))
+		if err != nil {
+			_ = w.Close()
+			return nil, err
+		}
+		return &Conn{Conn: conn, r: r, w: w}, nil
+	case Snappy:
+		return &Conn{Conn: conn, r: snappy.NewReader(conn), w: snappy.NewBufferedWriter(conn)}, nil
+	default:
+		return nil, &UnsupportedError{Algorithm: algo}
+	}
+}
+
+// UnsupportedError 不认识的压缩算法
+type UnsupportedError struct {
+	Algorithm string
+}
+
+func (e *UnsupportedError) Error() string {
+	return "unsupported compression: " + e.Algorithm
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *Conn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n, err := c.w.Write(b)
+	if err != nil {
+		return n, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Close 写出压缩流的结尾后关闭底层连接
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	_ = c.w.Close()
+	c.mu.Unlock()
+	return c.Conn.Close()
+}
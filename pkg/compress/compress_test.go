@@ -0,0 +1,62 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// 交互式往返：每次 Write 的数据必须能被对端立即读到，不能等后续数据或关闭
+func TestWrapRoundTrip(t *testing.T) {
+	for _, algo := range []string{"", Zstd, Snappy} {
+		a, b := net.Pipe()
+		ca, err := Wrap(a, algo)
+		if err != nil {
+			t.Fatalf("%s: wrap: %v", algo, err)
+		}
+		cb, err := Wrap(b, algo)
+		if err != nil {
+			t.Fatalf("%s: wrap: %v", algo, err)
+		}
+		// 对端回显
+		go func() {
+			_, _ = io.Copy(cb, cb)
+		}()
+
+		for _, msg := range [][]byte{[]byte("ls -la\n"), bytes.Repeat([]byte("log line "), 4096)} {
+			// net.Pipe 没有缓冲，写和读必须并发进行
+			errCh := make(chan error, 1)
+			go func(msg []byte) {
+				_, err := ca.Write(msg)
+				errCh <- err
+			}(msg)
+			_ = a.SetReadDeadline(time.Now().Add(2 * time.Second))
+			got := make([]byte, len(msg))
+			if _, err := io.ReadFull(ca, got); err != nil {
+				t.Fatalf("%s: read echo: %v", algo, err)
+			}
+			if err := <-errCh; err != nil {
+				t.Fatalf("%s: write: %v", algo, err)
+			}
+			if !bytes.Equal(got, msg) {
+				t.Fatalf("%s: echo mismatch", algo)
+			}
+		}
+		_ = ca.Close()
+		_ = cb.Close()
+	}
+}
+
+func TestWrapUnsupported(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	if _, err := Wrap(a, "lz4"); err == nil {
+		t.Fatal("expected error for unsupported algorithm")
+	}
+	if !ValidAlgorithm("") || !ValidAlgorithm(Snappy) || ValidAlgorithm("gzip") {
+		t.Fatal("ValidAlgorithm mismatch")
+	}
+}
@@ -54,6 +54,15 @@ type Manager struct {
 	Auth Auth        `yaml:"auth,omitempty" json:"auth"`
 }
 
+// AllowedDestinations 本地的目的地址白名单，edge 在拨号前强制检查。
+// 三个列表都为空表示不限制；否则目的主机必须命中 CIDRs 或 Hosts，
+// 且（Ports 非空时）端口必须落在 Ports 中。
+type AllowedDestinations struct {
+	CIDRs []string `yaml:"cidrs,omitempty" json:"cidrs"` // 例如 192.168.1.0/24
+	Ports []string `yaml:"ports,omitempty" json:"ports"` // 例如 22、8000-8100
+	Hosts []string `yaml:"hosts,omitempty" json:"hosts"` // 例如 nas.lan、*.home.arpa
+}
+
 type Log struct {
 	Level    string `yaml:"level"`
 	File     string `yaml:"file"`
@@ -62,9 +71,10 @@ type Log struct {
 }
 
 type Configuration struct {
-	Daemon  Daemon  `yaml:"daemon,omitempty" json:"daemon"`
-	Manager Manager `yaml:"manager,omitempty" json:"manager"`
-	Log     Log     `yaml:"log,omitempty" json:"log"`
+	Daemon              Daemon              `yaml:"daemon,omitempty" json:"daemon"`
+	Manager             Manager             `yaml:"manager,omitempty" json:"manager"`
+	AllowedDestinations AllowedDestinations `yaml:"allowed_destinations,omitempty" json:"allowed_destinations"`
+	Log                 Log                 `yaml:"log,omitempty" json:"log"`
 }
 
 func initCmd() error {
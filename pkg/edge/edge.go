@@ -8,6 +8,7 @@ import (
 	"github.com/jumboframes/armorigo/log"
 	"github.com/liaisonio/liaison/pkg/edge/config"
 	"github.com/liaisonio/liaison/pkg/edge/frontierbound"
+	"github.com/liaisonio/liaison/pkg/edge/healthcheck"
 	"github.com/liaisonio/liaison/pkg/edge/pinger"
 	"github.com/liaisonio/liaison/pkg/edge/proxy"
 	"github.com/liaisonio/liaison/pkg/edge/reporter"
@@ -54,7 +55,7 @@ func NewEdge() (*Edge, error) {
 		return nil, err
 	}
 
-	_, err = proxy.NewProxy(frontierBound)
+	edgeProxy, err := proxy.NewProxy(frontierBound, &config.Conf.AllowedDestinations)
 	if err != nil {
 		log.Errorf("init proxy error: %v", err)
 		return nil, err
@@ -78,6 +79,12 @@ func NewEdge() (*Edge, error) {
 		return nil, err
 	}
 
+	_, err = healthcheck.NewHealthChecker(frontierBound, edgeProxy)
+	if err != nil {
+		log.Errorf("init health checker error: %v", err)
+		return nil, err
+	}
+
 	return &Edge{
 		frontierBound: frontierBound,
 	}, nil
@@ -45,8 +45,9 @@ func NewFrontierBound(conf *config.Configuration) (FrontierBound, error) {
 	}
 
 	meta := proto.Meta{
-		AccessKey: conf.Manager.Auth.AccessKey,
-		SecretKey: conf.Manager.Auth.SecretKey,
+		AccessKey:       conf.Manager.Auth.AccessKey,
+		SecretKey:       conf.Manager.Auth.SecretKey,
+		ProtocolVersion: proto.ProtocolVersion,
 	}
 	data, err := json.Marshal(meta)
 	if err != nil {
@@ -0,0 +1,300 @@
+// Package healthcheck 在 edge 上对应用的后端做主动健康检查，并把结果上报给 manager。
+//
+// 检查任务由 manager 下发（edge 定期拉取），只覆盖关联了本 edge 且开启了健康检查的应用。
+// 连续 riseThreshold 次成功才判为健康，连续 fallThreshold 次失败才判为不健康，避免抖动。
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/edge/frontierbound"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+const (
+	// pullInterval 拉取检查任务的间隔
+	pullInterval = 30 * time.Second
+	// tickInterval 调度检查的粒度
+	tickInterval = time.Second
+
+	riseThreshold = 2
+	fallThreshold = 2
+)
+
+type HealthChecker interface {
+	Close() error
+}
+
+// DestinationChecker 按本地 allowed_destinations 校验地址，由 edge 的 proxy 实现
+type DestinationChecker interface {
+	CheckDestination(ctx context.Context, addr string) (string, error)
+}
+
+type healthChecker struct {
+	frontierBound frontierbound.FrontierBound
+	destinations  DestinationChecker
+	edgeID        uint64
+	stopCh        chan struct{}
+
+	// 只在 loop goroutine 中访问
+	checks map[uint]*appCheck // application id -> check
+}
+
+type appCheck struct {
+	conf    proto.HealthCheck
+	nextAt  time.Time
+	targets map[string]*targetState // addr -> state
+}
+
+type targetState struct {
+	known   bool
+	healthy bool
+	streak  int // 与当前结论相反的连续结果数
+	lastErr string
+}
+
+func NewHealthChecker(frontierBound frontierbound.FrontierBound, destinations DestinationChecker) (HealthChecker, error) {
+	edgeID, err := frontierBound.EdgeID()
+	if err != nil {
+		return nil, err
+	}
+	hc := &healthChecker{
+		frontierBound: frontierBound,
+		destinations:  destinations,
+		edgeID:        edgeID,
+		stopCh:        make(chan struct{}),
+		checks:        make(map[uint]*appCheck),
+	}
+	go hc.loop(context.Background())
+	return hc, nil
+}
+
+func (hc *healthChecker) Close() error {
+	close(hc.stopCh)
+	return nil
+}
+
+func (hc *healthChecker) loop(ctx context.Context) {
+	pullTicker := time.NewTicker(pullInterval)
+	defer pullTicker.Stop()
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	// 立即拉取一次
+	hc.pull(ctx)
+
+	for {
+		select {
+		case <-hc.stopCh:
+			return
+		case <-pullTicker.C:
+			hc.pull(ctx)
+		case <-ticker.C:
+			hc.runDue(ctx)
+		}
+	}
+}
+
+// pull 拉取检查任务，配置没变的应用保留已有的检查状态
+func (hc *healthChecker) pull(ctx context.Context) {
+	data, err := json.Marshal(proto.GetEdgeHealthChecksRequest{EdgeID: hc.edgeID})
+	if err != nil {
+		log.Errorf("marshal get edge health checks request error: %v", err)
+		return
+	}
+	rsp, err := hc.frontierBound.Call(ctx, "get_edge_health_checks", hc.frontierBound.NewRequest(data))
+	if err != nil {
+		log.Errorf("call get_edge_health_checks error: %v", err)
+		return
+	}
+	if rsp.Error() != nil {
+		log.Errorf("get_edge_health_checks error: %v", rsp.Error())
+		return
+	}
+	var response proto.GetEdgeHealthChecksResponse
+	if err := json.Unmarshal(rsp.Data(), &response); err != nil {
+		log.Errorf("unmarshal get edge health checks response error: %v", err)
+		return
+	}
+
+	checks := make(map[uint]*appCheck, len(response.Checks))
+	for _, conf := range response.Checks {
+		check := &appCheck{conf: conf, targets: make(map[string]*targetState, len(conf.Targets))}
+		old, ok := hc.checks[conf.ApplicationID]
+		sameProbe := ok && old.conf.Type == conf.Type && old.conf.Path == conf.Path
+		if ok {
+			check.nextAt = old.nextAt
+		}
+		for _, addr := range conf.Targets {
+			if st, found := old.targetState(addr); sameProbe && found {
+				check.targets[addr] = st
+			} else {
+				check.targets[addr] = &targetState{}
+			}
+		}
+		checks[conf.ApplicationID] = check
+	}
+	hc.checks = checks
+}
+
+func (c *appCheck) targetState(addr string) (*targetState, bool) {
+	if c == nil {
+		return nil, false
+	}
+	st, ok := c.targets[addr]
+	return st, ok
+}
+
+// runDue 检查所有到期的应用，同一轮内的后端并发检查
+func (hc *healthChecker) runDue(ctx context.Context) {
+	now := time.Now()
+	type probe struct {
+		check *appCheck
+		addr  string
+		err   error
+	}
+	probes := make([]*probe, 0)
+	due := make([]*appCheck, 0)
+	for _, check := range hc.checks {
+		if now.Before(check.nextAt) {
+			continue
+		}
+		check.nextAt = now.Add(time.Duration(check.conf.IntervalSec) * time.Second)
+		due = append(due, check)
+		for addr := range check.targets {
+			probes = append(probes, &probe{check: check, addr: addr})
+		}
+	}
+	if len(probes) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p *probe) {
+			defer wg.Done()
+			p.err = hc.probe(ctx, p.check.conf, p.addr)
+		}(p)
+	}
+	wg.Wait()
+
+	for _, p := range probes {
+		p.check.targets[p.addr].update(p.err)
+	}
+	for _, check := range due {
+		hc.report(ctx, check)
+	}
+}
+
+// update 记录一次检查结果，达到阈值时翻转结论
+func (st *targetState) update(err error) {
+	ok := err == nil
+	if err != nil {
+		st.lastErr = err.Error()
+	}
+	if !st.known {
+		st.known, st.healthy, st.streak = true, ok, 0
+		return
+	}
+	if ok == st.healthy {
+		st.streak = 0
+		return
+	}
+	st.streak++
+	threshold := fallThreshold
+	if ok {
+		threshold = riseThreshold
+	}
+	if st.streak >= threshold {
+		st.healthy, st.streak = ok, 0
+	}
+}
+
+func (hc *healthChecker) probe(ctx context.Context, conf proto.HealthCheck, addr string) error {
+	timeout := time.Duration(conf.TimeoutSec) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 健康检查也不能连接 allowed_destinations 之外的地址
+	dialAddr, err := hc.destinations.CheckDestination(ctx, addr)
+	if err != nil {
+		return err
+	}
+	switch conf.Type {
+	case proto.HealthCheckHTTP:
+		return probeHTTP(ctx, dialAddr, conf.Path)
+	default:
+		dialer := &net.Dialer{}
+		conn, err := dialer.DialContext(ctx, "tcp", dialAddr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+func probeHTTP(ctx context.Context, addr, path string) error {
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "liaison-edge-healthcheck")
+	client := &http.Client{
+		Transport: &http.Transport{DisableKeepAlives: true},
+		// 3xx 本身就算健康，不跟随跳转
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status %d", rsp.StatusCode)
+	}
+	return nil
+}
+
+func (hc *healthChecker) report(ctx context.Context, check *appCheck) {
+	request := proto.ReportTargetHealthRequest{
+		EdgeID:        hc.edgeID,
+		ApplicationID: check.conf.ApplicationID,
+		Targets:       make([]proto.TargetHealth, 0, len(check.targets)),
+	}
+	for addr, st := range check.targets {
+		if !st.known {
+			continue
+		}
+		th := proto.TargetHealth{Addr: addr, Healthy: st.healthy}
+		if !st.healthy {
+			th.Error = st.lastErr
+		}
+		request.Targets = append(request.Targets, th)
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		log.Errorf("marshal report target health request error: %v", err)
+		return
+	}
+	rsp, err := hc.frontierBound.Call(ctx, "report_target_health", hc.frontierBound.NewRequest(data))
+	if err != nil {
+		log.Errorf("call report_target_health error: %v", err)
+		return
+	}
+	if rsp.Error() != nil {
+		log.Errorf("report_target_health error: %v", rsp.Error())
+	}
+}
@@ -0,0 +1,38 @@
+package healthcheck
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTargetStateHysteresis(t *testing.T) {
+	fail := errors.New("connection refused")
+	st := &targetState{}
+
+	// 第一次结果直接生效
+	st.update(nil)
+	if !st.known || !st.healthy {
+		t.Fatalf("first success should mark healthy: %+v", st)
+	}
+
+	st.update(fail)
+	if !st.healthy {
+		t.Fatalf("a single failure should not flip the state")
+	}
+	st.update(fail)
+	if st.healthy || st.lastErr != fail.Error() {
+		t.Fatalf("%d failures should mark unhealthy: %+v", fallThreshold, st)
+	}
+
+	// 成功之间夹杂失败时重新计数
+	st.update(nil)
+	st.update(fail)
+	st.update(nil)
+	if st.healthy {
+		t.Fatalf("interrupted successes should not mark healthy")
+	}
+	st.update(nil)
+	if !st.healthy {
+		t.Fatalf("%d successes should mark healthy", riseThreshold)
+	}
+}
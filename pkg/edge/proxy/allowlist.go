@@ -0,0 +1,173 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/liaisonio/liaison/pkg/edge/config"
+)
+
+// errDestinationDenied 目的地址不在本地白名单中
+var errDestinationDenied = errors.New("destination not allowed")
+
+type portRange struct {
+	from, to int
+}
+
+// allowlist 是 liaison-edge.yaml 中 allowed_destinations 的解析结果。
+// 它是站点所有者对本 edge 可达范围的最终控制，manager 下发的 Dst 无法绕过。
+type allowlist struct {
+	nets  []*net.IPNet
+	ports []portRange
+	hosts []string // 小写；"*.example.com" 形式的通配保存为 ".example.com"
+}
+
+// newAllowlist 解析配置；配置为空时返回 nil，表示不限制
+func newAllowlist(conf *config.AllowedDestinations) (*allowlist, error) {
+	if conf == nil || (len(conf.CIDRs) == 0 && len(conf.Ports) == 0 && len(conf.Hosts) == 0) {
+		return nil, nil
+	}
+	al := &allowlist{}
+	for _, c := range conf.CIDRs {
+		_, ipnet, err := net.ParseCIDR(c)
+		if err != nil {
+			// 允许直接写单个 IP
+			ip := net.ParseIP(c)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", c, err)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 128
+			}
+			ipnet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		}
+		al.nets = append(al.nets, ipnet)
+	}
+	for _, p := range conf.Ports {
+		pr, err := parsePortRange(p)
+		if err != nil {
+			return nil, err
+		}
+		al.ports = append(al.ports, pr)
+	}
+	for _, h := range conf.Hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" {
+			continue
+		}
+		al.hosts = append(al.hosts, strings.TrimPrefix(h, "*"))
+	}
+	return al, nil
+}
+
+// parsePortRange 解析 "22" 或 "8000-8100"
+func parsePortRange(s string) (portRange, error) {
+	s = strings.TrimSpace(s)
+	from, to := s, s
+	if i := strings.Index(s, "-"); i >= 0 {
+		from, to = s[:i], s[i+1:]
+	}
+	f, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return portRange{}, fmt.Errorf("invalid port %q", s)
+	}
+	t, err := strconv.Atoi(strings.TrimSpace(to))
+	if err != nil {
+		return portRange{}, fmt.Errorf("invalid port %q", s)
+	}
+	if f < 1 || t > 65535 || f > t {
+		return portRange{}, fmt.Errorf("invalid port range %q", s)
+	}
+	return portRange{from: f, to: t}, nil
+}
+
+// check 校验目的地址，返回实际应该拨号的地址。
+// 主机名如果只靠 CIDR 放行，会在这里解析并返回解析后的 IP，
+// 避免校验和拨号之间 DNS 结果变化（DNS rebinding）绕过白名单。
+func (al *allowlist) check(ctx context.Context, addr string) (string, error) {
+	if al == nil {
+		return addr, nil
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid address %q", errDestinationDenied, addr)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid port %q", errDestinationDenied, portStr)
+	}
+	if !al.portAllowed(port) {
+		return "", fmt.Errorf("%w: port %d", errDestinationDenied, port)
+	}
+	// 未配置任何主机限制时，只限制端口
+	if len(al.nets) == 0 && len(al.hosts) == 0 {
+		return addr, nil
+	}
+	if al.hostAllowed(host) {
+		return addr, nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if al.ipAllowed(ip) {
+			return addr, nil
+		}
+		return "", fmt.Errorf("%w: %s", errDestinationDenied, host)
+	}
+	if len(al.nets) == 0 {
+		return "", fmt.Errorf("%w: %s", errDestinationDenied, host)
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	for _, ip := range ips {
+		if !al.ipAllowed(ip.IP) {
+			return "", fmt.Errorf("%w: %s resolves to %s", errDestinationDenied, host, ip.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("%w: %s has no address", errDestinationDenied, host)
+	}
+	return net.JoinHostPort(ips[0].IP.String(), portStr), nil
+}
+
+func (al *allowlist) portAllowed(port int) bool {
+	if len(al.ports) == 0 {
+		return true
+	}
+	for _, pr := range al.ports {
+		if port >= pr.from && port <= pr.to {
+			return true
+		}
+	}
+	return false
+}
+
+func (al *allowlist) hostAllowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, h := range al.hosts {
+		if strings.HasPrefix(h, ".") {
+			if strings.HasSuffix(host, h) {
+				return true
+			}
+			continue
+		}
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+func (al *allowlist) ipAllowed(ip net.IP) bool {
+	for _, n := range al.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/edge/config"
+)
+
+func TestAllowlistEmptyAllowsAll(t *testing.T) {
+	al, err := newAllowlist(&config.AllowedDestinations{})
+	if err != nil {
+		t.Fatalf("newAllowlist error: %s", err)
+	}
+	addr, err := al.check(context.Background(), "10.0.0.1:22")
+	if err != nil || addr != "10.0.0.1:22" {
+		t.Fatalf("expected allow, got %q, %v", addr, err)
+	}
+}
+
+func TestAllowlistCheck(t *testing.T) {
+	al, err := newAllowlist(&config.AllowedDestinations{
+		CIDRs: []string{"192.168.1.0/24", "10.0.0.5"},
+		Ports: []string{"22", "8000-8100"},
+		Hosts: []string{"nas.lan", "*.home.arpa"},
+	})
+	if err != nil {
+		t.Fatalf("newAllowlist error: %s", err)
+	}
+
+	allowed := []string{
+		"192.168.1.10:22",
+		"10.0.0.5:8080",
+		"nas.lan:8000",
+		"NAS.lan:22",
+		"printer.home.arpa:8100",
+	}
+	for _, addr := range allowed {
+		if _, err := al.check(context.Background(), addr); err != nil {
+			t.Fatalf("expected %s allowed, got %v", addr, err)
+		}
+	}
+
+	denied := []string{
+		"192.168.2.10:22",
+		"192.168.1.10:80",
+		"10.0.0.6:22",
+		"[::1]:22",
+	}
+	for _, addr := range denied {
+		if _, err := al.check(context.Background(), addr); !errors.Is(err, errDestinationDenied) {
+			t.Fatalf("expected %s denied, got %v", addr, err)
+		}
+	}
+}
+
+func TestAllowlistHostsOnly(t *testing.T) {
+	// 没有 CIDR 时主机名不会被解析，未命中 hosts 直接拒绝
+	al, err := newAllowlist(&config.AllowedDestinations{
+		Hosts: []string{"*.home.arpa"},
+	})
+	if err != nil {
+		t.Fatalf("newAllowlist error: %s", err)
+	}
+	for _, addr := range []string{"evil.lan:22", "home.arpa.evil.com:22", "192.168.1.1:22"} {
+		if _, err := al.check(context.Background(), addr); !errors.Is(err, errDestinationDenied) {
+			t.Fatalf("expected %s denied, got %v", addr, err)
+		}
+	}
+	if _, err := al.check(context.Background(), "a.b.home.arpa:443"); err != nil {
+		t.Fatalf("expected allow, got %v", err)
+	}
+}
+
+func TestAllowlistInvalidConfig(t *testing.T) {
+	bad := []*config.AllowedDestinations{
+		{CIDRs: []string{"not-a-cidr"}},
+		{Ports: []string{"70000"}},
+		{Ports: []string{"100-10"}},
+	}
+	for _, conf := range bad {
+		if _, err := newAllowlist(conf); err == nil {
+			t.Fatalf("expected error for %+v", conf)
+		}
+	}
+}
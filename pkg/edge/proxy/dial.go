@@ -0,0 +1,43 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// dialTimeout edge 拨号后端的超时，需小于 entry 等待拨号结果的超时
+const dialTimeout = 10 * time.Second
+
+func dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// classifyDialError 把拨号错误归类成回传给 entry 的结果码
+func classifyDialError(err error) proto.DialCode {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return proto.DialCodeTimeout
+		}
+		return proto.DialCodeDNSFailure
+	}
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return proto.DialCodeRefused
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return proto.DialCodeUnreachable
+	case errors.Is(err, context.DeadlineExceeded):
+		return proto.DialCodeTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return proto.DialCodeTimeout
+	}
+	return proto.DialCodeFailed
+}
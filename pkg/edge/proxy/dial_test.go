@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// timeoutError 模拟 net.Dialer 的超时错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyDialError(t *testing.T) {
+	opErr := func(err error) error {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}
+	}
+	cases := []struct {
+		name string
+		err  error
+		code proto.DialCode
+	}{
+		{"refused", opErr(syscall.ECONNREFUSED), proto.DialCodeRefused},
+		{"host unreachable", opErr(syscall.EHOSTUNREACH), proto.DialCodeUnreachable},
+		{"net unreachable", opErr(syscall.ENETUNREACH), proto.DialCodeUnreachable},
+		{"dns", &net.DNSError{Err: "no such host", Name: "backend.invalid", IsNotFound: true}, proto.DialCodeDNSFailure},
+		{"dns timeout", &net.DNSError{Err: "timeout", Name: "backend.local", IsTimeout: true}, proto.DialCodeTimeout},
+		{"deadline", fmt.Errorf("dial: %w", context.DeadlineExceeded), proto.DialCodeTimeout},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}, proto.DialCodeTimeout},
+		{"other", errors.New("something else"), proto.DialCodeFailed},
+	}
+	for _, c := range cases {
+		if code := classifyDialError(c.err); code != c.code {
+			t.Errorf("%s: code = %s, want %s", c.name, code, c.code)
+		}
+	}
+}
+
+// 后端拒绝连接时，entry 的握手收到带类别的拨号结果
+func TestProxyDialRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	p := &proxy{}
+	conn, done := openStream(p)
+	defer conn.Close()
+
+	_, err = proto.Handshake(conn, &proto.Dst{
+		Addr:    addr,
+		Network: proto.NetworkTCP,
+		Version: proto.ProtocolVersion,
+	}, 2*time.Second)
+	var dialErr *proto.DialError
+	if !errors.As(err, &dialErr) {
+		t.Fatalf("handshake err = %v, want a dial error", err)
+	}
+	if dialErr.Result.Code != proto.DialCodeRefused {
+		t.Errorf("code = %s, want refused", dialErr.Result.Code)
+	}
+	<-done
+}
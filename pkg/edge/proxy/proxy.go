@@ -2,8 +2,7 @@ package proxy
 
 import (
 	"context"
-	"encoding/binary"
-	"encoding/json"
+	"errors"
 	"io"
 	"net"
 	"strings"
@@ -11,19 +10,33 @@ import (
 
 	"github.com/jumboframes/armorigo/log"
 	"github.com/singchia/geminio"
+	"github.com/liaisonio/liaison/pkg/compress"
+	"github.com/liaisonio/liaison/pkg/edge/config"
 	"github.com/liaisonio/liaison/pkg/edge/frontierbound"
 	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/liaisonio/liaison/pkg/proxyproto"
 )
 
-type Proxy interface{}
+type Proxy interface {
+	// CheckDestination 按本地 allowed_destinations 校验地址，返回实际应该连接的地址。
+	// edge 上其他会主动连接应用的模块（如健康检查）也要经过它
+	CheckDestination(ctx context.Context, addr string) (string, error)
+}
 
 type proxy struct {
 	frontierBound frontierbound.FrontierBound
+	// 本地目的地址白名单，nil 表示不限制
+	allowlist *allowlist
 }
 
-func NewProxy(frontierBound frontierbound.FrontierBound) (Proxy, error) {
+func NewProxy(frontierBound frontierbound.FrontierBound, allowed *config.AllowedDestinations) (Proxy, error) {
+	al, err := newAllowlist(allowed)
+	if err != nil {
+		return nil, err
+	}
 	proxy := &proxy{
 		frontierBound: frontierBound,
+		allowlist:     al,
 	}
 
 	proxy.frontierBound.RegisterStreamHandler(proxy.proxy)
@@ -31,31 +44,87 @@ func NewProxy(frontierBound frontierbound.FrontierBound) (Proxy, error) {
 	return proxy, nil
 }
 
+func (p *proxy) CheckDestination(ctx context.Context, addr string) (string, error) {
+	return p.allowlist.check(ctx, addr)
+}
+
 func (p *proxy) proxy(ctx context.Context, stream geminio.Stream) {
-	// 读取前4个字节获取meta长度
-	lengthBuf := make([]byte, 4)
-	_, err := io.ReadFull(stream, lengthBuf)
+	// 读取长度前缀的目的地址信息
+	var dst proto.Dst
+	if err := proto.ReadFrame(stream, &dst); err != nil {
+		log.Errorf("proxy stream read meta err: %s", err)
+		_ = stream.Close()
+		return
+	}
+
+	// 老版本 entry 不读取 DialResult，也不会请求压缩
+	legacy := dst.Version < proto.ProtocolDialResult
+
+	// 本地白名单：不管 manager 下发什么，站点所有者说了算
+	dialAddr, err := p.allowlist.check(ctx, dst.Addr)
 	if err != nil {
-		log.Errorf("proxy stream read meta length err: %s", err)
+		if errors.Is(err, errDestinationDenied) {
+			log.Warnf("proxy stream denied by allowed_destinations, proxy: %d, application: %d, dst: %s, err: %s",
+				dst.ProxyID, dst.ApplicationID, dst.Addr, err)
+			p.reject(stream, legacy, proto.DialCodeDenied, err)
+			return
+		}
+		log.Errorf("proxy stream check dst %s err: %s", dst.Addr, err)
+		p.reject(stream, legacy, classifyDialError(err), err)
 		return
 	}
-	length := binary.BigEndian.Uint32(lengthBuf)
-	dataBuf := make([]byte, length)
-	_, err = io.ReadFull(stream, dataBuf)
+
+	network := proto.NetworkTCP
+	if dst.Network == proto.NetworkUDP {
+		network = proto.NetworkUDP
+	}
+	conn, err := dial(ctx, network, dialAddr)
 	if err != nil {
-		log.Errorf("proxy stream read meta data err: %s", err)
+		code := classifyDialError(err)
+		log.Errorf("proxy stream dial err, proxy: %d, application: %d, dst: %s, code: %s, err: %s",
+			dst.ProxyID, dst.ApplicationID, dst.Addr, code, err)
+		p.reject(stream, legacy, code, err)
+		return
+	}
+	// 后端需要客户端真实地址时，先写 PROXY protocol 头再开始转发（仅 TCP）
+	if dst.ProxyProtocol != "" && network == proto.NetworkTCP {
+		header := proxyproto.HeaderFromAddrs(dst.ClientAddr, dst.EntryAddr)
+		if err := proxyproto.WriteHeader(conn, dst.ProxyProtocol, header); err != nil {
+			log.Errorf("proxy stream write proxy protocol header err, proxy: %d, dst: %s, err: %s",
+				dst.ProxyID, dst.Addr, err)
+			_ = conn.Close()
+			p.reject(stream, legacy, proto.DialCodeFailed, err)
+			return
+		}
+	}
+	// 压缩只用于 TCP；不认识的算法不确认，entry 会按不压缩处理
+	compression := ""
+	if network == proto.NetworkTCP && dst.Compression != "" && compress.ValidAlgorithm(dst.Compression) {
+		compression = dst.Compression
+	}
+	if legacy {
+		compression = ""
+	} else if err := proto.WriteFrame(stream, &proto.DialResult{Code: proto.DialCodeOK, Compression: compression}); err != nil {
+		log.Errorf("proxy stream write dial result err: %s", err)
+		_ = conn.Close()
+		_ = stream.Close()
 		return
 	}
 
-	var dst proto.Dst
-	if err := json.Unmarshal(dataBuf, &dst); err != nil {
-		log.Errorf("proxy stream meta unmarshal err: %s", err)
+	if dst.Network == proto.NetworkUDP {
+		p.proxyUDP(stream, conn, &dst)
 		return
 	}
+	p.proxyTCP(stream, conn, compression)
+}
 
-	conn, err := net.Dial("tcp", dst.Addr)
+// proxyTCP 在 stream 和后端连接之间双向转发，compression 非空时 stream 一侧按该算法解压/压缩
+func (p *proxy) proxyTCP(raw geminio.Stream, conn net.Conn, compression string) {
+	stream, err := compress.Wrap(raw, compression)
 	if err != nil {
-		log.Errorf("proxy stream dial err: %s", err)
+		log.Errorf("proxy stream wrap compression %s err: %s", compression, err)
+		_ = raw.Close()
+		_ = conn.Close()
 		return
 	}
 
@@ -89,6 +158,16 @@ func (p *proxy) proxy(ctx context.Context, stream geminio.Stream) {
 	wg.Wait()
 }
 
+// reject 把失败原因写回 entry 并关闭 stream，老版本 entry 只关闭 stream
+func (p *proxy) reject(stream geminio.Stream, legacy bool, code proto.DialCode, reason error) {
+	if !legacy {
+		if err := proto.WriteFrame(stream, &proto.DialResult{Code: code, Reason: reason.Error()}); err != nil {
+			log.Debugf("proxy stream write dial result err: %s", err)
+		}
+	}
+	_ = stream.Close()
+}
+
 func IsErrClosed(err error) bool {
 	if strings.Contains(err.Error(), net.ErrClosed.Error()) {
 		return true
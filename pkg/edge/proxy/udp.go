@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/singchia/geminio"
+)
+
+// udpIdleTimeout 两个方向都没有数据报超过该时间，关闭后端 socket 和 stream
+const udpIdleTimeout = 60 * time.Second
+
+// proxyUDP 把 stream 上长度前缀的数据报转发给 UDP 后端，并把后端的回包写回 stream
+func (p *proxy) proxyUDP(stream geminio.Stream, conn net.Conn, dst *proto.Dst) {
+	var lastActive int64
+	touch := func() { atomic.StoreInt64(&lastActive, time.Now().UnixNano()) }
+	touch()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		// 从 stream 读取数据报写入后端（从客户端到服务器）
+		buf := make([]byte, proto.MaxDatagramSize)
+		for {
+			n, err := proto.ReadDatagram(stream, buf)
+			if err != nil {
+				if !IsErrClosed(err) {
+					log.Debugf("read udp datagram from stream, dst: %s, err: %s", dst.Addr, err)
+				}
+				break
+			}
+			touch()
+			if _, err := conn.Write(buf[:n]); err != nil {
+				log.Debugf("write udp datagram to %s err: %s", dst.Addr, err)
+			}
+		}
+		_ = stream.Close()
+		_ = conn.Close()
+	}()
+
+	go func() {
+		defer wg.Done()
+		// 从后端读取数据报写回 stream（从服务器到客户端）
+		buf := make([]byte, proto.MaxDatagramSize)
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(udpIdleTimeout))
+			n, err := conn.Read(buf)
+			if err != nil {
+				if ne, ok := err.(net.Error); ok && ne.Timeout() {
+					// 客户端方向仍有数据，只是后端暂时没有回包
+					if time.Since(time.Unix(0, atomic.LoadInt64(&lastActive))) < udpIdleTimeout {
+						continue
+					}
+					log.Debugf("udp session to %s idle, closing", dst.Addr)
+				} else if !IsErrClosed(err) {
+					log.Debugf("read udp datagram from %s err: %s", dst.Addr, err)
+				}
+				break
+			}
+			touch()
+			if err := proto.WriteDatagram(stream, buf[:n]); err != nil {
+				if !IsErrClosed(err) {
+					log.Debugf("write udp datagram to stream err: %s", err)
+				}
+				break
+			}
+		}
+		_ = stream.Close()
+		_ = conn.Close()
+	}()
+
+	wg.Wait()
+}
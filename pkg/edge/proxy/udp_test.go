@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/edge/config"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/singchia/geminio"
+)
+
+// pipeStream 只实现 net.Conn 部分的 geminio.Stream
+type pipeStream struct {
+	geminio.Stream
+	conn net.Conn
+}
+
+func (p *pipeStream) Read(b []byte) (int, error)         { return p.conn.Read(b) }
+func (p *pipeStream) Write(b []byte) (int, error)        { return p.conn.Write(b) }
+func (p *pipeStream) Close() error                       { return p.conn.Close() }
+func (p *pipeStream) LocalAddr() net.Addr                { return p.conn.LocalAddr() }
+func (p *pipeStream) RemoteAddr() net.Addr               { return p.conn.RemoteAddr() }
+func (p *pipeStream) SetDeadline(t time.Time) error      { return p.conn.SetDeadline(t) }
+func (p *pipeStream) SetReadDeadline(t time.Time) error  { return p.conn.SetReadDeadline(t) }
+func (p *pipeStream) SetWriteDeadline(t time.Time) error { return p.conn.SetWriteDeadline(t) }
+
+// startUDPEcho 启动 UDP 后端，每个数据报加上前缀写回发送方
+func startUDPEcho(t *testing.T) net.PacketConn {
+	backend, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { backend.Close() })
+	go func() {
+		buf := make([]byte, proto.MaxDatagramSize)
+		for {
+			n, addr, err := backend.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = backend.WriteTo(append([]byte("echo:"), buf[:n]...), addr)
+		}
+	}()
+	return backend
+}
+
+// openStream 把 stream 交给 p 处理，返回 entry 一侧和处理结束的信号
+func openStream(p *proxy) (net.Conn, chan struct{}) {
+	entrySide, edgeSide := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.proxy(context.Background(), &pipeStream{conn: edgeSide})
+	}()
+	return entrySide, done
+}
+
+func TestProxyUDP(t *testing.T) {
+	backend := startUDPEcho(t)
+	p := &proxy{}
+	conn, done := openStream(p)
+	defer conn.Close()
+
+	_, err := proto.Handshake(conn, &proto.Dst{
+		Addr:    backend.LocalAddr().String(),
+		Network: proto.NetworkUDP,
+		Version: proto.ProtocolVersion,
+	}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 数据报边界保持不变，回包按顺序写回同一个 stream
+	for _, payload := range []string{"a", "bb", "ccc"} {
+		if err := proto.WriteDatagram(conn, []byte(payload)); err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		buf := make([]byte, proto.MaxDatagramSize)
+		n, err := proto.ReadDatagram(conn, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != "echo:"+payload {
+			t.Errorf("reply = %q, want %q", got, "echo:"+payload)
+		}
+	}
+
+	// entry 关闭 stream 后，edge 关闭后端 socket 并结束
+	conn.Close()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("udp proxy did not exit after the stream closed")
+	}
+}
+
+func TestProxyUDPDenied(t *testing.T) {
+	backend := startUDPEcho(t)
+	al, err := newAllowlist(&config.AllowedDestinations{CIDRs: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &proxy{allowlist: al}
+	conn, done := openStream(p)
+	defer conn.Close()
+
+	_, err = proto.Handshake(conn, &proto.Dst{
+		Addr:    backend.LocalAddr().String(),
+		Network: proto.NetworkUDP,
+		Version: proto.ProtocolVersion,
+	}, time.Second)
+	var dialErr *proto.DialError
+	if !errors.As(err, &dialErr) || dialErr.Result.Code != proto.DialCodeDenied {
+		t.Fatalf("handshake err = %v, want denied", err)
+	}
+	<-done
+}
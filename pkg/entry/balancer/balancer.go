@@ -0,0 +1,82 @@
+// Package balancer 在应用的多个后端之间按权重选择本次连接的目的地址。
+//
+// 使用平滑加权轮询（与 nginx 相同），只在健康的后端之间选择；
+// 全部后端都不健康时退化为在全部后端之间选择，避免健康检查误判导致应用完全不可用。
+package balancer
+
+import (
+	"sync"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// Balancer 单个代理的后端选择器，可通过 Update 热更新
+type Balancer struct {
+	mu      sync.Mutex
+	targets []*target
+}
+
+type target struct {
+	addr    string
+	weight  int
+	healthy bool
+	current int
+}
+
+// New 创建选择器
+func New(targets []proto.Target) *Balancer {
+	b := &Balancer{}
+	b.Update(targets)
+	return b
+}
+
+// Update 替换后端列表和健康状态，地址不变的后端保留轮询进度
+func (b *Balancer) Update(targets []proto.Target) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	old := make(map[string]*target, len(b.targets))
+	for _, t := range b.targets {
+		old[t.addr] = t
+	}
+	b.targets = make([]*target, 0, len(targets))
+	for _, t := range targets {
+		weight := t.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		nt := &target{addr: t.Addr, weight: weight, healthy: t.Healthy}
+		if prev, ok := old[t.Addr]; ok {
+			nt.current = prev.current
+		}
+		b.targets = append(b.targets, nt)
+	}
+}
+
+// Pick 返回本次使用的后端地址，没有后端时返回空字符串
+func (b *Balancer) Pick() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	candidates := make([]*target, 0, len(b.targets))
+	for _, t := range b.targets {
+		if t.healthy {
+			candidates = append(candidates, t)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = b.targets
+	}
+	var best *target
+	total := 0
+	for _, t := range candidates {
+		t.current += t.weight
+		total += t.weight
+		if best == nil || t.current > best.current {
+			best = t
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	best.current -= total
+	return best.addr
+}
@@ -0,0 +1,50 @@
+package balancer
+
+import (
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestPickWeighted(t *testing.T) {
+	b := New([]proto.Target{
+		{Addr: "10.0.0.1:80", Weight: 3, Healthy: true},
+		{Addr: "10.0.0.2:80", Weight: 1, Healthy: true},
+	})
+	counts := map[string]int{}
+	for i := 0; i < 8; i++ {
+		counts[b.Pick()]++
+	}
+	if counts["10.0.0.1:80"] != 6 || counts["10.0.0.2:80"] != 2 {
+		t.Fatalf("unexpected distribution %v", counts)
+	}
+}
+
+func TestPickSkipsUnhealthy(t *testing.T) {
+	b := New([]proto.Target{
+		{Addr: "10.0.0.1:80", Healthy: false},
+		{Addr: "10.0.0.2:80", Healthy: true},
+	})
+	for i := 0; i < 4; i++ {
+		if got := b.Pick(); got != "10.0.0.2:80" {
+			t.Fatalf("Pick = %s, want the healthy target", got)
+		}
+	}
+
+	// 全部不健康时退化为全部后端
+	b.Update([]proto.Target{
+		{Addr: "10.0.0.1:80", Healthy: false},
+		{Addr: "10.0.0.2:80", Healthy: false},
+	})
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		seen[b.Pick()] = true
+	}
+	if len(seen) != 2 {
+		t.Fatalf("expected both targets when none is healthy, got %v", seen)
+	}
+
+	if got := New(nil).Pick(); got != "" {
+		t.Fatalf("Pick on empty balancer = %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package entry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/jumboframes/armorigo/log"
+	pkgconfig "github.com/liaisonio/liaison/pkg/config"
+	"github.com/liaisonio/liaison/pkg/liaison/config"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// loadCertificates 加载静态证书，第一个证书是没有匹配 SNI 时的默认证书。
+// 加载失败的证书跳过，不影响其它代理
+func loadCertificates(certKeys []pkgconfig.CertKey) []tls.Certificate {
+	certs := make([]tls.Certificate, 0, len(certKeys))
+	for _, ck := range certKeys {
+		cert, err := tls.LoadX509KeyPair(ck.Cert, ck.Key)
+		if err != nil {
+			log.Errorf("failed to load certificate %s: %s", ck.Cert, err)
+			continue
+		}
+		certs = append(certs, cert)
+	}
+	return certs
+}
+
+// newACMEManager 创建 ACME 证书管理器，证书和账户密钥缓存在 CacheDir，到期前自动续期
+func newACMEManager(conf config.ACME, hostPolicy autocert.HostPolicy) (*autocert.Manager, error) {
+	if err := os.MkdirAll(conf.CacheDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create acme cache dir: %w", err)
+	}
+	client := &acme.Client{DirectoryURL: conf.DirectoryURL}
+	if client.DirectoryURL == "" {
+		client.DirectoryURL = autocert.DefaultACMEDirectory
+	}
+	if conf.CACert != "" {
+		pem, err := os.ReadFile(conf.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read acme ca cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in acme ca cert")
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		client.HTTPClient = &http.Client{Transport: transport}
+	}
+	log.Infof("acme enabled, directory %s, cache %s", client.DirectoryURL, conf.CacheDir)
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(conf.CacheDir),
+		HostPolicy: hostPolicy,
+		Client:     client,
+		Email:      conf.Email,
+	}, nil
+}
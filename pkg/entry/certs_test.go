@@ -0,0 +1,62 @@
+package entry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/entry/http"
+	"github.com/liaisonio/liaison/pkg/liaison/config"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ACME 只为运行中代理配置的主机名签发证书
+func TestACMEManagerHostPolicy(t *testing.T) {
+	httpServer := http.NewServer(nil)
+	defer httpServer.Close()
+	if err := httpServer.ServeVirtualHosts("127.0.0.1:0", ""); err != nil {
+		t.Fatal(err)
+	}
+	err := httpServer.CreateProxy(context.TODO(), &proto.Proxy{
+		ID:              1,
+		ApplicationType: "http",
+		Hostname:        "app.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir := filepath.Join(t.TempDir(), "acme")
+	m, err := newACMEManager(config.ACME{CacheDir: cacheDir}, httpServer.HostPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Errorf("cache dir not created: %v", err)
+	}
+	if m.Client.DirectoryURL != autocert.DefaultACMEDirectory {
+		t.Errorf("directory = %q, want the default", m.Client.DirectoryURL)
+	}
+
+	for host, allowed := range map[string]bool{
+		"app.example.com":   true,
+		"APP.example.com":   true,
+		"other.example.com": false,
+		"example.com":       false,
+	} {
+		err := m.HostPolicy(context.TODO(), host)
+		if (err == nil) != allowed {
+			t.Errorf("host policy for %s: err = %v, want allowed %v", host, err, allowed)
+		}
+	}
+
+	// 删除代理之后不再签发
+	if err := httpServer.DeleteProxy(context.TODO(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.HostPolicy(context.TODO(), "app.example.com"); err == nil {
+		t.Error("host policy allows a deleted proxy")
+	}
+}
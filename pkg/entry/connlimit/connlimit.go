@@ -0,0 +1,129 @@
+// Package connlimit 限制代理的并发连接数：整个代理一个上限，每个来源 IP 一个上限。
+// 被拒绝的连接按原因计数，供管理 API 查询。
+package connlimit
+
+import (
+	"net"
+	"sync"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// Limiter 单个代理的并发连接限制，可通过 Update 热更新
+type Limiter struct {
+	proxyID int
+
+	mu     sync.Mutex
+	conf   proto.ConnLimit
+	active int64
+	perIP  map[string]int64
+
+	rejectedByProxy int64
+	rejectedByIP    int64
+}
+
+// NewLimiter 创建连接限制，conf 为零值时不限制
+func NewLimiter(proxyID int, conf proto.ConnLimit) *Limiter {
+	return &Limiter{
+		proxyID: proxyID,
+		conf:    conf,
+		perIP:   make(map[string]int64),
+	}
+}
+
+// Update 修改上限，已建立的连接不受影响
+func (l *Limiter) Update(conf proto.ConnLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.conf = conf
+}
+
+// Acquire 为来自 ip 的新连接（或 UDP 会话）占一个名额，超限返回 false
+func (l *Limiter) Acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conf.MaxConnections > 0 && l.active >= int64(l.conf.MaxConnections) {
+		l.rejectedByProxy++
+		log.Debugf("connlimit: rejected %s for proxy %d, %d connections active", ip, l.proxyID, l.active)
+		return false
+	}
+	if l.conf.MaxConnectionsPerIP > 0 && l.perIP[ip] >= int64(l.conf.MaxConnectionsPerIP) {
+		l.rejectedByIP++
+		log.Debugf("connlimit: rejected %s for proxy %d, %d connections active from this ip", ip, l.proxyID, l.perIP[ip])
+		return false
+	}
+	l.active++
+	l.perIP[ip]++
+	return true
+}
+
+// Release 归还 Acquire 占用的名额
+func (l *Limiter) Release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	if l.perIP[ip]--; l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+}
+
+// Stats 返回当前活跃连接数和自监听启动以来的拒绝计数
+func (l *Limiter) Stats() proto.ProxyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return proto.ProxyStats{
+		ActiveConnections:    l.active,
+		RejectedByProxyLimit: l.rejectedByProxy,
+		RejectedByIPLimit:    l.rejectedByIP,
+	}
+}
+
+// Listener 在 Accept 时做连接数检查：超限的连接直接关闭，不会交给上层，
+// 也就不会为它打开到 edge 的 stream
+type Listener struct {
+	net.Listener
+	limiter *Limiter
+}
+
+// NewListener 包装监听器。需要放在 PROXY protocol 解析之后，才能按真实客户端 IP 计数
+func NewListener(ln net.Listener, l *Limiter) net.Listener {
+	return &Listener{Listener: ln, limiter: l}
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		ip := hostOf(conn.RemoteAddr())
+		if !l.limiter.Acquire(ip) {
+			_ = conn.Close()
+			continue
+		}
+		return &limitedConn{Conn: conn, limiter: l.limiter, ip: ip}, nil
+	}
+}
+
+// limitedConn 关闭时归还名额
+type limitedConn struct {
+	net.Conn
+	limiter   *Limiter
+	ip        string
+	closeOnce sync.Once
+}
+
+func (c *limitedConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.limiter.Release(c.ip)
+	})
+	return c.Conn.Close()
+}
+
+func hostOf(addr net.Addr) string {
+	if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+		return host
+	}
+	return addr.String()
+}
@@ -0,0 +1,42 @@
+package connlimit
+
+import (
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	l := NewLimiter(1, proto.ConnLimit{MaxConnections: 3, MaxConnectionsPerIP: 2})
+
+	if !l.Acquire("192.0.2.1") || !l.Acquire("192.0.2.1") {
+		t.Fatal("first two connections should be allowed")
+	}
+	if l.Acquire("192.0.2.1") {
+		t.Fatal("third connection from the same ip should be rejected")
+	}
+	if !l.Acquire("192.0.2.2") {
+		t.Fatal("connection from another ip should be allowed")
+	}
+	if l.Acquire("192.0.2.3") {
+		t.Fatal("proxy limit should reject the fourth connection")
+	}
+
+	stats := l.Stats()
+	if stats.ActiveConnections != 3 || stats.RejectedByIPLimit != 1 || stats.RejectedByProxyLimit != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	l.Release("192.0.2.1")
+	if !l.Acquire("192.0.2.3") {
+		t.Fatal("released slot should be reusable")
+	}
+
+	// 热更新为不限，立即生效
+	l.Update(proto.ConnLimit{})
+	for i := 0; i < 10; i++ {
+		if !l.Acquire("192.0.2.1") {
+			t.Fatal("unlimited proxy should not reject")
+		}
+	}
+}
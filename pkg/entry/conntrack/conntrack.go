@@ -0,0 +1,186 @@
+// Package conntrack 登记代理上的活跃客户端连接，供管理 API 查询和断开。
+// 每个代理一张表，连接 ID 在整个进程内唯一。
+package conntrack
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+var nextID atomic.Uint64
+
+// Table 单个代理的活跃连接
+type Table struct {
+	mu    sync.Mutex
+	conns map[uint64]*Entry
+}
+
+// NewTable 创建空的连接表
+func NewTable() *Table {
+	return &Table{conns: make(map[uint64]*Entry)}
+}
+
+// Entry 表中的一条连接。bytes 返回到目前为止客户端 -> 后端和后端 -> 客户端的字节数，
+// disconnect 断开连接，之后连接的处理自行结束并调用 Untrack
+type Entry struct {
+	id         uint64
+	clientAddr string
+	clientIP   string
+	start      time.Time
+	kind       atomic.Pointer[string]
+	edgeID     atomic.Uint64
+	lastActive atomic.Int64 // unix nano
+	bytes      func() (int64, int64)
+	disconnect func()
+	table      *Table
+}
+
+// Track 登记一条连接
+func (t *Table) Track(kind, clientAddr string, bytes func() (int64, int64), disconnect func()) *Entry {
+	e := &Entry{
+		id:         nextID.Add(1),
+		clientAddr: clientAddr,
+		clientIP:   hostOf(clientAddr),
+		start:      time.Now(),
+		bytes:      bytes,
+		disconnect: disconnect,
+		table:      t,
+	}
+	e.kind.Store(&kind)
+	e.lastActive.Store(e.start.UnixNano())
+	t.mu.Lock()
+	t.conns[e.id] = e
+	t.mu.Unlock()
+	return e
+}
+
+// Untrack 从表中移除连接，可以重复调用
+func (e *Entry) Untrack() {
+	e.table.mu.Lock()
+	delete(e.table.conns, e.id)
+	e.table.mu.Unlock()
+}
+
+// SetKind 修改连接类型，如 HTTP 连接升级为 WebSocket
+func (e *Entry) SetKind(kind string) {
+	e.kind.Store(&kind)
+}
+
+// SetEdge 记录连接最近使用的 edge
+func (e *Entry) SetEdge(edgeID uint64) {
+	e.edgeID.Store(edgeID)
+}
+
+// Touch 记录连接上刚刚收发了数据
+func (e *Entry) Touch() {
+	e.lastActive.Store(time.Now().UnixNano())
+}
+
+// LastActive 返回连接最近一次收发数据的时间，还没有收发过时为建立时间
+func (e *Entry) LastActive() time.Time {
+	return time.Unix(0, e.lastActive.Load())
+}
+
+func (e *Entry) info() proto.ConnectionInfo {
+	in, out := e.bytes()
+	return proto.ConnectionInfo{
+		ID:         e.id,
+		Kind:       *e.kind.Load(),
+		ClientAddr: e.clientAddr,
+		ClientIP:   e.clientIP,
+		Start:      e.start,
+		BytesIn:    in,
+		BytesOut:   out,
+		EdgeID:     e.edgeID.Load(),
+		LastActive: e.LastActive(),
+	}
+}
+
+// List 返回表中的连接，按建立时间排序，没有连接时返回空切片
+func (t *Table) List() []proto.ConnectionInfo {
+	t.mu.Lock()
+	entries := make([]*Entry, 0, len(t.conns))
+	for _, e := range t.conns {
+		entries = append(entries, e)
+	}
+	t.mu.Unlock()
+	infos := make([]proto.ConnectionInfo, len(entries))
+	for i, e := range entries {
+		infos[i] = e.info()
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Close 断开匹配 filter 的连接并从表中移除，返回断开的数量。filter 为零值时不匹配任何连接
+func (t *Table) Close(filter proto.ConnectionFilter) int {
+	if filter.ID == 0 && filter.ClientIP == "" {
+		return 0
+	}
+	var matched []*Entry
+	t.mu.Lock()
+	for id, e := range t.conns {
+		if filter.ID != 0 && id != filter.ID || filter.ClientIP != "" && e.clientIP != filter.ClientIP {
+			continue
+		}
+		matched = append(matched, e)
+		delete(t.conns, id)
+	}
+	t.mu.Unlock()
+	for _, e := range matched {
+		e.disconnect()
+	}
+	return len(matched)
+}
+
+// Conn 统计读写字节数的客户端连接，关闭时从表中移除
+type Conn struct {
+	net.Conn
+	Entry    *Entry
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+}
+
+// TrackConn 登记 conn，断开时关闭它
+func (t *Table) TrackConn(conn net.Conn, kind string) *Conn {
+	c := &Conn{Conn: conn}
+	c.Entry = t.Track(kind, conn.RemoteAddr().String(), func() (int64, int64) {
+		return c.bytesIn.Load(), c.bytesOut.Load()
+	}, func() { _ = c.Conn.Close() })
+	return c
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.bytesIn.Add(int64(n))
+		c.Entry.Touch()
+	}
+	return n, err
+}
+
+func (c *Conn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.bytesOut.Add(int64(n))
+		c.Entry.Touch()
+	}
+	return n, err
+}
+
+func (c *Conn) Close() error {
+	c.Entry.Untrack()
+	return c.Conn.Close()
+}
+
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
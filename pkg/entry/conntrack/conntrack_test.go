@@ -0,0 +1,80 @@
+package conntrack
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestTableClose(t *testing.T) {
+	table := NewTable()
+	var conns []*Conn
+	for _, addr := range []string{"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.2:1000"} {
+		client, server := net.Pipe()
+		defer client.Close()
+		conns = append(conns, table.TrackConn(&addrConn{Conn: server, remote: addr}, proto.ConnectionHTTP))
+	}
+	list := table.List()
+	if len(list) != 3 || list[0].ClientIP != "192.0.2.1" || list[2].ClientAddr != "192.0.2.2:1000" {
+		t.Fatalf("list = %+v", list)
+	}
+	conns[2].Entry.SetKind(proto.ConnectionWebSocket)
+	if kind := table.List()[2].Kind; kind != proto.ConnectionWebSocket {
+		t.Errorf("kind = %s", kind)
+	}
+
+	if n := table.Close(proto.ConnectionFilter{}); n != 0 {
+		t.Fatalf("empty filter closed %d connections", n)
+	}
+	if n := table.Close(proto.ConnectionFilter{ClientIP: "192.0.2.1"}); n != 2 {
+		t.Fatalf("closed %d connections from 192.0.2.1, want 2", n)
+	}
+	if _, err := conns[1].Read(make([]byte, 1)); err == nil {
+		t.Error("disconnected connection still readable")
+	}
+	if n := table.Close(proto.ConnectionFilter{ID: list[2].ID}); n != 1 {
+		t.Fatalf("closed %d connections by id, want 1", n)
+	}
+	if list := table.List(); len(list) != 0 {
+		t.Errorf("%d connections left", len(list))
+	}
+}
+
+func TestConnCountsBytes(t *testing.T) {
+	table := NewTable()
+	client, server := net.Pipe()
+	defer client.Close()
+	conn := table.TrackConn(&addrConn{Conn: server, remote: "192.0.2.1:1000"}, proto.ConnectionTCP)
+	go func() {
+		client.Write([]byte("ping"))
+		io.ReadFull(client, make([]byte, 5))
+	}()
+	buf := make([]byte, 8)
+	n, _ := conn.Read(buf)
+	conn.Write(buf[:n])
+	conn.Write([]byte("!"))
+	info := table.List()[0]
+	if info.BytesIn != 4 || info.BytesOut != 5 {
+		t.Errorf("bytes in %d out %d, want 4 and 5", info.BytesIn, info.BytesOut)
+	}
+	if info.LastActive.Before(info.Start) {
+		t.Errorf("last active %v before start %v", info.LastActive, info.Start)
+	}
+	conn.Close()
+	if len(table.List()) != 0 {
+		t.Error("closed connection still listed")
+	}
+}
+
+// addrConn 指定 RemoteAddr 的连接
+type addrConn struct {
+	net.Conn
+	remote string
+}
+
+func (c *addrConn) RemoteAddr() net.Addr {
+	addr, _ := net.ResolveTCPAddr("tcp", c.remote)
+	return addr
+}
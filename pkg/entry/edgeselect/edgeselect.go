@@ -0,0 +1,160 @@
+// Package edgeselect 为关联了多个 edge 的代理选择本次连接使用的 edge。
+//
+// 两种策略：failover 按配置顺序优先使用第一个可用的 edge；round_robin 在各 edge 之间轮转。
+// 在某个 edge 上打开 stream 失败时依次尝试下一个，失败的 edge 在一段冷却时间内排到最后。
+package edgeselect
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/singchia/geminio"
+)
+
+// failCooldown 打开 stream 失败的 edge 在这段时间内排在候选列表末尾
+const failCooldown = 10 * time.Second
+
+// ErrNoEdge 代理没有关联任何 edge
+var ErrNoEdge = errors.New("no edge associated with proxy")
+
+// Opener 打开到指定 edge 的 stream，由 frontierbound.FrontierBound 实现
+type Opener interface {
+	OpenStream(ctx context.Context, edgeID uint64) (geminio.Stream, error)
+}
+
+// Selector 单个代理的 edge 选择器，可通过 Update 热更新
+type Selector struct {
+	proxyID int
+
+	mu     sync.Mutex
+	policy string
+	edges  []uint64
+	next   int
+	stats  map[uint64]*edgeState
+}
+
+type edgeState struct {
+	streams     int64
+	failures    int64
+	lastUsedAt  time.Time
+	failedUntil time.Time
+}
+
+// NewSelector 创建选择器，policy 为空时使用 failover
+func NewSelector(proxyID int, policy string, edges []uint64) *Selector {
+	s := &Selector{proxyID: proxyID, stats: make(map[uint64]*edgeState)}
+	s.Update(policy, edges)
+	return s
+}
+
+// Update 修改策略和 edge 列表，只影响之后打开的 stream
+func (s *Selector) Update(policy string, edges []uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+	s.edges = append([]uint64(nil), edges...)
+	stats := make(map[uint64]*edgeState, len(edges))
+	for _, id := range edges {
+		if st, ok := s.stats[id]; ok {
+			stats[id] = st
+		} else {
+			stats[id] = &edgeState{}
+		}
+	}
+	s.stats = stats
+}
+
+// candidates 返回本次尝试的 edge 顺序：按策略排好后，冷却中的 edge 挪到末尾
+func (s *Selector) candidates(now time.Time) []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.edges)
+	if n == 0 {
+		return nil
+	}
+	ordered := make([]uint64, 0, n)
+	start := 0
+	if s.policy == proto.EdgePolicyRoundRobin {
+		start = s.next % n
+		s.next = (s.next + 1) % n
+	}
+	for i := 0; i < n; i++ {
+		ordered = append(ordered, s.edges[(start+i)%n])
+	}
+	healthy := make([]uint64, 0, n)
+	cooling := make([]uint64, 0)
+	for _, id := range ordered {
+		if now.Before(s.stats[id].failedUntil) {
+			cooling = append(cooling, id)
+		} else {
+			healthy = append(healthy, id)
+		}
+	}
+	return append(healthy, cooling...)
+}
+
+// OpenStream 按策略依次在各 edge 上打开 stream，返回第一个成功的 stream 和对应的 edge。
+// 全部失败时返回最后一个错误
+func (s *Selector) OpenStream(ctx context.Context, opener Opener) (geminio.Stream, uint64, error) {
+	candidates := s.candidates(time.Now())
+	if len(candidates) == 0 {
+		return nil, 0, ErrNoEdge
+	}
+	var lastErr error
+	for _, edgeID := range candidates {
+		stream, err := opener.OpenStream(ctx, edgeID)
+		if err == nil {
+			s.markUsed(edgeID)
+			return stream, edgeID, nil
+		}
+		lastErr = err
+		s.markFailed(edgeID)
+		log.Warnf("proxy %d open stream to edge %d err: %s", s.proxyID, edgeID, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, 0, lastErr
+}
+
+func (s *Selector) markUsed(edgeID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.stats[edgeID]; ok {
+		st.streams++
+		st.lastUsedAt = time.Now()
+		st.failedUntil = time.Time{}
+	}
+}
+
+func (s *Selector) markFailed(edgeID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.stats[edgeID]; ok {
+		st.failures++
+		st.failedUntil = time.Now().Add(failCooldown)
+	}
+}
+
+// Stats 返回每个 edge 承载的 stream 数和失败次数，顺序与配置一致
+func (s *Selector) Stats() []proto.EdgeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	stats := make([]proto.EdgeStats, 0, len(s.edges))
+	for _, id := range s.edges {
+		st := s.stats[id]
+		stats = append(stats, proto.EdgeStats{
+			EdgeID:     id,
+			Streams:    st.streams,
+			Failures:   st.failures,
+			LastUsedAt: st.lastUsedAt,
+			Healthy:    !now.Before(st.failedUntil),
+		})
+	}
+	return stats
+}
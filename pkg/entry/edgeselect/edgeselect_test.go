@@ -0,0 +1,70 @@
+package edgeselect
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/singchia/geminio"
+)
+
+// fakeOpener 记录尝试顺序，down 中的 edge 打开失败
+type fakeOpener struct {
+	down  map[uint64]bool
+	tried []uint64
+}
+
+func (f *fakeOpener) OpenStream(ctx context.Context, edgeID uint64) (geminio.Stream, error) {
+	f.tried = append(f.tried, edgeID)
+	if f.down[edgeID] {
+		return nil, errors.New("edge offline")
+	}
+	return nil, nil
+}
+
+func TestFailover(t *testing.T) {
+	s := NewSelector(1, proto.EdgePolicyFailover, []uint64{1, 2, 3})
+	opener := &fakeOpener{down: map[uint64]bool{1: true}}
+
+	_, edgeID, err := s.OpenStream(context.Background(), opener)
+	if err != nil || edgeID != 2 {
+		t.Fatalf("OpenStream = %d, %v, want edge 2", edgeID, err)
+	}
+	// 失败的 edge 冷却期间排到最后，直接使用 edge 2
+	opener.tried = nil
+	if _, edgeID, _ = s.OpenStream(context.Background(), opener); edgeID != 2 || len(opener.tried) != 1 {
+		t.Fatalf("second OpenStream used %d after trying %v", edgeID, opener.tried)
+	}
+
+	stats := s.Stats()
+	if stats[0].Healthy || stats[0].Failures != 1 || stats[1].Streams != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	opener.down = map[uint64]bool{1: true, 2: true, 3: true}
+	if _, _, err := s.OpenStream(context.Background(), opener); err == nil {
+		t.Fatal("expected error when every edge is down")
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	s := NewSelector(1, proto.EdgePolicyRoundRobin, []uint64{1, 2})
+	opener := &fakeOpener{}
+	var got []uint64
+	for i := 0; i < 4; i++ {
+		_, edgeID, err := s.OpenStream(context.Background(), opener)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, edgeID)
+	}
+	if got[0] == got[1] || got[0] != got[2] || got[1] != got[3] {
+		t.Fatalf("round robin order %v", got)
+	}
+
+	s.Update(proto.EdgePolicyRoundRobin, nil)
+	if _, _, err := s.OpenStream(context.Background(), opener); !errors.Is(err, ErrNoEdge) {
+		t.Fatalf("expected ErrNoEdge, got %v", err)
+	}
+}
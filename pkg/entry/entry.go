@@ -3,10 +3,8 @@ package entry
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/jumboframes/armorigo/log"
-	v1 "github.com/liaisonio/liaison/api/v1"
 	"github.com/liaisonio/liaison/pkg/entry/firewall"
 	"github.com/liaisonio/liaison/pkg/entry/frontierbound"
 	"github.com/liaisonio/liaison/pkg/entry/http"
@@ -14,6 +12,8 @@ import (
 	"github.com/liaisonio/liaison/pkg/liaison/config"
 	"github.com/liaisonio/liaison/pkg/liaison/manager/controlplane"
 	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/liaisonio/liaison/pkg/proxyproto"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 type Entry struct {
@@ -26,32 +26,55 @@ type Entry struct {
 }
 
 func NewEntry(conf *config.Configuration, manager controlplane.ControlPlane, trafficCollector interface {
-	RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64)
-}) (*Entry, error) {
+	RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64)
+	RecordCacheStats(proxyID, applicationID uint, stats proto.CacheStats)
+}, accessLogger proto.AccessLogger, sessionRecorder proto.SessionRecorder, userAuthenticator proto.UserAuthenticator) (*Entry, error) {
 
 	frontierBound, err := frontierbound.NewFrontierBound(conf)
 	if err != nil {
 		return nil, err
 	}
+	// 按 edge 上报的协议版本握手，老版本 edge 不写回拨号结果
+	frontierBound.SetProtocolResolver(manager.EdgeProtocolVersion)
+
+	// 可信的前置负载均衡器
+	trustedProxies, err := proxyproto.ParseTrusted(conf.Manager.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
 
 	// 创建 TCP 端口管理器
 	gatekeeper := transport.NewGatekeeper(frontierBound)
+	gatekeeper.SetTrustedProxies(trustedProxies)
 	// 设置流量统计器
 	if trafficCollector != nil {
 		gatekeeper.SetTrafficCollector(trafficCollector)
 	}
+	// TCP 代理的会话记录
+	if sessionRecorder != nil {
+		gatekeeper.SetSessionRecorder(sessionRecorder)
+	}
 
 	// 创建 HTTP 服务器
 	httpServer := http.NewServer(frontierBound)
+	httpServer.SetTrustedProxies(trustedProxies)
 	// 设置流量统计器
 	if trafficCollector != nil {
 		httpServer.SetTrafficCollector(trafficCollector)
 	}
+	// HTTP 代理的访问日志
+	if accessLogger != nil {
+		httpServer.SetAccessLogger(accessLogger)
+	}
 
 	// 创建防火墙管理器（in-memory CIDR 注册表），共享给两个数据面
 	firewallManager := firewall.NewManager()
 	gatekeeper.SetFirewall(firewallManager)
 	httpServer.SetFirewall(firewallManager)
+	// 登录保护的代理用管理端的用户登录，会话签名密钥从 JWT 密钥派生
+	if userAuthenticator != nil {
+		httpServer.SetUserAuthenticator(userAuthenticator, []byte(conf.Manager.JWTSecret))
+	}
 
 	// 创建统一的 ProxyManager，根据应用类型路由到不同的服务器
 	proxyManager := &unifiedProxyManager{
@@ -59,8 +82,29 @@ func NewEntry(conf *config.Configuration, manager controlplane.ControlPlane, tra
 		httpServer: httpServer,
 		conf:       conf,
 	}
+	// HTTPS 证书：listen.tls.certs 中的静态证书，以及可选的 ACME 自动证书
+	var acmeManager *autocert.Manager
+	if conf.Manager.ACME.Enable {
+		if !conf.Manager.VirtualHost.Enable {
+			return nil, errors.New("acme requires virtual_host to be enabled")
+		}
+		acmeManager, err = newACMEManager(conf.Manager.ACME, httpServer.HostPolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	httpServer.SetCertificates(loadCertificates(conf.Manager.Listen.TLS.Certs), acmeManager)
+
+	// 共享监听需要在拉取代理配置之前启动，设置了主机名的代理才能注册上去
+	if vh := conf.Manager.VirtualHost; vh.Enable {
+		if err := httpServer.ServeVirtualHosts(vh.HTTPAddr, vh.HTTPSAddr); err != nil {
+			return nil, err
+		}
+	}
+
 	manager.RegisterProxyManager(proxyManager)
 	manager.RegisterFirewallManager(firewallManager)
+	manager.RegisterCertificateManager(httpServer)
 
 	entry := &Entry{
 		gatekeeper:      gatekeeper,
@@ -88,19 +132,59 @@ type unifiedProxyManager struct {
 func (u *unifiedProxyManager) CreateProxy(ctx context.Context, protoproxy *proto.Proxy) error {
 	// 如果是 HTTP 应用，使用 HTTP 服务器
 	if protoproxy.ApplicationType == "http" {
-		// 获取 TLS 证书配置
-		var certFile, keyFile string
-		if protoproxy.UseHTTPS && len(u.conf.Manager.Listen.TLS.Certs) > 0 {
-			// 使用配置的第一个证书
-			certFile = u.conf.Manager.Listen.TLS.Certs[0].Cert
-			keyFile = u.conf.Manager.Listen.TLS.Certs[0].Key
-		}
-		return u.httpServer.CreateProxy(ctx, protoproxy, certFile, keyFile)
+		return u.httpServer.CreateProxy(ctx, protoproxy)
 	}
 	// 其他应用类型使用 TCP gatekeeper
 	return u.gatekeeper.CreateProxy(ctx, protoproxy)
 }
 
+func (u *unifiedProxyManager) UpdateProxy(ctx context.Context, protoproxy *proto.Proxy) error {
+	// 同 DeleteProxy，两个数据面对不属于自己的 id 都直接返回 nil
+	if err := u.httpServer.UpdateProxy(ctx, protoproxy); err != nil {
+		return err
+	}
+	return u.gatekeeper.UpdateProxy(ctx, protoproxy)
+}
+
+func (u *unifiedProxyManager) GetProxyStats(ctx context.Context, id int) (*proto.ProxyStats, error) {
+	stats, err := u.httpServer.GetProxyStats(ctx, id)
+	if err != nil || stats != nil {
+		return stats, err
+	}
+	return u.gatekeeper.GetProxyStats(ctx, id)
+}
+
+func (u *unifiedProxyManager) ListConnections(ctx context.Context, id int) ([]proto.ConnectionInfo, error) {
+	conns, err := u.httpServer.ListConnections(ctx, id)
+	if err != nil || conns != nil {
+		return conns, err
+	}
+	return u.gatekeeper.ListConnections(ctx, id)
+}
+
+func (u *unifiedProxyManager) CloseConnections(ctx context.Context, id int, filter proto.ConnectionFilter) (int, error) {
+	// 代理只在其中一个数据面上，另一个返回 0
+	httpClosed, err := u.httpServer.CloseConnections(ctx, id, filter)
+	if err != nil {
+		return httpClosed, err
+	}
+	tcpClosed, err := u.gatekeeper.CloseConnections(ctx, id, filter)
+	return httpClosed + tcpClosed, err
+}
+
+// 请求检查器只在 HTTP 数据面上
+func (u *unifiedProxyManager) ListInspectedRequests(ctx context.Context, id int) ([]proto.InspectedRequest, error) {
+	return u.httpServer.ListInspectedRequests(ctx, id)
+}
+
+func (u *unifiedProxyManager) GetInspectedRequest(ctx context.Context, id int, requestID uint64) (*proto.InspectedRequest, error) {
+	return u.httpServer.GetInspectedRequest(ctx, id, requestID)
+}
+
+func (u *unifiedProxyManager) ReplayRequest(ctx context.Context, id int, requestID uint64) (*proto.InspectedRequest, error) {
+	return u.httpServer.ReplayRequest(ctx, id, requestID)
+}
+
 func (u *unifiedProxyManager) DeleteProxy(ctx context.Context, id int) error {
 	// 始终两个数据面都调一次——两者对「不在本 map 里的 id」都返回 nil，所以
 	// 双调是安全且必要的：老实现只要 httpServer 返回 nil 就退出，导致 TCP
@@ -119,45 +203,16 @@ func (u *unifiedProxyManager) DeleteProxy(ctx context.Context, id int) error {
 
 // pullProxyConfigs 定期从manager同步Proxy配置
 func (e *Entry) pullProxyConfigs() error {
-	rsp, err := e.manager.ListProxies(context.Background(), &v1.ListProxiesRequest{
-		Page:     -1,
-		PageSize: -1,
-	})
+	protoproxies, err := e.manager.ListProxyRuntimes(context.Background())
 	if err != nil {
 		log.Errorf("failed to list proxies: %s", err)
 		return err
 	}
-	if rsp.Code != 200 {
-		log.Errorf("failed to list proxies: %s", rsp.Message)
-		return errors.New(rsp.Message)
-	}
-	log.Infof("list proxies: %s", rsp.Data.GetProxies())
+	log.Infof("list proxies: %d", len(protoproxies))
 
-	for _, proxy := range rsp.Data.GetProxies() {
-		application := proxy.Application
-		if application == nil {
-			log.Warnf("proxy %d (name: %s) has no associated application, skipping", proxy.Id, proxy.Name)
-			continue
-		}
-		dst := fmt.Sprintf("%s:%d", application.Ip, application.Port)
-		
-		// 判断是否是 HTTP 应用，如果是则默认使用 HTTPS
-		useHTTPS := false
-		if application.ApplicationType == "http" {
-			useHTTPS = true
-		}
-		
+	for _, protoproxy := range protoproxies {
 		// 使用统一的 ProxyManager
-		e.proxyManager.CreateProxy(context.Background(), &proto.Proxy{
-			ID:              int(proxy.Id),
-			Name:            proxy.Name,
-			ProxyPort:       int(proxy.Port),
-			EdgeID:          application.EdgeId,
-			ApplicationID:   uint(application.Id),
-			Dst:             dst,
-			ApplicationType: application.ApplicationType,
-			UseHTTPS:        useHTTPS,
-		})
+		e.proxyManager.CreateProxy(context.Background(), protoproxy)
 	}
 
 	return nil
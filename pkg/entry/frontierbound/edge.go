@@ -3,6 +3,7 @@ package frontierbound
 import (
 	"context"
 
+	"github.com/liaisonio/liaison/pkg/proto"
 	"github.com/singchia/geminio"
 )
 
@@ -13,3 +14,17 @@ func (fb *frontierBound) OpenStream(ctx context.Context, edgeID uint64) (geminio
 	}
 	return stream, nil
 }
+
+// SetProtocolResolver 设置查询 edge 协议版本的函数
+func (fb *frontierBound) SetProtocolResolver(resolve func(edgeID uint64) int) {
+	fb.protocol.Store(&resolve)
+}
+
+// EdgeProtocol 没有设置查询函数时按当前版本握手
+func (fb *frontierBound) EdgeProtocol(edgeID uint64) int {
+	resolve := fb.protocol.Load()
+	if resolve == nil {
+		return proto.ProtocolVersion
+	}
+	return (*resolve)(edgeID)
+}
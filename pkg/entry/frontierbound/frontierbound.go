@@ -5,6 +5,7 @@ import (
 	"errors"
 	"math/rand"
 	"net"
+	"sync/atomic"
 
 	"github.com/singchia/frontier/api/dataplane/v1/service"
 	"github.com/singchia/geminio"
@@ -14,12 +15,16 @@ import (
 
 type FrontierBound interface {
 	OpenStream(ctx context.Context, edgeID uint64) (geminio.Stream, error)
+	// EdgeProtocol edge 的 stream 协议版本，打开 stream 之后按它握手
+	EdgeProtocol(edgeID uint64) int
 	Close() error
 }
 
 // 这是edge向frontier注册的连接
 type frontierBound struct {
 	svc service.Service
+	// 查询 edge 上报的协议版本，由管理端提供
+	protocol atomic.Pointer[func(edgeID uint64) int]
 }
 
 func NewFrontierBound(conf *config.Configuration) (*frontierBound, error) {
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// SetAccessLogger 设置 HTTP 代理访问日志的接收方，没有设置时不记录
+func (s *Server) SetAccessLogger(logger proto.AccessLogger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessLogger = logger
+}
+
+// accessLog 一个请求的访问日志，nil 表示不记录
+type accessLog struct {
+	logger proto.AccessLogger
+	entry  proto.AccessLogEntry
+}
+
+// newAccessLog 在登录保护检查、路由和改写之前记下客户端请求的信息，被登录保护拦截的请求也要记录
+func (s *Server) newAccessLog(req *http.Request, clientConn net.Conn, protoproxy *proto.Proxy) *accessLog {
+	s.mu.RLock()
+	logger := s.accessLogger
+	s.mu.RUnlock()
+	if logger == nil {
+		return nil
+	}
+	clientIP, _, _ := net.SplitHostPort(clientConn.RemoteAddr().String())
+	return &accessLog{
+		logger: logger,
+		entry: proto.AccessLogEntry{
+			ProxyID:       uint(protoproxy.ID),
+			ApplicationID: protoproxy.ApplicationID,
+			Time:          time.Now(),
+			ClientIP:      clientIP,
+			Method:        req.Method,
+			Host:          req.Host,
+			Path:          req.URL.EscapedPath(),
+			Proto:         req.Proto,
+			UserAgent:     req.UserAgent(),
+		},
+	}
+}
+
+// authorized 登录保护放行之后记下登录用户。authorize 会去掉客户端自带的 X-Forwarded-User，
+// 此时该请求头只可能是登录保护写入的
+func (l *accessLog) authorized(req *http.Request) {
+	if l == nil {
+		return
+	}
+	l.entry.User = req.Header.Get(forwardedUserHeader)
+}
+
+// finish 上报访问日志，applicationID 为 0 时保留代理自己的应用
+func (l *accessLog) finish(applicationID uint, status int, bytesIn, bytesOut int64) {
+	if l == nil {
+		return
+	}
+	if applicationID != 0 {
+		l.entry.ApplicationID = applicationID
+	}
+	l.entry.Duration = time.Since(l.entry.Time)
+	l.entry.Status = status
+	l.entry.BytesIn, l.entry.BytesOut = bytesIn, bytesOut
+	l.logger.RecordAccess(&l.entry)
+}
+
+// routeApplication 请求匹配的应用，转发失败、没有 stream 时用于访问日志
+func routeApplication(route *httpRoute) uint {
+	if route == nil {
+		return 0
+	}
+	return route.ApplicationID
+}
+
+// statusRecorder 记下写给客户端的状态码和响应体大小，用于被登录保护拦截的 HTTP/2 请求
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+type fakeAccessLogger struct {
+	mu      sync.Mutex
+	entries []proto.AccessLogEntry
+}
+
+func (l *fakeAccessLogger) RecordAccess(entry *proto.AccessLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, *entry)
+}
+
+func TestAccessLog(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		io.WriteString(w, "short and stout")
+	})}
+	s, base := startTestProxy(t, edge)
+	logger := &fakeAccessLogger{}
+	s.SetAccessLogger(logger)
+	s.proxies[1].setRoutes([]proto.Route{
+		{PathPrefix: "/api", StripPrefix: true, ApplicationID: 2, EdgeIDs: []uint64{2}, Dst: "10.0.0.2:8080"},
+	})
+	client := &http.Client{}
+	defer client.CloseIdleConnections()
+
+	req, _ := http.NewRequest(http.MethodGet, base+"/api/teapot?token=secret", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(logger.entries))
+	}
+	entry := logger.entries[0]
+	// 记录客户端请求的原始路径，不含查询参数
+	if entry.ProxyID != 1 || entry.ApplicationID != 2 || entry.Path != "/api/teapot" ||
+		entry.Status != http.StatusTeapot || entry.Method != http.MethodGet || entry.ClientIP != "127.0.0.1" ||
+		entry.UserAgent != "test-agent" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.BytesIn == 0 || entry.BytesOut == 0 {
+		t.Errorf("bytes in %d, out %d", entry.BytesIn, entry.BytesOut)
+	}
+}
+
+// 被登录保护拦截的请求和登录页面也要记录
+func TestAccessLogLoginRejected(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+	s, base := startTestProxy(t, edge)
+	logger := &fakeAccessLogger{}
+	s.SetAccessLogger(logger)
+	s.SetUserAuthenticator(&fakeAuthenticator{}, []byte("jwt secret"))
+	s.proxies[1].setAuth(proto.ProxyAuth{Required: true})
+	client := &http.Client{}
+	defer client.CloseIdleConnections()
+
+	for _, path := range []string{"/api", loginPath} {
+		req, _ := http.NewRequest(http.MethodGet, base+path, nil)
+		req.Header.Set(forwardedUserHeader, "admin@example.com")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(logger.entries))
+	}
+	for i, want := range []struct {
+		path   string
+		status int
+	}{{"/api", http.StatusUnauthorized}, {loginPath, http.StatusOK}} {
+		entry := logger.entries[i]
+		// 客户端伪造的登录用户不能进入访问日志
+		if entry.Path != want.path || entry.Status != want.status || entry.User != "" || entry.BytesOut == 0 {
+			t.Errorf("entry %d = %+v", i, entry)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// 登录保护的代理上保留给登录页面的路径，不转发给后端
+const (
+	authPathPrefix = "/.liaison/"
+	loginPath      = "/.liaison/login"
+	logoutPath     = "/.liaison/logout"
+)
+
+const (
+	// sessionTTL 登录会话的有效期
+	sessionTTL = 12 * time.Hour
+	// userSessionTTL 用户启用状态和会话代数的缓存时间，禁用、删除或者在其他 entry 上
+	// 退出登录的用户最迟在这之后被拒绝
+	userSessionTTL = time.Minute
+	// loginFailureDelay 登录失败后的延迟，拖慢在线猜测密码
+	loginFailureDelay = time.Second
+	// maxLoginFormBytes 登录表单的大小上限
+	maxLoginFormBytes = 64 << 10
+	// sessionCookiePrefix 会话 cookie 名的前缀，后接代理 ID。cookie 不区分端口，
+	// 同一主机名上所有代理的 cookie 都会发给每个代理，转发前全部去掉
+	sessionCookiePrefix = "liaison_session_"
+	// csrfCookieSuffix 登录表单的 CSRF token 的 cookie 名后缀，接在会话 cookie 名之后
+	csrfCookieSuffix = "_csrf"
+	// forwardedUserHeader 转发给后端的登录用户邮箱
+	forwardedUserHeader = "X-Forwarded-User"
+)
+
+// session 登录保护代理的会话，签名后放在 cookie 中。cookie 不设置 Domain，
+// 只发给代理的主机名；载荷中的代理 ID 保证不能拿到其他代理上使用。
+// 用户退出登录时递增会话代数，之前签发的会话随之失效
+type session struct {
+	UserID     uint   `json:"uid"`
+	Email      string `json:"email"`
+	ProxyID    int    `json:"pid"`
+	Generation uint   `json:"gen"`
+	Expires    int64  `json:"exp"`
+}
+
+// authGate 校验登录保护代理的访问者：用户和密码交给管理端的 IAM，会话 cookie 由本地签名
+type authGate struct {
+	authenticator proto.UserAuthenticator
+	key           []byte
+
+	mu sync.Mutex
+	// 用户 ID -> 最近一次确认处于启用状态的时间和当时的会话代数
+	users map[uint]userSession
+}
+
+type userSession struct {
+	checked    time.Time
+	generation uint
+}
+
+// newAuthGate 会话签名密钥从管理端的 JWT 密钥派生，两者不能互相冒用
+func newAuthGate(authenticator proto.UserAuthenticator, secret []byte) *authGate {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("liaison proxy session"))
+	return &authGate{
+		authenticator: authenticator,
+		key:           mac.Sum(nil),
+		users:         make(map[uint]userSession),
+	}
+}
+
+func (g *authGate) mac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// sign 把会话编码为 cookie 的值：base64(载荷).base64(HMAC)
+func (g *authGate) sign(sess *session) string {
+	payload, _ := json.Marshal(sess)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(g.mac(payload))
+}
+
+// verify 校验 cookie 的签名、代理和有效期，不通过返回 nil
+func (g *authGate) verify(value string, proxyID int, now time.Time) *session {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, g.mac(payload)) {
+		return nil
+	}
+	var sess session
+	if err := json.Unmarshal(payload, &sess); err != nil {
+		return nil
+	}
+	if sess.ProxyID != proxyID || now.Unix() >= sess.Expires {
+		return nil
+	}
+	return &sess
+}
+
+// valid 会话的用户是否仍然启用并且没有退出登录，结果缓存 userSessionTTL
+func (g *authGate) valid(sess *session) bool {
+	generation, ok := g.userSession(sess.UserID)
+	return ok && sess.Generation == generation
+}
+
+// userSession 用户当前的会话代数，用户不存在或者已经禁用时返回 false
+func (g *authGate) userSession(userID uint) (uint, bool) {
+	now := time.Now()
+	g.mu.Lock()
+	user, ok := g.users[userID]
+	g.mu.Unlock()
+	if ok && now.Sub(user.checked) < userSessionTTL {
+		return user.generation, true
+	}
+	generation, active := g.authenticator.UserSession(userID)
+	g.mu.Lock()
+	if active {
+		g.users[userID] = userSession{checked: now, generation: generation}
+	} else {
+		delete(g.users, userID)
+	}
+	g.mu.Unlock()
+	return generation, active
+}
+
+// revoke 注销用户的所有会话，本地的缓存立即失效
+func (g *authGate) revoke(userID uint) error {
+	err := g.authenticator.RevokeUserSessions(userID)
+	g.mu.Lock()
+	delete(g.users, userID)
+	g.mu.Unlock()
+	return err
+}
+
+// SetUserAuthenticator 设置登录保护代理使用的用户校验，secret 用于派生会话签名密钥。
+// 没有设置时，要求登录的代理拒绝所有请求
+func (s *Server) SetUserAuthenticator(authenticator proto.UserAuthenticator, secret []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth = newAuthGate(authenticator, secret)
+}
+
+// authorize 登录保护检查。通过时去掉请求中所有代理的会话 cookie，登录保护的代理带上登录用户的邮箱，
+// 返回 true；不通过时已经写好登录页面、跳转或者错误响应，返回 false
+func (s *Server) authorize(w http.ResponseWriter, req *http.Request, proxyID int, clientConn net.Conn) bool {
+	s.mu.RLock()
+	gate := s.auth
+	s.mu.RUnlock()
+	proxy := s.runningProxy(proxyID)
+	if proxy == nil {
+		writeHandlerError(w, http.StatusServiceUnavailable, "proxy_unavailable", "proxy is not running")
+		return false
+	}
+	auth := proxy.auth.Load()
+	if auth == nil || !auth.Required {
+		// 同一主机名上登录保护代理的会话 cookie 也会发到这里，不能交给后端；
+		// 客户端自带的 X-Forwarded-User 也要去掉，后端只能信任 entry 写入的值
+		removeSessionCookies(req)
+		req.Header.Del(forwardedUserHeader)
+		return true
+	}
+	if gate == nil {
+		log.Warnf("http proxy %d requires login but no authenticator is configured", proxyID)
+		writeHandlerError(w, http.StatusServiceUnavailable, "auth_unavailable", "login is not available")
+		return false
+	}
+	if strings.HasPrefix(req.URL.Path, authPathPrefix) {
+		gate.serveAuthPage(w, req, proxy, *auth, clientConn)
+		return false
+	}
+
+	cookieName := sessionCookieName(proxyID)
+	var sess *session
+	if cookie, err := req.Cookie(cookieName); err == nil {
+		sess = gate.verify(cookie.Value, proxyID, time.Now())
+	}
+	if sess == nil || !gate.valid(sess) {
+		challenge(w, req)
+		return false
+	}
+	if !auth.Allows(sess.Email) {
+		writeHandlerError(w, http.StatusForbidden, "user_not_allowed",
+			fmt.Sprintf("%s is not allowed to access this proxy", sess.Email))
+		return false
+	}
+	removeSessionCookies(req)
+	req.Header.Set(forwardedUserHeader, sess.Email)
+	return true
+}
+
+// authorizeConn HTTP/1.1 连接上的登录保护检查，不通过时写出响应并记录访问日志，由调用方关闭连接
+func (s *Server) authorizeConn(clientConn net.Conn, req *http.Request, proxyID int, alog *accessLog) bool {
+	rw := &bufferedResponse{header: make(http.Header)}
+	if s.authorize(rw, req, proxyID, clientConn) {
+		alog.authorized(req)
+		return true
+	}
+	rw.WriteHeader(http.StatusOK)
+	alog.finish(0, rw.status, requestLineBytes(req)+headerBytes(req.Header), headerBytes(rw.header)+int64(rw.body.Len()))
+	if err := rw.writeTo(clientConn); err != nil {
+		log.Debugf("write login response to %s err: %s", clientConn.RemoteAddr(), err)
+	}
+	return false
+}
+
+// challenge 未登录的请求：浏览器的页面请求跳转到登录页，其余请求返回 401
+func challenge(w http.ResponseWriter, req *http.Request) {
+	if (req.Method == http.MethodGet || req.Method == http.MethodHead) &&
+		strings.Contains(req.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, req, loginPath+"?next="+url.QueryEscape(req.URL.RequestURI()), http.StatusFound)
+		return
+	}
+	writeHandlerError(w, http.StatusUnauthorized, "login_required", "login required")
+}
+
+// serveAuthPage 处理登录和退出。登录表单用 cookie 和表单中相同的 token 防止 CSRF，
+// 登录和退出都只接受本站发起的请求
+func (g *authGate) serveAuthPage(w http.ResponseWriter, req *http.Request, proxy *httpProxy, auth proto.ProxyAuth, clientConn net.Conn) {
+	cookieName := sessionCookieName(proxy.id)
+	secure := isTLS(clientConn)
+	switch req.URL.Path {
+	case logoutPath:
+		if !sameOrigin(req) {
+			writeHandlerError(w, http.StatusForbidden, "cross_origin", "cross-origin request")
+			return
+		}
+		// 注销用户的会话，cookie 被复制走也不能再用
+		if cookie, err := req.Cookie(cookieName); err == nil {
+			if sess := g.verify(cookie.Value, proxy.id, time.Now()); sess != nil {
+				if err := g.revoke(sess.UserID); err != nil {
+					log.Errorf("http proxy %d: revoke sessions of %s err: %s", proxy.id, sess.Email, err)
+					writeHandlerError(w, http.StatusInternalServerError, "logout_failed", "logout failed")
+					return
+				}
+			}
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name: cookieName, Value: "", Path: "/", MaxAge: -1,
+			HttpOnly: true, Secure: secure, SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, req, loginPath, http.StatusSeeOther)
+	case loginPath:
+		page := loginPage{ProxyName: proxy.protoproxy.Name, Next: safeRedirect(req.URL.Query().Get("next"))}
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			page.CSRF = setCSRFCookie(w, cookieName, secure)
+			page.render(w, http.StatusOK)
+			return
+		}
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, POST")
+			writeHandlerError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+			return
+		}
+		req.Body = http.MaxBytesReader(w, req.Body, maxLoginFormBytes)
+		if err := req.ParseForm(); err != nil {
+			writeHandlerError(w, http.StatusBadRequest, "invalid_form", err.Error())
+			return
+		}
+		page.Next = safeRedirect(req.PostForm.Get("next"))
+		page.Email = req.PostForm.Get("email")
+		if !sameOrigin(req) || !validCSRF(req, cookieName) {
+			page.CSRF = setCSRFCookie(w, cookieName, secure)
+			page.Error = "The form has expired, please try again."
+			page.render(w, http.StatusForbidden)
+			return
+		}
+		ip, _, _ := net.SplitHostPort(clientConn.RemoteAddr().String())
+		userID, email, err := g.authenticator.Authenticate(page.Email, req.PostForm.Get("password"), ip)
+		if err != nil {
+			log.Infof("http proxy %d: login as %q from %s failed: %s", proxy.id, page.Email, ip, err)
+			time.Sleep(loginFailureDelay)
+			page.CSRF = setCSRFCookie(w, cookieName, secure)
+			page.Error = "Invalid email or password."
+			page.render(w, http.StatusUnauthorized)
+			return
+		}
+		if !auth.Allows(email) {
+			log.Infof("http proxy %d: login as %s from %s not allowed", proxy.id, email, ip)
+			page.CSRF = setCSRFCookie(w, cookieName, secure)
+			page.Error = "This account is not allowed to access this site."
+			page.render(w, http.StatusForbidden)
+			return
+		}
+		generation, active := g.userSession(userID)
+		if !active {
+			writeHandlerError(w, http.StatusForbidden, "user_disabled", "user account is disabled")
+			return
+		}
+		expires := time.Now().Add(sessionTTL)
+		sess := &session{UserID: userID, Email: email, ProxyID: proxy.id, Generation: generation, Expires: expires.Unix()}
+		http.SetCookie(w, &http.Cookie{
+			Name: cookieName + csrfCookieSuffix, Value: "", Path: loginPath, MaxAge: -1,
+			HttpOnly: true, Secure: secure, SameSite: http.SameSiteStrictMode,
+		})
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookieName,
+			Value:    g.sign(sess),
+			Path:     "/",
+			Expires:  expires,
+			HttpOnly: true,
+			Secure:   secure,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, req, page.Next, http.StatusSeeOther)
+	default:
+		writeHandlerError(w, http.StatusNotFound, "not_found", "not found")
+	}
+}
+
+func sessionCookieName(proxyID int) string {
+	return fmt.Sprintf("%s%d", sessionCookiePrefix, proxyID)
+}
+
+// removeSessionCookies 去掉请求中所有代理的会话 cookie 和登录表单的 token，
+// 其余 cookie 原样保留
+func removeSessionCookies(req *http.Request) {
+	values := req.Header.Values("Cookie")
+	if len(values) == 0 {
+		return
+	}
+	kept := make([]string, 0, len(values))
+	removed := false
+	for _, value := range values {
+		parts := strings.Split(value, ";")
+		n := 0
+		for _, part := range parts {
+			if strings.HasPrefix(strings.TrimSpace(part), sessionCookiePrefix) {
+				removed = true
+				continue
+			}
+			parts[n] = part
+			n++
+		}
+		if value = strings.TrimSpace(strings.Join(parts[:n], ";")); value != "" {
+			kept = append(kept, value)
+		}
+	}
+	if !removed {
+		return
+	}
+	req.Header.Del("Cookie")
+	for _, value := range kept {
+		req.Header.Add("Cookie", value)
+	}
+}
+
+// setCSRFCookie 生成登录表单的 token，同时放在只发给登录页面的 cookie 中
+func setCSRFCookie(w http.ResponseWriter, cookieName string, secure bool) string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	token := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name: cookieName + csrfCookieSuffix, Value: token, Path: loginPath,
+		HttpOnly: true, Secure: secure, SameSite: http.SameSiteStrictMode,
+	})
+	return token
+}
+
+// validCSRF 表单中的 token 是否和 cookie 中的一致
+func validCSRF(req *http.Request, cookieName string) bool {
+	cookie, err := req.Cookie(cookieName + csrfCookieSuffix)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	return hmac.Equal([]byte(cookie.Value), []byte(req.PostForm.Get("csrf")))
+}
+
+// sameOrigin 请求是否由本站发起。浏览器的 POST 都带 Origin，没有 Origin 时看 Sec-Fetch-Site；
+// 两者都没有的不是浏览器的跨站请求
+func sameOrigin(req *http.Request) bool {
+	if origin := req.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		return err == nil && u.Host != "" && strings.EqualFold(u.Host, req.Host)
+	}
+	switch req.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+		return true
+	}
+	return false
+}
+
+// safeRedirect 登录后跳转的地址只接受本站的路径，防止跳到外部站点
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") ||
+		strings.HasPrefix(next, "/\\") || strings.HasPrefix(next, authPathPrefix) {
+		return "/"
+	}
+	u, err := url.Parse(next)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return "/"
+	}
+	return next
+}
+
+// loginPage 登录页面的内容
+type loginPage struct {
+	ProxyName string
+	Email     string
+	Next      string
+	CSRF      string
+	Error     string
+}
+
+func (p *loginPage) render(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	if err := loginTemplate.Execute(w, p); err != nil {
+		log.Debugf("render login page err: %s", err)
+	}
+}
+
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in{{if .ProxyName}} - {{.ProxyName}}{{end}}</title>
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;background:#f5f5f5;margin:0}
+form{max-width:320px;margin:12vh auto;padding:32px;background:#fff;border-radius:8px;box-shadow:0 2px 8px rgba(0,0,0,.1)}
+h1{font-size:20px;margin:0 0 24px}
+input{display:block;width:100%;box-sizing:border-box;margin:0 0 16px;padding:8px;border:1px solid #d9d9d9;border-radius:4px}
+button{width:100%;padding:10px;border:0;border-radius:4px;background:#1677ff;color:#fff;cursor:pointer}
+.error{color:#ff4d4f;margin:0 0 16px}
+</style>
+</head>
+<body>
+<form method="post" action="/.liaison/login">
+<h1>Sign in{{if .ProxyName}} to {{.ProxyName}}{{end}}</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<input type="hidden" name="next" value="{{.Next}}">
+<input type="hidden" name="csrf" value="{{.CSRF}}">
+<input type="email" name="email" placeholder="Email" value="{{.Email}}" required autofocus>
+<input type="password" name="password" placeholder="Password" required>
+<button type="submit">Sign in</button>
+</form>
+</body>
+</html>
+`))
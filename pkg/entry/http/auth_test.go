@@ -0,0 +1,235 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+type fakeAuthenticator struct {
+	mu         sync.Mutex
+	inactive   bool
+	generation uint
+}
+
+func (a *fakeAuthenticator) Authenticate(email, password, clientIP string) (uint, string, error) {
+	if password != "secret" {
+		return 0, "", errors.New("invalid password")
+	}
+	return 7, email, nil
+}
+
+func (a *fakeAuthenticator) UserSession(userID uint) (uint, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.generation, !a.inactive
+}
+
+func (a *fakeAuthenticator) RevokeUserSessions(userID uint) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.generation++
+	return nil
+}
+
+func TestSessionCookie(t *testing.T) {
+	gate := newAuthGate(&fakeAuthenticator{}, []byte("jwt secret"))
+	now := time.Now()
+	value := gate.sign(&session{UserID: 7, Email: "a@example.com", ProxyID: 1, Expires: now.Add(time.Hour).Unix()})
+	if sess := gate.verify(value, 1, now); sess == nil || sess.Email != "a@example.com" {
+		t.Fatalf("verify = %+v", sess)
+	}
+	if gate.verify(value, 2, now) != nil {
+		t.Error("session accepted on another proxy")
+	}
+	if gate.verify(value, 1, now.Add(2*time.Hour)) != nil {
+		t.Error("expired session accepted")
+	}
+	if gate.verify("x"+value, 1, now) != nil {
+		t.Error("tampered session accepted")
+	}
+	other := newAuthGate(&fakeAuthenticator{}, []byte("another secret"))
+	if other.verify(value, 1, now) != nil {
+		t.Error("session accepted with another key")
+	}
+}
+
+func TestRemoveSessionCookies(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://web/", nil)
+	req.Header.Add("Cookie", "a=1; liaison_session_1=x; b=2")
+	req.Header.Add("Cookie", "liaison_session_2_csrf=y")
+	removeSessionCookies(req)
+	if got := req.Header.Values("Cookie"); len(got) != 1 || got[0] != "a=1; b=2" {
+		t.Errorf("cookies = %q", got)
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	cases := map[string]string{
+		"/app?x=1":             "/app?x=1",
+		"":                     "/",
+		"https://evil.example": "/",
+		"//evil.example/":      "/",
+		"/\\evil.example":      "/",
+		"/.liaison/logout":     "/",
+	}
+	for next, want := range cases {
+		if got := safeRedirect(next); got != want {
+			t.Errorf("safeRedirect(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
+
+func TestLoginProtectedProxy(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Cookie"), sessionCookiePrefix) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, r.Header.Get(forwardedUserHeader))
+	})}
+	s, base := startTestProxy(t, edge)
+	authenticator := &fakeAuthenticator{}
+	s.SetUserAuthenticator(authenticator, []byte("jwt secret"))
+	s.proxies[1].setAuth(proto.ProxyAuth{Required: true, AllowedUsers: []string{"Alice@example.com"}})
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	defer client.CloseIdleConnections()
+
+	// 浏览器跳转到登录页，其他请求返回 401
+	req, _ := http.NewRequest(http.MethodGet, base+"/app", nil)
+	req.Header.Set("Accept", "text/html")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound || !strings.HasPrefix(resp.Header.Get("Location"), loginPath) {
+		t.Fatalf("browser got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	resp, err = client.Get(base + "/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("api got %d", resp.StatusCode)
+	}
+
+	// 登录表单带着 cookie 中的 CSRF token
+	csrfField := regexp.MustCompile(`name="csrf" value="([^"]+)"`)
+	csrf := func() string {
+		t.Helper()
+		resp, err := client.Get(base + loginPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		m := csrfField.FindSubmatch(page)
+		if m == nil {
+			t.Fatalf("login page without csrf token: %s", page)
+		}
+		return string(m[1])
+	}
+	post := func(form url.Values, origin string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, base+loginPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	login := func(email, password string) *http.Response {
+		t.Helper()
+		return post(url.Values{"email": {email}, "password": {password}, "next": {"/app"}, "csrf": {csrf()}}, base)
+	}
+	alice := url.Values{"email": {"alice@example.com"}, "password": {"secret"}}
+	if resp := post(alice, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("login without csrf token got %d", resp.StatusCode)
+	}
+	alice.Set("csrf", csrf())
+	if resp := post(alice, "http://evil.example"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("cross-origin login got %d", resp.StatusCode)
+	}
+	if resp := login("bob@example.com", "secret"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("not allowed user got %d", resp.StatusCode)
+	}
+	if resp := login("alice@example.com", "secret"); resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/app" {
+		t.Fatalf("login got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	resp, err = client.Get(base + "/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "alice@example.com" {
+		t.Fatalf("logged in got %d %q", resp.StatusCode, body)
+	}
+
+	// 退出登录后，被复制走的会话 cookie 也不能再用
+	u, _ := url.Parse(base)
+	stolen := jar.Cookies(u)
+	resp, err = client.Get(base + logoutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if generation, _ := authenticator.UserSession(7); resp.StatusCode != http.StatusSeeOther || generation != 1 {
+		t.Fatalf("logout got %d, generation %d", resp.StatusCode, generation)
+	}
+	req, _ = http.NewRequest(http.MethodGet, base+"/app", nil)
+	for _, cookie := range stolen {
+		req.AddCookie(cookie)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("revoked session got %d", resp.StatusCode)
+	}
+}
+
+// 不需要登录的代理不转发客户端伪造的 X-Forwarded-User
+func TestPublicProxyStripsForwardedUser(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Header.Get(forwardedUserHeader))
+	})}
+	s, base := startTestProxy(t, edge)
+	s.SetUserAuthenticator(&fakeAuthenticator{}, []byte("jwt secret"))
+
+	client := &http.Client{}
+	defer client.CloseIdleConnections()
+	req, _ := http.NewRequest(http.MethodGet, base+"/app", nil)
+	req.Header.Set(forwardedUserHeader, "admin@example.com")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(body) != 0 {
+		t.Errorf("backend got %d %q, want no forwarded user", resp.StatusCode, body)
+	}
+}
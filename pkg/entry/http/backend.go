@@ -0,0 +1,291 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/singchia/geminio"
+	"golang.org/x/net/http2"
+)
+
+// maxIdleBackendConns 每个客户端连接最多保留的空闲 stream。HTTP/1.1 客户端同时只有一个请求，
+// HTTP/2 客户端的并发请求可能用到多个
+const maxIdleBackendConns = 8
+
+var errPoolClosed = errors.New("client connection closed")
+
+// backendConn 一条已经与 edge 握手、承载 HTTP/1.1 的 stream。请求结束后放回 backendPool，
+// 同一个客户端连接上的后续请求复用它，edge 到后端的连接也因此保持 keep-alive
+type backendConn struct {
+	stream     geminio.Stream
+	wire       *wireCounter
+	conn       net.Conn // 可能经过压缩
+	reader     *bufio.Reader
+	route      *httpRoute // nil 表示代理自己的应用
+	up         upstream
+	compressed bool
+	// 是否已经承载过请求，复用的 stream 可能已经被后端关闭
+	reused bool
+}
+
+func (bc *backendConn) close() {
+	bc.stream.Close()
+}
+
+// wireBytes 返回上次统计之后 stream 上的 wire 流量，没有压缩时与 logical 流量一致。
+// bc 为 nil（响应来自缓存）时没有 wire 流量
+func (bc *backendConn) wireBytes(logicalIn, logicalOut int64) (int64, int64) {
+	if bc == nil {
+		return 0, 0
+	}
+	return bc.wire.bytes(bc.compressed, logicalIn, logicalOut)
+}
+
+// h2cConn 到 h2c 后端的 HTTP/2 连接，一个客户端连接上的请求在其上多路复用
+type h2cConn struct {
+	bc *backendConn
+	cc *http2.ClientConn
+}
+
+// backendPool 一个客户端连接使用的到后端的 stream，随客户端连接关闭。
+// 复用 stream 省去每个请求打开 stream 和等待 edge 拨号结果的往返；后端和 edge 在建立 stream 时选定，
+// 所以同一个客户端连接上、匹配同一条路由的请求落在同一个后端
+type backendPool struct {
+	server     *Server
+	ctx        context.Context
+	clientConn net.Conn
+	protoproxy *proto.Proxy
+
+	mu     sync.Mutex
+	idle   []*backendConn
+	h2c    map[*httpRoute]*h2cConn
+	closed bool
+}
+
+func newBackendPool(s *Server, ctx context.Context, clientConn net.Conn, protoproxy *proto.Proxy) *backendPool {
+	return &backendPool{server: s, ctx: ctx, clientConn: clientConn, protoproxy: protoproxy, h2c: make(map[*httpRoute]*h2cConn)}
+}
+
+// dial 按代理或者路由的 edge 策略打开 stream，写入目标地址并等待 edge 的拨号结果
+func (p *backendPool) dial(route *httpRoute) (*backendConn, error) {
+	stream, up, err := p.server.openStream(p.ctx, p.protoproxy, route)
+	if err != nil {
+		log.Errorf("failed to open stream: %s", err)
+		return nil, fmt.Errorf("%w: %s", errEdgeUnavailable, err)
+	}
+	if tracked := trackedEntry(p.clientConn); tracked != nil {
+		tracked.SetEdge(up.edgeID)
+	}
+	wire := &wireCounter{Conn: stream}
+	conn, compressed, err := p.server.writeDstInfo(wire, p.clientConn, p.protoproxy, up)
+	if err != nil {
+		log.Warnf("http proxy %d handshake with edge %d err: %s", p.protoproxy.ID, up.edgeID, err)
+		stream.Close()
+		return nil, err
+	}
+	return &backendConn{
+		stream:     stream,
+		wire:       wire,
+		conn:       conn,
+		reader:     bufio.NewReader(conn),
+		route:      route,
+		up:         up,
+		compressed: compressed,
+	}, nil
+}
+
+// get 取一条到路由对应应用的空闲 stream，没有时新建
+func (p *backendPool) get(route *httpRoute) (*backendConn, error) {
+	p.mu.Lock()
+	for i := len(p.idle) - 1; i >= 0; i-- {
+		if bc := p.idle[i]; bc.route == route {
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			p.mu.Unlock()
+			return bc, nil
+		}
+	}
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return nil, errPoolClosed
+	}
+	return p.dial(route)
+}
+
+// put 放回空闲的 stream，超出上限时关闭最早放回的
+func (p *backendPool) put(bc *backendConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		bc.close()
+		return
+	}
+	if len(p.idle) >= maxIdleBackendConns {
+		p.idle[0].close()
+		p.idle = p.idle[1:]
+	}
+	bc.reused = true
+	p.idle = append(p.idle, bc)
+}
+
+// close 关闭空闲的 stream；正在使用的 stream 在响应体关闭时关闭
+func (p *backendPool) close() {
+	p.mu.Lock()
+	idle, h2c := p.idle, p.h2c
+	p.idle, p.h2c, p.closed = nil, nil, true
+	p.mu.Unlock()
+	for _, bc := range idle {
+		bc.close()
+	}
+	for _, conn := range h2c {
+		conn.cc.Close()
+		conn.bc.close()
+	}
+}
+
+// forward 经 stream 把请求发给后端并读取响应，同时返回承载本次请求的 stream，用于统计 wire 流量。
+// 关闭响应体时，读完且后端没有要求关闭的 stream 放回池中。复用的 stream 上失败、
+// 请求可以安全重发时换一条 stream 重试。route 为 nil 时转发到代理自己的应用
+func (p *backendPool) forward(req *http.Request, route *httpRoute) (*http.Response, *backendConn, error) {
+	if p.protoproxy.BackendProtocol == proto.BackendProtocolH2C {
+		return p.forwardH2C(req, route)
+	}
+	for {
+		bc, err := p.get(route)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, err := p.roundTrip(bc, req)
+		if err == nil {
+			return resp, bc, nil
+		}
+		bc.close()
+		if bc.reused && replayable(req) {
+			log.Debugf("http proxy %d reused stream to %s broken, retry: %s", p.protoproxy.ID, bc.up.dst, err)
+			continue
+		}
+		log.Errorf("http proxy %d forward to %s err: %s", p.protoproxy.ID, bc.up.dst, err)
+		return nil, nil, err
+	}
+}
+
+// roundTrip 以 HTTP/1.1 把请求写到 stream 并读取响应。来自 HTTP/2 客户端、长度未知的请求体由 Request.Write 用 chunked 发送
+func (p *backendPool) roundTrip(bc *backendConn, req *http.Request) (*http.Response, error) {
+	if err := p.server.sendRequest(req.Context(), bc.conn, req, bc.up.dst); err != nil {
+		return nil, err
+	}
+	resp, err := p.server.readResponse(req.Context(), bc.reader, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &pooledBody{
+		ReadCloser: resp.Body,
+		eof:        resp.Body == http.NoBody,
+		release: func(reusable bool) {
+			if reusable && !resp.Close {
+				p.put(bc)
+			} else {
+				bc.close()
+			}
+		},
+	}
+	return resp, nil
+}
+
+// forwardH2C 在客户端连接共享的、到路由对应应用的 h2c 连接上转发请求
+func (p *backendPool) forwardH2C(req *http.Request, route *httpRoute) (*http.Response, *backendConn, error) {
+	for {
+		h2c, reused, err := p.h2cConn(route)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, err := roundTripH2C(h2c.cc, req, h2c.bc.up.dst)
+		if err == nil {
+			return resp, h2c.bc, nil
+		}
+		// 连接仍然可用说明只是这个请求失败，不重试
+		if reused && !h2c.cc.CanTakeNewRequest() && replayable(req) {
+			log.Debugf("http proxy %d reused h2c connection to %s broken, retry: %s", p.protoproxy.ID, h2c.bc.up.dst, err)
+			continue
+		}
+		log.Errorf("http proxy %d forward to %s err: %s", p.protoproxy.ID, h2c.bc.up.dst, err)
+		return nil, nil, err
+	}
+}
+
+// h2cConn 返回可用的 h2c 连接，没有或者已经不能再发请求时新建；旧连接上的请求结束后关闭旧连接
+func (p *backendPool) h2cConn(route *httpRoute) (*h2cConn, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, false, errPoolClosed
+	}
+	if old := p.h2c[route]; old != nil {
+		if old.cc.CanTakeNewRequest() {
+			return old, true, nil
+		}
+		delete(p.h2c, route)
+		go func() {
+			_ = old.cc.Shutdown(context.Background())
+			old.bc.close()
+		}()
+	}
+	bc, err := p.dial(route)
+	if err != nil {
+		return nil, false, err
+	}
+	cc, err := (&http2.Transport{AllowHTTP: true}).NewClientConn(bc.conn)
+	if err != nil {
+		bc.close()
+		return nil, false, fmt.Errorf("failed to start h2c: %w", err)
+	}
+	conn := &h2cConn{bc: bc, cc: cc}
+	p.h2c[route] = conn
+	return conn, false, nil
+}
+
+// replayable 请求是否可以安全地重发：没有请求体的幂等请求
+func replayable(req *http.Request) bool {
+	if req.ContentLength != 0 || req.TransferEncoding != nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// pooledBody 响应体读完并关闭后才能把 stream 给下一个请求使用，没读完就关闭的 stream 上还有残留数据，直接关闭
+type pooledBody struct {
+	io.ReadCloser
+	eof     bool
+	release func(reusable bool)
+	once    sync.Once
+}
+
+func (b *pooledBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+func (b *pooledBody) Close() error {
+	if !b.eof {
+		// 先关闭 stream，否则 Close 会把剩余的响应体读完，流式响应可能永远读不完
+		b.once.Do(func() { b.release(false) })
+	}
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.release(err == nil) })
+	return err
+}
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/singchia/geminio"
+)
+
+// fakeEdge 模拟 frontier 和 edge：打开 stream 和 edge 拨号后端各有一次 latency 的往返，
+// 握手之后 stream 直接由 handler 按 HTTP/1.1 keep-alive 处理。legacy 模拟不写回 DialResult 的老版本 edge
+type fakeEdge struct {
+	handler http.Handler
+	latency time.Duration
+	legacy  bool
+	opened  atomic.Int64
+}
+
+func (e *fakeEdge) OpenStream(ctx context.Context, edgeID uint64) (geminio.Stream, error) {
+	time.Sleep(e.latency)
+	e.opened.Add(1)
+	entrySide, edgeSide := net.Pipe()
+	go e.serve(edgeSide)
+	return &pipeStream{conn: entrySide}, nil
+}
+
+func (e *fakeEdge) EdgeProtocol(edgeID uint64) int {
+	if e.legacy {
+		return proto.ProtocolLegacy
+	}
+	return proto.ProtocolVersion
+}
+
+func (e *fakeEdge) Close() error {
+	return nil
+}
+
+func (e *fakeEdge) serve(conn net.Conn) {
+	var dst proto.Dst
+	if err := proto.ReadFrame(conn, &dst); err != nil {
+		conn.Close()
+		return
+	}
+	time.Sleep(e.latency)
+	if e.legacy != (dst.Version < proto.ProtocolDialResult) {
+		conn.Close()
+		return
+	}
+	if !e.legacy {
+		if err := proto.WriteFrame(conn, &proto.DialResult{Code: proto.DialCodeOK}); err != nil {
+			conn.Close()
+			return
+		}
+	}
+	// 响应头带上 stream 握手时的应用 ID
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test-Application", fmt.Sprint(dst.ApplicationID))
+		e.handler.ServeHTTP(w, r)
+	})
+	_ = (&http.Server{Handler: handler}).Serve(&singleConnListener{conn: conn})
+}
+
+// pipeStream 只实现 net.Conn 部分的 geminio.Stream
+type pipeStream struct {
+	geminio.Stream
+	conn net.Conn
+}
+
+func (p *pipeStream) Read(b []byte) (int, error)         { return p.conn.Read(b) }
+func (p *pipeStream) Write(b []byte) (int, error)        { return p.conn.Write(b) }
+func (p *pipeStream) Close() error                       { return p.conn.Close() }
+func (p *pipeStream) LocalAddr() net.Addr                { return p.conn.LocalAddr() }
+func (p *pipeStream) RemoteAddr() net.Addr               { return p.conn.RemoteAddr() }
+func (p *pipeStream) SetDeadline(t time.Time) error      { return p.conn.SetDeadline(t) }
+func (p *pipeStream) SetReadDeadline(t time.Time) error  { return p.conn.SetReadDeadline(t) }
+func (p *pipeStream) SetWriteDeadline(t time.Time) error { return p.conn.SetWriteDeadline(t) }
+
+// singleConnListener 只 Accept 一次的 listener
+type singleConnListener struct {
+	conn net.Conn
+	once sync.Once
+	done chan struct{}
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.once.Do(func() {
+		conn = l.conn
+		l.done = make(chan struct{})
+	})
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.done
+	return nil, net.ErrClosed
+}
+
+func (l *singleConnListener) Close() error {
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+func startTestProxy(t testing.TB, edge *fakeEdge) (*Server, string) {
+	s := NewServer(edge)
+	protoproxy := &proto.Proxy{ID: 1, Name: "web", EdgeIDs: []uint64{1}, Dst: "10.0.0.1:8080", ApplicationType: "http"}
+	if err := s.CreateProxy(context.Background(), protoproxy); err != nil {
+		t.Fatalf("create proxy: %v", err)
+	}
+	t.Cleanup(s.Close)
+	return s, fmt.Sprintf("http://127.0.0.1:%d", protoproxy.ProxyPort)
+}
+
+func TestBackendStreamReuse(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/close" {
+			w.Header().Set("Connection", "close")
+		}
+		io.WriteString(w, r.URL.Path)
+	})}
+	_, url := startTestProxy(t, edge)
+	client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: 1}}
+	defer client.CloseIdleConnections()
+
+	get := func(path string) {
+		t.Helper()
+		resp, err := client.Get(url + path)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != path {
+			t.Fatalf("get %s: body %q", path, body)
+		}
+	}
+	for _, path := range []string{"/a", "/b", "/c"} {
+		get(path)
+	}
+	if n := edge.opened.Load(); n != 1 {
+		t.Errorf("opened %d streams for 3 keep-alive requests, want 1", n)
+	}
+	// 后端要求关闭之后换新的 stream
+	get("/close")
+	get("/d")
+	if n := edge.opened.Load(); n != 2 {
+		t.Errorf("opened %d streams, want 2", n)
+	}
+}
+
+// 老版本 edge 不写回 DialResult，写入 Dst 之后直接转发
+func TestLegacyEdge(t *testing.T) {
+	edge := &fakeEdge{legacy: true, handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "legacy")
+	})}
+	_, url := startTestProxy(t, edge)
+	client := &http.Client{Timeout: 5 * time.Second}
+	defer client.CloseIdleConnections()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "legacy" {
+		t.Errorf("got %d %q", resp.StatusCode, body)
+	}
+}
+
+// 模拟每次打开 stream 和 edge 拨号各 1ms 的往返：keep-alive 连接上复用 stream，
+// 对比每个请求新建客户端连接（因而新建 stream）的情况
+func BenchmarkBackendStream(b *testing.B) {
+	edge := &fakeEdge{
+		latency: time.Millisecond,
+		handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "ok")
+		}),
+	}
+	_, url := startTestProxy(b, edge)
+	for _, bc := range []struct {
+		name      string
+		keepAlive bool
+	}{{"reuse", true}, {"per-request", false}} {
+		b.Run(bc.name, func(b *testing.B) {
+			client := &http.Client{Transport: &http.Transport{DisableKeepAlives: !bc.keepAlive}}
+			defer client.CloseIdleConnections()
+			for i := 0; i < b.N; i++ {
+				resp, err := client.Get(url)
+				if err != nil {
+					b.Fatal(err)
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+		})
+	}
+}
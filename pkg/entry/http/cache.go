@@ -0,0 +1,455 @@
+package http
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// responseCache 代理的响应缓存，作为共享缓存遵循后端的 Cache-Control。按占用的字节数 LRU 淘汰
+type responseCache struct {
+	mu      sync.Mutex
+	conf    proto.ResponseCache // 已经填上默认值
+	entries map[string]*cacheEntry
+	lru     *list.List // 最近使用的在前
+	size    int64
+}
+
+// cacheEntry 缓存的一个响应。条目不会被修改，重新验证时换成新的条目
+type cacheEntry struct {
+	key           string
+	applicationID uint
+	// 响应 Vary 中的请求头和存入时这些请求头的值，一个 URL 只保留最近的一个变体
+	vary       []string
+	varyValues []string
+	header     http.Header
+	body       []byte
+	stored     time.Time     // 收到响应或者重新验证的时间
+	initialAge time.Duration // 收到时响应已有的 Age
+	lifetime   time.Duration // 新鲜期，0 表示每次使用前都要重新验证
+	elem       *list.Element
+}
+
+func (e *cacheEntry) size() int64 {
+	return int64(len(e.key)+len(e.body)) + headerBytes(e.header)
+}
+
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	return e.initialAge + now.Sub(e.stored)
+}
+
+// validatable 过期之后能否向后端重新验证
+func (e *cacheEntry) validatable() bool {
+	return e.header.Get("ETag") != "" || e.header.Get("Last-Modified") != ""
+}
+
+func (p *httpProxy) setResponseCache(conf proto.ResponseCache) {
+	if !conf.Enabled {
+		p.cache.Store(nil)
+		return
+	}
+	if conf.MaxBytes <= 0 {
+		conf.MaxBytes = proto.DefaultCacheBytes
+	}
+	if conf.MaxObjectBytes <= 0 {
+		conf.MaxObjectBytes = proto.DefaultCacheObjectBytes
+	}
+	conf.MaxObjectBytes = min(conf.MaxObjectBytes, conf.MaxBytes)
+	if current := p.cache.Load(); current != nil {
+		current.resize(conf)
+		return
+	}
+	p.cache.Store(&responseCache{conf: conf, entries: make(map[string]*cacheEntry), lru: list.New()})
+}
+
+// resize 修改容量，超出新容量的条目被淘汰
+func (c *responseCache) resize(conf proto.ResponseCache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conf = conf
+	c.evict()
+}
+
+// get 返回与请求的 Vary 请求头匹配的条目
+func (c *responseCache) get(key string, req *http.Request) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entries[key]
+	if e == nil {
+		return nil
+	}
+	for i, name := range e.vary {
+		if strings.Join(req.Header.Values(name), ", ") != e.varyValues[i] {
+			return nil
+		}
+	}
+	c.lru.MoveToFront(e.elem)
+	return e
+}
+
+func (c *responseCache) put(e *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeLocked(e.key)
+	if e.size() > c.conf.MaxBytes {
+		return
+	}
+	e.elem = c.lru.PushFront(e)
+	c.entries[e.key] = e
+	c.size += e.size()
+	c.evict()
+}
+
+func (c *responseCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeLocked(key)
+}
+
+func (c *responseCache) removeLocked(key string) {
+	if e := c.entries[key]; e != nil {
+		c.lru.Remove(e.elem)
+		delete(c.entries, key)
+		c.size -= e.size()
+	}
+}
+
+// evict 淘汰最久没有使用的条目直到不超过容量，调用方持有锁
+func (c *responseCache) evict() {
+	for c.size > c.conf.MaxBytes {
+		c.removeLocked(c.lru.Back().Value.(*cacheEntry).key)
+	}
+}
+
+func (c *responseCache) maxObjectBytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conf.MaxObjectBytes
+}
+
+// cacheLookup 一个请求在响应缓存中的查找，nil 表示请求不经过缓存
+type cacheLookup struct {
+	cache *responseCache
+	key   string
+	req   *http.Request
+	// 匹配的条目，过期时用于重新验证
+	entry *cacheEntry
+	// 带上条目的验证器转发给后端，后端返回 304 时使用缓存
+	revalidating bool
+	// 修改资源的请求，后端成功响应之后删除缓存
+	invalidate bool
+	// 登录保护的代理，后端的响应可能是按登录用户生成的
+	login bool
+	stats proto.CacheStats
+}
+
+// lookupCache 在请求转发给后端之前查找缓存，键为请求所到的应用和改写之后的主机名、路径
+func (s *Server) lookupCache(req *http.Request, protoproxy *proto.Proxy, route *httpRoute) *cacheLookup {
+	proxy := s.runningProxy(protoproxy.ID)
+	if proxy == nil {
+		return nil
+	}
+	cache := proxy.cache.Load()
+	if cache == nil {
+		return nil
+	}
+	key := fmt.Sprintf("%d %s%s", routeApplication(route), req.Host, req.URL.RequestURI())
+	auth := proxy.auth.Load()
+	login := auth != nil && auth.Required
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodOptions, http.MethodTrace:
+		return nil
+	default:
+		return &cacheLookup{cache: cache, key: key, req: req, invalidate: true}
+	}
+	if req.Header.Get("Range") != "" || parseCacheControl(req.Header).has("no-store") {
+		return nil
+	}
+	return &cacheLookup{cache: cache, key: key, req: req, entry: cache.get(key, req), login: login}
+}
+
+// hit 缓存中有新鲜的条目时返回由缓存生成的响应和条目所属的应用。条目过期、可以重新验证时
+// 在请求上加上条件头，后端的响应交给 store 处理
+func (l *cacheLookup) hit() (*http.Response, uint) {
+	if l == nil || l.invalidate || l.entry == nil {
+		return nil, 0
+	}
+	e, now := l.entry, time.Now()
+	if e.age(now) < min(e.lifetime, requestMaxAge(l.req.Header)) {
+		return l.respond(e, now), e.applicationID
+	}
+	// 客户端自己带了条件头时原样转发
+	if e.validatable() && l.req.Header.Get("If-None-Match") == "" && l.req.Header.Get("If-Modified-Since") == "" {
+		if etag := e.header.Get("ETag"); etag != "" {
+			l.req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := e.header.Get("Last-Modified"); lastModified != "" {
+			l.req.Header.Set("If-Modified-Since", lastModified)
+		}
+		l.revalidating = true
+	}
+	return nil, 0
+}
+
+// store 处理后端的响应：重新验证得到 304 时刷新条目并返回由缓存生成的响应；
+// 可以缓存的响应在响应体读完之后存入缓存
+func (l *cacheLookup) store(resp *http.Response, applicationID uint) *http.Response {
+	if l == nil {
+		return resp
+	}
+	if l.invalidate {
+		if resp.StatusCode < http.StatusBadRequest {
+			l.cache.remove(l.key)
+		}
+		return resp
+	}
+	now := time.Now()
+	if l.revalidating && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		e := l.entry.refresh(resp.Header, now)
+		l.cache.put(e)
+		return l.respond(e, now)
+	}
+	l.stats.Misses++
+	if l.req.Method != http.MethodGet {
+		return resp
+	}
+	lifetime, ok := cacheable(l.req, resp, l.login)
+	if !ok || resp.ContentLength > l.cache.maxObjectBytes() {
+		// 资源已经不能缓存，丢弃旧的条目
+		if l.entry != nil {
+			l.cache.remove(l.key)
+		}
+		return resp
+	}
+	header := resp.Header.Clone()
+	removeHopHeaders(header)
+	header.Del("Content-Length")
+	e := &cacheEntry{
+		key:           l.key,
+		applicationID: applicationID,
+		header:        header,
+		stored:        now,
+		initialAge:    ageOf(resp.Header),
+		lifetime:      lifetime,
+	}
+	for _, value := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = http.CanonicalHeaderKey(strings.TrimSpace(name)); name != "" {
+				e.vary = append(e.vary, name)
+				e.varyValues = append(e.varyValues, strings.Join(l.req.Header.Values(name), ", "))
+			}
+		}
+	}
+	resp.Body = &cachingBody{ReadCloser: resp.Body, cache: l.cache, entry: e, limit: l.cache.maxObjectBytes()}
+	return resp
+}
+
+// respond 由条目生成响应。客户端的条件请求与条目匹配时返回 304，重新验证时请求上的条件头是缓存自己加的
+func (l *cacheLookup) respond(e *cacheEntry, now time.Time) *http.Response {
+	l.stats.Hits++
+	resp := &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		ContentLength: int64(len(e.body)),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		Request:       l.req,
+	}
+	resp.Header.Set("Age", strconv.Itoa(int(e.age(now).Seconds())))
+	if !l.revalidating && notModified(l.req.Header, e.header) {
+		resp.Status, resp.StatusCode = "304 Not Modified", http.StatusNotModified
+		resp.ContentLength, resp.Body = 0, http.NoBody
+		return resp
+	}
+	if l.req.Method == http.MethodHead {
+		resp.Body = http.NoBody
+		return resp
+	}
+	l.stats.SavedBytes += int64(len(e.body))
+	return resp
+}
+
+// refresh 用 304 响应的头更新条目，返回新的条目
+func (e *cacheEntry) refresh(header http.Header, now time.Time) *cacheEntry {
+	updated := *e
+	updated.header = e.header.Clone()
+	for k, v := range header {
+		switch k {
+		case "Content-Length", "Content-Encoding", "Content-Type", "Transfer-Encoding", "Connection", "Keep-Alive":
+			continue
+		}
+		updated.header[k] = v
+	}
+	updated.stored, updated.initialAge = now, ageOf(header)
+	updated.lifetime = freshnessLifetime(updated.header, parseCacheControl(updated.header))
+	return &updated
+}
+
+// cacheable 共享缓存能否存储响应，可以时返回新鲜期。没有新鲜期的响应只在可以重新验证时存储。
+// 登录保护代理的请求都带着登录用户，和带 Authorization 的请求一样，只存储明确允许共享的响应
+func cacheable(req *http.Request, resp *http.Response, login bool) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Set-Cookie") != "" || resp.Header.Get("Content-Range") != "" {
+		return 0, false
+	}
+	cc := parseCacheControl(resp.Header)
+	if cc.has("no-store") || cc.has("private") {
+		return 0, false
+	}
+	if req.Header.Get("Authorization") != "" && !cc.has("public") && !cc.has("s-maxage") && !cc.has("must-revalidate") {
+		return 0, false
+	}
+	if login && !cc.has("public") && !cc.has("s-maxage") {
+		return 0, false
+	}
+	for _, value := range resp.Header.Values("Vary") {
+		if strings.TrimSpace(value) == "*" {
+			return 0, false
+		}
+	}
+	lifetime := freshnessLifetime(resp.Header, cc)
+	if lifetime <= 0 && resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return 0, false
+	}
+	return lifetime, true
+}
+
+// freshnessLifetime 按 s-maxage、max-age、Expires 的顺序计算新鲜期，no-cache 时为 0
+func freshnessLifetime(header http.Header, cc cacheControl) time.Duration {
+	if cc.has("no-cache") {
+		return 0
+	}
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if seconds, ok := cc.seconds(directive); ok {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = time.Now()
+	}
+	return max(expires.Sub(date), 0)
+}
+
+// requestMaxAge 客户端能接受的最大 Age：no-cache 要求先重新验证，没有限制时为最大值
+func requestMaxAge(header http.Header) time.Duration {
+	cc := parseCacheControl(header)
+	if cc.has("no-cache") || len(cc) == 0 && strings.Contains(header.Get("Pragma"), "no-cache") {
+		return 0
+	}
+	if seconds, ok := cc.seconds("max-age"); ok {
+		// 等于 max-age 的 Age 仍然可以接受
+		return time.Duration(seconds)*time.Second + time.Nanosecond
+	}
+	return time.Duration(1<<63 - 1)
+}
+
+func ageOf(header http.Header) time.Duration {
+	seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// notModified 请求的条件头是否与缓存的响应匹配，If-None-Match 优先，ETag 按弱比较
+func notModified(reqHeader, header http.Header) bool {
+	if inm := reqHeader.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(header.Get("ETag"), "W/")
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(reqHeader.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+	return err == nil && !modified.After(since)
+}
+
+// cacheControl 解析之后的 Cache-Control，指令名为小写
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				cc[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+			}
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+func (cc cacheControl) seconds(directive string) (int64, bool) {
+	arg, ok := cc[directive]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || seconds < 0 {
+		// 无法解析的值按已经过期处理
+		return 0, true
+	}
+	return seconds, true
+}
+
+// cachingBody 边转发边保留响应体，完整读完并且不超过 limit 时存入缓存
+type cachingBody struct {
+	io.ReadCloser
+	cache  *responseCache
+	entry  *cacheEntry
+	limit  int64
+	buf    bytes.Buffer
+	tooBig bool
+	stored bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.tooBig {
+		if int64(b.buf.Len()+n) > b.limit {
+			b.tooBig = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.tooBig && !b.stored {
+		b.stored = true
+		b.entry.body = b.buf.Bytes()
+		b.cache.put(b.entry)
+	}
+	return n, err
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestResponseCache(t *testing.T) {
+	var requests atomic.Int64
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/app.js":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+			io.WriteString(w, "console.log(1)")
+		case "/etag":
+			// 每次使用前都要重新验证
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			io.WriteString(w, "tagged")
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+			io.WriteString(w, "mine")
+		}
+	})}
+	s, base := startTestProxy(t, edge)
+	s.proxies[1].setResponseCache(proto.ResponseCache{Enabled: true})
+	client := &http.Client{}
+	defer client.CloseIdleConnections()
+	do := func(method, path string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, base+path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp, string(body)
+	}
+	expect := func(path, want string, backendRequests int64) *http.Response {
+		t.Helper()
+		resp, body := do(http.MethodGet, path)
+		if resp.StatusCode != http.StatusOK || body != want {
+			t.Fatalf("get %s: %d %q", path, resp.StatusCode, body)
+		}
+		if n := requests.Load(); n != backendRequests {
+			t.Fatalf("get %s: backend got %d requests, want %d", path, n, backendRequests)
+		}
+		return resp
+	}
+
+	expect("/app.js", "console.log(1)", 1)
+	if resp := expect("/app.js", "console.log(1)", 1); resp.Header.Get("Age") == "" {
+		t.Error("cached response without Age")
+	}
+	// 过期的条目用 ETag 重新验证，后端返回 304 时客户端仍然收到完整的响应
+	expect("/etag", "tagged", 2)
+	expect("/etag", "tagged", 3)
+	expect("/private", "mine", 4)
+	expect("/private", "mine", 5)
+	// 修改资源的请求使缓存失效
+	do(http.MethodPost, "/app.js")
+	expect("/app.js", "console.log(1)", 7)
+
+	// 统计在响应写出之后记录
+	want := proto.CacheStats{Hits: 2, Misses: 5, SavedBytes: int64(len("console.log(1)") + len("tagged"))}
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		s.mu.Lock()
+		var got proto.CacheStats
+		if stats := s.trafficStats["1:0"]; stats != nil {
+			got = stats.Cache
+		}
+		s.mu.Unlock()
+		if got == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("cache stats = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	p := &httpProxy{}
+	p.setResponseCache(proto.ResponseCache{Enabled: true, MaxBytes: 350})
+	cache := p.cache.Load()
+	req, _ := http.NewRequest(http.MethodGet, "http://web/", nil)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.put(&cacheEntry{key: key, header: http.Header{}, body: make([]byte, 100)})
+	}
+	cache.get("a", req)
+	cache.put(&cacheEntry{key: "d", header: http.Header{}, body: make([]byte, 100)})
+	// b 最久没有使用
+	if cache.get("b", req) != nil || cache.get("a", req) == nil || cache.get("d", req) == nil {
+		t.Errorf("unexpected entries after eviction: %v", cache.entries)
+	}
+	p.setResponseCache(proto.ResponseCache{Enabled: true, MaxBytes: 150})
+	if len(cache.entries) != 1 || cache.size > 150 {
+		t.Errorf("%d entries, %d bytes after shrinking", len(cache.entries), cache.size)
+	}
+}
+
+func TestResponseCacheLoginProxy(t *testing.T) {
+	var requests atomic.Int64
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/me":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/logo":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		}
+		io.WriteString(w, r.Header.Get(forwardedUserHeader))
+	})}
+	s, base := startTestProxy(t, edge)
+	s.SetUserAuthenticator(&fakeAuthenticator{}, []byte("jwt secret"))
+	s.proxies[1].setAuth(proto.ProxyAuth{Required: true})
+	s.proxies[1].setResponseCache(proto.ResponseCache{Enabled: true})
+	client := &http.Client{}
+	defer client.CloseIdleConnections()
+	get := func(path, email string) string {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, base+path, nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookieName(1), Value: s.auth.sign(&session{
+			UserID: 1, Email: email, ProxyID: 1, Expires: time.Now().Add(time.Hour).Unix(),
+		})})
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return string(body)
+	}
+
+	// 按登录用户生成的响应不在用户之间共享
+	if a, b := get("/me", "alice@example.com"), get("/me", "bob@example.com"); a != "alice@example.com" || b != "bob@example.com" {
+		t.Errorf("/me: alice got %q, bob got %q", a, b)
+	}
+	// 明确 public 的响应可以共享
+	get("/logo", "alice@example.com")
+	get("/logo", "bob@example.com")
+	if n := requests.Load(); n != 3 {
+		t.Errorf("backend got %d requests, want 3", n)
+	}
+}
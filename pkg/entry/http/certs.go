@@ -0,0 +1,202 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+)
+
+// acmeChallengePrefix HTTP-01 验证请求的路径前缀
+const acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+// SetCertificates 设置 HTTPS 代理使用的证书，只影响之后创建的监听。
+// certs 为静态证书，按 SNI 选择；acmeManager 非空时为虚拟主机名自动签发、续期证书，
+// 证书通过 GetCertificate 在握手时取得，续期后无需重建监听。没有 ACME 证书的主机名回退到静态证书
+func (s *Server) SetCertificates(certs []tls.Certificate, acmeManager *autocert.Manager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.certs = certs
+	s.acme = acmeManager
+}
+
+// HostPolicy 只为运行中代理的主机名签发证书，避免任意 SNI 触发签发
+func (s *Server) HostPolicy(_ context.Context, host string) error {
+	if s.lookupHost(normalizeHost(host)) == nil {
+		return fmt.Errorf("host %q is not served by any proxy", host)
+	}
+	return nil
+}
+
+// SetCertificate 加载或替换管理端上传的证书。握手时才取证书，替换后新连接立即使用新证书，已建立的连接不受影响
+func (s *Server) SetCertificate(id uint, certPEM, keyPEM []byte) error {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid certificate %d: %w", id, err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return fmt.Errorf("invalid certificate %d: %w", id, err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploaded[id] = &cert
+	log.Infof("certificate %d loaded, %v expires at %s", id, cert.Leaf.DNSNames, cert.Leaf.NotAfter.Format(time.DateTime))
+	return nil
+}
+
+// RemoveCertificate 卸载管理端上传的证书
+func (s *Server) RemoveCertificate(id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.uploaded, id)
+}
+
+// LoadedCertificates 返回已加载的上传证书及其到期时间
+func (s *Server) LoadedCertificates() map[uint]time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	loaded := make(map[uint]time.Time, len(s.uploaded))
+	for id, cert := range s.uploaded {
+		loaded[id] = cert.Leaf.NotAfter
+	}
+	return loaded
+}
+
+// tlsConfig 返回监听使用的 TLS 配置。proxyID 为独立端口所属的代理，共享监听为 0，按 SNI 找代理
+func (s *Server) tlsConfig(proxyID int) *tls.Config {
+	config := &tls.Config{
+		Certificates: s.certs,
+		// 优先 HTTP/2，见 serveHTTP2
+		NextProtos: []string{http2.NextProtoTLS, "http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return s.getCertificate(hello, proxyID)
+		},
+	}
+	if s.acme != nil {
+		// TLS-ALPN-01 验证
+		config.NextProtos = append(config.NextProtos, acme.ALPNProto)
+	}
+	return config
+}
+
+// getCertificate 按顺序选择证书：代理绑定的证书、SNI 匹配的上传证书、代理主机名的 ACME 证书，
+// 都没有时返回 nil 以使用静态证书
+func (s *Server) getCertificate(hello *tls.ClientHelloInfo, proxyID int) (*tls.Certificate, error) {
+	host := normalizeHost(hello.ServerName)
+	s.mu.RLock()
+	acmeManager, fallback := s.acme, len(s.certs) > 0
+	proxy := s.proxies[proxyID]
+	if proxyID == 0 {
+		if id, ok := s.hosts[host]; ok {
+			proxy = s.proxies[id]
+		}
+	}
+	var bound, matched *tls.Certificate
+	if proxy != nil {
+		bound = s.uploaded[uint(proxy.certificateID.Load())]
+	}
+	if bound == nil && host != "" {
+		// 多个证书都匹配时取最晚到期的
+		for _, cert := range s.uploaded {
+			if cert.Leaf.VerifyHostname(host) == nil &&
+				(matched == nil || cert.Leaf.NotAfter.After(matched.Leaf.NotAfter)) {
+				matched = cert
+			}
+		}
+	}
+	_, served := s.hosts[host]
+	s.mu.RUnlock()
+
+	if acmeManager != nil {
+		for _, proto := range hello.SupportedProtos {
+			if proto == acme.ALPNProto {
+				return acmeManager.GetCertificate(hello)
+			}
+		}
+	}
+	if bound != nil {
+		return bound, nil
+	}
+	if matched != nil {
+		return matched, nil
+	}
+	if acmeManager == nil || !served {
+		return nil, nil
+	}
+	cert, err := acmeManager.GetCertificate(hello)
+	if err != nil {
+		if fallback {
+			log.Warnf("acme certificate for %s unavailable, using static certificate: %s", hello.ServerName, err)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cert, nil
+}
+
+// serveACMEChallenge 在共享 HTTP 监听上响应 HTTP-01 验证，请求不是验证请求时返回 false
+func (s *Server) serveACMEChallenge(conn net.Conn, req *http.Request) bool {
+	s.mu.RLock()
+	acmeManager := s.acme
+	s.mu.RUnlock()
+	if acmeManager == nil || !strings.HasPrefix(req.URL.Path, acmeChallengePrefix) {
+		return false
+	}
+	rw := &bufferedResponse{header: make(http.Header)}
+	acmeManager.HTTPHandler(nil).ServeHTTP(rw, req)
+	if err := rw.writeTo(conn); err != nil {
+		log.Debugf("write acme challenge response to %s err: %s", conn.RemoteAddr(), err)
+	}
+	return true
+}
+
+// bufferedResponse 把 http.Handler 的输出缓存下来，写成一个完整的 HTTP/1.1 响应
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponse) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponse) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponse) writeTo(conn net.Conn) error {
+	w.WriteHeader(http.StatusOK)
+	resp := &http.Response{
+		StatusCode:    w.status,
+		Status:        fmt.Sprintf("%d %s", w.status, http.StatusText(w.status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		ContentLength: int64(w.body.Len()),
+		Body:          io.NopCloser(&w.body),
+	}
+	resp.Header.Set("Connection", "close")
+	return resp.Write(conn)
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newTestCert 生成 host 的自签证书
+func newTestCert(t *testing.T, host string, notAfter time.Time) (*tls.Certificate, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	// autocert 缓存的格式：私钥在前，证书链在后
+	var cached bytes.Buffer
+	_ = pem.Encode(&cached, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	_ = pem.Encode(&cached, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, cached.Bytes()
+}
+
+// newTestACMEServer 返回服务 app.example.com 的 Server，ACME 缓存里已有该主机名的证书，
+// ACME 目录不可达，其它主机名签发都会失败
+func newTestACMEServer(t *testing.T) (*Server, *tls.Certificate) {
+	acmeCert, cached := newTestCert(t, "app.example.com", time.Now().Add(90*24*time.Hour))
+	cache := autocert.DirCache(t.TempDir())
+	if err := cache.Put(context.TODO(), "app.example.com", cached); err != nil {
+		t.Fatal(err)
+	}
+	static, _ := newTestCert(t, "entry.example.com", time.Now().Add(time.Hour))
+	s := &Server{
+		proxies: map[int]*httpProxy{
+			1: {id: 1, hostname: "app.example.com"},
+			2: {id: 2, hostname: "new.example.com"},
+		},
+		hosts:    map[string]int{"app.example.com": 1, "new.example.com": 2},
+		certs:    []tls.Certificate{*static},
+		uploaded: make(map[uint]*tls.Certificate),
+	}
+	s.acme = &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      cache,
+		HostPolicy: s.HostPolicy,
+		Client:     &acme.Client{DirectoryURL: "http://127.0.0.1:1/directory"},
+	}
+	return s, acmeCert
+}
+
+// ecdsaHello 支持 ECDSA 证书的 ClientHello
+func ecdsaHello(serverName string, protos ...string) *tls.ClientHelloInfo {
+	return &tls.ClientHelloInfo{
+		ServerName:        serverName,
+		SupportedProtos:   protos,
+		CipherSuites:      []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		SupportedCurves:   []tls.CurveID{tls.CurveP256},
+		SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+		SupportedVersions: []uint16{tls.VersionTLS13, tls.VersionTLS12},
+	}
+}
+
+func TestGetCertificate(t *testing.T) {
+	s, acmeCert := newTestACMEServer(t)
+
+	// 代理的主机名使用 ACME 证书
+	cert, err := s.getCertificate(ecdsaHello("app.example.com"), 0)
+	if err != nil || cert == nil || !bytes.Equal(cert.Certificate[0], acmeCert.Certificate[0]) {
+		t.Fatalf("served host: cert = %v, err = %v, want the acme certificate", cert, err)
+	}
+	// 没有代理的主机名不触发签发，使用静态证书
+	if cert, err := s.getCertificate(ecdsaHello("other.example.com"), 0); cert != nil || err != nil {
+		t.Errorf("unserved host: cert = %v, err = %v, want static", cert, err)
+	}
+	// 签发失败时回退到静态证书
+	if cert, err := s.getCertificate(ecdsaHello("new.example.com"), 0); cert != nil || err != nil {
+		t.Errorf("failed issuance: cert = %v, err = %v, want static", cert, err)
+	}
+}
+
+// 证书的优先级：TLS-ALPN-01 验证、代理绑定的证书、SNI 匹配的上传证书、ACME 证书、静态证书
+func TestGetCertificatePrecedence(t *testing.T) {
+	s, acmeCert := newTestACMEServer(t)
+	bound, _ := newTestCert(t, "bound.example.com", time.Now().Add(time.Hour))
+	older, _ := newTestCert(t, "app.example.com", time.Now().Add(time.Hour))
+	newer, _ := newTestCert(t, "app.example.com", time.Now().Add(48*time.Hour))
+	s.uploaded[10] = bound
+	s.uploaded[20] = older
+	s.uploaded[21] = newer
+	s.proxies[1].certificateID.Store(10)
+
+	check := func(name string, hello *tls.ClientHelloInfo, proxyID int, want *tls.Certificate) {
+		t.Helper()
+		cert, err := s.getCertificate(hello, proxyID)
+		if err != nil || cert == nil || !bytes.Equal(cert.Certificate[0], want.Certificate[0]) {
+			t.Errorf("%s: cert = %v, err = %v", name, cert, err)
+		}
+	}
+	// TLS-ALPN-01 验证只交给 ACME，即使代理绑定了证书
+	if cert, err := s.getCertificate(ecdsaHello("app.example.com", acme.ALPNProto), 0); err == nil {
+		t.Errorf("acme-tls/1 hello without a challenge: cert = %v, want an error", cert)
+	}
+	check("bound by host", ecdsaHello("app.example.com"), 0, bound)
+	// 独立监听按代理 ID 取绑定的证书，不依赖 SNI
+	check("bound by listener", ecdsaHello(""), 1, bound)
+
+	// 多个上传证书匹配 SNI 时取最晚到期的，优先于 ACME
+	s.proxies[1].certificateID.Store(0)
+	check("sni upload", ecdsaHello("app.example.com"), 0, newer)
+
+	s.uploaded = map[uint]*tls.Certificate{10: bound}
+	check("acme", ecdsaHello("app.example.com"), 0, acmeCert)
+	if cert, err := s.getCertificate(ecdsaHello("other.example.com"), 0); cert != nil || err != nil {
+		t.Errorf("static: cert = %v, err = %v", cert, err)
+	}
+}
+
+func TestGetCertificateWithoutStatic(t *testing.T) {
+	s, _ := newTestACMEServer(t)
+	s.certs = nil
+	// 没有静态证书可回退时把签发失败交给 TLS 握手
+	if _, err := s.getCertificate(ecdsaHello("new.example.com"), 0); err == nil {
+		t.Error("failed issuance without a static certificate returned no error")
+	}
+}
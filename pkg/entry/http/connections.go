@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"net"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/entry/conntrack"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// ListConnections 返回运行中代理的活跃客户端连接，不在本数据面的代理返回 nil
+func (s *Server) ListConnections(ctx context.Context, id int) ([]proto.ConnectionInfo, error) {
+	proxy := s.runningProxy(id)
+	if proxy == nil {
+		return nil, nil
+	}
+	return proxy.conns.List(), nil
+}
+
+// CloseConnections 断开代理上匹配的客户端连接，连接上进行中的请求随之失败
+func (s *Server) CloseConnections(ctx context.Context, id int, filter proto.ConnectionFilter) (int, error) {
+	proxy := s.runningProxy(id)
+	if proxy == nil {
+		return 0, nil
+	}
+	n := proxy.conns.Close(filter)
+	if n > 0 {
+		log.Infof("http proxy %d: disconnected %d connections (%+v)", id, n, filter)
+	}
+	return n, nil
+}
+
+// unwrapConn 返回登记到活跃连接表之前的连接，用于判断是否为 TLS 连接
+func unwrapConn(conn net.Conn) net.Conn {
+	if c, ok := conn.(*conntrack.Conn); ok {
+		return c.Conn
+	}
+	return conn
+}
+
+// trackedEntry 返回客户端连接在活跃连接表中的登记，没有登记时返回 nil
+func trackedEntry(conn net.Conn) *conntrack.Entry {
+	switch c := conn.(type) {
+	case *conntrack.Conn:
+		return c.Entry
+	case *routedConn:
+		if c.tracked != nil {
+			return c.tracked.Entry
+		}
+	case *peekedConn:
+		return trackedEntry(c.Conn)
+	}
+	return nil
+}
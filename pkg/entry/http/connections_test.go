@@ -0,0 +1,51 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestProxyConnections(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})}
+	s, base := startTestProxy(t, edge)
+	conn, err := net.Dial("tcp", strings.TrimPrefix(base, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: web\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	conns, err := s.ListConnections(context.Background(), 1)
+	if err != nil || len(conns) != 1 {
+		t.Fatalf("list = %+v, %v", conns, err)
+	}
+	c := conns[0]
+	if c.Kind != proto.ConnectionHTTP || c.ClientIP != "127.0.0.1" || c.EdgeID != 1 || c.BytesIn == 0 || c.BytesOut == 0 {
+		t.Errorf("connection = %+v", c)
+	}
+	if list, _ := s.ListConnections(context.Background(), 2); list != nil {
+		t.Errorf("unknown proxy listed %+v", list)
+	}
+
+	if n, _ := s.CloseConnections(context.Background(), 1, proto.ConnectionFilter{ID: c.ID}); n != 1 {
+		t.Fatalf("closed %d connections", n)
+	}
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Error("client connection still open")
+	}
+}
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// 响应压缩在 entry 与客户端之间，与 entry 和 edge 之间 stream 的压缩（pkg/compress）互不影响
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+	// 即时压缩取速度和压缩率比较均衡的级别
+	brotliLevel = 4
+)
+
+// responseWriter 可以复用的压缩器
+type responseWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	encodingBrotli: {New: func() any { return brotli.NewWriterLevel(nil, brotliLevel) }},
+	encodingGzip: {New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+}
+
+func (p *httpProxy) setResponseCompression(conf proto.ResponseCompression) {
+	if !conf.Enabled {
+		p.compression.Store(nil)
+		return
+	}
+	if conf.MinBytes <= 0 {
+		conf.MinBytes = proto.DefaultCompressMinBytes
+	}
+	p.compression.Store(&conf)
+}
+
+// responseEncoder 一个请求的响应压缩，nil 表示不压缩
+type responseEncoder struct {
+	encoding string
+	minBytes int
+	body     *encodedBody
+}
+
+// newEncoder 按客户端的 Accept-Encoding 选择压缩算法，在中间件和头改写修改请求之前调用
+func (s *Server) newEncoder(req *http.Request, protoproxy *proto.Proxy) *responseEncoder {
+	proxy := s.runningProxy(protoproxy.ID)
+	if proxy == nil {
+		return nil
+	}
+	conf := proxy.compression.Load()
+	if conf == nil {
+		return nil
+	}
+	encoding := negotiateEncoding(req.Header.Values("Accept-Encoding"))
+	if encoding == "" {
+		return nil
+	}
+	return &responseEncoder{encoding: encoding, minBytes: conf.MinBytes}
+}
+
+// apply 压缩可以压缩的响应。压缩之后长度未知，ETag 改为弱 ETag，不再支持范围请求
+func (e *responseEncoder) apply(resp *http.Response, req *http.Request) {
+	if e == nil || !compressible(resp, req) {
+		return
+	}
+	if resp.ContentLength >= 0 && resp.ContentLength < int64(e.minBytes) {
+		return
+	}
+	header := resp.Header
+	header.Set("Content-Encoding", e.encoding)
+	header.Del("Content-Length")
+	header.Del("Accept-Ranges")
+	addVary(header, "Accept-Encoding")
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+	resp.ContentLength = -1
+	e.body = newEncodedBody(resp.Body, e.encoding)
+	resp.Body = e.body
+}
+
+// saved 压缩减少的字节数
+func (e *responseEncoder) saved() int64 {
+	if e == nil || e.body == nil {
+		return 0
+	}
+	return max(e.body.in-e.body.out, 0)
+}
+
+// negotiateEncoding 按 q 值选择 br 或者 gzip，相同时优先 br；都不接受时返回空
+func negotiateEncoding(values []string) string {
+	q := map[string]float64{}
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			weight := 1.0
+			if name, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					weight = f
+				}
+			}
+			q[strings.ToLower(strings.TrimSpace(coding))] = weight
+		}
+	}
+	weight := func(coding string) float64 {
+		if w, ok := q[coding]; ok {
+			return w
+		}
+		return q["*"]
+	}
+	br, gz := weight(encodingBrotli), weight(encodingGzip)
+	switch {
+	case br > 0 && br >= gz:
+		return encodingBrotli
+	case gz > 0:
+		return encodingGzip
+	}
+	return ""
+}
+
+// compressible 响应是否是后端没有压缩过、允许改写的文本类响应。事件流逐条推送，不压缩
+func compressible(resp *http.Response, req *http.Request) bool {
+	if !bodyAllowed(resp, req) || resp.StatusCode == http.StatusPartialContent || resp.Header.Get("Content-Range") != "" {
+		return false
+	}
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return false
+	}
+	if parseCacheControl(resp.Header).has("no-transform") {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/x-javascript", "application/xml",
+		"application/wasm", "image/svg+xml", "image/x-icon", "font/ttf", "font/otf":
+		return true
+	}
+	return false
+}
+
+// addVary 在 Vary 中加入请求头，已经有时不重复
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.TrimSpace(token); token == "*" || strings.EqualFold(token, name) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
+
+// encodedBody 边读边压缩的响应体。每读到一块就 flush，长度未知的响应（长轮询等）不会被压缩器攒住
+type encodedBody struct {
+	src   io.ReadCloser
+	w     responseWriter
+	pool  *sync.Pool
+	buf   bytes.Buffer
+	chunk []byte
+	eof   bool
+	// 读到的原始字节数和压缩之后交给调用方的字节数
+	in, out int64
+}
+
+func newEncodedBody(src io.ReadCloser, encoding string) *encodedBody {
+	b := &encodedBody{src: src, pool: encoderPools[encoding], chunk: make([]byte, 32*1024)}
+	b.w = b.pool.Get().(responseWriter)
+	b.w.Reset(&b.buf)
+	return b
+}
+
+func (b *encodedBody) Read(p []byte) (int, error) {
+	for b.buf.Len() == 0 {
+		if b.eof || b.w == nil {
+			return 0, io.EOF
+		}
+		n, err := b.src.Read(b.chunk)
+		if n > 0 {
+			b.in += int64(n)
+			if _, werr := b.w.Write(b.chunk[:n]); werr != nil {
+				return 0, werr
+			}
+			if werr := b.w.Flush(); werr != nil {
+				return 0, werr
+			}
+		}
+		if err == io.EOF {
+			b.eof = true
+			if werr := b.w.Close(); werr != nil {
+				return 0, werr
+			}
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	n, _ := b.buf.Read(p)
+	b.out += int64(n)
+	return n, nil
+}
+
+// Close 关闭后端的响应体，压缩器放回池中
+func (b *encodedBody) Close() error {
+	if b.w != nil {
+		b.w.Reset(io.Discard)
+		b.pool.Put(b.w)
+		b.w = nil
+	}
+	return b.src.Close()
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	for _, c := range []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"gzip, deflate", encodingGzip},
+		{"gzip, deflate, br", encodingBrotli},
+		{"br;q=0.5, gzip", encodingGzip},
+		{"br;q=0, gzip;q=0", ""},
+		{"*", encodingBrotli},
+		{"identity", ""},
+	} {
+		if got := negotiateEncoding([]string{c.accept}); got != c.want {
+			t.Errorf("accept %q: got %q, want %q", c.accept, got, c.want)
+		}
+	}
+}
+
+func TestResponseCompression(t *testing.T) {
+	text := strings.Repeat("liaison ", 512)
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("ETag", `"x"`)
+			io.WriteString(w, text)
+		case "/small":
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "tiny")
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, text)
+		}
+	})}
+	s, base := startTestProxy(t, edge)
+	s.proxies[1].setResponseCompression(proto.ResponseCompression{Enabled: true})
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	defer client.CloseIdleConnections()
+	get := func(path, accept string) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, base+path, nil)
+		req.Header.Set("Accept-Encoding", accept)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body io.Reader = resp.Body
+		switch resp.Header.Get("Content-Encoding") {
+		case encodingGzip:
+			if body, err = gzip.NewReader(resp.Body); err != nil {
+				t.Fatal(err)
+			}
+		case encodingBrotli:
+			body = brotli.NewReader(resp.Body)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		return resp, data
+	}
+
+	for _, encoding := range []string{encodingGzip, encodingBrotli} {
+		resp, body := get("/text", encoding)
+		if resp.Header.Get("Content-Encoding") != encoding || string(body) != text {
+			t.Fatalf("%s: encoding %q, %d bytes", encoding, resp.Header.Get("Content-Encoding"), len(body))
+		}
+		if resp.Header.Get("ETag") != `W/"x"` || resp.Header.Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: etag %q, vary %q", encoding, resp.Header.Get("ETag"), resp.Header.Get("Vary"))
+		}
+	}
+	for _, path := range []string{"/small", "/image"} {
+		if resp, _ := get(path, "gzip, br"); resp.Header.Get("Content-Encoding") != "" {
+			t.Errorf("%s compressed with %s", path, resp.Header.Get("Content-Encoding"))
+		}
+	}
+}
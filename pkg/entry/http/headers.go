@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// headerRewrite 一个请求使用的 HTTP 头改写规则和变量的值，nil 表示代理没有规则
+type headerRewrite struct {
+	rules []proto.HeaderRule
+	vars  *strings.Replacer
+}
+
+// newHeaderRewrite 取代理当前的改写规则，变量取改写之前的请求和客户端连接
+func (s *Server) newHeaderRewrite(req *http.Request, clientConn net.Conn, protoproxy *proto.Proxy) *headerRewrite {
+	proxy := s.runningProxy(protoproxy.ID)
+	if proxy == nil {
+		return nil
+	}
+	rules := proxy.headerRules.Load()
+	if rules == nil || len(*rules) == 0 {
+		return nil
+	}
+	clientIP, _, _ := net.SplitHostPort(clientConn.RemoteAddr().String())
+	scheme := "http"
+	if isTLS(clientConn) {
+		scheme = "https"
+	}
+	return &headerRewrite{
+		rules: *rules,
+		vars: strings.NewReplacer(
+			proto.HeaderVarClientIP, clientIP,
+			proto.HeaderVarProxyName, protoproxy.Name,
+			proto.HeaderVarHost, req.Host,
+			proto.HeaderVarScheme, scheme,
+		),
+	}
+}
+
+// request 改写转发给后端的请求头
+func (r *headerRewrite) request(header http.Header) {
+	r.apply(header, proto.HeaderDirectionRequest)
+}
+
+// response 改写返回给客户端的响应头
+func (r *headerRewrite) response(header http.Header) {
+	r.apply(header, proto.HeaderDirectionResponse)
+}
+
+func (r *headerRewrite) apply(header http.Header, direction string) {
+	if r == nil {
+		return
+	}
+	for _, rule := range r.rules {
+		if rule.Direction != direction {
+			continue
+		}
+		switch rule.Action {
+		case proto.HeaderActionAdd:
+			header.Add(rule.Name, r.vars.Replace(rule.Value))
+		case proto.HeaderActionSet:
+			header.Set(rule.Name, r.vars.Replace(rule.Value))
+		case proto.HeaderActionRemove:
+			header.Del(rule.Name)
+		}
+	}
+}
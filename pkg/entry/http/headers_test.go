@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestHeaderRewrite(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "backend")
+		w.Header().Set("X-Upstream", r.Header.Get("X-Forwarded-Proto")+" "+r.Header.Get("X-Origin")+" "+r.Header.Get("X-Proxy"))
+		if r.Header.Get("X-Secret") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})}
+	s, base := startTestProxy(t, edge)
+	rules := []proto.HeaderRule{
+		{Direction: "request", Action: "set", Name: "X-Forwarded-Proto", Value: "{scheme}"},
+		{Direction: "request", Action: "set", Name: "X-Origin", Value: "{client_ip} {host}"},
+		{Direction: "request", Action: "add", Name: "X-Proxy", Value: "{proxy_name}"},
+		{Direction: "request", Action: "remove", Name: "X-Secret"},
+		{Direction: "response", Action: "remove", Name: "Server"},
+		{Direction: "response", Action: "set", Name: "Strict-Transport-Security", Value: "max-age=600"},
+	}
+	s.proxies[1].headerRules.Store(&rules)
+
+	req, _ := http.NewRequest(http.MethodGet, base+"/", nil)
+	req.Header.Set("X-Secret", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	http.DefaultClient.CloseIdleConnections()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got, want := resp.Header.Get("X-Upstream"), "http 127.0.0.1 "+req.URL.Host+" web"; got != want {
+		t.Errorf("request headers = %q, want %q", got, want)
+	}
+	if resp.Header.Get("Server") != "" || resp.Header.Get("Strict-Transport-Security") != "max-age=600" {
+		t.Errorf("response headers = %v", resp.Header)
+	}
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/entry/transport"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"golang.org/x/net/http2"
+)
+
+// h2IdleTimeout HTTP/2 连接上没有活动 stream 时的空闲超时，与 HTTP/1.1 keep-alive 的读超时一致
+const h2IdleTimeout = 30 * time.Second
+
+// hopHeaders 只对一跳有效的头，不转发；HTTP/2 禁止这些头
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// serveHTTP2 在客户端连接上运行 HTTP/2，请求经 backendPool 中的 stream 转发给后端。
+// 准入检查在连接建立时已经完成；host 非空时（共享监听）请求的 :authority 必须是该主机名
+func (s *Server) serveHTTP2(ctx context.Context, clientConn net.Conn, protoproxy *proto.Proxy, host string) {
+	defer clientConn.Close()
+	// HTTP/2 连接可能一直有请求，代理删除时主动断开
+	stop := context.AfterFunc(ctx, func() { clientConn.Close() })
+	defer stop()
+
+	pool := newBackendPool(s, ctx, clientConn, protoproxy)
+	defer pool.close()
+	h2s := &http2.Server{IdleTimeout: h2IdleTimeout}
+	h2s.ServeConn(clientConn, &http2.ServeConnOpts{
+		Context: ctx,
+		Handler: &h2Handler{server: s, pool: pool, clientConn: clientConn, protoproxy: protoproxy, host: host},
+	})
+}
+
+// isH2CPreface 连接是否以 HTTP/2 prior knowledge 的前言开始。逐字节比较，
+// 普通 HTTP/1.1 请求在前一两个字节就不匹配，不会因为请求太短而阻塞
+func isH2CPreface(reader *bufio.Reader) bool {
+	for i := 1; i <= len(http2.ClientPreface); i++ {
+		b, err := reader.Peek(i)
+		if err != nil || b[i-1] != http2.ClientPreface[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// peekedConn 从已经预读过的 reader 继续读取的连接
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// h2Handler 把 HTTP/2 连接上的请求转发给后端，流量按请求统计
+type h2Handler struct {
+	server     *Server
+	pool       *backendPool
+	clientConn net.Conn
+	protoproxy *proto.Proxy
+	host       string
+}
+
+func (h *h2Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s, protoproxy := h.server, h.protoproxy
+	if h.host != "" && normalizeHost(req.Host) != h.host {
+		writeHandlerError(w, http.StatusMisdirectedRequest, "misdirected_request",
+			fmt.Sprintf("connection is bound to host %s", h.host))
+		return
+	}
+	alog := s.newAccessLog(req, h.clientConn, protoproxy)
+	recorder := &statusRecorder{ResponseWriter: w}
+	if !s.authorize(recorder, req, protoproxy.ID, h.clientConn) {
+		alog.finish(0, recorder.status, requestLineBytes(req)+headerBytes(req.Header), recorder.written)
+		return
+	}
+	alog.authorized(req)
+
+	if req.ContentLength == 0 {
+		// 没有请求体，便于判断能否重发
+		req.Body = http.NoBody
+	}
+	counter := &transport.TrafficCounter{}
+	req.Body = countBody(req.Body, counter, true)
+	enc := s.newEncoder(req, protoproxy)
+	route := s.routeRequest(req, protoproxy)
+	chain := s.requestMiddlewares(protoproxy, route)
+	if err := chain.ModifyRequest(req); err != nil {
+		log.Errorf("http proxy %d middleware modify request err: %s", protoproxy.ID, err)
+	}
+	if protoproxy.ForwardedHeaders {
+		setForwardedHeaders(req, h.clientConn)
+	}
+	rewrite := s.newHeaderRewrite(req, h.clientConn, protoproxy)
+	rewrite.request(req.Header)
+
+	cached := s.lookupCache(req, protoproxy, route)
+	resp, applicationID := cached.hit()
+	var bc *backendConn
+	var capt *capture
+	if resp == nil {
+		capt = s.newCapture(req, h.clientConn, protoproxy)
+		var err error
+		resp, bc, err = h.pool.forward(req, route)
+		if err != nil {
+			status, code, reason := dialErrorStatus(err)
+			writeHandlerError(w, status, code, reason)
+			bodyIn, _ := counter.Load()
+			alog.finish(routeApplication(route), status, requestLineBytes(req)+headerBytes(req.Header)+bodyIn, 0)
+			capt.finish(routeApplication(route), err)
+			return
+		}
+		capt.response(resp)
+		applicationID = bc.up.applicationID
+		resp = cached.store(resp, applicationID)
+	}
+	// 压缩会替换响应体，关闭最终的响应体
+	defer func() { resp.Body.Close() }()
+	if err := chain.ModifyResponse(resp); err != nil {
+		log.Errorf("http proxy %d middleware modify response err: %s", protoproxy.ID, err)
+	}
+	enc.apply(resp, req)
+
+	header := w.Header()
+	for k, v := range resp.Header {
+		header[k] = v
+	}
+	removeHopHeaders(header)
+	rewrite.response(header)
+	w.WriteHeader(resp.StatusCode)
+	written, err := copyResponse(w, resp)
+	if err != nil {
+		log.Debugf("http proxy %d copy HTTP/2 response err: %s", protoproxy.ID, err)
+	}
+	// gRPC 的状态在 trailer 中
+	for k, v := range resp.Trailer {
+		header[http.TrailerPrefix+k] = v
+	}
+
+	bodyIn, _ := counter.Load()
+	requestBytes := requestLineBytes(req) + headerBytes(req.Header) + bodyIn
+	responseBytes := statusLineBytes(resp) + headerBytes(resp.Header) + written
+	wireIn, wireOut := bc.wireBytes(requestBytes, responseBytes)
+	s.recordTraffic(uint(protoproxy.ID), applicationID, requestBytes, responseBytes, wireIn, wireOut)
+	s.recordCacheStats(uint(protoproxy.ID), applicationID, cached, enc)
+	alog.finish(applicationID, resp.StatusCode, requestBytes, responseBytes)
+	capt.finish(applicationID, nil)
+}
+
+// roundTripH2C 在到后端的 HTTP/2 连接（h2c）上发送请求
+func roundTripH2C(cc *http2.ClientConn, req *http.Request, dst string) (*http.Response, error) {
+	outreq := req.Clone(req.Context())
+	outreq.RequestURI = ""
+	outreq.URL.Scheme = "http"
+	outreq.URL.Host = dst
+	outreq.Host = dst
+	removeHopHeaders(outreq.Header)
+	return cc.RoundTrip(outreq)
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, k := range hopHeaders {
+		header.Del(k)
+	}
+}
+
+// copyResponse 复制响应体。流式响应（SSE、gRPC 等长度未知的响应）每次写入后立即 flush，
+// 其它响应由 ResponseWriter 缓冲
+func copyResponse(w http.ResponseWriter, resp *http.Response) (int64, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok || !isStreaming(resp) {
+		return io.Copy(w, resp.Body)
+	}
+	buf := make([]byte, 32*1024)
+	var written int64
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			m, werr := w.Write(buf[:n])
+			written += int64(m)
+			if werr != nil {
+				return written, werr
+			}
+			flusher.Flush()
+		}
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// isStreaming 响应是否是需要即时送达的流式响应
+func isStreaming(resp *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType == "text/event-stream" || resp.ContentLength < 0
+}
+
+// writeHandlerError 与 writeErrorResponse 相同格式的错误响应，用于 http.Handler
+func writeHandlerError(w http.ResponseWriter, status int, code, reason string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Liaison-Error", code)
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s: %s\n", code, reason)
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/http2"
+)
+
+func TestIsH2CPreface(t *testing.T) {
+	cases := map[string]bool{
+		http2.ClientPreface + "\x00\x00":    true,
+		"GET / HTTP/1.1\r\nHost: a\r\n\r\n": false,
+		"PRI * HTTP/1.1\r\nHost: a\r\n\r\n": false,
+		"POST /upload HTTP/1.1\r\n\r\n":     false,
+		"PUT / HTTP/1.0\r\n\r\n":            false,
+		http2.ClientPreface[:10]:            false,
+	}
+	for input, want := range cases {
+		reader := bufio.NewReader(strings.NewReader(input))
+		if got := isH2CPreface(reader); got != want {
+			t.Errorf("isH2CPreface(%q) = %v, want %v", input, got, want)
+		}
+		// 预读不能消费数据
+		if rest, _ := io.ReadAll(reader); string(rest) != input {
+			t.Errorf("isH2CPreface(%q) consumed input", input)
+		}
+	}
+}
+
+func TestRoundTripH2C(t *testing.T) {
+	client, backend := net.Pipe()
+	defer client.Close()
+	go (&http2.Server{}).ServeConn(backend, &http2.ServeConnOpts{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Host != "10.0.0.1:50051" || r.Header.Get("Connection") != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Trailer", "Grpc-Status")
+			io.WriteString(w, "pong")
+			w.Header().Set("Grpc-Status", "0")
+		}),
+	})
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://example.com/svc/Ping", strings.NewReader("ping"))
+	req.Header.Set("Connection", "keep-alive")
+	cc, err := (&http2.Transport{AllowHTTP: true}).NewClientConn(client)
+	if err != nil {
+		t.Fatalf("new client conn: %v", err)
+	}
+	resp, err := roundTripH2C(cc, req, "10.0.0.1:50051")
+	if err != nil {
+		t.Fatalf("roundTripH2C: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "pong" {
+		t.Fatalf("got %d %q", resp.StatusCode, body)
+	}
+	if resp.Trailer.Get("Grpc-Status") != "0" {
+		t.Errorf("trailer = %v, want Grpc-Status 0", resp.Trailer)
+	}
+}
+
+func TestRoundTripFromHTTP2(t *testing.T) {
+	client, backend := net.Pipe()
+	defer client.Close()
+	done := make(chan *http.Request, 1)
+	go func() {
+		reader := bufio.NewReader(backend)
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			done <- nil
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		req.Body = io.NopCloser(strings.NewReader(string(body)))
+		done <- req
+		io.WriteString(backend, "HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nok")
+	}()
+
+	// 来自 HTTP/2 客户端、长度未知的请求体
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com/upload", io.NopCloser(strings.NewReader("hello")))
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	req.ContentLength = -1
+	pool := &backendPool{server: &Server{}}
+	bc := &backendConn{conn: client, reader: bufio.NewReader(client), up: upstream{dst: "10.0.0.1:8080"}}
+	resp, err := pool.roundTrip(bc, req)
+	if err != nil {
+		t.Fatalf("roundTrip: %v", err)
+	}
+	got := <-done
+	if got == nil {
+		t.Fatal("backend failed to read request")
+	}
+	if got.ProtoMajor != 1 || len(got.TransferEncoding) == 0 || got.TransferEncoding[0] != "chunked" {
+		t.Errorf("backend got %s, transfer encoding %v", got.Proto, got.TransferEncoding)
+	}
+	if body, _ := io.ReadAll(got.Body); string(body) != "hello" {
+		t.Errorf("backend body = %q", body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+}
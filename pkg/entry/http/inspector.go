@@ -0,0 +1,353 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/entry/transport"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// inspectedIDs 检查器记录的 ID，在进程内唯一，关闭再开启检查器之后也不会重复
+var inspectedIDs atomic.Uint64
+
+// inspector 代理的请求检查器，在环形缓冲中保留最近的请求
+type inspector struct {
+	mu      sync.Mutex
+	conf    proto.Inspector // 已经填上默认值
+	records []*proto.InspectedRequest
+	next    int // 缓冲满之后下一条覆盖的位置
+}
+
+// setInspector 开启、关闭检查器或者修改容量。关闭时丢弃已有的记录，开启的检查器修改设置时保留最新的记录
+func (p *httpProxy) setInspector(conf proto.Inspector) {
+	if !conf.Enabled {
+		p.inspector.Store(nil)
+		return
+	}
+	if conf.MaxBodyBytes <= 0 {
+		conf.MaxBodyBytes = proto.DefaultInspectorBodyBytes
+	}
+	if conf.MaxRequests <= 0 {
+		conf.MaxRequests = proto.DefaultInspectorRequests
+	}
+	if current := p.inspector.Load(); current != nil {
+		current.update(conf)
+		return
+	}
+	p.inspector.Store(&inspector{conf: conf})
+}
+
+func (in *inspector) update(conf proto.Inspector) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if conf.MaxRequests != in.conf.MaxRequests {
+		records := in.newestFirst()
+		if len(records) > conf.MaxRequests {
+			records = records[:conf.MaxRequests]
+		}
+		in.records = make([]*proto.InspectedRequest, len(records))
+		for i, record := range records {
+			in.records[len(records)-1-i] = record
+		}
+		in.next = 0
+	}
+	in.conf = conf
+}
+
+func (in *inspector) add(record *proto.InspectedRequest) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if len(in.records) < in.conf.MaxRequests {
+		in.records = append(in.records, record)
+		return
+	}
+	in.records[in.next] = record
+	in.next = (in.next + 1) % len(in.records)
+}
+
+// newestFirst 返回从新到旧的记录，调用方持有锁
+func (in *inspector) newestFirst() []*proto.InspectedRequest {
+	n := len(in.records)
+	list := make([]*proto.InspectedRequest, n)
+	for i := range list {
+		list[i] = in.records[(in.next-1-i+2*n)%n]
+	}
+	return list
+}
+
+func (in *inspector) list() []*proto.InspectedRequest {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.newestFirst()
+}
+
+func (in *inspector) get(id uint64) *proto.InspectedRequest {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	for _, record := range in.records {
+		if record.ID == id {
+			return record
+		}
+	}
+	return nil
+}
+
+func (in *inspector) maxBodyBytes() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.conf.MaxBodyBytes
+}
+
+// capture 一个请求的检查记录，nil 表示代理没有开启检查器
+type capture struct {
+	inspector *inspector
+	record    *proto.InspectedRequest
+	reqBody   *cappedBuffer
+	respBody  *cappedBuffer
+}
+
+// newCapture 在请求转发给后端之前记下请求，请求体边转发边保留前 MaxBodyBytes 字节
+func (s *Server) newCapture(req *http.Request, clientConn net.Conn, protoproxy *proto.Proxy) *capture {
+	proxy := s.runningProxy(protoproxy.ID)
+	if proxy == nil {
+		return nil
+	}
+	in := proxy.inspector.Load()
+	if in == nil {
+		return nil
+	}
+	return in.capture(req, clientConn.RemoteAddr().String(), protoproxy.ApplicationID)
+}
+
+func (in *inspector) capture(req *http.Request, clientAddr string, applicationID uint) *capture {
+	limit := in.maxBodyBytes()
+	c := &capture{
+		inspector: in,
+		record: &proto.InspectedRequest{
+			ID:            inspectedIDs.Add(1),
+			ApplicationID: applicationID,
+			Time:          time.Now(),
+			ClientAddr:    clientAddr,
+			Method:        req.Method,
+			Host:          req.Host,
+			URL:           req.URL.RequestURI(),
+			Proto:         req.Proto,
+			RequestHeader: req.Header.Clone(),
+		},
+		reqBody:  &cappedBuffer{limit: limit},
+		respBody: &cappedBuffer{limit: limit},
+	}
+	req.Body = c.reqBody.tee(req.Body)
+	return c
+}
+
+// response 记下后端的响应，响应体边写给客户端边保留
+func (c *capture) response(resp *http.Response) {
+	if c == nil {
+		return
+	}
+	c.record.Status = resp.StatusCode
+	c.record.ResponseHeader = resp.Header.Clone()
+	resp.Body = c.respBody.tee(resp.Body)
+}
+
+// finish 请求结束后保存记录，err 为转发失败的原因。applicationID 为 0 时保留代理自己的应用
+func (c *capture) finish(applicationID uint, err error) *proto.InspectedRequest {
+	if c == nil {
+		return nil
+	}
+	record := c.record
+	if applicationID != 0 {
+		record.ApplicationID = applicationID
+	}
+	record.DurationMs = time.Since(record.Time).Milliseconds()
+	record.RequestBody, record.RequestBodyTruncated = c.reqBody.result()
+	record.ResponseBody, record.ResponseBodyTruncated = c.respBody.result()
+	if err != nil {
+		record.Error = err.Error()
+	}
+	c.inspector.add(record)
+	return record
+}
+
+// cappedBuffer 保留经过 body 的前 limit 字节
+type cappedBuffer struct {
+	mu        sync.Mutex
+	limit     int
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) result() ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes()), b.truncated
+}
+
+// tee 包装 body，读取时写入缓冲；http.NoBody 保持不变，避免被当作有请求体
+func (b *cappedBuffer) tee(body io.ReadCloser) io.ReadCloser {
+	if body == nil || body == http.NoBody {
+		return body
+	}
+	return &countedBody{Reader: io.TeeReader(body, b), Closer: body}
+}
+
+// inspectorOf 返回运行中代理开启的检查器
+func (s *Server) inspectorOf(id int) (*inspector, error) {
+	proxy := s.runningProxy(id)
+	if proxy == nil {
+		return nil, fmt.Errorf("proxy %d is not running", id)
+	}
+	in := proxy.inspector.Load()
+	if in == nil {
+		return nil, fmt.Errorf("inspector of proxy %d is not enabled", id)
+	}
+	return in, nil
+}
+
+// ListInspectedRequests 返回检查器记录的请求，从新到旧，不含请求体和响应体
+func (s *Server) ListInspectedRequests(ctx context.Context, id int) ([]proto.InspectedRequest, error) {
+	proxy := s.runningProxy(id)
+	if proxy == nil {
+		return nil, nil
+	}
+	in := proxy.inspector.Load()
+	if in == nil {
+		return nil, nil
+	}
+	records := in.list()
+	list := make([]proto.InspectedRequest, len(records))
+	for i, record := range records {
+		list[i] = *record
+		list[i].RequestHeader, list[i].RequestBody = nil, nil
+		list[i].ResponseHeader, list[i].ResponseBody = nil, nil
+	}
+	return list, nil
+}
+
+// GetInspectedRequest 返回一条完整的记录
+func (s *Server) GetInspectedRequest(ctx context.Context, id int, requestID uint64) (*proto.InspectedRequest, error) {
+	in, err := s.inspectorOf(id)
+	if err != nil {
+		return nil, err
+	}
+	return in.get(requestID), nil
+}
+
+// ReplayRequest 把记录的请求原样经 edge 发给后端，发往记录时匹配的应用，结果作为新的记录保存。
+// 请求体被截断的记录不能重放
+func (s *Server) ReplayRequest(ctx context.Context, id int, requestID uint64) (*proto.InspectedRequest, error) {
+	in, err := s.inspectorOf(id)
+	if err != nil {
+		return nil, err
+	}
+	original := in.get(requestID)
+	if original == nil {
+		return nil, fmt.Errorf("request %d not found", requestID)
+	}
+	if original.RequestBodyTruncated {
+		return nil, errors.New("request body was truncated, cannot replay")
+	}
+	proxy := s.runningProxy(id)
+	if proxy == nil {
+		return nil, fmt.Errorf("proxy %d is not running", id)
+	}
+	protoproxy := proxy.protoproxy
+	route, ok := proxy.routeTo(original.ApplicationID)
+	if !ok {
+		return nil, fmt.Errorf("application %d is no longer served by proxy %d", original.ApplicationID, id)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, original.Method, original.URL, bytes.NewReader(original.RequestBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Host = original.Host
+	req.Header = original.RequestHeader.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Del("Content-Length")
+	req.Header.Del("Transfer-Encoding")
+
+	// 重放的请求不经过客户端连接，到 edge 的 stream 按原客户端地址握手
+	clientAddr, _ := net.ResolveTCPAddr("tcp", original.ClientAddr)
+	pool := newBackendPool(s, ctx, &replayConn{remote: clientAddr}, protoproxy)
+	defer pool.close()
+	c := in.capture(req, original.ClientAddr, original.ApplicationID)
+	c.record.ReplayOf = requestID
+	counter := &transport.TrafficCounter{}
+	req.Body = countBody(req.Body, counter, true)
+
+	resp, bc, err := pool.forward(req, route)
+	if err != nil {
+		log.Warnf("http proxy %d replay request %d err: %s", id, requestID, err)
+		return c.finish(original.ApplicationID, err), nil
+	}
+	c.response(resp)
+	resp.Body = countBody(resp.Body, counter, false)
+	// 只需要检查器保留的部分，多读一个字节用于判断是否截断
+	_, err = io.Copy(io.Discard, io.LimitReader(resp.Body, int64(c.respBody.limit)+1))
+	resp.Body.Close()
+
+	bodyIn, bodyOut := counter.Load()
+	requestBytes := requestLineBytes(req) + headerBytes(req.Header) + bodyIn
+	responseBytes := statusLineBytes(resp) + headerBytes(resp.Header) + bodyOut
+	wireIn, wireOut := bc.wireBytes(requestBytes, responseBytes)
+	s.recordTraffic(uint(id), bc.up.applicationID, requestBytes, responseBytes, wireIn, wireOut)
+	return c.finish(bc.up.applicationID, err), nil
+}
+
+// routeTo 返回转发到应用的路由，应用是代理自己的应用时返回 nil；ok 为 false 表示代理已经不转发到该应用
+func (p *httpProxy) routeTo(applicationID uint) (*httpRoute, bool) {
+	if applicationID == p.protoproxy.ApplicationID {
+		return nil, true
+	}
+	if routes := p.routes.Load(); routes != nil {
+		for _, route := range *routes {
+			if route.ApplicationID == applicationID {
+				return route, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// replayConn 重放请求时代替客户端连接，backendPool 只用到它的地址
+type replayConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c *replayConn) RemoteAddr() net.Addr {
+	if c.remote == nil {
+		return &net.TCPAddr{}
+	}
+	return c.remote
+}
+
+func (c *replayConn) LocalAddr() net.Addr {
+	return &net.TCPAddr{}
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestInspector(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Echo", r.Header.Get("X-Signature"))
+		io.WriteString(w, "echo:"+string(body))
+	})}
+	s, base := startTestProxy(t, edge)
+	ctx := context.Background()
+	client := &http.Client{}
+	defer client.CloseIdleConnections()
+	post := func(body string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, base+"/hook?a=1", strings.NewReader(body))
+		req.Header.Set("X-Signature", "sig")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	// 记录在响应写完之后保存，客户端可能先收到响应
+	waitRecords := func(n int) []proto.InspectedRequest {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+			list, _ := s.ListInspectedRequests(ctx, 1)
+			if len(list) == n || time.Now().After(deadline) {
+				if len(list) != n {
+					t.Fatalf("got %d records, want %d", len(list), n)
+				}
+				return list
+			}
+		}
+	}
+
+	// 没有开启检查器时不记录
+	post("ignored")
+	if list, err := s.ListInspectedRequests(ctx, 1); list != nil || err != nil {
+		t.Fatalf("disabled inspector listed %+v, %v", list, err)
+	}
+
+	s.proxies[1].setInspector(proto.Inspector{Enabled: true, MaxBodyBytes: 8, MaxRequests: 2})
+	post("hello")
+	list := waitRecords(1)
+	if list[0].RequestHeader != nil || list[0].RequestBody != nil {
+		t.Errorf("list carries headers or bodies: %+v", list[0])
+	}
+	record, err := s.GetInspectedRequest(ctx, 1, list[0].ID)
+	if err != nil || record == nil {
+		t.Fatalf("get = %+v, %v", record, err)
+	}
+	if record.Method != http.MethodPost || record.URL != "/hook?a=1" || record.Status != http.StatusOK ||
+		string(record.RequestBody) != "hello" || record.RequestBodyTruncated ||
+		string(record.ResponseBody) != "echo:hel" || !record.ResponseBodyTruncated ||
+		record.RequestHeader.Get("X-Signature") != "sig" || record.ResponseHeader.Get("X-Echo") != "sig" {
+		t.Errorf("record = %+v", record)
+	}
+
+	replayed, err := s.ReplayRequest(ctx, 1, record.ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.ReplayOf != record.ID || replayed.Status != http.StatusOK || string(replayed.ResponseBody) != "echo:hel" {
+		t.Errorf("replayed = %+v", replayed)
+	}
+
+	// 请求体被截断的请求不能重放；缓冲只保留最近的 2 条
+	post("longer than eight")
+	list = waitRecords(2)
+	if list[1].ID != replayed.ID || !list[0].RequestBodyTruncated {
+		t.Errorf("list = %+v", list)
+	}
+	if _, err := s.ReplayRequest(ctx, 1, list[0].ID); err == nil {
+		t.Error("replayed a truncated request")
+	}
+
+	s.proxies[1].setInspector(proto.Inspector{})
+	if _, err := s.GetInspectedRequest(ctx, 1, record.ID); err == nil {
+		t.Error("get from disabled inspector succeeded")
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/middleware"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// buildChain 按应用启用的中间件创建插件链，创建失败的中间件跳过（管理端保存前已经校验过配置）
+func buildChain(proxyID int, specs []proto.Middleware) *middleware.Chain {
+	chain := make(middleware.Chain, 0, len(specs))
+	for _, spec := range specs {
+		m, err := middleware.New(spec.Name, spec.Config)
+		if err != nil {
+			log.Warnf("http proxy %d: %s, skipping", proxyID, err)
+			continue
+		}
+		chain = append(chain, m)
+	}
+	return &chain
+}
+
+func (p *httpProxy) setMiddlewares(specs []proto.Middleware) {
+	p.middlewares.Store(buildChain(p.id, specs))
+}
+
+// requestMiddlewares 返回请求所到应用启用的插件链：匹配路由时为路由的应用，否则为代理自己的应用
+func (s *Server) requestMiddlewares(protoproxy *proto.Proxy, route *httpRoute) middleware.Chain {
+	var chain *middleware.Chain
+	if route != nil {
+		chain = route.middlewares.Load()
+	} else if proxy := s.runningProxy(protoproxy.ID); proxy != nil {
+		chain = proxy.middlewares.Load()
+	}
+	if chain == nil {
+		return nil
+	}
+	return *chain
+}
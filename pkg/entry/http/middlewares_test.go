@@ -0,0 +1,48 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/middleware"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestApplicationMiddlewares(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "backend "+r.URL.Path)
+	})}
+	s, base := startTestProxy(t, edge)
+	replace := func(to string) []proto.Middleware {
+		return []proto.Middleware{{
+			Name:   middleware.BodyReplace,
+			Config: []byte(`{"replacements":[{"from":"backend","to":"` + to + `"}]}`),
+		}}
+	}
+	// 中间件只作用于启用它的应用
+	s.proxies[1].setMiddlewares(replace("proxy"))
+	s.proxies[1].setRoutes([]proto.Route{
+		{PathPrefix: "/api", ApplicationID: 2, EdgeIDs: []uint64{2}, Dst: "10.0.0.2:8080", Middlewares: replace("api")},
+		{PathPrefix: "/plain", ApplicationID: 3, EdgeIDs: []uint64{3}, Dst: "10.0.0.3:8080"},
+	})
+	client := &http.Client{}
+	defer client.CloseIdleConnections()
+
+	for path, want := range map[string]string{
+		"/":      "proxy /",
+		"/api":   "api /api",
+		"/plain": "backend /plain",
+	} {
+		resp, err := client.Get(base + path)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != want {
+			t.Errorf("get %s: body %q, want %q", path, body, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/liaisonio/liaison/pkg/entry/balancer"
+	"github.com/liaisonio/liaison/pkg/entry/edgeselect"
+	"github.com/liaisonio/liaison/pkg/middleware"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// httpRoute 按路径转发到其他应用的路由规则，有自己的 edge 选择和后端负载均衡
+type httpRoute struct {
+	proto.Route
+	selector *edgeselect.Selector
+	targets  *balancer.Balancer
+	// 路由的应用启用的插件链，可热更新
+	middlewares atomic.Pointer[middleware.Chain]
+}
+
+// setRoutes 更新代理的路由规则。前缀和应用都不变的规则沿用原来的 edge 选择状态，
+// 连接池中打开到它的 stream 也可以继续复用
+func (p *httpProxy) setRoutes(routes []proto.Route) {
+	var old []*httpRoute
+	if current := p.routes.Load(); current != nil {
+		old = *current
+	}
+	updated := make([]*httpRoute, 0, len(routes))
+	for _, route := range routes {
+		var reused *httpRoute
+		for _, r := range old {
+			if r.PathPrefix == route.PathPrefix && r.ApplicationID == route.ApplicationID {
+				reused = r
+				break
+			}
+		}
+		if reused != nil {
+			reused.selector.Update(route.EdgePolicy, route.EdgeIDs)
+			reused.targets.Update(route.TargetList())
+			// 不修改正在被请求读取的规则，StripPrefix 变化时换一个新的规则对象
+			if reused.StripPrefix != route.StripPrefix {
+				reused = &httpRoute{Route: route, selector: reused.selector, targets: reused.targets}
+			}
+			reused.middlewares.Store(buildChain(p.id, route.Middlewares))
+			updated = append(updated, reused)
+			continue
+		}
+		created := &httpRoute{
+			Route:    route,
+			selector: edgeselect.NewSelector(p.id, route.EdgePolicy, route.EdgeIDs),
+			targets:  balancer.New(route.TargetList()),
+		}
+		created.middlewares.Store(buildChain(p.id, route.Middlewares))
+		updated = append(updated, created)
+	}
+	p.routes.Store(&updated)
+}
+
+// matchRoute 返回第一条匹配路径的规则，都不匹配时返回 nil，即转发到代理自己的应用
+func (p *httpProxy) matchRoute(path string) *httpRoute {
+	routes := p.routes.Load()
+	if routes == nil {
+		return nil
+	}
+	for _, route := range *routes {
+		if route.MatchPath(path) {
+			return route
+		}
+	}
+	return nil
+}
+
+// routeRequest 按路径匹配路由规则，规则要求时去掉路径前缀，原前缀放在 X-Forwarded-Prefix 中
+func (s *Server) routeRequest(req *http.Request, protoproxy *proto.Proxy) *httpRoute {
+	proxy := s.runningProxy(protoproxy.ID)
+	if proxy == nil {
+		return nil
+	}
+	route := proxy.matchRoute(req.URL.Path)
+	if route == nil || !route.StripPrefix {
+		return route
+	}
+	// 前缀只包含不需要转义的字符，在转义后的路径中相同
+	if req.URL.RawPath != "" && route.MatchPath(req.URL.RawPath) {
+		req.URL.RawPath = route.StrippedPath(req.URL.RawPath)
+	} else {
+		req.URL.RawPath = ""
+	}
+	req.URL.Path = route.StrippedPath(req.URL.Path)
+	if prefix := strings.TrimSuffix(route.PathPrefix, "/"); prefix != "" {
+		req.Header.Set("X-Forwarded-Prefix", prefix)
+	}
+	return route
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestPathRoutes(t *testing.T) {
+	edge := &fakeEdge{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.RequestURI()+" "+r.Header.Get("X-Forwarded-Prefix"))
+	})}
+	s, base := startTestProxy(t, edge)
+	s.proxies[1].setRoutes([]proto.Route{
+		{PathPrefix: "/api", StripPrefix: true, ApplicationID: 2, EdgeIDs: []uint64{2}, Dst: "10.0.0.2:8080"},
+		{PathPrefix: "/static/", ApplicationID: 3, EdgeIDs: []uint64{3}, Dst: "10.0.0.3:8080"},
+	})
+	client := &http.Client{}
+	defer client.CloseIdleConnections()
+
+	cases := []struct {
+		path        string
+		body        string
+		application string
+	}{
+		{"/api/users?id=1", "/users?id=1 /api", "2"},
+		{"/api", "/ /api", "2"},
+		{"/apis", "/apis ", "0"},
+		{"/static/app.js", "/static/app.js ", "3"},
+		{"/", "/ ", "0"},
+	}
+	for _, c := range cases {
+		resp, err := client.Get(base + c.path)
+		if err != nil {
+			t.Fatalf("get %s: %v", c.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != c.body {
+			t.Errorf("get %s: backend got %q, want %q", c.path, body, c.body)
+		}
+		if got := resp.Header.Get("X-Test-Application"); got != c.application {
+			t.Errorf("get %s: routed to application %s, want %s", c.path, got, c.application)
+		}
+	}
+}
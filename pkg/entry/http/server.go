@@ -2,26 +2,39 @@ package http
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/binary"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
-	"net/http/httputil"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/compress"
+	"github.com/liaisonio/liaison/pkg/entry/balancer"
+	"github.com/liaisonio/liaison/pkg/entry/connlimit"
+	"github.com/liaisonio/liaison/pkg/entry/conntrack"
+	"github.com/liaisonio/liaison/pkg/entry/edgeselect"
 	"github.com/liaisonio/liaison/pkg/entry/frontierbound"
+	"github.com/liaisonio/liaison/pkg/entry/ratelimit"
+	"github.com/liaisonio/liaison/pkg/entry/transport"
+	"github.com/liaisonio/liaison/pkg/middleware"
 	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/liaisonio/liaison/pkg/proxyproto"
+	"github.com/singchia/geminio"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
 )
 
+// dialResultTimeout 等待 edge 返回拨号结果的超时
+const dialResultTimeout = 30 * time.Second
+
 // firewallChecker is the minimal contract the HTTP server needs from the
 // firewall package to gate incoming connections. Decoupling via interface
 // keeps this package test-friendly and avoids a hard import in tests.
@@ -37,9 +50,23 @@ type Server struct {
 	frontierBound  frontierbound.FrontierBound
 	// 可选的防火墙：有则在 Accept 后做 CIDR 检查
 	firewall firewallChecker
-	// 流量统计器（可选，如果设置了则统计流量）
+	// 可信的前置负载均衡器，来自它们的连接先解析 PROXY protocol 头
+	trustedProxies []*net.IPNet
+	// 共享的 80/443 监听，nil 表示未启用；hosts 为主机名 -> 代理 ID
+	vhost *virtualHosts
+	hosts map[string]int
+	// HTTPS 证书：静态证书、可选的 ACME 自动证书和管理端上传的证书（证书 ID -> 证书）
+	certs    []tls.Certificate
+	acme     *autocert.Manager
+	uploaded map[uint]*tls.Certificate
+	// 登录保护代理的用户校验，nil 表示未配置
+	auth *authGate
+	// 访问日志的接收方，nil 表示不记录
+	accessLogger proto.AccessLogger
+	// 流量统计器（可选，如果设置了则统计流量和响应缓存、压缩的效果）
 	trafficCollector interface {
-		RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64)
+		RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64)
+		RecordCacheStats(proxyID, applicationID uint, stats proto.CacheStats)
 	}
 	// 流量统计数据（每分钟上报一次）
 	trafficStats map[string]*trafficStats // key: "proxyID:applicationID"
@@ -51,15 +78,44 @@ type trafficStats struct {
 	ApplicationID uint
 	BytesIn       int64
 	BytesOut      int64
+	// entry 与 edge 之间实际传输的字节数，开启压缩时小于 BytesIn/BytesOut
+	WireBytesIn  int64
+	WireBytesOut int64
+	Cache        proto.CacheStats
 }
 
 type httpProxy struct {
-	id       int
-	port     int
-	listener net.Listener
-	ctx      context.Context
-	cancel   context.CancelFunc
-	wg       sync.WaitGroup
+	id   int
+	port int // 只通过共享监听访问时为 0
+	// 共享监听上路由到本代理的主机名，为空表示不参与虚拟主机
+	hostname   string
+	protoproxy *proto.Proxy
+	// 绑定的上传证书 ID，0 表示未绑定，可热更新
+	certificateID atomic.Uint64
+	// 登录保护和 HTTP 头改写规则，可热更新
+	auth        atomic.Pointer[proto.ProxyAuth]
+	headerRules atomic.Pointer[[]proto.HeaderRule]
+	// 按路径转发到其他应用的路由规则，可热更新
+	routes atomic.Pointer[[]*httpRoute]
+	// 代理的应用启用的插件链，可热更新
+	middlewares atomic.Pointer[middleware.Chain]
+	// 请求检查器，nil 表示没有开启
+	inspector atomic.Pointer[inspector]
+	// 响应压缩和响应缓存，nil 表示没有开启
+	compression atomic.Pointer[proto.ResponseCompression]
+	cache       atomic.Pointer[responseCache]
+	// 独立端口的监听，只通过共享监听访问时为 nil；tls 表示独立端口是否为 HTTPS
+	listener    net.Listener
+	tls         bool
+	limiter     *ratelimit.Limiter
+	connLimiter *connlimit.Limiter
+	selector    *edgeselect.Selector
+	targets     *balancer.Balancer
+	// 通过准入检查的客户端连接
+	conns  *conntrack.Table
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // NewServer 创建 HTTP 服务器
@@ -67,6 +123,8 @@ func NewServer(frontierBound frontierbound.FrontierBound) *Server {
 	s := &Server{
 		proxies:        make(map[int]*httpProxy),
 		proxiesIdxPort: make(map[int]int),
+		hosts:          make(map[string]int),
+		uploaded:       make(map[uint]*tls.Certificate),
 		frontierBound:  frontierBound,
 		trafficStats:   make(map[string]*trafficStats),
 		stop:           make(chan struct{}),
@@ -78,7 +136,8 @@ func NewServer(frontierBound frontierbound.FrontierBound) *Server {
 
 // SetTrafficCollector 设置流量统计器
 func (s *Server) SetTrafficCollector(collector interface {
-	RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64)
+	RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64)
+	RecordCacheStats(proxyID, applicationID uint, stats proto.CacheStats)
 }) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -92,8 +151,15 @@ func (s *Server) SetFirewall(fw firewallChecker) {
 	s.firewall = fw
 }
 
-// CreateProxy 创建 HTTP/HTTPS 代理
-func (s *Server) CreateProxy(ctx context.Context, protoproxy *proto.Proxy, certFile, keyFile string) error {
+// SetTrustedProxies 设置可信的前置负载均衡器，只影响之后创建的监听
+func (s *Server) SetTrustedProxies(trusted []*net.IPNet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trustedProxies = trusted
+}
+
+// CreateProxy 创建 HTTP/HTTPS 代理，HTTPS 使用 SetCertificates 设置的证书，没有可用证书时退化为 HTTP
+func (s *Server) CreateProxy(ctx context.Context, protoproxy *proto.Proxy) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -113,56 +179,179 @@ func (s *Server) CreateProxy(ctx context.Context, protoproxy *proto.Proxy, certF
 		}
 	}
 
-	// 创建监听器
+	// 主机名：启用共享监听时注册到路由表，没有指定端口的代理只通过共享监听访问
+	hostname := normalizeHost(protoproxy.Hostname)
+	if hostname != "" {
+		if s.vhost == nil {
+			log.Warnf("proxy %d: hostname %s ignored, virtual hosting is not enabled", protoproxy.ID, hostname)
+			hostname = ""
+		} else if id, exists := s.hosts[hostname]; exists && id != protoproxy.ID {
+			return fmt.Errorf("hostname %s conflict with proxy %d", hostname, id)
+		}
+	}
+	dedicated := hostname == "" || requestedPort != 0
+
+	// 带宽限制，始终创建以便之后通过 UpdateProxy 热更新
+	limiter := ratelimit.NewLimiter(protoproxy.RateLimit)
 	var listener net.Listener
-	var err error
-	if certFile != "" && keyFile != "" {
-		// HTTPS
-		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	actualPort, useTLS := 0, false
+	if dedicated {
+		var err error
+		// 没有静态证书时，只有绑定了证书或者可以申请 ACME 证书的代理才能使用 HTTPS
+		useTLS = protoproxy.UseHTTPS &&
+			(len(s.certs) > 0 || protoproxy.CertificateID != 0 || s.acme != nil && hostname != "")
+		listener, err = s.listenDedicated(protoproxy, limiter, useTLS)
 		if err != nil {
-			return fmt.Errorf("failed to load certificate: %w", err)
+			return err
 		}
-		config := &tls.Config{
-			Certificates: []tls.Certificate{cert},
+		// 获取实际端口
+		actualPort = listener.Addr().(*net.TCPAddr).Port
+		if requestedPort == 0 {
+			log.Infof("proxy %d: system allocated port %d", protoproxy.ID, actualPort)
+			protoproxy.ProxyPort = actualPort
 		}
-		listener, err = tls.Listen("tcp", fmt.Sprintf(":%d", requestedPort), config)
-	} else {
-		// HTTP
-		listener, err = net.Listen("tcp", fmt.Sprintf(":%d", requestedPort))
-	}
-	if err != nil {
-		return fmt.Errorf("failed to listen on port %d: %w", requestedPort, err)
-	}
-
-	// 获取实际端口
-	actualPort := listener.Addr().(*net.TCPAddr).Port
-	if requestedPort == 0 {
-		log.Infof("proxy %d: system allocated port %d", protoproxy.ID, actualPort)
-		protoproxy.ProxyPort = actualPort
 	}
 
 	// 创建可取消的 context
 	proxyCtx, cancel := context.WithCancel(context.Background())
 
 	proxy := &httpProxy{
-		id:       protoproxy.ID,
-		port:     actualPort,
-		listener: listener,
-		ctx:      proxyCtx,
-		cancel:   cancel,
+		id:          protoproxy.ID,
+		port:        actualPort,
+		hostname:    hostname,
+		protoproxy:  protoproxy,
+		listener:    listener,
+		tls:         useTLS,
+		limiter:     limiter,
+		connLimiter: connlimit.NewLimiter(protoproxy.ID, protoproxy.ConnLimit),
+		selector:    edgeselect.NewSelector(protoproxy.ID, protoproxy.EdgePolicy, protoproxy.EdgeIDs),
+		targets:     balancer.New(protoproxy.TargetList()),
+		conns:       conntrack.NewTable(),
+		ctx:         proxyCtx,
+		cancel:      cancel,
 	}
 
-	// 启动处理 goroutine
-	proxy.wg.Add(1)
-	go proxy.serve(s, protoproxy)
-
+	proxy.certificateID.Store(uint64(protoproxy.CertificateID))
+	proxy.setAuth(protoproxy.Auth)
+	proxy.headerRules.Store(&protoproxy.HeaderRules)
+	proxy.setRoutes(protoproxy.Routes)
+	proxy.setMiddlewares(protoproxy.Middlewares)
+	proxy.setInspector(protoproxy.Inspector)
+	proxy.setResponseCompression(protoproxy.ResponseCompression)
+	proxy.setResponseCache(protoproxy.ResponseCache)
 	s.proxies[protoproxy.ID] = proxy
-	s.proxiesIdxPort[actualPort] = protoproxy.ID
+	if hostname != "" {
+		s.hosts[hostname] = protoproxy.ID
+		log.Infof("HTTP proxy %d serving host %s", protoproxy.ID, hostname)
+	}
+	if listener != nil {
+		// 启动处理 goroutine
+		proxy.wg.Add(1)
+		go proxy.serve(s, protoproxy)
+		s.proxiesIdxPort[actualPort] = protoproxy.ID
+		log.Infof("HTTP proxy %d listening on port %d", protoproxy.ID, actualPort)
+	}
+	return nil
+}
+
+// listenDedicated 为代理创建独立端口的监听。PROXY protocol 头在 TLS 握手之前，所以先包装原始 TCP 监听；
+// 带宽限制按 TLS 之下的原始字节计算
+func (s *Server) listenDedicated(protoproxy *proto.Proxy, limiter *ratelimit.Limiter, useTLS bool) (net.Listener, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", protoproxy.ProxyPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on port %d: %w", protoproxy.ProxyPort, err)
+	}
+	listener = proxyproto.NewListener(listener, s.trustedProxies)
+	listener = ratelimit.NewListener(listener, limiter)
+	if useTLS {
+		// HTTPS
+		listener = tls.NewListener(listener, s.tlsConfig(protoproxy.ID))
+	}
+	return listener, nil
+}
 
-	log.Infof("HTTP proxy %d listening on port %d", protoproxy.ID, actualPort)
+// UpdateProxy 热更新运行中代理的带宽、连接数限制、edge 列表、后端健康状态、绑定的证书、登录保护、头改写、路由规则、中间件、请求检查器、响应压缩和缓存，不在本数据面的代理直接忽略
+func (s *Server) UpdateProxy(ctx context.Context, protoproxy *proto.Proxy) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	proxy, exists := s.proxies[protoproxy.ID]
+	if !exists {
+		return nil
+	}
+	proxy.limiter.Update(protoproxy.RateLimit)
+	proxy.connLimiter.Update(protoproxy.ConnLimit)
+	proxy.selector.Update(protoproxy.EdgePolicy, protoproxy.EdgeIDs)
+	proxy.targets.Update(protoproxy.TargetList())
+	proxy.certificateID.Store(uint64(protoproxy.CertificateID))
+	proxy.setAuth(protoproxy.Auth)
+	proxy.headerRules.Store(&protoproxy.HeaderRules)
+	proxy.setRoutes(protoproxy.Routes)
+	proxy.setMiddlewares(protoproxy.Middlewares)
+	proxy.setInspector(protoproxy.Inspector)
+	proxy.setResponseCompression(protoproxy.ResponseCompression)
+	proxy.setResponseCache(protoproxy.ResponseCache)
+	if proxy.listener != nil && !proxy.tls && protoproxy.CertificateID != 0 {
+		log.Warnf("HTTP proxy %d: port %d is plain http, certificate %d takes effect after restart",
+			protoproxy.ID, proxy.port, protoproxy.CertificateID)
+	}
+	log.Infof("HTTP proxy %d updated: rate %+v, conn %+v, edges %v (%s)", protoproxy.ID,
+		protoproxy.RateLimit, protoproxy.ConnLimit, protoproxy.EdgeIDs, protoproxy.EdgePolicy)
 	return nil
 }
 
+func (p *httpProxy) setAuth(auth proto.ProxyAuth) {
+	p.auth.Store(&auth)
+}
+
+// runningProxy 返回运行中的代理，已删除时返回 nil
+func (s *Server) runningProxy(id int) *httpProxy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.proxies[id]
+}
+
+// GetProxyStats 返回运行中代理的连接计数，不在本数据面的代理返回 nil
+func (s *Server) GetProxyStats(ctx context.Context, id int) (*proto.ProxyStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	proxy, exists := s.proxies[id]
+	if !exists {
+		return nil, nil
+	}
+	stats := proxy.connLimiter.Stats()
+	stats.Edges = proxy.selector.Stats()
+	return &stats, nil
+}
+
+// upstream 一次请求选中的应用、edge 和后端
+type upstream struct {
+	applicationID uint
+	edgeID        uint64
+	dst           string
+}
+
+// openStream 按 edge 策略打开 stream，并为本次请求选择后端。route 为 nil 时转发到代理自己的应用，
+// 否则转发到路由规则的应用
+func (s *Server) openStream(ctx context.Context, protoproxy *proto.Proxy, route *httpRoute) (geminio.Stream, upstream, error) {
+	s.mu.RLock()
+	proxy, exists := s.proxies[protoproxy.ID]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, upstream{}, fmt.Errorf("proxy %d is not running", protoproxy.ID)
+	}
+	applicationID, selector, targets := protoproxy.ApplicationID, proxy.selector, proxy.targets
+	if route != nil {
+		applicationID, selector, targets = route.ApplicationID, route.selector, route.targets
+	}
+	stream, edgeID, err := selector.OpenStream(ctx, s.frontierBound)
+	if err != nil {
+		return nil, upstream{}, err
+	}
+	return stream, upstream{applicationID: applicationID, edgeID: edgeID, dst: targets.Pick()}, nil
+}
+
 // DeleteProxy 删除代理
 func (s *Server) DeleteProxy(ctx context.Context, id int) error {
 	s.mu.Lock()
@@ -178,8 +367,10 @@ func (s *Server) DeleteProxy(ctx context.Context, id int) error {
 	proxy.cancel()
 
 	// 关闭监听器
-	if err := proxy.listener.Close(); err != nil {
-		log.Errorf("failed to close listener for proxy %d: %s", id, err)
+	if proxy.listener != nil {
+		if err := proxy.listener.Close(); err != nil {
+			log.Errorf("failed to close listener for proxy %d: %s", id, err)
+		}
 	}
 
 	// 等待 goroutine 退出
@@ -197,7 +388,12 @@ func (s *Server) DeleteProxy(ctx context.Context, id int) error {
 	}
 
 	delete(s.proxies, id)
-	delete(s.proxiesIdxPort, proxy.port)
+	if proxy.listener != nil {
+		delete(s.proxiesIdxPort, proxy.port)
+	}
+	if proxy.hostname != "" {
+		delete(s.hosts, proxy.hostname)
+	}
 
 	log.Infof("HTTP proxy %d deleted", id)
 	return nil
@@ -211,20 +407,26 @@ func (s *Server) Close() {
 	// 停止流量统计上报
 	close(s.stop)
 
+	if s.vhost != nil {
+		s.vhost.close()
+	}
 	for _, proxy := range s.proxies {
 		proxy.cancel()
-		proxy.listener.Close()
+		if proxy.listener != nil {
+			proxy.listener.Close()
+		}
 	}
 	for _, proxy := range s.proxies {
 		proxy.wg.Wait()
 	}
 	s.proxies = make(map[int]*httpProxy)
 	s.proxiesIdxPort = make(map[int]int)
+	s.hosts = make(map[string]int)
 }
 
-// recordTraffic 记录流量（累积到stats中，由定时器每分钟上报一次）
-func (s *Server) recordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64) {
-	if bytesIn == 0 && bytesOut == 0 {
+// recordTraffic 记录流量（累积到stats中，由定时器每分钟上报一次），wireBytes* 为压缩后的字节数
+func (s *Server) recordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64) {
+	if bytesIn == 0 && bytesOut == 0 && wireBytesIn == 0 && wireBytesOut == 0 {
 		return
 	}
 
@@ -243,6 +445,37 @@ func (s *Server) recordTraffic(proxyID, applicationID uint, bytesIn, bytesOut in
 
 	stats.BytesIn += bytesIn
 	stats.BytesOut += bytesOut
+	stats.WireBytesIn += wireBytesIn
+	stats.WireBytesOut += wireBytesOut
+}
+
+// recordCacheStats 记录响应缓存和压缩的效果，与流量一起上报
+func (s *Server) recordCacheStats(proxyID, applicationID uint, cache *cacheLookup, enc *responseEncoder) {
+	var stats proto.CacheStats
+	if cache != nil {
+		stats = cache.stats
+	}
+	stats.CompressionSavedBytes = enc.saved()
+	if stats.IsZero() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := fmt.Sprintf("%d:%d", proxyID, applicationID)
+	total, exists := s.trafficStats[key]
+	if !exists {
+		total = &trafficStats{
+			ProxyID:       proxyID,
+			ApplicationID: applicationID,
+		}
+		s.trafficStats[key] = total
+	}
+	total.Cache.Hits += stats.Hits
+	total.Cache.Misses += stats.Misses
+	total.Cache.SavedBytes += stats.SavedBytes
+	total.Cache.CompressionSavedBytes += stats.CompressionSavedBytes
 }
 
 // reportLoop 每分钟上报一次流量统计
@@ -278,6 +511,9 @@ func (s *Server) flushAndReport() {
 			ApplicationID: stats.ApplicationID,
 			BytesIn:       stats.BytesIn,
 			BytesOut:      stats.BytesOut,
+			WireBytesIn:   stats.WireBytesIn,
+			WireBytesOut:  stats.WireBytesOut,
+			Cache:         stats.Cache,
 		})
 	}
 
@@ -289,7 +525,11 @@ func (s *Server) flushAndReport() {
 	// 上报（在锁外执行，避免阻塞）
 	if trafficCollector != nil {
 		for _, stats := range statsToReport {
-			trafficCollector.RecordTraffic(stats.ProxyID, stats.ApplicationID, stats.BytesIn, stats.BytesOut)
+			trafficCollector.RecordTraffic(stats.ProxyID, stats.ApplicationID,
+				stats.BytesIn, stats.BytesOut, stats.WireBytesIn, stats.WireBytesOut)
+			if !stats.Cache.IsZero() {
+				trafficCollector.RecordCacheStats(stats.ProxyID, stats.ApplicationID, stats.Cache)
+			}
 		}
 		log.Debugf("HTTP server reported %d traffic metrics", len(statsToReport))
 	}
@@ -328,35 +568,87 @@ func (p *httpProxy) serve(s *Server, protoproxy *proto.Proxy) {
 			continue
 		}
 
-		// 为每个连接启动 goroutine
+		// 并发连接限制，放在防火墙之后，被防火墙拒绝的连接不占名额也不计入拒绝数
+		ip, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+		if !p.connLimiter.Acquire(ip) {
+			_ = conn.Close()
+			continue
+		}
+
+		// 为每个连接启动 goroutine，连接登记到活跃连接表，关闭时移除
 		p.wg.Add(1)
 		go func(clientConn net.Conn) {
 			defer p.wg.Done()
+			defer p.connLimiter.Release(ip)
 			defer clientConn.Close()
 			s.handleConnection(p.ctx, clientConn, protoproxy)
-		}(conn)
+		}(p.conns.TrackConn(conn, proto.ConnectionHTTP))
 	}
 }
 
 // handleConnection 处理单个连接（支持 HTTP keep-alive 和 WebSocket）
+// TLS 上通过 ALPN 协商 HTTP/2，明文端口在开启 h2c 时识别 HTTP/2 前言
 func (s *Server) handleConnection(ctx context.Context, clientConn net.Conn, protoproxy *proto.Proxy) {
+	if tlsConn, ok := unwrapConn(clientConn).(*tls.Conn); ok {
+		_ = tlsConn.SetDeadline(time.Now().Add(30 * time.Second))
+		if err := tlsConn.Handshake(); err != nil {
+			log.Debugf("tls handshake with %s err: %s", clientConn.RemoteAddr(), err)
+			return
+		}
+		_ = tlsConn.SetDeadline(time.Time{})
+		if tlsConn.ConnectionState().NegotiatedProtocol == http2.NextProtoTLS {
+			s.serveHTTP2(ctx, clientConn, protoproxy, "")
+			return
+		}
+	}
+	reader := bufio.NewReader(clientConn)
+	if protoproxy.H2C && !isTLS(clientConn) {
+		_ = clientConn.SetReadDeadline(time.Now().Add(30 * time.Second))
+		if isH2CPreface(reader) {
+			_ = clientConn.SetReadDeadline(time.Time{})
+			s.serveHTTP2(ctx, &peekedConn{Conn: clientConn, reader: reader}, protoproxy, "")
+			return
+		}
+	}
+	s.serveRequests(ctx, clientConn, reader, nil, protoproxy, "")
+}
+
+// serveRequests 循环处理连接上的请求。req 非空时为已经读出的第一个请求；
+// host 非空时（共享监听）之后的请求必须是同一个主机名，否则返回 421
+func (s *Server) serveRequests(ctx context.Context, clientConn net.Conn, reader *bufio.Reader, req *http.Request, protoproxy *proto.Proxy, host string) {
 	defer clientConn.Close()
 
-	reader := bufio.NewReader(clientConn)
+	// 连接上的请求复用到后端的 stream
+	pool := newBackendPool(s, ctx, clientConn, protoproxy)
+	defer pool.close()
 
 	// 处理 keep-alive 连接，循环读取多个请求
 	for {
-		// 设置读取超时
-		clientConn.SetReadDeadline(time.Now().Add(30 * time.Second))
-
-		// 读取 HTTP 请求
-		req, err := http.ReadRequest(reader)
-		if err != nil {
-			if err == io.EOF {
-				// 连接关闭
+		if req == nil {
+			// 设置读取超时
+			clientConn.SetReadDeadline(time.Now().Add(30 * time.Second))
+
+			// 读取 HTTP 请求
+			var err error
+			req, err = http.ReadRequest(reader)
+			if err != nil {
+				if err == io.EOF {
+					// 连接关闭
+					return
+				}
+				log.Errorf("failed to read request: %s", err)
 				return
 			}
-			log.Errorf("failed to read request: %s", err)
+			if host != "" && normalizeHost(req.Host) != host {
+				writeErrorResponse(clientConn, http.StatusMisdirectedRequest, "misdirected_request",
+					fmt.Sprintf("connection is bound to host %s", host))
+				return
+			}
+		}
+
+		// 登录保护，不通过时已经写出登录页面或者错误响应
+		alog := s.newAccessLog(req, clientConn, protoproxy)
+		if !s.authorizeConn(clientConn, req, protoproxy.ID, alog) {
 			return
 		}
 
@@ -365,12 +657,14 @@ func (s *Server) handleConnection(ctx context.Context, clientConn net.Conn, prot
 			// WebSocket 处理（会接管整个连接，不会返回）
 			// 移除读取超时限制，WebSocket 需要保持长时间连接
 			clientConn.SetReadDeadline(time.Time{})
-			s.handleWebSocket(ctx, clientConn, reader, req, protoproxy)
+			pool.close()
+			s.handleWebSocket(ctx, clientConn, reader, req, protoproxy, alog)
 			return
 		}
 
 		// 处理普通 HTTP 请求
-		keepAlive := s.handleRequest(ctx, clientConn, reader, req, protoproxy)
+		keepAlive := s.handleRequest(ctx, clientConn, req, protoproxy, pool, alog)
+		req = nil
 
 		// 如果不是 keep-alive，关闭连接
 		if !keepAlive {
@@ -379,153 +673,295 @@ func (s *Server) handleConnection(ctx context.Context, clientConn net.Conn, prot
 	}
 }
 
-// handleRequest 处理单个 HTTP 请求
-func (s *Server) handleRequest(ctx context.Context, clientConn net.Conn, reader *bufio.Reader, req *http.Request, protoproxy *proto.Proxy) bool {
+// handleRequest 处理单个 HTTP 请求。请求体和响应体都边读边写，不在内存中缓存，
+// 流量由计数的 reader 统计
+func (s *Server) handleRequest(ctx context.Context, clientConn net.Conn, req *http.Request, protoproxy *proto.Proxy, pool *backendPool, alog *accessLog) bool {
 	defer req.Body.Close()
 
 	// 检查是否是 keep-alive 连接
 	keepAlive := req.ProtoAtLeast(1, 1) && req.Header.Get("Connection") != "close"
 
-	// 统计请求流量（入站）
-	var requestBytes int64
-	if req.ContentLength > 0 {
-		requestBytes = req.ContentLength
-	} else {
-		// 如果ContentLength未知，读取请求体来统计
-		if req.Body != nil && req.Body != http.NoBody {
-			bodyBytes, _ := io.ReadAll(req.Body)
-			requestBytes = int64(len(bodyBytes))
-			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-		}
+	// 统计请求流量（入站），请求行和请求头为估算大小
+	counter := &transport.TrafficCounter{}
+	requestHeaderBytes := requestLineBytes(req) + headerBytes(req.Header)
+	req.Body = countBody(req.Body, counter, true)
+	enc := s.newEncoder(req, protoproxy)
+
+	// 匹配的应用启用的中间件改写请求，改写失败时继续发送请求
+	route := s.routeRequest(req, protoproxy)
+	chain := s.requestMiddlewares(protoproxy, route)
+	if err := chain.ModifyRequest(req); err != nil {
+		log.Errorf("http proxy %d middleware modify request err: %s", protoproxy.ID, err)
 	}
-	// 加上请求行和请求头的大小（估算）
-	requestLineSize := int64(len(req.Method) + len(req.URL.RequestURI()) + len(req.Proto) + 4) // +4 for spaces and CRLF
-	var headerSize int64
-	for k, v := range req.Header {
-		headerSize += int64(len(k) + 2) // key + ": "
-		for _, val := range v {
-			headerSize += int64(len(val) + 2) // value + CRLF
+
+	if protoproxy.ForwardedHeaders {
+		setForwardedHeaders(req, clientConn)
+	}
+	rewrite := s.newHeaderRewrite(req, clientConn, protoproxy)
+	rewrite.request(req.Header)
+
+	// 开启了响应缓存时先查缓存，命中时不经过 edge
+	cached := s.lookupCache(req, protoproxy, route)
+	resp, applicationID := cached.hit()
+	var bc *backendConn
+	var capt *capture
+	if resp == nil {
+		// 开启了检查器时记下转发给后端的请求和后端的响应
+		capt = s.newCapture(req, clientConn, protoproxy)
+
+		// 经 edge 转发请求并读取响应，请求和响应走复用的 stream（可能经过压缩）
+		var err error
+		resp, bc, err = pool.forward(req, route)
+		if err != nil {
+			writeDialError(clientConn, err)
+			status, _, _ := dialErrorStatus(err)
+			bodyIn, _ := counter.Load()
+			alog.finish(routeApplication(route), status, requestHeaderBytes+bodyIn, 0)
+			capt.finish(routeApplication(route), err)
+			return false
 		}
+		capt.response(resp)
+		applicationID = bc.up.applicationID
+		resp = cached.store(resp, applicationID)
 	}
-	requestBytes += requestLineSize + headerSize + 2 // +2 for final CRLF
+	defer resp.Body.Close()
+	if err := chain.ModifyResponse(resp); err != nil {
+		log.Errorf("http proxy %d middleware modify response err: %s", protoproxy.ID, err)
+	}
+	enc.apply(resp, req)
+	rewrite.response(resp.Header)
+	prepareResponse(resp, req, keepAlive)
 
-	// 打开到 edge 的 stream
-	stream, err := s.frontierBound.OpenStream(ctx, protoproxy.EdgeID)
-	if err != nil {
-		log.Errorf("failed to open stream: %s", err)
-		// 写入错误响应
-		resp := &http.Response{
-			StatusCode: http.StatusInternalServerError,
-			Status:     "Internal Server Error",
-			Proto:      "HTTP/1.1",
-			ProtoMajor: 1,
-			ProtoMinor: 1,
-			Header:     make(http.Header),
-			Body:       http.NoBody,
-		}
-		if !keepAlive {
-			resp.Header.Set("Connection", "close")
-		}
-		_ = resp.Write(clientConn)
-		return false
+	// 设置 keep-alive 响应头
+	if keepAlive {
+		resp.Header.Set("Connection", "keep-alive")
+	} else {
+		resp.Header.Set("Connection", "close")
 	}
-	defer stream.Close()
 
-	// 写入目标地址信息（类似 gatekeeper 的 preWrite）
-	if err := s.writeDstInfo(stream, protoproxy); err != nil {
-		log.Errorf("failed to write dst info: %s", err)
+	// 统计响应流量（出站），状态行和响应头为估算大小
+	responseHeaderBytes := statusLineBytes(resp) + headerBytes(resp.Header)
+	resp.Body = countBody(resp.Body, counter, false)
+
+	// 写入响应到客户端，直接写连接，流式响应（SSE、长轮询）的每一块都立即发出
+	writeErr := resp.Write(clientConn)
+
+	// 记录流量统计，写失败时也统计已经转发的部分
+	bodyIn, bodyOut := counter.Load()
+	requestBytes, responseBytes := requestHeaderBytes+bodyIn, responseHeaderBytes+bodyOut
+	wireIn, wireOut := bc.wireBytes(requestBytes, responseBytes)
+	// 流量计入匹配到的应用
+	s.recordTraffic(uint(protoproxy.ID), applicationID, requestBytes, responseBytes, wireIn, wireOut)
+	s.recordCacheStats(uint(protoproxy.ID), applicationID, cached, enc)
+	alog.finish(applicationID, resp.StatusCode, requestBytes, responseBytes)
+	capt.finish(applicationID, nil)
+
+	if writeErr != nil {
+		log.Errorf("failed to write response: %s", writeErr)
 		return false
 	}
+	return keepAlive
+}
 
-	// 检查并修改 PlaybackInfo 请求的请求体
-	if err := s.modifyPlaybackInfoRequest(req); err != nil {
-		log.Errorf("failed to modify PlaybackInfo request: %s", err)
-		// 即使修改失败，也继续发送请求
+// prepareResponse 让响应可以在 keep-alive 连接上流式写给 HTTP/1.1 客户端：
+// 长度未知、靠关闭连接结束的响应体（包括来自 HTTP/2 后端的响应）改用 chunked
+func prepareResponse(resp *http.Response, req *http.Request, keepAlive bool) {
+	if resp.ProtoMajor == 2 || keepAlive {
+		resp.Proto, resp.ProtoMajor, resp.ProtoMinor = "HTTP/1.1", 1, 1
 	}
-
-	// 构建并发送 HTTP 请求
-	if err := s.sendRequest(ctx, stream, req, protoproxy); err != nil {
-		log.Errorf("failed to send request: %s", err)
-		return false
+	if keepAlive && resp.ContentLength < 0 && len(resp.TransferEncoding) == 0 && bodyAllowed(resp, req) {
+		resp.TransferEncoding = []string{"chunked"}
 	}
+}
 
-	// 读取响应
-	resp, err := s.readResponse(ctx, stream, req)
-	if err != nil {
-		log.Errorf("failed to read response: %s", err)
+// bodyAllowed 响应是否可以有响应体
+func bodyAllowed(resp *http.Response, req *http.Request) bool {
+	if req.Method == http.MethodHead {
 		return false
 	}
-	defer resp.Body.Close()
+	status := resp.StatusCode
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
 
-	// 设置 keep-alive 响应头
-	if keepAlive {
-		resp.Header.Set("Connection", "keep-alive")
-	} else {
-		resp.Header.Set("Connection", "close")
+// countBody 包装 body，读取时计入 counter；http.NoBody 保持不变，避免被当作有请求体
+func countBody(body io.ReadCloser, counter *transport.TrafficCounter, inbound bool) io.ReadCloser {
+	if body == nil || body == http.NoBody {
+		return body
 	}
+	return &countedBody{Reader: transport.NewCountingReader(body, counter, inbound), Closer: body}
+}
 
-	// 统计响应流量（出站）
-	var responseBytes int64
-	if resp.ContentLength > 0 {
-		responseBytes = resp.ContentLength
-	} else if resp.Body != nil && resp.Body != http.NoBody {
-		// 如果ContentLength未知，读取响应体来统计
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		responseBytes = int64(len(bodyBytes))
-		resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-	}
-	// 加上状态行和响应头的大小（估算）
-	statusLineSize := int64(len(resp.Status) + len(resp.Proto) + 4) // +4 for spaces and CRLF
-	var respHeaderSize int64
-	for k, v := range resp.Header {
-		respHeaderSize += int64(len(k) + 2) // key + ": "
-		for _, val := range v {
-			respHeaderSize += int64(len(val) + 2) // value + CRLF
-		}
-	}
-	responseBytes += statusLineSize + respHeaderSize + 2 // +2 for final CRLF
+type countedBody struct {
+	io.Reader
+	io.Closer
+}
 
-	// 写入响应到客户端
-	if err := resp.Write(clientConn); err != nil {
-		log.Errorf("failed to write response: %s", err)
-		return false
+// writeDstInfo 写入目标地址信息，并等待 edge 返回拨号结果。
+// 返回之后读写后端数据使用的连接，edge 确认压缩时 compressed 为 true
+func (s *Server) writeDstInfo(stream net.Conn, clientConn net.Conn, protoproxy *proto.Proxy, up upstream) (net.Conn, bool, error) {
+	result, err := proto.Handshake(stream, &proto.Dst{
+		Addr:          up.dst,
+		ApplicationID: up.applicationID,
+		ProxyID:       uint(protoproxy.ID),
+		ClientAddr:    clientConn.RemoteAddr().String(),
+		EntryAddr:     clientConn.LocalAddr().String(),
+		ProxyProtocol: protoproxy.ProxyProtocol,
+		Compression:   protoproxy.Compression,
+		Version:       s.frontierBound.EdgeProtocol(up.edgeID),
+	}, dialResultTimeout)
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := compress.Wrap(stream, result.Compression)
+	if err != nil {
+		return nil, false, err
 	}
+	return conn, result.Compression != "", nil
+}
 
-	// 记录流量统计
-	s.recordTraffic(uint(protoproxy.ID), protoproxy.ApplicationID, requestBytes, responseBytes)
+// wireCounter 统计 stream 上实际传输的字节数，开启压缩时即压缩后的大小
+type wireCounter struct {
+	net.Conn
+	read    int64
+	written int64
+	// 已经统计过的字节数，stream 复用时每个请求只统计增量
+	reportedRead    int64
+	reportedWritten int64
+}
 
-	return keepAlive
+func (w *wireCounter) Read(b []byte) (int, error) {
+	n, err := w.Conn.Read(b)
+	atomic.AddInt64(&w.read, int64(n))
+	return n, err
 }
 
-// writeDstInfo 写入目标地址信息
-func (s *Server) writeDstInfo(stream io.Writer, protoproxy *proto.Proxy) error {
-	dst := proto.Dst{
-		Addr:          protoproxy.Dst,
-		ApplicationID: protoproxy.ApplicationID,
-		ProxyID:       uint(protoproxy.ID),
+func (w *wireCounter) Write(b []byte) (int, error) {
+	n, err := w.Conn.Write(b)
+	atomic.AddInt64(&w.written, int64(n))
+	return n, err
+}
+
+// bytes 返回上次统计之后的 wire 流量（入站、出站）。没有压缩时与 logical 流量的统计口径保持一致
+func (w *wireCounter) bytes(compressed bool, logicalIn, logicalOut int64) (int64, int64) {
+	if !compressed {
+		return logicalIn, logicalOut
 	}
-	data, err := json.Marshal(dst)
+	written, read := atomic.LoadInt64(&w.written), atomic.LoadInt64(&w.read)
+	return written - atomic.SwapInt64(&w.reportedWritten, written), read - atomic.SwapInt64(&w.reportedRead, read)
+}
+
+// setForwardedHeaders 为不支持 PROXY protocol 的后端注入客户端真实地址。
+// X-Forwarded-For 追加在已有值之后，X-Real-IP 以本次连接为准，避免客户端伪造。
+func setForwardedHeaders(req *http.Request, clientConn net.Conn) {
+	clientIP, _, err := net.SplitHostPort(clientConn.RemoteAddr().String())
 	if err != nil {
-		return fmt.Errorf("failed to marshal dst: %w", err)
+		return
+	}
+	if prior := req.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+		req.Header.Set("X-Forwarded-For", strings.Join(prior, ", ")+", "+clientIP)
+	} else {
+		req.Header.Set("X-Forwarded-For", clientIP)
 	}
+	req.Header.Set("X-Real-IP", clientIP)
+	scheme := "http"
+	if isTLS(clientConn) {
+		scheme = "https"
+	}
+	req.Header.Set("X-Forwarded-Proto", scheme)
+	if req.Host != "" {
+		req.Header.Set("X-Forwarded-Host", req.Host)
+	}
+}
+
+// isTLS 客户端连接是否经过 TLS，包括共享监听上的连接
+func isTLS(clientConn net.Conn) bool {
+	switch c := clientConn.(type) {
+	case *tls.Conn:
+		return true
+	case *routedConn:
+		return c.tls
+	case *conntrack.Conn:
+		return isTLS(c.Conn)
+	}
+	return false
+}
+
+// errEdgeUnavailable 没有可用的 edge，打不开 stream
+var errEdgeUnavailable = errors.New("edge unavailable")
 
-	// 写入长度（4字节）
-	lengthBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(lengthBuf, uint32(len(data)))
-	if _, err := stream.Write(lengthBuf); err != nil {
-		return fmt.Errorf("failed to write dst length: %w", err)
+// writeDialError 把转发失败转换成 HTTP 错误响应，见 dialErrorStatus
+func writeDialError(clientConn net.Conn, err error) {
+	status, code, reason := dialErrorStatus(err)
+	writeErrorResponse(clientConn, status, code, reason)
+}
+
+// dialErrorStatus 把转发失败转换成状态码和错误类别：没有可用 edge 返回 502，
+// 超时返回 504，edge 本地策略拒绝返回 403，其余（拒绝连接、DNS 失败、不可达等）返回 502。
+func dialErrorStatus(err error) (int, string, string) {
+	status, code, reason := http.StatusBadGateway, proto.DialCodeFailed.String(), err.Error()
+	var dialErr *proto.DialError
+	switch {
+	case errors.Is(err, errEdgeUnavailable):
+		code = "edge_unavailable"
+	case errors.As(err, &dialErr):
+		code, reason = dialErr.Result.Code.String(), dialErr.Result.Reason
+		switch dialErr.Result.Code {
+		case proto.DialCodeDenied:
+			status = http.StatusForbidden
+		case proto.DialCodeTimeout:
+			status = http.StatusGatewayTimeout
+		}
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		// edge 迟迟没有返回拨号结果
+		status, code = http.StatusGatewayTimeout, proto.DialCodeTimeout.String()
 	}
+	return status, code, reason
+}
+
+// requestLineBytes 估算请求行的大小
+func requestLineBytes(req *http.Request) int64 {
+	return int64(len(req.Method) + len(req.URL.RequestURI()) + len(req.Proto) + 4) // +4 for spaces and CRLF
+}
+
+// statusLineBytes 估算状态行的大小
+func statusLineBytes(resp *http.Response) int64 {
+	return int64(len(resp.Status) + len(resp.Proto) + 4) // +4 for spaces and CRLF
+}
 
-	// 写入数据
-	if _, err := stream.Write(data); err != nil {
-		return fmt.Errorf("failed to write dst: %w", err)
+// headerBytes 估算头部的大小，包括结尾的空行
+func headerBytes(header http.Header) int64 {
+	var size int64
+	for k, v := range header {
+		size += int64(len(k) + 2) // key + ": "
+		for _, val := range v {
+			size += int64(len(val) + 2) // value + CRLF
+		}
 	}
+	return size + 2 // +2 for final CRLF
+}
 
-	return nil
+// writeErrorResponse 向客户端写一个带原因的错误响应，并告知关闭连接。
+// X-Liaison-Error 携带机器可读的错误类别，便于排查。
+func writeErrorResponse(clientConn net.Conn, status int, code, reason string) {
+	body := fmt.Sprintf("%s: %s\n", code, reason)
+	resp := &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp.Header.Set("Connection", "close")
+	resp.Header.Set("X-Liaison-Error", code)
+	_ = resp.Write(clientConn)
 }
 
-// sendRequest 发送 HTTP 请求到 stream
-func (s *Server) sendRequest(ctx context.Context, stream io.Writer, req *http.Request, protoproxy *proto.Proxy) error {
+// sendRequest 发送 HTTP 请求到 stream，dst 为本次选中的后端
+func (s *Server) sendRequest(ctx context.Context, stream io.Writer, req *http.Request, dst string) error {
 	// 创建请求副本，避免修改原始请求
 	reqCopy := req.Clone(ctx)
 	if reqCopy == nil {
@@ -546,39 +982,37 @@ func (s *Server) sendRequest(ctx context.Context, stream io.Writer, req *http.Re
 	}
 
 	// 修改请求的 Host 和目标地址
-	reqCopy.Host = protoproxy.Dst
+	reqCopy.Host = dst
 	if reqCopy.URL != nil {
 		// 解析目标地址
-		dstURL := fmt.Sprintf("http://%s%s", protoproxy.Dst, req.URL.RequestURI())
+		dstURL := fmt.Sprintf("http://%s%s", dst, req.URL.RequestURI())
 		parsedURL, err := req.URL.Parse(dstURL)
 		if err == nil {
 			reqCopy.URL = parsedURL
 		} else {
 			// 如果解析失败，直接设置 Host
-			reqCopy.URL.Host = protoproxy.Dst
+			reqCopy.URL.Host = dst
 		}
 	}
 
-	// 构建请求（类似参考代码的 buildReqReader）
-	reqData, err := httputil.DumpRequest(reqCopy, true)
-	if err != nil {
-		return fmt.Errorf("failed to dump request: %w", err)
+	// 客户端没有 User-Agent 时不让 Request.Write 补上默认值
+	if _, ok := reqCopy.Header["User-Agent"]; !ok {
+		reqCopy.Header["User-Agent"] = []string{""}
 	}
 
-	// 写入请求到 stream
-	_, err = stream.Write(reqData)
-	if err != nil {
+	// 流式写入请求，长度未知的请求体用 chunked；经过缓冲减少 stream 上的小包
+	bw := bufio.NewWriter(stream)
+	if err := reqCopy.Write(bw); err != nil {
+		return fmt.Errorf("failed to write request: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
 		return fmt.Errorf("failed to write request: %w", err)
 	}
-
 	return nil
 }
 
-// readResponse 从 stream 读取 HTTP 响应
-func (s *Server) readResponse(ctx context.Context, stream io.Reader, req *http.Request) (*http.Response, error) {
-	// 创建带超时的 reader
-	reader := bufio.NewReader(stream)
-
+// readResponse 从 stream 读取 HTTP 响应。reader 随 stream 复用，不能每次新建，否则会丢掉已缓冲的数据
+func (s *Server) readResponse(ctx context.Context, reader *bufio.Reader, req *http.Request) (*http.Response, error) {
 	// 读取响应（类似参考代码的 http.ReadResponse）
 	resp, err := http.ReadResponse(reader, req)
 	if err != nil {
@@ -597,123 +1031,51 @@ func (s *Server) isWebSocketUpgrade(req *http.Request) bool {
 	return strings.Contains(connection, "upgrade") && strings.Contains(upgrade, "websocket")
 }
 
-// isDirectPlay 检查是否是 Direct Play 请求
-func (s *Server) isDirectPlay(req *http.Request) bool {
-	// 检查路径是否包含 "/stream"
-	path := strings.ToLower(req.URL.Path)
-	if !strings.Contains(path, "/stream") {
-		return false
-	}
-
-	// 检查请求头是否包含 "Range"
-	rangeHeader := req.Header.Get("Range")
-	if rangeHeader == "" {
-		return false
-	}
-
-	return true
-}
-
-// modifyPlaybackInfoRequest 修改 PlaybackInfo 请求的请求体，清空 DirectPlayProfiles
-func (s *Server) modifyPlaybackInfoRequest(req *http.Request) error {
-	// 检查路径是否包含 "PlaybackInfo"
-	path := strings.ToLower(req.URL.Path)
-	if !strings.Contains(path, "playbackinfo") {
-		return nil
-	}
-
-	// 只处理 POST 和 PUT 请求
-	if req.Method != "POST" && req.Method != "PUT" {
-		return nil
-	}
-
-	// 检查 Content-Type 是否为 JSON
-	contentType := req.Header.Get("Content-Type")
-	if !strings.Contains(strings.ToLower(contentType), "application/json") {
-		return nil
-	}
-
-	// 读取请求体
-	bodyBytes, err := io.ReadAll(req.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read request body: %w", err)
-	}
-	req.Body.Close()
-
-	// 如果请求体为空，直接返回
-	if len(bodyBytes) == 0 {
-		req.Body = http.NoBody
-		return nil
-	}
-
-	// 解析 JSON
-	var jsonData map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &jsonData); err != nil {
-		// 如果不是有效的 JSON，直接返回原始请求体
-		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-		return nil
-	}
-
-	// 清空 DeviceProfile.DirectPlayProfiles 数组
-	if deviceProfile, exists := jsonData["DeviceProfile"]; exists {
-		if deviceProfileMap, ok := deviceProfile.(map[string]interface{}); ok {
-			// 强制声明只支持 AAC
-			deviceProfileMap["SupportedAudioCodecs"] = []interface{}{"aac"}
-
-			// 限制为立体声
-			deviceProfileMap["MaxAudioChannels"] = 2
-			if _, hasDirectPlayProfiles := deviceProfileMap["DirectPlayProfiles"]; hasDirectPlayProfiles {
-				deviceProfileMap["DirectPlayProfiles"] = []interface{}{}
-				deviceProfileMap["DirectStreamProfiles"] = []interface{}{}
-
-				log.Debugf("Cleared DeviceProfile.DirectPlayProfiles in PlaybackInfo request: %s", req.URL.Path)
-			}
-		}
-	}
-
-	// 重新序列化为 JSON
-	modifiedBody, err := json.Marshal(jsonData)
-	if err != nil {
-		// 如果序列化失败，使用原始请求体
-		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-		return fmt.Errorf("failed to marshal modified JSON: %w", err)
-	}
-
-	// 设置修改后的请求体
-	req.Body = io.NopCloser(bytes.NewReader(modifiedBody))
-	// 更新 Content-Length
-	req.ContentLength = int64(len(modifiedBody))
-	req.Header.Set("Content-Length", fmt.Sprintf("%d", len(modifiedBody)))
-
-	return nil
-}
-
 // handleWebSocket 处理 WebSocket 连接
-func (s *Server) handleWebSocket(ctx context.Context, clientConn net.Conn, reader *bufio.Reader, req *http.Request, protoproxy *proto.Proxy) {
+func (s *Server) handleWebSocket(ctx context.Context, clientConn net.Conn, reader *bufio.Reader, req *http.Request, protoproxy *proto.Proxy, alog *accessLog) {
 	log.Infof("handling WebSocket connection for proxy %d", protoproxy.ID)
 
-	// 打开到 edge 的 stream
-	stream, err := s.frontierBound.OpenStream(ctx, protoproxy.EdgeID)
+	// 按路径匹配的应用打开到 edge 的 stream
+	route := s.routeRequest(req, protoproxy)
+	stream, up, err := s.openStream(ctx, protoproxy, route)
 	if err != nil {
 		log.Errorf("failed to open stream for WebSocket: %s", err)
+		writeErrorResponse(clientConn, http.StatusBadGateway, "edge_unavailable", err.Error())
+		alog.finish(routeApplication(route), http.StatusBadGateway, 0, 0)
 		return
 	}
 	defer stream.Close()
+	if tracked := trackedEntry(clientConn); tracked != nil {
+		tracked.SetKind(proto.ConnectionWebSocket)
+		tracked.SetEdge(up.edgeID)
+	}
 
-	// 写入目标地址信息
-	if err := s.writeDstInfo(stream, protoproxy); err != nil {
-		log.Errorf("failed to write dst info for WebSocket: %s", err)
+	// 写入目标地址信息，之后的数据走 conn（可能经过压缩）
+	wire := &wireCounter{Conn: stream}
+	conn, compressed, err := s.writeDstInfo(wire, clientConn, protoproxy, up)
+	if err != nil {
+		log.Warnf("http proxy %d WebSocket handshake with edge %d err: %s", protoproxy.ID, up.edgeID, err)
+		writeDialError(clientConn, err)
+		status, _, _ := dialErrorStatus(err)
+		alog.finish(up.applicationID, status, 0, 0)
 		return
 	}
 
+	if protoproxy.ForwardedHeaders {
+		setForwardedHeaders(req, clientConn)
+	}
+	rewrite := s.newHeaderRewrite(req, clientConn, protoproxy)
+	rewrite.request(req.Header)
+
 	// 构建并发送 HTTP 请求（包含 WebSocket 升级头）
-	if err := s.sendRequest(ctx, stream, req, protoproxy); err != nil {
+	if err := s.sendRequest(ctx, conn, req, up.dst); err != nil {
 		log.Errorf("failed to send WebSocket request: %s", err)
 		return
 	}
 
-	// 读取 WebSocket 升级响应
-	resp, err := s.readResponse(ctx, stream, req)
+	// 读取 WebSocket 升级响应，之后后端的数据从 backendReader 读，其中可能已经缓冲了 WebSocket 帧
+	backendReader := bufio.NewReader(conn)
+	resp, err := s.readResponse(ctx, backendReader, req)
 	if err != nil {
 		log.Errorf("failed to read WebSocket upgrade response: %s", err)
 		return
@@ -726,6 +1088,7 @@ func (s *Server) handleWebSocket(ctx context.Context, clientConn net.Conn, reade
 	}
 
 	// 将升级响应写入客户端
+	rewrite.response(resp.Header)
 	if err := resp.Write(clientConn); err != nil {
 		log.Errorf("failed to write WebSocket upgrade response: %s", err)
 		return
@@ -741,7 +1104,7 @@ func (s *Server) handleWebSocket(ctx context.Context, clientConn net.Conn, reade
 
 	// 从客户端读取，写入 stream（入站流量）
 	go func() {
-		n, err := io.Copy(stream, clientConn)
+		n, err := io.Copy(conn, clientConn)
 		if n > 0 {
 			atomic.AddInt64(&bytesIn, n)
 		}
@@ -753,7 +1116,7 @@ func (s *Server) handleWebSocket(ctx context.Context, clientConn net.Conn, reade
 
 	// 从 stream 读取，写入客户端（出站流量）
 	go func() {
-		n, err := io.Copy(clientConn, stream)
+		n, err := io.Copy(clientConn, backendReader)
 		if n > 0 {
 			atomic.AddInt64(&bytesOut, n)
 		}
@@ -774,27 +1137,14 @@ func (s *Server) handleWebSocket(ctx context.Context, clientConn net.Conn, reade
 	finalBytesOut := atomic.LoadInt64(&bytesOut)
 
 	// 加上WebSocket升级请求和响应的流量
-	upgradeRequestBytes := int64(len(req.Method) + len(req.URL.RequestURI()) + len(req.Proto) + 4)
-	for k, v := range req.Header {
-		upgradeRequestBytes += int64(len(k) + 2)
-		for _, val := range v {
-			upgradeRequestBytes += int64(len(val) + 2)
-		}
-	}
-	upgradeRequestBytes += 2
-
-	upgradeResponseBytes := int64(len(resp.Status) + len(resp.Proto) + 4)
-	for k, v := range resp.Header {
-		upgradeResponseBytes += int64(len(k) + 2)
-		for _, val := range v {
-			upgradeResponseBytes += int64(len(val) + 2)
-		}
-	}
-	upgradeResponseBytes += 2
+	upgradeRequestBytes := requestLineBytes(req) + headerBytes(req.Header)
+	upgradeResponseBytes := statusLineBytes(resp) + headerBytes(resp.Header)
 
 	totalBytesIn := finalBytesIn + upgradeRequestBytes
 	totalBytesOut := finalBytesOut + upgradeResponseBytes
-	s.recordTraffic(uint(protoproxy.ID), protoproxy.ApplicationID, totalBytesIn, totalBytesOut)
+	wireIn, wireOut := wire.bytes(compressed, totalBytesIn, totalBytesOut)
+	s.recordTraffic(uint(protoproxy.ID), up.applicationID, totalBytesIn, totalBytesOut, wireIn, wireOut)
+	alog.finish(up.applicationID, resp.StatusCode, totalBytesIn, totalBytesOut)
 
 	log.Debugf("WebSocket connection closed for proxy %d", protoproxy.ID)
 }
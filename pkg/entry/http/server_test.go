@@ -0,0 +1,126 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestPrepareResponse(t *testing.T) {
+	get, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	head, _ := http.NewRequest(http.MethodHead, "http://example.com/", nil)
+	cases := []struct {
+		name      string
+		resp      *http.Response
+		req       *http.Request
+		keepAlive bool
+		chunked   bool
+	}{
+		{"unknown length", &http.Response{StatusCode: 200, ProtoMajor: 1, ContentLength: -1}, get, true, true},
+		{"from http2", &http.Response{StatusCode: 200, ProtoMajor: 2, ContentLength: -1}, get, true, true},
+		{"known length", &http.Response{StatusCode: 200, ProtoMajor: 1, ProtoMinor: 1, ContentLength: 5}, get, true, false},
+		{"closing", &http.Response{StatusCode: 200, ProtoMajor: 1, ContentLength: -1}, get, false, false},
+		{"head", &http.Response{StatusCode: 200, ProtoMajor: 1, ContentLength: -1}, head, true, false},
+		{"not modified", &http.Response{StatusCode: 304, ProtoMajor: 1, ContentLength: -1}, get, true, false},
+	}
+	for _, c := range cases {
+		prepareResponse(c.resp, c.req, c.keepAlive)
+		chunked := len(c.resp.TransferEncoding) > 0 && c.resp.TransferEncoding[0] == "chunked"
+		if chunked != c.chunked {
+			t.Errorf("%s: chunked = %v, want %v", c.name, chunked, c.chunked)
+		}
+		if c.keepAlive && c.resp.Proto != "HTTP/1.1" {
+			t.Errorf("%s: proto = %q", c.name, c.resp.Proto)
+		}
+	}
+}
+
+// 请求体在读完之前就开始转发，不在内存中缓存
+func TestSendRequestStreamsBody(t *testing.T) {
+	client, backend := net.Pipe()
+	defer client.Close()
+	defer backend.Close()
+	bodyReader, bodyWriter := io.Pipe()
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/upload", bodyReader)
+	req.ContentLength = -1
+
+	s := &Server{}
+	sent := make(chan error, 1)
+	go func() { sent <- s.sendRequest(context.Background(), client, req, "10.0.0.1:8080") }()
+	go bodyWriter.Write([]byte("first"))
+
+	_ = backend.SetReadDeadline(time.Now().Add(5 * time.Second))
+	got, err := http.ReadRequest(bufio.NewReader(backend))
+	if err != nil {
+		t.Fatalf("read request: %v", err)
+	}
+	if got.Host != "10.0.0.1:8080" || got.Header.Get("User-Agent") != "" {
+		t.Errorf("host %q, user agent %q", got.Host, got.Header.Get("User-Agent"))
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(got.Body, buf); err != nil || string(buf) != "first" {
+		t.Fatalf("first chunk = %q, %v", buf, err)
+	}
+	bodyWriter.Close()
+	if rest, _ := io.ReadAll(got.Body); len(rest) != 0 {
+		t.Errorf("unexpected trailing body %q", rest)
+	}
+	if err := <-sent; err != nil {
+		t.Errorf("sendRequest: %v", err)
+	}
+}
+
+func TestDialErrorStatus(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"denied", &proto.DialError{Result: proto.DialResult{Code: proto.DialCodeDenied, Reason: "not allowed"}}, http.StatusForbidden, "denied"},
+		{"edge timeout", &proto.DialError{Result: proto.DialResult{Code: proto.DialCodeTimeout}}, http.StatusGatewayTimeout, "timeout"},
+		{"no dial result", fmt.Errorf("read dial result: %w", os.ErrDeadlineExceeded), http.StatusGatewayTimeout, "timeout"},
+		{"refused", &proto.DialError{Result: proto.DialResult{Code: proto.DialCodeRefused}}, http.StatusBadGateway, "refused"},
+		{"dns", &proto.DialError{Result: proto.DialResult{Code: proto.DialCodeDNSFailure}}, http.StatusBadGateway, "dns_failure"},
+		{"no edge", fmt.Errorf("%w: no online edge", errEdgeUnavailable), http.StatusBadGateway, "edge_unavailable"},
+		{"other", errors.New("stream closed"), http.StatusBadGateway, "failed"},
+	}
+	for _, c := range cases {
+		status, code, _ := dialErrorStatus(c.err)
+		if status != c.status || code != c.code {
+			t.Errorf("%s: got %d %s, want %d %s", c.name, status, code, c.status, c.code)
+		}
+	}
+}
+
+// edge 回传的拒绝原因带到客户端的响应里
+func TestWriteDialError(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		writeDialError(server, &proto.DialError{Result: proto.DialResult{Code: proto.DialCodeDenied, Reason: "10.0.0.1:22 not allowed"}})
+	}()
+
+	_ = client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(client), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Liaison-Error"); got != "denied" {
+		t.Errorf("X-Liaison-Error = %q, want denied", got)
+	}
+}
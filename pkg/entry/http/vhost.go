@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/entry/conntrack"
+	"github.com/liaisonio/liaison/pkg/entry/ratelimit"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/liaisonio/liaison/pkg/proxyproto"
+	"golang.org/x/net/http2"
+)
+
+// vhostHandshakeTimeout 共享监听上完成 TLS 握手、读到第一个请求的超时
+const vhostHandshakeTimeout = 30 * time.Second
+
+// virtualHosts 共享的 HTTP/HTTPS 监听：HTTPS 按 TLS SNI（没有 SNI 时按 Host 头）、
+// HTTP 按 Host 头把连接路由到设置了主机名的代理。一个连接只服务一个主机名
+type virtualHosts struct {
+	httpListener  net.Listener
+	httpsListener net.Listener
+}
+
+// ServeVirtualHosts 启动共享监听，地址为空的一侧不监听。HTTPS 的证书见 getCertificate；
+// 同时监听 HTTPS 时，HTTP 上的请求重定向到 HTTPS。需要在创建代理之前调用
+func (s *Server) ServeVirtualHosts(httpAddr, httpsAddr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.vhost != nil {
+		return errors.New("virtual hosting already started")
+	}
+	vh := &virtualHosts{}
+	if httpsAddr != "" {
+		if len(s.certs) == 0 && s.acme == nil {
+			log.Warnf("virtual hosting on https without static or acme certificates, only hosts with uploaded certificates are served")
+		}
+		ln, err := net.Listen("tcp", httpsAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", httpsAddr, err)
+		}
+		ln = proxyproto.NewListener(ln, s.trustedProxies)
+		vh.httpsListener = tls.NewListener(ln, s.tlsConfig(0))
+	}
+	if httpAddr != "" {
+		ln, err := net.Listen("tcp", httpAddr)
+		if err != nil {
+			vh.close()
+			return fmt.Errorf("failed to listen on %s: %w", httpAddr, err)
+		}
+		vh.httpListener = proxyproto.NewListener(ln, s.trustedProxies)
+	}
+	s.vhost = vh
+	httpsPort := 0
+	if vh.httpsListener != nil {
+		httpsPort = vh.httpsListener.Addr().(*net.TCPAddr).Port
+		go s.serveVirtualHosts(vh.httpsListener, 0)
+		log.Infof("virtual hosting on https %s", httpsAddr)
+	}
+	if vh.httpListener != nil {
+		go s.serveVirtualHosts(vh.httpListener, httpsPort)
+		log.Infof("virtual hosting on http %s", httpAddr)
+	}
+	return nil
+}
+
+func (vh *virtualHosts) close() {
+	if vh.httpListener != nil {
+		vh.httpListener.Close()
+	}
+	if vh.httpsListener != nil {
+		vh.httpsListener.Close()
+	}
+}
+
+// serveVirtualHosts 共享监听的 accept 循环，监听关闭后退出。httpsPort 非 0 时把请求重定向到该端口上的 HTTPS
+func (s *Server) serveVirtualHosts(ln net.Listener, httpsPort int) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Errorf("virtual host accept err: %s", err)
+			continue
+		}
+		go s.handleVirtualHost(conn, httpsPort)
+	}
+}
+
+// handleVirtualHost 确定连接的主机名并交给对应代理处理，准入检查与独立端口一致：先防火墙，再连接数限制
+func (s *Server) handleVirtualHost(raw net.Conn, httpsPort int) {
+	_ = raw.SetDeadline(time.Now().Add(vhostHandshakeTimeout))
+	host := ""
+	tlsConn, isTLS := raw.(*tls.Conn)
+	if isTLS {
+		if err := tlsConn.Handshake(); err != nil {
+			log.Debugf("virtual host tls handshake with %s err: %s", raw.RemoteAddr(), err)
+			_ = raw.Close()
+			return
+		}
+		host = normalizeHost(tlsConn.ConnectionState().ServerName)
+	}
+	conn := &routedConn{Conn: raw, tls: isTLS}
+	if isTLS && tlsConn.ConnectionState().NegotiatedProtocol == http2.NextProtoTLS {
+		// HTTP/2 连接上的请求可能多路复用，只能按 SNI 路由，每个请求的 :authority 必须与 SNI 一致
+		_ = conn.SetDeadline(time.Time{})
+		proxy := s.lookupHost(host)
+		if proxy == nil {
+			log.Debugf("virtual host http2 from %s: no proxy for server name %q", conn.RemoteAddr(), host)
+			_ = conn.Close()
+			return
+		}
+		release, ok := s.admit(conn, proxy, host)
+		if !ok {
+			return
+		}
+		defer release()
+		s.serveHTTP2(proxy.ctx, conn, proxy.protoproxy, host)
+		return
+	}
+	reader := bufio.NewReader(conn)
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		log.Debugf("virtual host read request from %s err: %s", conn.RemoteAddr(), err)
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	if host == "" {
+		host = normalizeHost(req.Host)
+	} else if reqHost := normalizeHost(req.Host); reqHost != host {
+		// SNI 与 Host 不一致，拒绝以免绕过按主机名的路由
+		writeErrorResponse(conn, http.StatusMisdirectedRequest, "misdirected_request",
+			fmt.Sprintf("host %s does not match server name %s", reqHost, host))
+		_ = conn.Close()
+		return
+	}
+
+	if !isTLS && s.serveACMEChallenge(conn, req) {
+		_ = conn.Close()
+		return
+	}
+	if httpsPort > 0 {
+		redirectToHTTPS(conn, req, httpsPort)
+		_ = conn.Close()
+		return
+	}
+
+	proxy := s.lookupHost(host)
+	if proxy == nil {
+		writeErrorResponse(conn, http.StatusNotFound, "unknown_host", fmt.Sprintf("no proxy for host %q", host))
+		_ = conn.Close()
+		return
+	}
+
+	release, ok := s.admit(conn, proxy, host)
+	if !ok {
+		return
+	}
+	defer release()
+	s.serveRequests(proxy.ctx, conn, reader, req, proxy.protoproxy, host)
+}
+
+// admit 对路由到代理的连接做准入检查，先防火墙，再连接数限制；不通过时关闭连接。
+// 通过后按代理限速，返回释放连接名额的函数
+func (s *Server) admit(conn *routedConn, proxy *httpProxy, host string) (func(), bool) {
+	s.mu.RLock()
+	fw := s.firewall
+	s.mu.RUnlock()
+	if fw != nil && !fw.CheckAddr(proxy.id, conn.RemoteAddr()) {
+		log.Infof("firewall: rejected %s for http proxy %d (host %s)", conn.RemoteAddr(), proxy.id, host)
+		_ = conn.Close()
+		return nil, false
+	}
+	ip, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	if !proxy.connLimiter.Acquire(ip) {
+		_ = conn.Close()
+		return nil, false
+	}
+	// 带宽限制在路由之后才能确定代理，作用在 TLS 之上的明文字节
+	conn.limit(proxy.limiter)
+	conn.track(proxy.conns)
+	return func() {
+		_ = conn.Close()
+		proxy.connLimiter.Release(ip)
+	}, true
+}
+
+// lookupHost 返回主机名对应的运行中代理
+func (s *Server) lookupHost(host string) *httpProxy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.hosts[host]
+	if !ok {
+		return nil
+	}
+	return s.proxies[id]
+}
+
+// routedConn 共享监听上的客户端连接：确定代理之前不限速，确定之后按代理的带宽限制读写，
+// 并登记到代理的活跃连接表。limit、track 在同一个 goroutine 中、开始转发之前调用，无需加锁
+type routedConn struct {
+	net.Conn
+	tls     bool
+	limited net.Conn
+	tracked *conntrack.Conn
+}
+
+func (c *routedConn) limit(l *ratelimit.Limiter) {
+	c.limited = ratelimit.WrapConn(c.Conn, l)
+}
+
+func (c *routedConn) track(table *conntrack.Table) {
+	c.tracked = table.TrackConn(c.current(), proto.ConnectionHTTP)
+}
+
+func (c *routedConn) current() net.Conn {
+	if c.tracked != nil {
+		return c.tracked
+	}
+	if c.limited != nil {
+		return c.limited
+	}
+	return c.Conn
+}
+
+func (c *routedConn) Read(b []byte) (int, error) {
+	return c.current().Read(b)
+}
+
+func (c *routedConn) Write(b []byte) (int, error) {
+	return c.current().Write(b)
+}
+
+func (c *routedConn) Close() error {
+	return c.current().Close()
+}
+
+// redirectToHTTPS 共享 HTTP 监听在启用 HTTPS 时把请求重定向到同一主机名的 HTTPS 地址
+func redirectToHTTPS(conn net.Conn, req *http.Request, httpsPort int) {
+	host := stripPort(req.Host)
+	if httpsPort != 443 {
+		host = net.JoinHostPort(host, fmt.Sprint(httpsPort))
+	}
+	target := "https://" + host + req.URL.RequestURI()
+	resp := &http.Response{
+		StatusCode: http.StatusPermanentRedirect,
+		Status:     fmt.Sprintf("%d %s", http.StatusPermanentRedirect, http.StatusText(http.StatusPermanentRedirect)),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+	}
+	resp.Header.Set("Location", target)
+	resp.Header.Set("Connection", "close")
+	_ = resp.Write(conn)
+}
+
+// normalizeHost 主机名统一为小写、去掉端口和末尾的点
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(stripPort(host)), ".")
+}
+
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
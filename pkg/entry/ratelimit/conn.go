@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// Conn 按代理和客户端 IP 限速的客户端连接：Read 消耗上行令牌，Write 消耗下行令牌
+type Conn struct {
+	net.Conn
+	client *Client
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// WrapConn 包装客户端一侧的连接。l 为 nil 时原样返回
+func WrapConn(conn net.Conn, l *Limiter) net.Conn {
+	if l == nil {
+		return conn
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Conn{
+		Conn:   conn,
+		client: l.Client(clientIP(conn.RemoteAddr())),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	// 单次读取不超过桶容量，读到多少扣多少
+	if burst := c.client.limiter.up.Burst(); len(b) > burst {
+		b = b[:burst]
+	}
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		if werr := c.client.waitUpload(c.ctx, n); werr != nil && err == nil {
+			err = net.ErrClosed
+		}
+	}
+	return n, err
+}
+
+func (c *Conn) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		chunk := b[written:]
+		if burst := c.client.limiter.down.Burst(); len(chunk) > burst {
+			chunk = chunk[:burst]
+		}
+		if err := c.client.waitDownload(c.ctx, len(chunk)); err != nil {
+			return written, net.ErrClosed
+		}
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (c *Conn) Close() error {
+	c.closeOnce.Do(func() {
+		c.cancel()
+		c.client.Release()
+	})
+	return c.Conn.Close()
+}
+
+// Listener 对 Accept 出的每个连接套上限速
+type Listener struct {
+	net.Listener
+	limiter *Limiter
+}
+
+// NewListener 包装监听器。监听需要放在 PROXY protocol 解析之后，才能按真实客户端 IP 限速
+func NewListener(ln net.Listener, l *Limiter) net.Listener {
+	return &Listener{Listener: ln, limiter: l}
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return WrapConn(conn, l.limiter), nil
+}
@@ -0,0 +1,166 @@
+// Package ratelimit 为 entry 的代理提供带宽限制。
+//
+// 每个代理一个 Limiter：整个代理共享上行、下行两个令牌桶，另外每个客户端 IP 各有一对令牌桶。
+// 上行指客户端 -> 后端，下行指后端 -> 客户端。Limiter 可以在连接存活期间通过 Update 修改限速，
+// 已经建立的连接立即按新的速率收发。
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+	"golang.org/x/time/rate"
+)
+
+// minBurst 令牌桶容量下限，避免限速很低时单次读写也拿不到足够的令牌
+const minBurst = 16 * 1024
+
+// Limiter 单个代理的带宽限制
+type Limiter struct {
+	mu      sync.Mutex
+	conf    proto.RateLimit
+	up      *rate.Limiter
+	down    *rate.Limiter
+	clients map[string]*clientBuckets // client ip -> buckets
+}
+
+// clientBuckets 单个客户端 IP 的令牌桶，按活跃连接数引用计数
+type clientBuckets struct {
+	up   *rate.Limiter
+	down *rate.Limiter
+	refs int
+}
+
+// NewLimiter 创建限速器，conf 为零值时不限速
+func NewLimiter(conf proto.RateLimit) *Limiter {
+	return &Limiter{
+		conf:    conf,
+		up:      newBucket(conf.UploadBytesPerSec),
+		down:    newBucket(conf.DownloadBytesPerSec),
+		clients: make(map[string]*clientBuckets),
+	}
+}
+
+func newBucket(bytesPerSec int64) *rate.Limiter {
+	l := rate.NewLimiter(rate.Inf, minBurst)
+	setBucket(l, bytesPerSec)
+	return l
+}
+
+func setBucket(l *rate.Limiter, bytesPerSec int64) {
+	if bytesPerSec <= 0 {
+		l.SetLimit(rate.Inf)
+		return
+	}
+	burst := int(bytesPerSec)
+	if burst < minBurst {
+		burst = minBurst
+	}
+	l.SetBurst(burst)
+	l.SetLimit(rate.Limit(bytesPerSec))
+}
+
+// Update 修改限速，对已有连接立即生效
+func (l *Limiter) Update(conf proto.RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.conf = conf
+	setBucket(l.up, conf.UploadBytesPerSec)
+	setBucket(l.down, conf.DownloadBytesPerSec)
+	for _, c := range l.clients {
+		setBucket(c.up, conf.PerClientUploadBytesPerSec)
+		setBucket(c.down, conf.PerClientDownloadBytesPerSec)
+	}
+}
+
+// Client 一个客户端 IP 在代理上的限速句柄，用完需要 Release
+type Client struct {
+	limiter *Limiter
+	ip      string
+	buckets *clientBuckets
+	once    sync.Once
+}
+
+// Client 获取客户端 IP 的限速句柄。同一 IP 的所有连接（UDP 会话）共享每客户端令牌桶
+func (l *Limiter) Client(ip string) *Client {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c, ok := l.clients[ip]
+	if !ok {
+		c = &clientBuckets{
+			up:   newBucket(l.conf.PerClientUploadBytesPerSec),
+			down: newBucket(l.conf.PerClientDownloadBytesPerSec),
+		}
+		l.clients[ip] = c
+	}
+	c.refs++
+	return &Client{limiter: l, ip: ip, buckets: c}
+}
+
+// Release 释放句柄，该 IP 没有活跃连接时回收它的令牌桶
+func (c *Client) Release() {
+	c.once.Do(func() {
+		l := c.limiter
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		c.buckets.refs--
+		if c.buckets.refs <= 0 && l.clients[c.ip] == c.buckets {
+			delete(l.clients, c.ip)
+		}
+	})
+}
+
+// AllowUpload 非阻塞地申请上行令牌，用于 UDP 数据报，返回 false 时应丢弃
+func (c *Client) AllowUpload(n int) bool {
+	now := time.Now()
+	return c.limiter.up.AllowN(now, n) && c.buckets.up.AllowN(now, n)
+}
+
+// AllowDownload 非阻塞地申请下行令牌，用于 UDP 数据报，返回 false 时应丢弃
+func (c *Client) AllowDownload(n int) bool {
+	now := time.Now()
+	return c.limiter.down.AllowN(now, n) && c.buckets.down.AllowN(now, n)
+}
+
+// waitUpload 阻塞直到共享和每客户端上行令牌桶都放行 n 字节
+func (c *Client) waitUpload(ctx context.Context, n int) error {
+	return wait(ctx, n, c.limiter.up, c.buckets.up)
+}
+
+// waitDownload 阻塞直到共享和每客户端下行令牌桶都放行 n 字节
+func (c *Client) waitDownload(ctx context.Context, n int) error {
+	return wait(ctx, n, c.limiter.down, c.buckets.down)
+}
+
+// wait 阻塞直到两个令牌桶都放行 n 字节，按桶容量分块申请
+func wait(ctx context.Context, n int, buckets ...*rate.Limiter) error {
+	for _, b := range buckets {
+		remaining := n
+		for remaining > 0 {
+			chunk := remaining
+			if burst := b.Burst(); chunk > burst {
+				chunk = burst
+			}
+			if err := b.WaitN(ctx, chunk); err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+				// 限速被并发修改导致 chunk 超过新的桶容量，重新分块
+				continue
+			}
+			remaining -= chunk
+		}
+	}
+	return nil
+}
+
+// clientIP 取地址中的 IP 部分，作为每客户端限速的键
+func clientIP(addr net.Addr) string {
+	if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+		return host
+	}
+	return addr.String()
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+func TestClientAllowAndUpdate(t *testing.T) {
+	l := NewLimiter(proto.RateLimit{PerClientUploadBytesPerSec: 1024})
+	a := l.Client("192.0.2.1")
+	defer a.Release()
+
+	// 桶容量下限是 minBurst
+	if !a.AllowUpload(minBurst) {
+		t.Fatal("first burst should be allowed")
+	}
+	if a.AllowUpload(1024) {
+		t.Fatal("bucket should be exhausted")
+	}
+	// 另一个 IP 有自己的桶
+	b := l.Client("192.0.2.2")
+	if !b.AllowUpload(1024) {
+		t.Fatal("other client should not share per-client bucket")
+	}
+	b.Release()
+
+	// 热更新为不限速，已有句柄立即生效
+	l.Update(proto.RateLimit{})
+	if !a.AllowUpload(1 << 20) {
+		t.Fatal("unlimited after update")
+	}
+	if !a.AllowDownload(1 << 20) {
+		t.Fatal("download should be unlimited")
+	}
+}
+
+func TestClientRelease(t *testing.T) {
+	l := NewLimiter(proto.RateLimit{})
+	a := l.Client("192.0.2.1")
+	b := l.Client("192.0.2.1")
+	if a.buckets != b.buckets {
+		t.Fatal("same ip should share buckets")
+	}
+	a.Release()
+	a.Release()
+	if len(l.clients) != 1 {
+		t.Fatalf("buckets released too early: %d", len(l.clients))
+	}
+	b.Release()
+	if len(l.clients) != 0 {
+		t.Fatalf("buckets not released: %d", len(l.clients))
+	}
+}
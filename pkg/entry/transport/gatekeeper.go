@@ -2,10 +2,8 @@ package transport
 
 import (
 	"context"
-	"encoding/binary"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -13,35 +11,70 @@ import (
 
 	"github.com/jumboframes/armorigo/log"
 	"github.com/jumboframes/armorigo/rproxy"
+	"github.com/liaisonio/liaison/pkg/compress"
+	"github.com/liaisonio/liaison/pkg/entry/balancer"
+	"github.com/liaisonio/liaison/pkg/entry/connlimit"
+	"github.com/liaisonio/liaison/pkg/entry/conntrack"
+	"github.com/liaisonio/liaison/pkg/entry/edgeselect"
 	"github.com/liaisonio/liaison/pkg/entry/frontierbound"
+	"github.com/liaisonio/liaison/pkg/entry/ratelimit"
 	"github.com/liaisonio/liaison/pkg/lerrors"
 	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/liaisonio/liaison/pkg/proxyproto"
 )
 
+// dialResultTimeout 等待 edge 返回拨号结果的超时，需覆盖 edge 侧的拨号耗时
+const dialResultTimeout = 30 * time.Second
+
 // firewallChecker mirrors the minimal contract used by the TCP data plane.
 type firewallChecker interface {
 	CheckAddr(proxyID int, addr net.Addr) bool
 }
 
-// Gatekeeper 端口管理器，负责动态管理TCP端口监听
+// firewallListener 在 Accept 时做防火墙检查，不通过的连接直接关闭。放在并发连接限制之前，
+// 被拒绝的来源不占连接名额，也不计入连接限制的拒绝数
+type firewallListener struct {
+	net.Listener
+	allow func(conn net.Conn) bool
+}
+
+func (l *firewallListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.allow(conn) {
+			return conn, nil
+		}
+		_ = conn.Close()
+	}
+}
+
+// Gatekeeper 端口管理器，负责动态管理TCP/UDP端口监听
 type Gatekeeper struct {
 	mu             sync.RWMutex
-	proxies        map[int]*proxy // id -> listener
-	proxiesIdxPort map[int]int    // port -> id
+	proxies        map[int]*proxy    // id -> listener
+	udpProxies     map[int]*udpProxy // id -> udp socket
+	proxiesIdxPort map[int]int       // port -> id
 	// proxy ID -> application ID 映射（用于流量统计）
 	proxyAppMap map[int]uint
 
 	// frontier
 	frontierBound frontierbound.FrontierBound
-	// 防火墙（可选，有则在 postAccept 阶段做 CIDR 检查）
+	// 防火墙（可选，有则在 Accept 阶段做 CIDR 检查）
 	firewall firewallChecker
+	// 可信的前置负载均衡器，来自它们的连接先解析 PROXY protocol 头
+	trustedProxies []*net.IPNet
 	// 流量统计器（可选，如果设置了则统计流量）
 	trafficCollector interface {
-		RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64)
+		RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64)
 	}
 	// 流量统计数据（每分钟上报一次）
 	trafficStats map[string]*trafficStats // key: "proxyID:applicationID"
-	stop         chan struct{}
+	// TCP 会话记录的接收方，nil 表示不记录
+	sessionRecorder proto.SessionRecorder
+	stop            chan struct{}
 }
 
 type trafficStats struct {
@@ -49,11 +82,15 @@ type trafficStats struct {
 	ApplicationID uint
 	BytesIn       int64
 	BytesOut      int64
+	// entry 与 edge 之间实际传输的字节数，开启压缩时小于 BytesIn/BytesOut
+	WireBytesIn  int64
+	WireBytesOut int64
 }
 
 func NewGatekeeper(frontierBound frontierbound.FrontierBound) *Gatekeeper {
 	gk := &Gatekeeper{
 		proxies:        make(map[int]*proxy),
+		udpProxies:     make(map[int]*udpProxy),
 		proxiesIdxPort: make(map[int]int),
 		proxyAppMap:    make(map[int]uint),
 		frontierBound:  frontierBound,
@@ -67,7 +104,7 @@ func NewGatekeeper(frontierBound frontierbound.FrontierBound) *Gatekeeper {
 
 // SetTrafficCollector 设置流量统计器
 func (m *Gatekeeper) SetTrafficCollector(collector interface {
-	RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64)
+	RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64)
 }) {
 	m.trafficCollector = collector
 }
@@ -79,12 +116,22 @@ func (m *Gatekeeper) SetFirewall(fw firewallChecker) {
 	m.firewall = fw
 }
 
+// SetTrustedProxies 设置可信的前置负载均衡器，只影响之后创建的监听
+func (m *Gatekeeper) SetTrustedProxies(trusted []*net.IPNet) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trustedProxies = trusted
+}
+
 func (m *Gatekeeper) CreateProxy(ctx context.Context, protoproxy *proto.Proxy) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// 检查是否已存在该代理
 	_, exists := m.proxies[protoproxy.ID]
+	if !exists {
+		_, exists = m.udpProxies[protoproxy.ID]
+	}
 	if exists {
 		log.Warnf("port %d is already in use", protoproxy.ProxyPort)
 		return nil
@@ -102,6 +149,11 @@ func (m *Gatekeeper) CreateProxy(ctx context.Context, protoproxy *proto.Proxy) e
 		}
 	}
 
+	// UDP 应用走独立的数据报监听
+	if protoproxy.ApplicationType == proto.NetworkUDP {
+		return m.createUDPProxy(protoproxy, requestedPort)
+	}
+
 	// 监听
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", requestedPort))
 	if err != nil {
@@ -116,72 +168,104 @@ func (m *Gatekeeper) CreateProxy(ctx context.Context, protoproxy *proto.Proxy) e
 		// 更新 protoproxy 的端口，以便后续使用
 		protoproxy.ProxyPort = actualPort
 	}
-	// hook 函数
-	postAccept := func(clientAddr net.Addr, _ net.Addr) (custom interface{}, err error) {
-		m.mu.RLock()
-		fw := m.firewall
-		m.mu.RUnlock()
-		if fw != nil && !fw.CheckAddr(protoproxy.ID, clientAddr) {
-			log.Infof("firewall: rejected %s for tcp proxy %d", clientAddr, protoproxy.ID)
-			return nil, fmt.Errorf("source %s not allowed", clientAddr)
-		}
-		pc := &proxyContext{
-			edgeID:        protoproxy.EdgeID,
-			dst:           protoproxy.Dst,
+	// 来自可信负载均衡器的连接，RemoteAddr 换成 PROXY protocol 头中的客户端地址
+	listener = proxyproto.NewListener(listener, m.trustedProxies)
+	// 创建可取消的 context，用于控制 Proxy 方法的退出
+	proxyCtx, cancel := context.WithCancel(context.Background())
+	newProxyContext := func(clientAddr net.Addr, entryAddr net.Addr) *proxyContext {
+		return &proxyContext{
 			applicationID: protoproxy.ApplicationID,
 			proxyID:       uint(protoproxy.ID),
+			clientAddr:    clientAddr.String(),
+			entryAddr:     entryAddr.String(),
+			proxyProtocol: protoproxy.ProxyProtocol,
+			compression:   protoproxy.Compression,
+			start:         time.Now(),
+			ctx:           proxyCtx,
 			gatekeeper:    m,
 		}
+	}
+	// 防火墙：和 HTTP 代理一样先于连接数限制检查
+	listener = &firewallListener{Listener: listener, allow: func(conn net.Conn) bool {
+		m.mu.RLock()
+		fw := m.firewall
+		m.mu.RUnlock()
+		if fw == nil || fw.CheckAddr(protoproxy.ID, conn.RemoteAddr()) {
+			return true
+		}
+		log.Infof("firewall: rejected %s for tcp proxy %d", conn.RemoteAddr(), protoproxy.ID)
+		m.recordSession(newProxyContext(conn.RemoteAddr(), conn.LocalAddr()), proto.SessionFirewallRejected, "")
+		return false
+	}}
+	// 并发连接限制在 postAccept 之前的 Accept 里完成：超限连接直接关闭，
+	// 放行的连接关闭时归还名额（postAccept 拿不到连接本身，无法在关闭时归还）
+	connLimiter := connlimit.NewLimiter(protoproxy.ID, protoproxy.ConnLimit)
+	listener = connlimit.NewListener(listener, connLimiter)
+	// 带宽限制，始终套上以便之后通过 UpdateProxy 热更新
+	limiter := ratelimit.NewLimiter(protoproxy.RateLimit)
+	listener = ratelimit.NewListener(listener, limiter)
+	selector := edgeselect.NewSelector(protoproxy.ID, protoproxy.EdgePolicy, protoproxy.EdgeIDs)
+	targets := balancer.New(protoproxy.TargetList())
+	conns := conntrack.NewTable()
+	// hook 函数
+	postAccept := func(clientAddr net.Addr, entryAddr net.Addr) (custom interface{}, err error) {
+		pc := newProxyContext(clientAddr, entryAddr)
+		pc.dst = targets.Pick()
 		return pc, nil
 	}
 	proxyDial := func(dst net.Addr, custom interface{}) (target net.Conn, err error) {
 		pc := custom.(*proxyContext)
-		stream, err := m.frontierBound.OpenStream(context.TODO(), pc.edgeID)
+		// 按策略选择 edge，打开失败时依次尝试下一个
+		stream, edgeID, err := selector.OpenStream(context.TODO(), m.frontierBound)
 		if err != nil {
+			log.Warnf("tcp proxy %d open stream err: %s", pc.proxyID, err)
+			m.recordSession(pc, proto.SessionEdgeDialFailed, err.Error())
 			return nil, err
 		}
-		// 包装stream连接以统计流量
-		return newCountingConn(stream, pc), nil
-	}
-	preWrite := func(writer io.Writer, custom interface{}) error {
-		pc := custom.(*proxyContext)
-		dst := proto.Dst{
+		pc.edgeID = edgeID
+		// 包装stream连接以统计流量，这一层是压缩后实际传输的字节
+		wire := newCountingConn(stream, pc, countWire)
+		// 写入目标地址并等待 edge 的拨号结果，失败时 rproxy 会直接关闭客户端连接
+		result, err := proto.Handshake(wire, &proto.Dst{
 			Addr:          pc.dst,
 			ApplicationID: pc.applicationID,
 			ProxyID:       pc.proxyID,
-		}
-		data, err := json.Marshal(dst)
+			ClientAddr:    pc.clientAddr,
+			EntryAddr:     pc.entryAddr,
+			ProxyProtocol: pc.proxyProtocol,
+			Compression:   pc.compression,
+			Version:       m.frontierBound.EdgeProtocol(edgeID),
+		}, dialResultTimeout)
 		if err != nil {
-			log.Errorf("failed to marshal dst: %s", err)
-			return err
+			logDialError("tcp", pc, err)
+			m.recordSession(pc, proto.SessionEdgeDialFailed, err.Error())
+			_ = wire.Close()
+			return nil, err
 		}
-		lengthBuf := make([]byte, 4)
-		binary.BigEndian.PutUint32(lengthBuf, uint32(len(data)))
-		_, err = writer.Write(lengthBuf)
-		if err != nil {
-			log.Errorf("failed to write dst length: %s", err)
-			return err
+		// edge 没有确认压缩时两层流量相同，只套一层
+		if result.Compression == "" {
+			wire.layers |= countLogical
+			return newSessionConn(wire, pc, conns), nil
 		}
-		_, err = writer.Write(data)
+		conn, err := compress.Wrap(wire, result.Compression)
 		if err != nil {
-			log.Errorf("failed to write dst: %s", err)
-			return err
+			log.Warnf("tcp proxy %d wrap compression %s err: %s", pc.proxyID, result.Compression, err)
+			m.recordSession(pc, proto.SessionEdgeDialFailed, err.Error())
+			_ = wire.Close()
+			return nil, err
 		}
-		return nil
+		return newSessionConn(newCountingConn(conn, pc, countLogical), pc, conns), nil
 	}
 
 	rp, err := rproxy.NewRProxy(listener,
 		rproxy.OptionRProxyPostAccept(postAccept),
-		rproxy.OptionRProxyDial(proxyDial),
-		rproxy.OptionRProxyPreWrite(preWrite))
+		rproxy.OptionRProxyDial(proxyDial))
 	if err != nil {
 		log.Errorf("failed to create rproxy: %s", err)
+		cancel()
 		return err
 	}
 
-	// 创建可取消的 context，用于控制 Proxy 方法的退出
-	proxyCtx, cancel := context.WithCancel(context.Background())
-
 	// 创建一个 done channel 来跟踪 goroutine 是否退出
 	done := make(chan struct{})
 
@@ -200,11 +284,16 @@ func (m *Gatekeeper) CreateProxy(ctx context.Context, protoproxy *proto.Proxy) e
 	}()
 
 	p := &proxy{
-		port:   actualPort, // 使用实际端口
-		rp:     rp,
-		ctx:    proxyCtx,
-		cancel: cancel,
-		done:   done,
+		port:        actualPort, // 使用实际端口
+		rp:          rp,
+		limiter:     limiter,
+		connLimiter: connLimiter,
+		selector:    selector,
+		targets:     targets,
+		conns:       conns,
+		ctx:         proxyCtx,
+		cancel:      cancel,
+		done:        done,
 	}
 	m.proxies[protoproxy.ID] = p
 	m.proxiesIdxPort[actualPort] = protoproxy.ID
@@ -216,10 +305,100 @@ func (m *Gatekeeper) CreateProxy(ctx context.Context, protoproxy *proto.Proxy) e
 	return nil
 }
 
+// UpdateProxy 热更新运行中代理的带宽、连接数限制、edge 列表和后端健康状态，不在本数据面的代理直接忽略
+func (m *Gatekeeper) UpdateProxy(ctx context.Context, protoproxy *proto.Proxy) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if p, ok := m.proxies[protoproxy.ID]; ok {
+		p.limiter.Update(protoproxy.RateLimit)
+		p.connLimiter.Update(protoproxy.ConnLimit)
+		p.selector.Update(protoproxy.EdgePolicy, protoproxy.EdgeIDs)
+		p.targets.Update(protoproxy.TargetList())
+		log.Infof("tcp proxy %d updated: rate %+v, conn %+v, edges %v (%s)", protoproxy.ID,
+			protoproxy.RateLimit, protoproxy.ConnLimit, protoproxy.EdgeIDs, protoproxy.EdgePolicy)
+		return nil
+	}
+	if up, ok := m.udpProxies[protoproxy.ID]; ok {
+		up.limiter.Update(protoproxy.RateLimit)
+		up.connLimiter.Update(protoproxy.ConnLimit)
+		up.selector.Update(protoproxy.EdgePolicy, protoproxy.EdgeIDs)
+		up.targets.Update(protoproxy.TargetList())
+		log.Infof("udp proxy %d updated: rate %+v, conn %+v, edges %v (%s)", protoproxy.ID,
+			protoproxy.RateLimit, protoproxy.ConnLimit, protoproxy.EdgeIDs, protoproxy.EdgePolicy)
+	}
+	return nil
+}
+
+// GetProxyStats 返回运行中代理的连接和 edge 计数，不在本数据面的代理返回 nil
+func (m *Gatekeeper) GetProxyStats(ctx context.Context, id int) (*proto.ProxyStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if p, ok := m.proxies[id]; ok {
+		stats := p.connLimiter.Stats()
+		stats.Edges = p.selector.Stats()
+		return &stats, nil
+	}
+	if up, ok := m.udpProxies[id]; ok {
+		stats := up.connLimiter.Stats()
+		stats.Edges = up.selector.Stats()
+		return &stats, nil
+	}
+	return nil, nil
+}
+
+// ListConnections 返回运行中 TCP 代理已经连上 edge 的活跃连接，UDP 代理返回每个客户端地址的会话。
+// 不在本数据面的代理返回 nil
+func (m *Gatekeeper) ListConnections(ctx context.Context, id int) ([]proto.ConnectionInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if p, ok := m.proxies[id]; ok {
+		return p.conns.List(), nil
+	}
+	if up, ok := m.udpProxies[id]; ok {
+		return up.conns.List(), nil
+	}
+	return nil, nil
+}
+
+// CloseConnections 断开代理上匹配的连接，TCP 会话记录的结束原因为 disconnected，UDP 会话直接回收
+func (m *Gatekeeper) CloseConnections(ctx context.Context, id int, filter proto.ConnectionFilter) (int, error) {
+	m.mu.RLock()
+	p, ok := m.proxies[id]
+	up, udpOK := m.udpProxies[id]
+	m.mu.RUnlock()
+	switch {
+	case ok:
+		n := p.conns.Close(filter)
+		if n > 0 {
+			log.Infof("tcp proxy %d: disconnected %d connections (%+v)", id, n, filter)
+		}
+		return n, nil
+	case udpOK:
+		n := up.conns.Close(filter)
+		if n > 0 {
+			log.Infof("udp proxy %d: disconnected %d sessions (%+v)", id, n, filter)
+		}
+		return n, nil
+	}
+	return 0, nil
+}
+
 func (m *Gatekeeper) DeleteProxy(ctx context.Context, id int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// UDP 代理
+	if up, ok := m.udpProxies[id]; ok {
+		up.close()
+		delete(m.udpProxies, id)
+		delete(m.proxiesIdxPort, up.port)
+		delete(m.proxyAppMap, id)
+		return nil
+	}
+
 	// 检查端口是否存在
 	p, exists := m.proxies[id]
 	if !exists {
@@ -271,14 +450,28 @@ func (m *Gatekeeper) Close() {
 		// 关闭监听器
 		p.rp.Close()
 	}
+	for _, up := range m.udpProxies {
+		up.close()
+	}
 	m.proxies = make(map[int]*proxy)
+	m.udpProxies = make(map[int]*udpProxy)
 }
 
 type proxyContext struct {
-	edgeID        uint64
+	edgeID        uint64 // 拨号时选中的 edge
 	dst           string
 	applicationID uint
 	proxyID       uint
+	// 客户端地址和它连接的本地地址，随 Dst 传给 edge
+	clientAddr string
+	entryAddr  string
+	// PROXY protocol 版本，为空不发送
+	proxyProtocol string
+	// 请求 edge 使用的压缩算法，为空不压缩
+	compression string
+	// 会话开始的时间和所属代理的 context，代理停止后结束的会话记为 proxy_stopped
+	start time.Time
+	ctx   context.Context
 	// 流量统计
 	bytesIn  int64 // 入站流量（从客户端到服务器）
 	bytesOut int64 // 出站流量（从服务器到客户端）
@@ -289,16 +482,22 @@ type proxyContext struct {
 }
 
 type proxy struct {
-	port   int
-	rp     *rproxy.RProxy
+	port        int
+	rp          *rproxy.RProxy
+	limiter     *ratelimit.Limiter
+	connLimiter *connlimit.Limiter
+	selector    *edgeselect.Selector
+	targets     *balancer.Balancer
+	// 已经连上 edge 的活跃连接
+	conns  *conntrack.Table
 	ctx    context.Context
 	cancel context.CancelFunc
 	done   chan struct{} // 用于跟踪 goroutine 是否退出
 }
 
-// recordTraffic 记录流量（累积到stats中，由定时器每分钟上报一次）
-func (m *Gatekeeper) recordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64) {
-	if bytesIn == 0 && bytesOut == 0 {
+// recordTraffic 记录流量（累积到stats中，由定时器每分钟上报一次），wireBytes* 为压缩后的字节数
+func (m *Gatekeeper) recordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64) {
+	if bytesIn == 0 && bytesOut == 0 && wireBytesIn == 0 && wireBytesOut == 0 {
 		return
 	}
 
@@ -317,6 +516,8 @@ func (m *Gatekeeper) recordTraffic(proxyID, applicationID uint, bytesIn, bytesOu
 
 	stats.BytesIn += bytesIn
 	stats.BytesOut += bytesOut
+	stats.WireBytesIn += wireBytesIn
+	stats.WireBytesOut += wireBytesOut
 }
 
 // reportLoop 每分钟上报一次流量统计
@@ -352,6 +553,8 @@ func (m *Gatekeeper) flushAndReport() {
 			ApplicationID: stats.ApplicationID,
 			BytesIn:       stats.BytesIn,
 			BytesOut:      stats.BytesOut,
+			WireBytesIn:   stats.WireBytesIn,
+			WireBytesOut:  stats.WireBytesOut,
 		})
 	}
 
@@ -362,35 +565,60 @@ func (m *Gatekeeper) flushAndReport() {
 	// 上报（在锁外执行，避免阻塞）
 	if m.trafficCollector != nil {
 		for _, stats := range statsToReport {
-			m.trafficCollector.RecordTraffic(stats.ProxyID, stats.ApplicationID, stats.BytesIn, stats.BytesOut)
+			m.trafficCollector.RecordTraffic(stats.ProxyID, stats.ApplicationID,
+				stats.BytesIn, stats.BytesOut, stats.WireBytesIn, stats.WireBytesOut)
 		}
 		log.Debugf("reported %d traffic metrics", len(statsToReport))
 	}
 }
 
+// countingLayer countingConn 统计哪一层的流量
+type countingLayer int
+
+const (
+	countLogical countingLayer = 1 << iota // 压缩前，即客户端与后端之间的流量
+	countWire                              // 压缩后，即 entry 与 edge 之间实际传输的流量
+)
+
 // countingConn 包装net.Conn以统计流量
 // rproxy内部会进行双向数据复制：
 // 1. 从客户端读取 -> 写入stream（入站流量，通过stream.Write统计）
 // 2. 从stream读取 -> 写入客户端（出站流量，通过stream.Read统计）
+// 开启压缩时 stream 上套两层：内层统计 wire 流量，压缩层之外统计 logical 流量
 type countingConn struct {
 	net.Conn
-	pc *proxyContext
+	pc     *proxyContext
+	layers countingLayer
 }
 
-func newCountingConn(conn net.Conn, pc *proxyContext) *countingConn {
+func newCountingConn(conn net.Conn, pc *proxyContext, layers countingLayer) *countingConn {
 	return &countingConn{
-		Conn: conn,
-		pc:   pc,
+		Conn:   conn,
+		pc:     pc,
+		layers: layers,
+	}
+}
+
+// split 按统计层次拆分 n 字节：返回 (logical, wire)
+func (c *countingConn) split(n int) (int64, int64) {
+	var logical, wire int64
+	if c.layers&countLogical != 0 {
+		logical = int64(n)
+	}
+	if c.layers&countWire != 0 {
+		wire = int64(n)
 	}
+	return logical, wire
 }
 
 func (c *countingConn) Read(b []byte) (n int, err error) {
 	n, err = c.Conn.Read(b)
 	if n > 0 {
 		// 从stream读取，是出站流量（从服务器到客户端）
-		atomic.AddInt64(&c.pc.bytesOut, int64(n))
+		logical, wire := c.split(n)
+		atomic.AddInt64(&c.pc.bytesOut, logical)
 		// 实时累积到gatekeeper的stats中（不等待连接关闭）
-		c.pc.gatekeeper.recordTraffic(c.pc.proxyID, c.pc.applicationID, 0, int64(n))
+		c.pc.gatekeeper.recordTraffic(c.pc.proxyID, c.pc.applicationID, 0, logical, 0, wire)
 	}
 	return n, err
 }
@@ -399,9 +627,10 @@ func (c *countingConn) Write(b []byte) (n int, err error) {
 	n, err = c.Conn.Write(b)
 	if n > 0 {
 		// 向stream写入，是入站流量（从客户端到服务器）
-		atomic.AddInt64(&c.pc.bytesIn, int64(n))
+		logical, wire := c.split(n)
+		atomic.AddInt64(&c.pc.bytesIn, logical)
 		// 实时累积到gatekeeper的stats中（不等待连接关闭）
-		c.pc.gatekeeper.recordTraffic(c.pc.proxyID, c.pc.applicationID, int64(n), 0)
+		c.pc.gatekeeper.recordTraffic(c.pc.proxyID, c.pc.applicationID, logical, 0, wire, 0)
 	}
 	return n, err
 }
@@ -409,3 +638,14 @@ func (c *countingConn) Write(b []byte) (n int, err error) {
 func (c *countingConn) Close() error {
 	return c.Conn.Close()
 }
+
+// logDialError 记录 edge 返回的拨号失败，带上 proxy、edge、目标地址和失败类别，便于在 manager 侧排查
+func logDialError(network string, pc *proxyContext, err error) {
+	var dialErr *proto.DialError
+	if errors.As(err, &dialErr) {
+		log.Warnf("%s proxy %d: edge %d failed to dial %s (%s): %s",
+			network, pc.proxyID, pc.edgeID, pc.dst, dialErr.Result.Code, dialErr.Result.Reason)
+		return
+	}
+	log.Warnf("%s proxy %d: handshake with edge %d for %s err: %s", network, pc.proxyID, pc.edgeID, pc.dst, err)
+}
@@ -0,0 +1,73 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// denyFirewall 拒绝所有来源，检查时记下代理当时占用的连接数
+type denyFirewall struct {
+	gk *Gatekeeper
+
+	mu     sync.Mutex
+	active []int64
+}
+
+func (f *denyFirewall) CheckAddr(proxyID int, addr net.Addr) bool {
+	stats, _ := f.gk.GetProxyStats(context.TODO(), proxyID)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.active = append(f.active, stats.ActiveConnections)
+	return false
+}
+
+func TestFirewallBeforeConnLimit(t *testing.T) {
+	gk := NewGatekeeper(nil)
+	defer gk.Close()
+	recorder := &fakeSessionRecorder{}
+	gk.SetSessionRecorder(recorder)
+	fw := &denyFirewall{gk: gk}
+	gk.SetFirewall(fw)
+	protoproxy := &proto.Proxy{ID: 1, ConnLimit: proto.ConnLimit{MaxConnections: 1}}
+	if err := gk.CreateProxy(context.TODO(), protoproxy); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", protoproxy.ProxyPort))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 被拒绝的连接直接关闭
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("denied connection read err = %v, want EOF", err)
+		}
+		conn.Close()
+	}
+
+	stats, _ := gk.GetProxyStats(context.TODO(), 1)
+	if stats.ActiveConnections != 0 || stats.RejectedByProxyLimit != 0 || stats.RejectedByIPLimit != 0 {
+		t.Errorf("stats after denied connections = %+v", stats)
+	}
+	fw.mu.Lock()
+	for _, active := range fw.active {
+		if active != 0 {
+			t.Errorf("denied connection took a slot before the firewall check: %v", fw.active)
+			break
+		}
+	}
+	fw.mu.Unlock()
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.records) != 3 || recorder.records[0].CloseReason != proto.SessionFirewallRejected {
+		t.Errorf("recorded sessions = %+v", recorder.records)
+	}
+}
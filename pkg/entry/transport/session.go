@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/entry/conntrack"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// SetSessionRecorder 设置 TCP 会话记录的接收方，没有设置时不记录
+func (m *Gatekeeper) SetSessionRecorder(recorder proto.SessionRecorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessionRecorder = recorder
+}
+
+// recordSession 上报一个会话，end 为零值时取当前时间
+func (m *Gatekeeper) recordSession(pc *proxyContext, reason, detail string) {
+	m.mu.RLock()
+	recorder := m.sessionRecorder
+	m.mu.RUnlock()
+	if recorder == nil {
+		return
+	}
+	recorder.RecordSession(&proto.SessionRecord{
+		ProxyID:       pc.proxyID,
+		ApplicationID: pc.applicationID,
+		EdgeID:        pc.edgeID,
+		ClientAddr:    pc.clientAddr,
+		Start:         pc.start,
+		End:           time.Now(),
+		BytesIn:       atomic.LoadInt64(&pc.bytesIn),
+		BytesOut:      atomic.LoadInt64(&pc.bytesOut),
+		CloseReason:   reason,
+		Detail:        detail,
+	})
+}
+
+// sessionConn 交给 rproxy 的、到 edge 的连接，用于判断会话如何结束。
+// rproxy 在任一方向结束时关闭两端：客户端先关闭时，对 stream 的 Close 先于读错误；
+// edge 或后端先关闭时，从 stream 读到错误在先。两个方向都结束（第二次 Close）时上报会话
+type sessionConn struct {
+	net.Conn
+	pc     *proxyContext
+	closes atomic.Int32
+	once   sync.Once
+	// 第一个结束的原因
+	reason atomic.Pointer[string]
+	// 活跃连接表中的登记，nil 表示没有登记
+	tracked *conntrack.Entry
+}
+
+// newSessionConn 包装到 edge 的连接，并登记到代理的活跃连接表。
+// 断开登记的连接时关闭 stream，rproxy 随之关闭客户端连接
+func newSessionConn(conn net.Conn, pc *proxyContext, conns *conntrack.Table) *sessionConn {
+	c := &sessionConn{Conn: conn, pc: pc}
+	if conns != nil {
+		c.tracked = conns.Track(proto.ConnectionTCP, pc.clientAddr, func() (int64, int64) {
+			return atomic.LoadInt64(&pc.bytesIn), atomic.LoadInt64(&pc.bytesOut)
+		}, func() {
+			c.setReason(proto.SessionDisconnected)
+			_ = c.Close()
+		})
+		c.tracked.SetEdge(pc.edgeID)
+	}
+	return c
+}
+
+func (c *sessionConn) setReason(reason string) {
+	c.reason.CompareAndSwap(nil, &reason)
+}
+
+func (c *sessionConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err != nil && c.closes.Load() == 0 {
+		c.setReason(proto.SessionEdgeClosed)
+	}
+	return n, err
+}
+
+func (c *sessionConn) Close() error {
+	if c.pc.ctx != nil && c.pc.ctx.Err() != nil {
+		c.setReason(proto.SessionProxyStopped)
+	}
+	c.setReason(proto.SessionClientClosed)
+	if c.tracked != nil {
+		c.tracked.Untrack()
+	}
+	err := c.Conn.Close()
+	if c.closes.Add(1) == 2 {
+		c.once.Do(func() {
+			c.pc.gatekeeper.recordSession(c.pc, *c.reason.Load(), "")
+		})
+	}
+	return err
+}
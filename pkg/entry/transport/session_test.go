@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/entry/conntrack"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+type fakeSessionRecorder struct {
+	mu      sync.Mutex
+	records []*proto.SessionRecord
+}
+
+func (r *fakeSessionRecorder) RecordSession(record *proto.SessionRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+}
+
+func TestSessionConnCloseReason(t *testing.T) {
+	recorder := &fakeSessionRecorder{}
+	m := &Gatekeeper{sessionRecorder: recorder}
+	conns := conntrack.NewTable()
+	newSession := func() (*sessionConn, net.Conn) {
+		stream, edge := net.Pipe()
+		pc := &proxyContext{proxyID: 1, applicationID: 2, edgeID: 3, clientAddr: "10.0.0.1:5000", start: time.Now(), gatekeeper: m}
+		return newSessionConn(stream, pc, conns), edge
+	}
+
+	// 客户端先关闭：rproxy 先关闭 stream，另一个方向随后读到错误
+	conn, edge := newSession()
+	conn.Close()
+	conn.Read(make([]byte, 1))
+	conn.Close()
+	edge.Close()
+
+	// edge 先关闭：先从 stream 读到 EOF
+	conn, edge = newSession()
+	edge.Close()
+	conn.Read(make([]byte, 1))
+	conn.Close()
+	conn.Close()
+	conn.Close()
+
+	// 通过管理 API 断开：关闭 stream，rproxy 随后关闭两端
+	conn, edge = newSession()
+	if list := conns.List(); len(list) != 1 || list[0].EdgeID != 3 {
+		t.Fatalf("tracked connections = %+v", list)
+	}
+	if n := conns.Close(proto.ConnectionFilter{ClientIP: "10.0.0.1"}); n != 1 {
+		t.Fatalf("disconnected %d connections", n)
+	}
+	conn.Read(make([]byte, 1))
+	conn.Close()
+	conn.Close()
+	edge.Close()
+
+	if len(recorder.records) != 3 {
+		t.Fatalf("recorded %d sessions, want 3", len(recorder.records))
+	}
+	if len(conns.List()) != 0 {
+		t.Error("closed sessions still tracked")
+	}
+	for i, want := range []string{proto.SessionClientClosed, proto.SessionEdgeClosed, proto.SessionDisconnected} {
+		record := recorder.records[i]
+		if record.CloseReason != want || record.ProxyID != 1 || record.EdgeID != 3 || record.ClientAddr != "10.0.0.1:5000" {
+			t.Errorf("session %d = %+v, want reason %s", i, record, want)
+		}
+	}
+}
@@ -2,14 +2,20 @@ package transport
 
 import (
 	"io"
+	"sync/atomic"
 )
 
-// TrafficCounter 流量统计器
+// TrafficCounter 流量统计器，计数用原子操作，可以在读写的同时从其它 goroutine 读取
 type TrafficCounter struct {
 	BytesIn  int64
 	BytesOut int64
 }
 
+// Load 返回当前的入站、出站字节数
+func (c *TrafficCounter) Load() (int64, int64) {
+	return atomic.LoadInt64(&c.BytesIn), atomic.LoadInt64(&c.BytesOut)
+}
+
 // CountingReader 统计读取流量的 Reader
 type CountingReader struct {
 	reader io.Reader
@@ -29,9 +35,9 @@ func (cr *CountingReader) Read(p []byte) (n int, err error) {
 	n, err = cr.reader.Read(p)
 	if n > 0 {
 		if cr.isInbound {
-			cr.counter.BytesIn += int64(n)
+			atomic.AddInt64(&cr.counter.BytesIn, int64(n))
 		} else {
-			cr.counter.BytesOut += int64(n)
+			atomic.AddInt64(&cr.counter.BytesOut, int64(n))
 		}
 	}
 	return n, err
@@ -56,9 +62,9 @@ func (cw *CountingWriter) Write(p []byte) (n int, err error) {
 	n, err = cw.writer.Write(p)
 	if n > 0 {
 		if cw.isInbound {
-			cw.counter.BytesIn += int64(n)
+			atomic.AddInt64(&cw.counter.BytesIn, int64(n))
 		} else {
-			cw.counter.BytesOut += int64(n)
+			atomic.AddInt64(&cw.counter.BytesOut, int64(n))
 		}
 	}
 	return n, err
@@ -0,0 +1,368 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/entry/balancer"
+	"github.com/liaisonio/liaison/pkg/entry/connlimit"
+	"github.com/liaisonio/liaison/pkg/entry/conntrack"
+	"github.com/liaisonio/liaison/pkg/entry/edgeselect"
+	"github.com/liaisonio/liaison/pkg/entry/ratelimit"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+const (
+	// udpSessionIdleTimeout 客户端地址超过该时间没有收发数据，会话即被回收
+	udpSessionIdleTimeout = 60 * time.Second
+	// udpSweepInterval 空闲会话的扫描间隔
+	udpSweepInterval = 10 * time.Second
+	// udpSessionQueueSize 每个会话待发往 edge 的数据报队列长度，满了直接丢弃（UDP 语义）
+	udpSessionQueueSize = 128
+	// udpOpenStreamTimeout 为新会话打开 stream 的超时
+	udpOpenStreamTimeout = 10 * time.Second
+)
+
+// udpProxy UDP 代理：一个监听 socket，按客户端地址把数据报映射到各自的 geminio stream。
+// stream 上的数据报使用 proto.WriteDatagram 的长度前缀格式。
+type udpProxy struct {
+	id         int
+	port       int
+	conn       net.PacketConn
+	protoproxy *proto.Proxy
+	gatekeeper *Gatekeeper
+	limiter    *ratelimit.Limiter
+	// 并发连接限制按会话计数
+	connLimiter *connlimit.Limiter
+	// 每个会话打开 stream 时选择 edge
+	selector *edgeselect.Selector
+	// 每个会话选择一个后端，会话存续期间不变
+	targets *balancer.Balancer
+	// 活跃会话，每个客户端地址一条，断开后客户端再发数据报会建立新会话
+	conns *conntrack.Table
+
+	mu       sync.Mutex
+	sessions map[string]*udpSession // client addr -> session
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// udpSession 一个客户端地址对应的会话
+type udpSession struct {
+	clientAddr net.Addr
+	pc         *proxyContext
+	limit      *ratelimit.Client
+	host       string // 客户端 IP，会话关闭时归还连接名额
+	conns      *connlimit.Limiter
+	in         chan []byte // 客户端 -> edge
+	entry      *conntrack.Entry
+	closeOnce  sync.Once
+	closed     chan struct{}
+
+	mu     sync.Mutex
+	stream net.Conn
+}
+
+// createUDPProxy 创建 UDP 代理，调用方需持有 m.mu
+func (m *Gatekeeper) createUDPProxy(protoproxy *proto.Proxy, requestedPort int) error {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", requestedPort))
+	if err != nil {
+		log.Errorf("failed to listen udp on port %d: %s", requestedPort, err)
+		return err
+	}
+	actualPort := conn.LocalAddr().(*net.UDPAddr).Port
+	if requestedPort == 0 {
+		log.Infof("udp proxy %d: system allocated port %d", protoproxy.ID, actualPort)
+		protoproxy.ProxyPort = actualPort
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	up := &udpProxy{
+		id:          protoproxy.ID,
+		port:        actualPort,
+		conn:        conn,
+		protoproxy:  protoproxy,
+		gatekeeper:  m,
+		limiter:     ratelimit.NewLimiter(protoproxy.RateLimit),
+		connLimiter: connlimit.NewLimiter(protoproxy.ID, protoproxy.ConnLimit),
+		selector:    edgeselect.NewSelector(protoproxy.ID, protoproxy.EdgePolicy, protoproxy.EdgeIDs),
+		targets:     balancer.New(protoproxy.TargetList()),
+		conns:       conntrack.NewTable(),
+		sessions:    make(map[string]*udpSession),
+		ctx:         ctx,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	go up.serve()
+	go up.sweepLoop()
+
+	m.udpProxies[protoproxy.ID] = up
+	m.proxiesIdxPort[actualPort] = protoproxy.ID
+	if protoproxy.ApplicationID > 0 {
+		m.proxyAppMap[protoproxy.ID] = protoproxy.ApplicationID
+	}
+	log.Infof("UDP proxy %d listening on port %d", protoproxy.ID, actualPort)
+	return nil
+}
+
+// serve 读取客户端数据报并分发到会话
+func (up *udpProxy) serve() {
+	defer close(up.done)
+
+	buf := make([]byte, proto.MaxDatagramSize)
+	for {
+		n, addr, err := up.conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-up.ctx.Done():
+				return
+			default:
+			}
+			if isErrClosed(err) {
+				return
+			}
+			log.Errorf("udp proxy %d read err: %s", up.id, err)
+			continue
+		}
+
+		sess := up.getOrCreateSession(addr)
+		if sess == nil {
+			continue
+		}
+		sess.entry.Touch()
+
+		// 超出带宽限制的数据报直接丢弃（UDP 语义）
+		if !sess.limit.AllowUpload(n) {
+			continue
+		}
+		datagram := make([]byte, n)
+		copy(datagram, buf[:n])
+		select {
+		case sess.in <- datagram:
+		default:
+			log.Debugf("udp proxy %d session %s queue full, dropping datagram", up.id, addr)
+		}
+	}
+}
+
+// getOrCreateSession 找到客户端地址对应的会话，不存在则在防火墙检查通过后新建
+func (up *udpProxy) getOrCreateSession(addr net.Addr) *udpSession {
+	key := addr.String()
+	up.mu.Lock()
+	sess, ok := up.sessions[key]
+	up.mu.Unlock()
+	if ok {
+		return sess
+	}
+
+	// 防火墙检查放在 up.mu 之外，避免和持有 m.mu 的 DeleteProxy 互相等待
+	m := up.gatekeeper
+	m.mu.RLock()
+	fw := m.firewall
+	m.mu.RUnlock()
+	if fw != nil && !fw.CheckAddr(up.id, addr) {
+		log.Infof("firewall: rejected %s for udp proxy %d", addr, up.id)
+		return nil
+	}
+
+	host, _, _ := net.SplitHostPort(key)
+	// 会话只在 serve 中创建，占名额和写入会话表之间不会有并发
+	if !up.connLimiter.Acquire(host) {
+		return nil
+	}
+	sess = &udpSession{
+		clientAddr: addr,
+		limit:      up.limiter.Client(host),
+		host:       host,
+		conns:      up.connLimiter,
+		pc: &proxyContext{
+			dst:           up.targets.Pick(),
+			applicationID: up.protoproxy.ApplicationID,
+			proxyID:       uint(up.protoproxy.ID),
+			clientAddr:    addr.String(),
+			entryAddr:     up.conn.LocalAddr().String(),
+			gatekeeper:    m,
+		},
+		in:     make(chan []byte, udpSessionQueueSize),
+		closed: make(chan struct{}),
+	}
+	sess.entry = up.conns.Track(proto.ConnectionUDP, key, func() (int64, int64) {
+		return atomic.LoadInt64(&sess.pc.bytesIn), atomic.LoadInt64(&sess.pc.bytesOut)
+	}, func() { up.removeSession(sess) })
+	up.mu.Lock()
+	up.sessions[key] = sess
+	up.mu.Unlock()
+	go up.runSession(sess)
+	return sess
+}
+
+// runSession 为会话打开 stream，并在两个方向上搬运数据报
+func (up *udpProxy) runSession(sess *udpSession) {
+	defer up.removeSession(sess)
+
+	ctx, cancel := context.WithTimeout(up.ctx, udpOpenStreamTimeout)
+	stream, edgeID, err := up.selector.OpenStream(ctx, up.gatekeeper.frontierBound)
+	cancel()
+	if err != nil {
+		log.Errorf("udp proxy %d open stream for %s err: %s", up.id, sess.clientAddr, err)
+		return
+	}
+	sess.pc.edgeID = edgeID
+	sess.entry.SetEdge(edgeID)
+	// UDP 不压缩，两层流量相同
+	conn := newCountingConn(stream, sess.pc, countLogical|countWire)
+	sess.mu.Lock()
+	sess.stream = conn
+	sess.mu.Unlock()
+	// 会话可能在拨号期间已被回收
+	select {
+	case <-sess.closed:
+		_ = conn.Close()
+		return
+	default:
+	}
+
+	_, err = proto.Handshake(conn, &proto.Dst{
+		Addr:          sess.pc.dst,
+		Network:       proto.NetworkUDP,
+		ApplicationID: sess.pc.applicationID,
+		ProxyID:       sess.pc.proxyID,
+		ClientAddr:    sess.pc.clientAddr,
+		EntryAddr:     sess.pc.entryAddr,
+		Version:       up.gatekeeper.frontierBound.EdgeProtocol(edgeID),
+	}, dialResultTimeout)
+	if err != nil {
+		logDialError("udp", sess.pc, err)
+		return
+	}
+
+	// edge -> 客户端
+	go func() {
+		defer up.removeSession(sess)
+		buf := make([]byte, proto.MaxDatagramSize)
+		for {
+			n, err := proto.ReadDatagram(conn, buf)
+			if err != nil {
+				if !isErrClosed(err) {
+					log.Debugf("udp proxy %d session %s read stream err: %s", up.id, sess.clientAddr, err)
+				}
+				return
+			}
+			sess.entry.Touch()
+			if !sess.limit.AllowDownload(n) {
+				continue
+			}
+			if _, err := up.conn.WriteTo(buf[:n], sess.clientAddr); err != nil {
+				log.Debugf("udp proxy %d write to %s err: %s", up.id, sess.clientAddr, err)
+				return
+			}
+		}
+	}()
+
+	// 客户端 -> edge
+	for {
+		select {
+		case <-sess.closed:
+			return
+		case datagram := <-sess.in:
+			if err := proto.WriteDatagram(conn, datagram); err != nil {
+				if !isErrClosed(err) {
+					log.Debugf("udp proxy %d session %s write stream err: %s", up.id, sess.clientAddr, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// removeSession 关闭会话并从表中删除，可重复调用
+func (up *udpProxy) removeSession(sess *udpSession) {
+	up.mu.Lock()
+	if cur, ok := up.sessions[sess.clientAddr.String()]; ok && cur == sess {
+		delete(up.sessions, sess.clientAddr.String())
+	}
+	up.mu.Unlock()
+	sess.close()
+}
+
+func (sess *udpSession) close() {
+	sess.closeOnce.Do(func() {
+		close(sess.closed)
+		sess.entry.Untrack()
+		sess.limit.Release()
+		sess.conns.Release(sess.host)
+		sess.mu.Lock()
+		if sess.stream != nil {
+			_ = sess.stream.Close()
+		}
+		sess.mu.Unlock()
+	})
+}
+
+// sweepLoop 定期回收空闲会话
+func (up *udpProxy) sweepLoop() {
+	ticker := time.NewTicker(udpSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-up.ctx.Done():
+			return
+		case <-ticker.C:
+			up.sweep(time.Now().Add(-udpSessionIdleTimeout))
+		}
+	}
+}
+
+// sweep 回收 deadline 之后没有收发数据的会话
+func (up *udpProxy) sweep(deadline time.Time) {
+	up.mu.Lock()
+	idle := make([]*udpSession, 0)
+	for _, sess := range up.sessions {
+		if sess.entry.LastActive().Before(deadline) {
+			idle = append(idle, sess)
+		}
+	}
+	up.mu.Unlock()
+	for _, sess := range idle {
+		log.Debugf("udp proxy %d session %s idle, closing", up.id, sess.clientAddr)
+		up.removeSession(sess)
+	}
+}
+
+// close 关闭监听 socket 和所有会话
+func (up *udpProxy) close() {
+	up.cancel()
+	_ = up.conn.Close()
+	select {
+	case <-up.done:
+	case <-time.After(1 * time.Second):
+		log.Warnf("udp proxy %d goroutine did not exit within 1 second", up.id)
+	}
+	up.mu.Lock()
+	sessions := make([]*udpSession, 0, len(up.sessions))
+	for _, sess := range up.sessions {
+		sessions = append(sessions, sess)
+	}
+	up.sessions = make(map[string]*udpSession)
+	up.mu.Unlock()
+	for _, sess := range sessions {
+		sess.close()
+	}
+}
+
+// isErrClosed 检查是否是连接关闭错误
+func isErrClosed(err error) bool {
+	return errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) ||
+		strings.Contains(err.Error(), net.ErrClosed.Error())
+}
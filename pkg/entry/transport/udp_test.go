@@ -0,0 +1,244 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/singchia/geminio"
+)
+
+// echoEdge 模拟 frontier 和 edge：握手后把 stream 上的每个数据报原样写回
+type echoEdge struct {
+	opened atomic.Int64
+}
+
+func (e *echoEdge) OpenStream(ctx context.Context, edgeID uint64) (geminio.Stream, error) {
+	e.opened.Add(1)
+	entrySide, edgeSide := net.Pipe()
+	go e.serve(edgeSide)
+	return &pipeStream{conn: entrySide}, nil
+}
+
+func (e *echoEdge) EdgeProtocol(edgeID uint64) int {
+	return proto.ProtocolVersion
+}
+
+func (e *echoEdge) Close() error {
+	return nil
+}
+
+func (e *echoEdge) serve(conn net.Conn) {
+	defer conn.Close()
+	var dst proto.Dst
+	if err := proto.ReadFrame(conn, &dst); err != nil {
+		return
+	}
+	if err := proto.WriteFrame(conn, &proto.DialResult{Code: proto.DialCodeOK}); err != nil {
+		return
+	}
+	buf := make([]byte, proto.MaxDatagramSize)
+	for {
+		n, err := proto.ReadDatagram(conn, buf)
+		if err != nil {
+			return
+		}
+		if err := proto.WriteDatagram(conn, buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+// pipeStream 只实现 net.Conn 部分的 geminio.Stream
+type pipeStream struct {
+	geminio.Stream
+	conn net.Conn
+}
+
+func (p *pipeStream) Read(b []byte) (int, error)         { return p.conn.Read(b) }
+func (p *pipeStream) Write(b []byte) (int, error)        { return p.conn.Write(b) }
+func (p *pipeStream) Close() error                       { return p.conn.Close() }
+func (p *pipeStream) LocalAddr() net.Addr                { return p.conn.LocalAddr() }
+func (p *pipeStream) RemoteAddr() net.Addr               { return p.conn.RemoteAddr() }
+func (p *pipeStream) SetDeadline(t time.Time) error      { return p.conn.SetDeadline(t) }
+func (p *pipeStream) SetReadDeadline(t time.Time) error  { return p.conn.SetReadDeadline(t) }
+func (p *pipeStream) SetWriteDeadline(t time.Time) error { return p.conn.SetWriteDeadline(t) }
+
+// startUDPProxy 在随机端口上启动经过 edge 的 UDP 代理
+func startUDPProxy(t *testing.T, gk *Gatekeeper) *proto.Proxy {
+	protoproxy := &proto.Proxy{
+		ID:              1,
+		ApplicationType: proto.NetworkUDP,
+		EdgeIDs:         []uint64{7},
+		Dst:             "127.0.0.1:53",
+	}
+	if err := gk.CreateProxy(context.TODO(), protoproxy); err != nil {
+		t.Fatal(err)
+	}
+	return protoproxy
+}
+
+// dialUDP 连接代理端口
+func dialUDP(t *testing.T, protoproxy *proto.Proxy) net.Conn {
+	conn, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", protoproxy.ProxyPort))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// roundTrip 发送一个数据报并等待回包
+func roundTrip(conn net.Conn, payload string) (string, error) {
+	if _, err := conn.Write([]byte(payload)); err != nil {
+		return "", err
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, proto.MaxDatagramSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
+func TestUDPConnections(t *testing.T) {
+	gk := NewGatekeeper(&echoEdge{})
+	defer gk.Close()
+	protoproxy := startUDPProxy(t, gk)
+
+	conn := dialUDP(t, protoproxy)
+	if reply, err := roundTrip(conn, "ping"); err != nil || reply != "ping" {
+		t.Fatalf("reply = %q, err = %v", reply, err)
+	}
+
+	conns, err := gk.ListConnections(context.TODO(), protoproxy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conns) != 1 {
+		t.Fatalf("connections = %+v", conns)
+	}
+	info := conns[0]
+	if info.Kind != proto.ConnectionUDP || info.ClientAddr != conn.LocalAddr().String() || info.EdgeID != 7 {
+		t.Errorf("connection = %+v", info)
+	}
+	if info.BytesIn == 0 || info.BytesOut == 0 || info.LastActive.Before(info.Start) {
+		t.Errorf("connection counters = %+v", info)
+	}
+
+	n, err := gk.CloseConnections(context.TODO(), protoproxy.ID, proto.ConnectionFilter{ID: info.ID})
+	if err != nil || n != 1 {
+		t.Fatalf("closed %d sessions, err = %v", n, err)
+	}
+	if conns, _ := gk.ListConnections(context.TODO(), protoproxy.ID); len(conns) != 0 {
+		t.Errorf("connections after close = %+v", conns)
+	}
+	// 断开后客户端再发数据报会建立新会话
+	if reply, err := roundTrip(conn, "again"); err != nil || reply != "again" {
+		t.Fatalf("reply after close = %q, err = %v", reply, err)
+	}
+	if conns, _ := gk.ListConnections(context.TODO(), protoproxy.ID); len(conns) != 1 || conns[0].ID == info.ID {
+		t.Errorf("connections after new datagram = %+v", conns)
+	}
+}
+
+func TestUDPSessionPerClient(t *testing.T) {
+	edge := &echoEdge{}
+	gk := NewGatekeeper(edge)
+	defer gk.Close()
+	protoproxy := startUDPProxy(t, gk)
+
+	clients := []net.Conn{dialUDP(t, protoproxy), dialUDP(t, protoproxy)}
+	// 每个客户端只收到自己的回包，同一客户端的后续数据报复用会话
+	for round := 0; round < 2; round++ {
+		for i, conn := range clients {
+			payload := fmt.Sprintf("client %d round %d", i, round)
+			if reply, err := roundTrip(conn, payload); err != nil || reply != payload {
+				t.Fatalf("client %d reply = %q, err = %v", i, reply, err)
+			}
+		}
+	}
+	if opened := edge.opened.Load(); opened != 2 {
+		t.Errorf("opened %d streams, want one per client", opened)
+	}
+	conns, _ := gk.ListConnections(context.TODO(), protoproxy.ID)
+	if len(conns) != 2 || conns[0].ClientAddr == conns[1].ClientAddr {
+		t.Errorf("connections = %+v", conns)
+	}
+	stats, _ := gk.GetProxyStats(context.TODO(), protoproxy.ID)
+	if stats.ActiveConnections != 2 {
+		t.Errorf("active sessions = %d, want 2", stats.ActiveConnections)
+	}
+}
+
+func TestUDPIdleSweep(t *testing.T) {
+	edge := &echoEdge{}
+	gk := NewGatekeeper(edge)
+	defer gk.Close()
+	protoproxy := startUDPProxy(t, gk)
+	gk.mu.RLock()
+	up := gk.udpProxies[protoproxy.ID]
+	gk.mu.RUnlock()
+
+	conn := dialUDP(t, protoproxy)
+	if reply, err := roundTrip(conn, "ping"); err != nil || reply != "ping" {
+		t.Fatalf("reply = %q, err = %v", reply, err)
+	}
+	// 最近有过数据的会话保留
+	up.sweep(time.Now().Add(-time.Minute))
+	if conns, _ := gk.ListConnections(context.TODO(), protoproxy.ID); len(conns) != 1 {
+		t.Fatalf("active session swept: %+v", conns)
+	}
+	up.sweep(time.Now().Add(time.Second))
+	if conns, _ := gk.ListConnections(context.TODO(), protoproxy.ID); len(conns) != 0 {
+		t.Fatalf("idle session kept: %+v", conns)
+	}
+	if stats, _ := gk.GetProxyStats(context.TODO(), protoproxy.ID); stats.ActiveConnections != 0 {
+		t.Errorf("idle session still holds a slot: %+v", stats)
+	}
+	// 回收后客户端再发数据报会打开新的 stream
+	if reply, err := roundTrip(conn, "again"); err != nil || reply != "again" {
+		t.Fatalf("reply after sweep = %q, err = %v", reply, err)
+	}
+	if opened := edge.opened.Load(); opened != 2 {
+		t.Errorf("opened %d streams, want 2", opened)
+	}
+}
+
+func TestUDPFirewall(t *testing.T) {
+	edge := &echoEdge{}
+	gk := NewGatekeeper(edge)
+	defer gk.Close()
+	fw := &denyFirewall{gk: gk}
+	gk.SetFirewall(fw)
+	protoproxy := startUDPProxy(t, gk)
+
+	conn := dialUDP(t, protoproxy)
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if n, err := conn.Read(make([]byte, 16)); err == nil {
+		t.Fatalf("denied client got a %d byte reply", n)
+	}
+	fw.mu.Lock()
+	checked := len(fw.active)
+	fw.mu.Unlock()
+	if checked == 0 {
+		t.Fatal("firewall not checked")
+	}
+	if opened := edge.opened.Load(); opened != 0 {
+		t.Errorf("opened %d streams for a denied client", opened)
+	}
+	if conns, _ := gk.ListConnections(context.TODO(), protoproxy.ID); len(conns) != 0 {
+		t.Errorf("connections = %+v", conns)
+	}
+	if stats, _ := gk.GetProxyStats(context.TODO(), protoproxy.ID); stats.ActiveConnections != 0 {
+		t.Errorf("denied client holds a slot: %+v", stats)
+	}
+}
@@ -46,6 +46,43 @@ type Manager struct {
 	WebDir           string        `yaml:"web_dir,omitempty" json:"web_dir"`                       // 前端文件目录，如果为空则不提供前端服务
 	FrontierEdgePort int           `yaml:"frontier_edge_port,omitempty" json:"frontier_edge_port"` // Edge 和 Frontier 之间的通信端口
 	JWTSecret        string        `yaml:"jwt_secret,omitempty" json:"jwt_secret"`                // JWT 密钥（必需，至少32字符）
+	// 可信的前置负载均衡器（CIDR 或 IP）。来自这些地址的 TCP/HTTP 代理连接必须携带
+	// PROXY protocol 头，头部中的客户端地址用于防火墙检查、日志和流量统计
+	TrustedProxies []string `yaml:"trusted_proxies,omitempty" json:"trusted_proxies"`
+	// 共享 80/443 监听上按主机名路由 HTTP 代理
+	VirtualHost VirtualHost `yaml:"virtual_host,omitempty" json:"virtual_host"`
+	// 为虚拟主机名自动签发证书，需要启用 virtual_host
+	ACME ACME `yaml:"acme,omitempty" json:"acme"`
+	// HTTP 代理访问日志和 TCP 会话记录的保留策略
+	AccessLog AccessLog `yaml:"access_log,omitempty" json:"access_log"`
+}
+
+// AccessLog 访问日志和 TCP 会话记录按时间和条数保留（两者分别计数），超出任一限制的旧记录每小时删除一次
+type AccessLog struct {
+	RetentionDays int `yaml:"retention_days,omitempty" json:"retention_days"` // 默认 7 天
+	MaxEntries    int `yaml:"max_entries,omitempty" json:"max_entries"`       // 默认 1000000 条
+}
+
+// ACME 自动证书配置。HTTP-01 验证走 virtual_host 的 HTTP 监听，TLS-ALPN-01 验证走 HTTPS 监听，
+// 所以至少一个监听要能从公网以 80/443 端口访问
+type ACME struct {
+	Enable bool   `yaml:"enable" json:"enable"`
+	Email  string `yaml:"email,omitempty" json:"email"`
+	// ACME 目录地址，默认 Let's Encrypt 生产环境；测试时可以指向本地的 Pebble
+	DirectoryURL string `yaml:"directory_url,omitempty" json:"directory_url"`
+	// 访问 ACME 目录时额外信任的 CA 证书（如 Pebble 的自签 CA）
+	CACert string `yaml:"ca_cert,omitempty" json:"ca_cert"`
+	// 证书和账户密钥的缓存目录，默认 /opt/liaison/data/acme
+	CacheDir string `yaml:"cache_dir,omitempty" json:"cache_dir"`
+}
+
+// VirtualHost 虚拟主机配置。HTTPS 使用 listen.tls.certs 中的证书，按 SNI 选择
+type VirtualHost struct {
+	Enable    bool   `yaml:"enable" json:"enable"`
+	HTTPAddr  string `yaml:"http_addr,omitempty" json:"http_addr"`   // 为空不监听 HTTP
+	HTTPSAddr string `yaml:"https_addr,omitempty" json:"https_addr"` // 为空不监听 HTTPS
+	// 基础域名，代理只填写一级名字（如 jellyfin）时补全为 jellyfin.<base_domain>
+	BaseDomain string `yaml:"base_domain,omitempty" json:"base_domain"`
 }
 
 type Frontier struct {
@@ -111,6 +148,19 @@ func initConf() error {
 	if Conf.Manager.FrontierEdgePort == 0 {
 		Conf.Manager.FrontierEdgePort = 30012
 	}
+	if vh := &Conf.Manager.VirtualHost; vh.Enable && vh.HTTPAddr == "" && vh.HTTPSAddr == "" {
+		vh.HTTPAddr = "0.0.0.0:80"
+		vh.HTTPSAddr = "0.0.0.0:443"
+	}
+	if Conf.Manager.ACME.Enable && Conf.Manager.ACME.CacheDir == "" {
+		Conf.Manager.ACME.CacheDir = "/opt/liaison/data/acme"
+	}
+	if Conf.Manager.AccessLog.RetentionDays == 0 {
+		Conf.Manager.AccessLog.RetentionDays = 7
+	}
+	if Conf.Manager.AccessLog.MaxEntries == 0 {
+		Conf.Manager.AccessLog.MaxEntries = 1000000
+	}
 	return nil
 }
 
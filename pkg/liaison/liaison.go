@@ -5,9 +5,11 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"runtime"
+	"time"
 
 	"github.com/liaisonio/liaison/pkg/entry"
 	"github.com/liaisonio/liaison/pkg/liaison/config"
+	"github.com/liaisonio/liaison/pkg/liaison/manager/accesslog"
 	"github.com/liaisonio/liaison/pkg/liaison/manager/controlplane"
 	"github.com/liaisonio/liaison/pkg/liaison/manager/frontierbound"
 	"github.com/liaisonio/liaison/pkg/liaison/manager/iam"
@@ -19,12 +21,13 @@ import (
 )
 
 type Liaison struct {
-	web              web.Web
-	frontierBound    frontierbound.FrontierBound
-	entry            *entry.Entry
-	repo             repo.Repo
-	iamService       *iam.IAMService
-	trafficCollector *traffic.TrafficCollector
+	web                web.Web
+	frontierBound      frontierbound.FrontierBound
+	entry              *entry.Entry
+	repo               repo.Repo
+	iamService         *iam.IAMService
+	trafficCollector   *traffic.TrafficCollector
+	accessLogCollector *accesslog.Collector
 }
 
 func NewLiaison() (*Liaison, error) {
@@ -54,6 +57,9 @@ func NewLiaison() (*Liaison, error) {
 	}
 	// traffic collector
 	trafficCollector := traffic.NewTrafficCollector(repo)
+	// HTTP 代理访问日志
+	accessLogCollector := accesslog.NewCollector(repo,
+		time.Duration(config.Conf.Manager.AccessLog.RetentionDays)*24*time.Hour, config.Conf.Manager.AccessLog.MaxEntries)
 	// frontier bound
 	frontierBound, err := frontierbound.NewFrontierBound(config.Conf, repo, trafficCollector)
 	if err != nil {
@@ -79,20 +85,23 @@ func NewLiaison() (*Liaison, error) {
 		return nil, err
 	}
 	// entry layer
-	entry, err := entry.NewEntry(config.Conf, controlPlane, trafficCollector)
+	entry, err := entry.NewEntry(config.Conf, controlPlane, trafficCollector, accessLogCollector, accessLogCollector, iamService)
 	if err != nil {
 		return nil, err
 	}
 	// 把持久化的防火墙规则推回数据面 —— entry 此时已经把 proxies 起起来了，
 	// 在这里恢复 CIDR 白名单可以避免重启后的短暂宽松窗口。
 	controlPlane.RestoreFirewallRules()
+	// 上传的证书同样在代理启动后加载，绑定了证书的代理在此之前使用静态证书
+	controlPlane.RestoreCertificates()
 	return &Liaison{
-		web:              web,
-		frontierBound:    frontierBound,
-		entry:            entry,
-		repo:             repo,
-		iamService:       iamService,
-		trafficCollector: trafficCollector,
+		web:                web,
+		frontierBound:      frontierBound,
+		entry:              entry,
+		repo:               repo,
+		iamService:         iamService,
+		trafficCollector:   trafficCollector,
+		accessLogCollector: accessLogCollector,
 	}, nil
 }
 
@@ -116,6 +125,9 @@ func (l *Liaison) Close() error {
 	if l.trafficCollector != nil {
 		l.trafficCollector.Stop()
 	}
+	if l.accessLogCollector != nil {
+		l.accessLogCollector.Stop()
+	}
 	err = l.repo.Close()
 	if err != nil {
 		return err
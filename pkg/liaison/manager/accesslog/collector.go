@@ -0,0 +1,214 @@
+package accesslog
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+const (
+	// 队列满时丢弃新的日志，不阻塞请求处理
+	queueSize = 8192
+	// 攒够一批或者到了间隔就落盘
+	batchSize     = 500
+	flushInterval = 2 * time.Second
+	// 检查保留策略的间隔
+	retentionInterval = time.Hour
+)
+
+// Collector HTTP 代理访问日志和 TCP 会话记录的收集器，异步批量落盘，并按保留策略删除旧记录
+type Collector struct {
+	repo       dao.Dao
+	retention  time.Duration
+	maxEntries int
+
+	queue           chan *model.AccessLog
+	sessions        chan *model.TCPSession
+	dropped         atomic.Int64
+	droppedSessions atomic.Int64
+	stop            chan struct{}
+	done            chan struct{}
+}
+
+// NewCollector 创建收集器，访问日志和会话记录各保留 retention 之内、最多 maxEntries 条，0 表示不限
+func NewCollector(repo dao.Dao, retention time.Duration, maxEntries int) *Collector {
+	c := &Collector{
+		repo:       repo,
+		retention:  retention,
+		maxEntries: maxEntries,
+		queue:      make(chan *model.AccessLog, queueSize),
+		sessions:   make(chan *model.TCPSession, queueSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go c.loop()
+	return c
+}
+
+// RecordAccess 实现 proto.AccessLogger
+func (c *Collector) RecordAccess(entry *proto.AccessLogEntry) {
+	record := &model.AccessLog{
+		ProxyID:       entry.ProxyID,
+		ApplicationID: entry.ApplicationID,
+		Time:          entry.Time,
+		DurationMs:    entry.Duration.Milliseconds(),
+		ClientIP:      entry.ClientIP,
+		User:          entry.User,
+		Method:        entry.Method,
+		Host:          entry.Host,
+		Path:          entry.Path,
+		Proto:         entry.Proto,
+		Status:        entry.Status,
+		BytesIn:       entry.BytesIn,
+		BytesOut:      entry.BytesOut,
+		UserAgent:     entry.UserAgent,
+	}
+	select {
+	case c.queue <- record:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// RecordSession 实现 proto.SessionRecorder
+func (c *Collector) RecordSession(record *proto.SessionRecord) {
+	clientIP := record.ClientAddr
+	if host, _, err := net.SplitHostPort(record.ClientAddr); err == nil {
+		clientIP = host
+	}
+	session := &model.TCPSession{
+		ProxyID:       record.ProxyID,
+		ApplicationID: record.ApplicationID,
+		EdgeID:        record.EdgeID,
+		ClientAddr:    record.ClientAddr,
+		ClientIP:      clientIP,
+		Start:         record.Start,
+		End:           record.End,
+		DurationMs:    record.End.Sub(record.Start).Milliseconds(),
+		BytesIn:       record.BytesIn,
+		BytesOut:      record.BytesOut,
+		CloseReason:   record.CloseReason,
+		Detail:        record.Detail,
+	}
+	select {
+	case c.sessions <- session:
+	default:
+		c.droppedSessions.Add(1)
+	}
+}
+
+func (c *Collector) loop() {
+	defer close(c.done)
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+	retentionTicker := time.NewTicker(retentionInterval)
+	defer retentionTicker.Stop()
+
+	c.applyRetention()
+	batch := make([]*model.AccessLog, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := c.repo.CreateAccessLogs(batch); err != nil {
+			log.Errorf("failed to write %d access logs: %s", len(batch), err)
+		}
+		batch = make([]*model.AccessLog, 0, batchSize)
+		if dropped := c.dropped.Swap(0); dropped > 0 {
+			log.Warnf("access log queue full, dropped %d entries", dropped)
+		}
+	}
+	sessions := make([]*model.TCPSession, 0, batchSize)
+	flushSessions := func() {
+		if len(sessions) == 0 {
+			return
+		}
+		if err := c.repo.CreateTCPSessions(sessions); err != nil {
+			log.Errorf("failed to write %d tcp sessions: %s", len(sessions), err)
+		}
+		sessions = make([]*model.TCPSession, 0, batchSize)
+		if dropped := c.droppedSessions.Swap(0); dropped > 0 {
+			log.Warnf("tcp session queue full, dropped %d records", dropped)
+		}
+	}
+	for {
+		select {
+		case record := <-c.queue:
+			batch = append(batch, record)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case session := <-c.sessions:
+			sessions = append(sessions, session)
+			if len(sessions) >= batchSize {
+				flushSessions()
+			}
+		case <-flushTicker.C:
+			flush()
+			flushSessions()
+		case <-retentionTicker.C:
+			c.applyRetention()
+		case <-c.stop:
+			// 退出前写完队列中的记录
+			for {
+				select {
+				case record := <-c.queue:
+					batch = append(batch, record)
+				case session := <-c.sessions:
+					sessions = append(sessions, session)
+				default:
+					flush()
+					flushSessions()
+					return
+				}
+			}
+		}
+	}
+}
+
+// applyRetention 删除超过保留时间和保留条数的访问日志和会话记录
+func (c *Collector) applyRetention() {
+	if c.retention > 0 {
+		deleted, err := c.repo.DeleteAccessLogsBefore(time.Now().Add(-c.retention))
+		if err != nil {
+			log.Errorf("failed to delete expired access logs: %s", err)
+		} else if deleted > 0 {
+			log.Infof("deleted %d access logs older than %s", deleted, c.retention)
+		}
+	}
+	if c.maxEntries > 0 {
+		deleted, err := c.repo.TrimAccessLogs(c.maxEntries)
+		if err != nil {
+			log.Errorf("failed to trim access logs: %s", err)
+		} else if deleted > 0 {
+			log.Infof("deleted %d access logs beyond the latest %d", deleted, c.maxEntries)
+		}
+	}
+	if c.retention > 0 {
+		deleted, err := c.repo.DeleteTCPSessionsBefore(time.Now().Add(-c.retention))
+		if err != nil {
+			log.Errorf("failed to delete expired tcp sessions: %s", err)
+		} else if deleted > 0 {
+			log.Infof("deleted %d tcp sessions older than %s", deleted, c.retention)
+		}
+	}
+	if c.maxEntries > 0 {
+		deleted, err := c.repo.TrimTCPSessions(c.maxEntries)
+		if err != nil {
+			log.Errorf("failed to trim tcp sessions: %s", err)
+		} else if deleted > 0 {
+			log.Infof("deleted %d tcp sessions beyond the latest %d", deleted, c.maxEntries)
+		}
+	}
+}
+
+// Stop 停止收集器，等待队列中的记录落盘
+func (c *Collector) Stop() {
+	close(c.stop)
+	<-c.done
+}
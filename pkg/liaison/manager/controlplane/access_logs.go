@@ -0,0 +1,193 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+)
+
+const (
+	defaultAccessLogPageSize = 50
+	maxAccessLogPageSize     = 1000
+	// exportAccessLogBatch is how many rows an export reads per query.
+	exportAccessLogBatch = 1000
+)
+
+// AccessLogFilter narrows the access logs of a proxy. Empty fields match
+// everything. Start and End are RFC3339 or local "2006-01-02T15:04:05" times,
+// Status is a status class such as "5xx", PathPrefix matches the request path
+// as sent by the client.
+type AccessLogFilter struct {
+	Start      string
+	End        string
+	Status     string
+	ClientIP   string
+	PathPrefix string
+}
+
+// AccessLogData is one request through an HTTP proxy.
+type AccessLogData struct {
+	ID            uint      `json:"id"`
+	ProxyID       uint      `json:"proxy_id"`
+	ApplicationID uint      `json:"application_id"`
+	Time          time.Time `json:"time"`
+	DurationMs    int64     `json:"duration_ms"`
+	ClientIP      string    `json:"client_ip"`
+	User          string    `json:"user,omitempty"`
+	Method        string    `json:"method"`
+	Host          string    `json:"host"`
+	Path          string    `json:"path"`
+	Proto         string    `json:"proto"`
+	Status        int       `json:"status"`
+	BytesIn       int64     `json:"bytes_in"`
+	BytesOut      int64     `json:"bytes_out"`
+	UserAgent     string    `json:"user_agent,omitempty"`
+}
+
+// AccessLogsData is a page of access logs, newest first.
+type AccessLogsData struct {
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+	Logs     []*AccessLogData `json:"logs"`
+}
+
+// ListProxyAccessLogs returns a page of the access logs of a proxy.
+func (cp *controlPlane) ListProxyAccessLogs(ctx context.Context, proxyID uint, filter *AccessLogFilter, page, pageSize int) (*AccessLogsData, error) {
+	query, err := cp.accessLogsQuery(proxyID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultAccessLogPageSize
+	}
+	if pageSize > maxAccessLogPageSize {
+		pageSize = maxAccessLogPageSize
+	}
+	total, err := cp.repo.CountAccessLogs(query)
+	if err != nil {
+		return nil, err
+	}
+	query.Page, query.PageSize = page, pageSize
+	logs, err := cp.repo.ListAccessLogs(query)
+	if err != nil {
+		return nil, err
+	}
+	data := &AccessLogsData{Total: total, Page: page, PageSize: pageSize, Logs: make([]*AccessLogData, len(logs))}
+	for i, record := range logs {
+		data.Logs[i] = transformAccessLog(record)
+	}
+	return data, nil
+}
+
+// ExportProxyAccessLogs calls fn for every access log of a proxy matching
+// filter, newest first, reading the database in batches. It stops at the
+// first error returned by fn or when ctx is done.
+func (cp *controlPlane) ExportProxyAccessLogs(ctx context.Context, proxyID uint, filter *AccessLogFilter, fn func(*AccessLogData) error) error {
+	query, err := cp.accessLogsQuery(proxyID, filter)
+	if err != nil {
+		return err
+	}
+	query.PageSize = exportAccessLogBatch
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		logs, err := cp.repo.ListAccessLogs(query)
+		if err != nil {
+			return err
+		}
+		for _, record := range logs {
+			if err := fn(transformAccessLog(record)); err != nil {
+				return err
+			}
+		}
+		if len(logs) < exportAccessLogBatch {
+			return nil
+		}
+		query.BeforeID = logs[len(logs)-1].ID
+	}
+}
+
+// accessLogsQuery validates filter and checks that the proxy exists.
+func (cp *controlPlane) accessLogsQuery(proxyID uint, filter *AccessLogFilter) (*dao.ListAccessLogsQuery, error) {
+	if _, err := cp.repo.GetProxyByID(proxyID); err != nil {
+		return nil, err
+	}
+	query := &dao.ListAccessLogsQuery{ProxyID: proxyID}
+	if filter == nil {
+		return query, nil
+	}
+	var err error
+	if query.Start, err = parseFilterTime(filter.Start); err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	if query.End, err = parseFilterTime(filter.End); err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+	if filter.Status != "" {
+		class, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(filter.Status), "xx"))
+		if err != nil || class < 1 || class > 5 {
+			return nil, fmt.Errorf("invalid status class %q, want 1xx to 5xx", filter.Status)
+		}
+		query.StatusClass = class
+	}
+	if filter.ClientIP != "" {
+		ip := net.ParseIP(filter.ClientIP)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid client ip %q", filter.ClientIP)
+		}
+		query.ClientIP = ip.String()
+	}
+	if filter.PathPrefix != "" {
+		if !strings.HasPrefix(filter.PathPrefix, "/") {
+			return nil, fmt.Errorf("path prefix must start with /")
+		}
+		query.PathPrefix = filter.PathPrefix
+	}
+	return query, nil
+}
+
+// parseFilterTime accepts the same time formats as the traffic metrics API.
+func parseFilterTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.ParseInLocation(localTimeFormat, value, time.Local)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func transformAccessLog(record *model.AccessLog) *AccessLogData {
+	return &AccessLogData{
+		ID:            record.ID,
+		ProxyID:       record.ProxyID,
+		ApplicationID: record.ApplicationID,
+		Time:          record.Time,
+		DurationMs:    record.DurationMs,
+		ClientIP:      record.ClientIP,
+		User:          record.User,
+		Method:        record.Method,
+		Host:          record.Host,
+		Path:          record.Path,
+		Proto:         record.Proto,
+		Status:        record.Status,
+		BytesIn:       record.BytesIn,
+		BytesOut:      record.BytesOut,
+		UserAgent:     record.UserAgent,
+	}
+}
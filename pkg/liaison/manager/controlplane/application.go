@@ -2,11 +2,15 @@ package controlplane
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/jumboframes/armorigo/log"
 	v1 "github.com/liaisonio/liaison/api/v1"
 	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
 	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
 )
 
 // getDefaultPortByApplicationType 根据应用类型返回默认端口
@@ -45,12 +49,55 @@ func detectApplicationTypeByPort(port int) string {
 	return "tcp" // 默认返回 tcp
 }
 
+// applicationEdges 校验并去重应用关联的 edge，保持顺序（即 failover 优先级）
+func (cp *controlPlane) applicationEdges(edgeIDs []uint64) (model.UintSlice, error) {
+	if len(edgeIDs) == 0 {
+		return nil, errors.New("at least one edge is required")
+	}
+	edges := make(model.UintSlice, 0, len(edgeIDs))
+	seen := make(map[uint64]bool, len(edgeIDs))
+	for _, edgeID := range edgeIDs {
+		if seen[edgeID] {
+			continue
+		}
+		seen[edgeID] = true
+		if _, err := cp.repo.GetEdge(edgeID); err != nil {
+			return nil, fmt.Errorf("edge %d: %w", edgeID, err)
+		}
+		edges = append(edges, uint(edgeID))
+	}
+	return edges, nil
+}
+
+func validateEdgePolicy(policy string) error {
+	switch policy {
+	case "", proto.EdgePolicyFailover, proto.EdgePolicyRoundRobin:
+		return nil
+	}
+	return fmt.Errorf("invalid edge_policy %q, expect %s or %s", policy, proto.EdgePolicyFailover, proto.EdgePolicyRoundRobin)
+}
+
 func (cp *controlPlane) CreateApplication(_ context.Context, req *v1.CreateApplicationRequest) (*v1.CreateApplicationResponse, error) {
-	// 验证 edge 是否存在
-	_, err := cp.repo.GetEdge(req.EdgeId)
+	// 验证 edge 是否存在，edge_ids 非空时优先于 edge_id
+	edgeIDs := req.EdgeIds
+	if len(edgeIDs) == 0 {
+		edgeIDs = []uint64{req.EdgeId}
+	}
+	edges, err := cp.applicationEdges(edgeIDs)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateEdgePolicy(req.EdgePolicy); err != nil {
+		return nil, err
+	}
+	targets, healthCheck := fromV1Targets(req.Targets), fromV1HealthCheck(req.HealthCheck)
+	if err := validateTargets(targets, healthCheck); err != nil {
+		return nil, err
+	}
+	// 多个后端时 ip/port 取第一个，设备关联和默认端口推断都以它为准
+	if len(targets) > 0 {
+		req.Ip, req.Port = targets[0].IP, int32(targets[0].Port)
+	}
 
 	// 根据应用的 IP 地址查找对应的 Device
 	var deviceID uint
@@ -62,7 +109,7 @@ func (cp *controlPlane) CreateApplication(_ context.Context, req *v1.CreateAppli
 		if req.Ip == "127.0.0.1" || req.Ip == "::1" || req.Ip == "localhost" {
 			// 获取 edge 所在的 device（通过 EdgeDevice 关系表，类型为 Host）
 			hostType := model.EdgeDeviceRelationHost
-			edgeDevices, err := cp.repo.GetEdgeDevicesByEdgeID(req.EdgeId, &hostType)
+			edgeDevices, err := cp.repo.GetEdgeDevicesByEdgeID(uint64(edges[0]), &hostType)
 			if err == nil && len(edgeDevices) > 0 {
 				deviceID = edgeDevices[0].DeviceID
 			}
@@ -106,7 +153,10 @@ func (cp *controlPlane) CreateApplication(_ context.Context, req *v1.CreateAppli
 		IP:              req.Ip,
 		Port:            port,
 		ApplicationType: model.ApplicationType(appType),
-		EdgeIDs:         model.UintSlice{uint(req.EdgeId)},
+		EdgeIDs:         edges,
+		EdgePolicy:      req.EdgePolicy,
+		Targets:         targets,
+		HealthCheck:     healthCheck,
 		DeviceID:        deviceID,
 	}
 	err = cp.repo.CreateApplication(application)
@@ -123,7 +173,7 @@ func (cp *controlPlane) CreateApplication(_ context.Context, req *v1.CreateAppli
 	return &v1.CreateApplicationResponse{
 		Code:    200,
 		Message: "success",
-		Data:    transformApplication(createdApplication),
+		Data:    cp.transformApplication(createdApplication),
 	}, nil
 }
 
@@ -275,7 +325,7 @@ func (cp *controlPlane) ListApplications(_ context.Context, req *v1.ListApplicat
 		Message: "success",
 		Data: &v1.Applications{
 			Total:        int32(count),
-			Applications: transformApplications(applications),
+			Applications: cp.transformApplications(applications),
 		},
 	}, nil
 }
@@ -291,10 +341,63 @@ func (cp *controlPlane) UpdateApplication(_ context.Context, req *v1.UpdateAppli
 	if req.Description != "" {
 		application.Description = req.Description
 	}
+	edgesChanged := false
+	if len(req.EdgeIds) > 0 {
+		edges, err := cp.applicationEdges(req.EdgeIds)
+		if err != nil {
+			return nil, err
+		}
+		application.EdgeIDs = edges
+		edgesChanged = true
+	}
+	if req.EdgePolicy != nil {
+		if err := validateEdgePolicy(*req.EdgePolicy); err != nil {
+			return nil, err
+		}
+		application.EdgePolicy = *req.EdgePolicy
+		edgesChanged = true
+	}
+	targetsChanged := false
+	if len(req.Targets) > 0 || req.HealthCheck != nil {
+		targets, healthCheck := application.Targets, application.HealthCheck
+		if len(req.Targets) > 0 {
+			targets = fromV1Targets(req.Targets)
+		}
+		if req.HealthCheck != nil {
+			healthCheck = fromV1HealthCheck(req.HealthCheck)
+		}
+		if err := validateTargets(targets, healthCheck); err != nil {
+			return nil, err
+		}
+		application.Targets, application.HealthCheck = targets, healthCheck
+		if len(targets) > 0 {
+			application.IP, application.Port = targets[0].IP, targets[0].Port
+		}
+		targetsChanged = true
+	}
 	err = cp.repo.UpdateApplication(application)
 	if err != nil {
 		return nil, err
 	}
+	if targetsChanged {
+		// 之前的检查结果针对旧的后端和检查方式，丢弃后等 edge 按新配置重新上报
+		cp.targetHealth.forget(application.ID)
+	}
+	if edgesChanged || targetsChanged {
+		// 运行中的代理热更新 edge 列表和后端，已建立的连接不受影响
+		proxies, err := cp.repo.ListProxies(&dao.ListProxiesQuery{ApplicationIDs: []uint{application.ID}})
+		if err != nil {
+			return nil, err
+		}
+		for _, proxy := range proxies {
+			if err := cp.updateProxyRuntime(proxy, application); err != nil {
+				log.Errorf("application %d: update proxy=%d failed: %v", application.ID, proxy.ID, err)
+				return nil, err
+			}
+		}
+		// 其他代理按路径路由到本应用的规则也要更新
+		cp.updateRoutingProxies(application.ID)
+	}
 	// 重新获取更新后的 application 以返回完整数据
 	updatedApplication, err := cp.repo.GetApplicationByID(uint(req.Id))
 	if err != nil {
@@ -303,7 +406,7 @@ func (cp *controlPlane) UpdateApplication(_ context.Context, req *v1.UpdateAppli
 	return &v1.UpdateApplicationResponse{
 		Code:    200,
 		Message: "success",
-		Data:    transformApplication(updatedApplication),
+		Data:    cp.transformApplication(updatedApplication),
 	}, nil
 }
 
@@ -318,24 +421,90 @@ func (cp *controlPlane) DeleteApplication(_ context.Context, req *v1.DeleteAppli
 	}, nil
 }
 
-func transformApplications(applications []*model.Application) []*v1.Application {
+// transformTargets lists every target of the application with its current
+// health; an application without targets has its ip:port as the only one.
+func (cp *controlPlane) transformTargets(application *model.Application) []*v1.ApplicationTarget {
+	targets := application.Targets
+	if len(targets) == 0 {
+		targets = model.TargetList{{IP: application.IP, Port: application.Port}}
+	}
+	staleAfter := healthStaleAfter(application.HealthCheck)
+	targetsV1 := make([]*v1.ApplicationTarget, len(targets))
+	for i, t := range targets {
+		health, lastErr := targetHealthUnknown, ""
+		if application.HealthCheck.Type != "" {
+			health, lastErr = cp.targetHealth.health(application.ID, t.Addr(), staleAfter)
+		}
+		targetsV1[i] = &v1.ApplicationTarget{
+			Ip:     t.IP,
+			Port:   int32(t.Port),
+			Weight: int32(t.Weight),
+			Health: health,
+			Error:  lastErr,
+		}
+	}
+	return targetsV1
+}
+
+func fromV1Targets(targets []*v1.ApplicationTarget) model.TargetList {
+	if len(targets) == 0 {
+		return nil
+	}
+	list := make(model.TargetList, len(targets))
+	for i, t := range targets {
+		list[i] = model.Target{IP: t.Ip, Port: int(t.Port), Weight: int(t.Weight)}
+	}
+	return list
+}
+
+func fromV1HealthCheck(hc *v1.ApplicationHealthCheck) model.HealthCheck {
+	if hc == nil {
+		return model.HealthCheck{}
+	}
+	return model.HealthCheck{
+		Type:        hc.Type,
+		Path:        hc.Path,
+		IntervalSec: int(hc.IntervalSec),
+		TimeoutSec:  int(hc.TimeoutSec),
+	}
+}
+
+func (cp *controlPlane) transformApplications(applications []*model.Application) []*v1.Application {
 	applicationsV1 := make([]*v1.Application, len(applications))
 	for i, application := range applications {
-		applicationsV1[i] = transformApplication(application)
+		applicationsV1[i] = cp.transformApplication(application)
 	}
 	return applicationsV1
 }
 
-func transformApplication(application *model.Application) *v1.Application {
-	// 获取第一个 edge_id，因为目前一个应用只关联一个 edge
+func (cp *controlPlane) transformApplication(application *model.Application) *v1.Application {
+	// edge_id 保留为第一个（优先级最高的）edge，兼容只认识单个 edge 的调用方
 	var edgeId uint64
-	if len(application.EdgeIDs) > 0 {
-		edgeId = uint64(application.EdgeIDs[0])
+	edgeIds := make([]uint64, len(application.EdgeIDs))
+	for i, id := range application.EdgeIDs {
+		edgeIds[i] = uint64(id)
+	}
+	if len(edgeIds) > 0 {
+		edgeId = edgeIds[0]
+	}
+	edgePolicy := application.EdgePolicy
+	if edgePolicy == "" {
+		edgePolicy = proto.EdgePolicyFailover
+	}
+	healthCheck := &v1.ApplicationHealthCheck{
+		Type:        application.HealthCheck.Type,
+		Path:        application.HealthCheck.Path,
+		IntervalSec: int32(application.HealthCheck.IntervalSec),
+		TimeoutSec:  int32(application.HealthCheck.TimeoutSec),
 	}
 
 	appV1 := &v1.Application{
 		Id:              uint64(application.ID),
 		EdgeId:          edgeId,
+		EdgeIds:         edgeIds,
+		EdgePolicy:      edgePolicy,
+		Targets:         cp.transformTargets(application),
+		HealthCheck:     healthCheck,
 		Name:            application.Name,
 		Description:     application.Description,
 		Ip:              application.IP,
@@ -0,0 +1,119 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/middleware"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// maxApplicationMiddlewares caps the middlewares enabled on one application.
+const maxApplicationMiddlewares = 16
+
+// MiddlewareInfo describes a middleware plugin available in this build.
+type MiddlewareInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ApplicationMiddlewaresData is the middlewares enabled on an application,
+// run in order on every request proxied to it.
+type ApplicationMiddlewaresData struct {
+	ApplicationID uint                          `json:"application_id"`
+	Middlewares   []model.ApplicationMiddleware `json:"middlewares"`
+}
+
+// ListMiddlewares returns the middleware plugins that can be enabled.
+func (cp *controlPlane) ListMiddlewares(ctx context.Context) []MiddlewareInfo {
+	plugins := middleware.List()
+	list := make([]MiddlewareInfo, len(plugins))
+	for i, plugin := range plugins {
+		list[i] = MiddlewareInfo{Name: plugin.Name, Description: plugin.Description}
+	}
+	return list
+}
+
+// GetApplicationMiddlewares returns the middlewares enabled on an application.
+func (cp *controlPlane) GetApplicationMiddlewares(ctx context.Context, applicationID uint) (*ApplicationMiddlewaresData, error) {
+	application, err := cp.repo.GetApplicationByID(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return transformApplicationMiddlewares(application), nil
+}
+
+// UpdateApplicationMiddlewares replaces the middlewares of an HTTP
+// application. Each config is validated by its plugin; running proxies of the
+// application, and proxies routing paths to it, pick up the change without a
+// restart.
+func (cp *controlPlane) UpdateApplicationMiddlewares(ctx context.Context, applicationID uint, middlewares []model.ApplicationMiddleware) (*ApplicationMiddlewaresData, error) {
+	application, err := cp.repo.GetApplicationByID(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.ApplicationType != model.ApplicationTypeHTTP {
+		return nil, fmt.Errorf("middlewares are only supported on http applications")
+	}
+	normalized, err := validateApplicationMiddlewares(middlewares)
+	if err != nil {
+		return nil, err
+	}
+	if err := cp.repo.UpdateApplicationMiddlewares(applicationID, normalized); err != nil {
+		return nil, err
+	}
+	application.Middlewares = normalized
+	proxies, err := cp.repo.ListProxies(&dao.ListProxiesQuery{ApplicationIDs: []uint{application.ID}})
+	if err != nil {
+		return nil, err
+	}
+	for _, proxy := range proxies {
+		if err := cp.updateProxyRuntime(proxy, application); err != nil {
+			log.Errorf("application middlewares: update proxy=%d failed: %v", proxy.ID, err)
+			return nil, err
+		}
+	}
+	cp.updateRoutingProxies(application.ID)
+	return transformApplicationMiddlewares(application), nil
+}
+
+func validateApplicationMiddlewares(middlewares []model.ApplicationMiddleware) (model.ApplicationMiddlewares, error) {
+	if len(middlewares) > maxApplicationMiddlewares {
+		return nil, fmt.Errorf("too many middlewares, at most %d", maxApplicationMiddlewares)
+	}
+	normalized := make(model.ApplicationMiddlewares, 0, len(middlewares))
+	for i, m := range middlewares {
+		m.Name = strings.TrimSpace(m.Name)
+		if _, err := middleware.New(m.Name, m.Config); err != nil {
+			return nil, fmt.Errorf("middleware %d: %w", i+1, err)
+		}
+		if string(m.Config) == "null" {
+			m.Config = nil
+		}
+		normalized = append(normalized, m)
+	}
+	return normalized, nil
+}
+
+func protoMiddlewares(middlewares model.ApplicationMiddlewares) []proto.Middleware {
+	if len(middlewares) == 0 {
+		return nil
+	}
+	protoMiddlewares := make([]proto.Middleware, len(middlewares))
+	for i, m := range middlewares {
+		protoMiddlewares[i] = proto.Middleware{Name: m.Name, Config: m.Config}
+	}
+	return protoMiddlewares
+}
+
+func transformApplicationMiddlewares(application *model.Application) *ApplicationMiddlewaresData {
+	middlewares := []model.ApplicationMiddleware(application.Middlewares)
+	if middlewares == nil {
+		middlewares = []model.ApplicationMiddleware{}
+	}
+	return &ApplicationMiddlewaresData{ApplicationID: application.ID, Middlewares: middlewares}
+}
@@ -0,0 +1,279 @@
+package controlplane
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/manager/timefmt"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// CertificateParams is the body for uploading or rotating a certificate.
+// Cert and Key are PEM encoded; Cert may contain the full chain.
+type CertificateParams struct {
+	Name string `json:"name"`
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+}
+
+// CertificateData is the API-level representation of a certificate. The
+// private key is never returned.
+type CertificateData struct {
+	ID            uint     `json:"id"`
+	Name          string   `json:"name"`
+	Domains       []string `json:"domains"`
+	NotBefore     string   `json:"not_before"`
+	NotAfter      string   `json:"not_after"`
+	ExpiresInDays int      `json:"expires_in_days"` // negative once expired
+	ProxyIDs      []uint   `json:"proxy_ids"`       // proxies bound to this certificate
+	Loaded        bool     `json:"loaded"`          // whether the data plane currently serves it
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+// ProxyCertificateData is the certificate binding of a proxy.
+type ProxyCertificateData struct {
+	ProxyID       uint `json:"proxy_id"`
+	CertificateID uint `json:"certificate_id"` // 0 means selected by SNI
+}
+
+func (cp *controlPlane) RegisterCertificateManager(certificateManager proto.CertificateManager) {
+	cp.certificateManager = certificateManager
+}
+
+// ListCertificates returns every stored certificate with its expiry and bindings.
+func (cp *controlPlane) ListCertificates(ctx context.Context) ([]*CertificateData, error) {
+	certs, err := cp.repo.ListCertificates()
+	if err != nil {
+		return nil, err
+	}
+	proxies, err := cp.repo.ListProxies(&dao.ListProxiesQuery{Query: dao.Query{Page: -1, PageSize: -1}})
+	if err != nil {
+		return nil, err
+	}
+	bindings := make(map[uint][]uint)
+	for _, proxy := range proxies {
+		if proxy.CertificateID != 0 {
+			bindings[proxy.CertificateID] = append(bindings[proxy.CertificateID], proxy.ID)
+		}
+	}
+	loaded := cp.loadedCertificates()
+	data := make([]*CertificateData, 0, len(certs))
+	for _, cert := range certs {
+		data = append(data, transformCertificate(cert, bindings[cert.ID], loaded))
+	}
+	return data, nil
+}
+
+// GetCertificate returns a single certificate.
+func (cp *controlPlane) GetCertificate(ctx context.Context, id uint) (*CertificateData, error) {
+	cert, err := cp.repo.GetCertificateByID(id)
+	if err != nil {
+		return nil, err
+	}
+	proxyIDs, err := cp.certificateProxyIDs(id)
+	if err != nil {
+		return nil, err
+	}
+	return transformCertificate(cert, proxyIDs, cp.loadedCertificates()), nil
+}
+
+// CreateCertificate validates and stores an uploaded certificate and pushes
+// it to the data plane.
+func (cp *controlPlane) CreateCertificate(ctx context.Context, params *CertificateParams) (*CertificateData, error) {
+	cert := &model.Certificate{Name: params.Name}
+	if err := parseCertificate(cert, params.Cert, params.Key); err != nil {
+		return nil, err
+	}
+	if cert.Name == "" && len(cert.Domains) > 0 {
+		cert.Name = cert.Domains[0]
+	}
+	if err := cp.repo.CreateCertificate(cert); err != nil {
+		return nil, err
+	}
+	cp.pushCertificate(cert)
+	return cp.GetCertificate(ctx, cert.ID)
+}
+
+// UpdateCertificate renames a certificate and, when a new certificate and key
+// are given, rotates it. The data plane swaps it in for new handshakes only,
+// so established connections are kept.
+func (cp *controlPlane) UpdateCertificate(ctx context.Context, id uint, params *CertificateParams) (*CertificateData, error) {
+	cert, err := cp.repo.GetCertificateByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if params.Name != "" {
+		cert.Name = params.Name
+	}
+	rotated := params.Cert != "" || params.Key != ""
+	if rotated {
+		if err := parseCertificate(cert, params.Cert, params.Key); err != nil {
+			return nil, err
+		}
+	}
+	if err := cp.repo.UpdateCertificate(cert); err != nil {
+		return nil, err
+	}
+	if rotated {
+		cp.pushCertificate(cert)
+	}
+	return cp.GetCertificate(ctx, id)
+}
+
+// DeleteCertificate removes a certificate that no proxy is bound to.
+func (cp *controlPlane) DeleteCertificate(ctx context.Context, id uint) error {
+	if _, err := cp.repo.GetCertificateByID(id); err != nil {
+		return err
+	}
+	proxyIDs, err := cp.certificateProxyIDs(id)
+	if err != nil {
+		return err
+	}
+	if len(proxyIDs) > 0 {
+		return fmt.Errorf("certificate is bound to proxies %v", proxyIDs)
+	}
+	if err := cp.repo.DeleteCertificate(id); err != nil {
+		return err
+	}
+	if cp.certificateManager != nil {
+		cp.certificateManager.RemoveCertificate(id)
+	}
+	return nil
+}
+
+// GetProxyCertificate returns the certificate binding of a proxy.
+func (cp *controlPlane) GetProxyCertificate(ctx context.Context, proxyID uint) (*ProxyCertificateData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyCertificateData{ProxyID: proxyID, CertificateID: proxy.CertificateID}, nil
+}
+
+// BindProxyCertificate binds a certificate to an HTTP proxy; certificateID 0
+// removes the binding. Running proxies pick it up without a restart.
+func (cp *controlPlane) BindProxyCertificate(ctx context.Context, proxyID, certificateID uint) (*ProxyCertificateData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.ApplicationType != model.ApplicationTypeHTTP {
+		return nil, fmt.Errorf("certificates can only be bound to http proxies")
+	}
+	if certificateID != 0 {
+		if _, err := cp.repo.GetCertificateByID(certificateID); err != nil {
+			return nil, fmt.Errorf("certificate %d not found: %w", certificateID, err)
+		}
+	}
+	if err := cp.repo.UpdateProxyCertificate(proxyID, certificateID); err != nil {
+		return nil, err
+	}
+	proxy.CertificateID = certificateID
+	if err := cp.updateProxyRuntime(proxy, application); err != nil {
+		log.Errorf("certificate: update proxy=%d failed: %v", proxyID, err)
+		return nil, err
+	}
+	return &ProxyCertificateData{ProxyID: proxyID, CertificateID: certificateID}, nil
+}
+
+// RestoreCertificates loads every stored certificate into the data plane on
+// process startup.
+func (cp *controlPlane) RestoreCertificates() {
+	if cp.certificateManager == nil || cp.repo == nil {
+		return
+	}
+	certs, err := cp.repo.ListCertificates()
+	if err != nil {
+		log.Warnf("certificate: list all failed: %v", err)
+		return
+	}
+	for _, cert := range certs {
+		cp.pushCertificate(cert)
+	}
+	log.Infof("certificate: restored %d certificate(s) from DB", len(certs))
+}
+
+func (cp *controlPlane) pushCertificate(cert *model.Certificate) {
+	if cp.certificateManager == nil {
+		return
+	}
+	if err := cp.certificateManager.SetCertificate(cert.ID, []byte(cert.CertPEM), []byte(cert.KeyPEM)); err != nil {
+		log.Warnf("certificate: load %d failed: %v", cert.ID, err)
+	}
+}
+
+func (cp *controlPlane) loadedCertificates() map[uint]time.Time {
+	if cp.certificateManager == nil {
+		return nil
+	}
+	return cp.certificateManager.LoadedCertificates()
+}
+
+func (cp *controlPlane) certificateProxyIDs(certificateID uint) ([]uint, error) {
+	proxies, err := cp.repo.ListProxies(&dao.ListProxiesQuery{
+		Query:         dao.Query{Page: -1, PageSize: -1},
+		CertificateID: certificateID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(proxies))
+	for _, proxy := range proxies {
+		ids = append(ids, proxy.ID)
+	}
+	return ids, nil
+}
+
+// parseCertificate checks that the key matches the certificate and fills in
+// the fields derived from the leaf certificate.
+func parseCertificate(cert *model.Certificate, certPEM, keyPEM string) error {
+	pair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return fmt.Errorf("invalid certificate or key: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %w", err)
+	}
+	domains := leaf.DNSNames
+	if len(domains) == 0 && leaf.Subject.CommonName != "" {
+		domains = []string{leaf.Subject.CommonName}
+	}
+	cert.CertPEM = certPEM
+	cert.KeyPEM = keyPEM
+	cert.Domains = model.StringSlice(domains)
+	cert.NotBefore = leaf.NotBefore
+	cert.NotAfter = leaf.NotAfter
+	return nil
+}
+
+func transformCertificate(cert *model.Certificate, proxyIDs []uint, loaded map[uint]time.Time) *CertificateData {
+	if proxyIDs == nil {
+		proxyIDs = []uint{}
+	}
+	_, isLoaded := loaded[cert.ID]
+	return &CertificateData{
+		ID:            cert.ID,
+		Name:          cert.Name,
+		Domains:       []string(cert.Domains),
+		NotBefore:     timefmt.FormatDateTime(cert.NotBefore),
+		NotAfter:      timefmt.FormatDateTime(cert.NotAfter),
+		ExpiresInDays: int(math.Floor(time.Until(cert.NotAfter).Hours() / 24)),
+		ProxyIDs:      proxyIDs,
+		Loaded:        isLoaded,
+		CreatedAt:     timefmt.FormatDateTime(cert.CreatedAt),
+		UpdatedAt:     timefmt.FormatDateTime(cert.UpdatedAt),
+	}
+}
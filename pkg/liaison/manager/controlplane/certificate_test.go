@@ -0,0 +1,65 @@
+package controlplane
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+)
+
+func selfSigned(t *testing.T, notAfter time.Time, names ...string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "liaison test"},
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestParseCertificate(t *testing.T) {
+	notAfter := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	certPEM, keyPEM := selfSigned(t, notAfter, "a.example.com", "b.example.com")
+
+	var cert model.Certificate
+	if err := parseCertificate(&cert, certPEM, keyPEM); err != nil {
+		t.Fatal(err)
+	}
+	if len(cert.Domains) != 2 || cert.Domains[0] != "a.example.com" {
+		t.Errorf("domains = %v", cert.Domains)
+	}
+	if !cert.NotAfter.Equal(notAfter) {
+		t.Errorf("not_after = %v, want %v", cert.NotAfter, notAfter)
+	}
+	if days := transformCertificate(&cert, nil, nil).ExpiresInDays; days != 29 {
+		t.Errorf("expires_in_days = %d, want 29", days)
+	}
+
+	// key does not belong to the certificate
+	_, otherKey := selfSigned(t, notAfter, "a.example.com")
+	if err := parseCertificate(&cert, certPEM, otherKey); err == nil {
+		t.Error("mismatched key accepted")
+	}
+}
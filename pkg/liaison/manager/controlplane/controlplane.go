@@ -7,6 +7,7 @@ import (
 	"github.com/liaisonio/liaison/pkg/liaison/config"
 	"github.com/liaisonio/liaison/pkg/liaison/manager/frontierbound"
 	"github.com/liaisonio/liaison/pkg/liaison/repo"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
 	"github.com/liaisonio/liaison/pkg/proto"
 )
 
@@ -41,14 +42,72 @@ type ControlPlane interface {
 	GetProxyFirewall(ctx context.Context, proxyID uint) (*FirewallData, error)
 	UpsertProxyFirewall(ctx context.Context, proxyID uint, cidrs []string) (*FirewallData, error)
 	DeleteProxyFirewall(ctx context.Context, proxyID uint) error
+	// EdgeProtocolVersion returns the stream protocol version an edge reported
+	// when it came online, so the entry can handshake with older edges.
+	EdgeProtocolVersion(edgeID uint64) int
+
+	// Proxy options
+	GetProxyOptions(ctx context.Context, proxyID uint) (*ProxyOptionsData, error)
+	UpdateProxyOptions(ctx context.Context, proxyID uint, options model.ProxyOptions) (*ProxyOptionsData, error)
+	GetProxyStats(ctx context.Context, proxyID uint) (*ProxyStatsData, error)
+
+	// Certificates
+	ListCertificates(ctx context.Context) ([]*CertificateData, error)
+	GetCertificate(ctx context.Context, id uint) (*CertificateData, error)
+	CreateCertificate(ctx context.Context, params *CertificateParams) (*CertificateData, error)
+	UpdateCertificate(ctx context.Context, id uint, params *CertificateParams) (*CertificateData, error)
+	DeleteCertificate(ctx context.Context, id uint) error
+	GetProxyCertificate(ctx context.Context, proxyID uint) (*ProxyCertificateData, error)
+	BindProxyCertificate(ctx context.Context, proxyID, certificateID uint) (*ProxyCertificateData, error)
+
+	// Proxy login protection
+	GetProxyAuth(ctx context.Context, proxyID uint) (*ProxyAuthData, error)
+	UpdateProxyAuth(ctx context.Context, proxyID uint, params *ProxyAuthParams) (*ProxyAuthData, error)
+
+	// Proxy header rewrite rules
+	GetProxyHeaderRules(ctx context.Context, proxyID uint) (*ProxyHeaderRulesData, error)
+	UpdateProxyHeaderRules(ctx context.Context, proxyID uint, rules []model.HeaderRule) (*ProxyHeaderRulesData, error)
+
+	// Proxy path routes
+	GetProxyRoutes(ctx context.Context, proxyID uint) (*ProxyRoutesData, error)
+	UpdateProxyRoutes(ctx context.Context, proxyID uint, routes []model.ProxyRoute) (*ProxyRoutesData, error)
+
+	// Application HTTP middlewares
+	ListMiddlewares(ctx context.Context) []MiddlewareInfo
+	GetApplicationMiddlewares(ctx context.Context, applicationID uint) (*ApplicationMiddlewaresData, error)
+	UpdateApplicationMiddlewares(ctx context.Context, applicationID uint, middlewares []model.ApplicationMiddleware) (*ApplicationMiddlewaresData, error)
+
+	// HTTP proxy access logs
+	ListProxyAccessLogs(ctx context.Context, proxyID uint, filter *AccessLogFilter, page, pageSize int) (*AccessLogsData, error)
+	ExportProxyAccessLogs(ctx context.Context, proxyID uint, filter *AccessLogFilter, fn func(*AccessLogData) error) error
+
+	// TCP proxy session records
+	ListProxySessions(ctx context.Context, proxyID uint, filter *SessionFilter, page, pageSize int) (*SessionsData, error)
+	ListApplicationSessions(ctx context.Context, applicationID uint, filter *SessionFilter, page, pageSize int) (*SessionsData, error)
+
+	// Live connections of a proxy
+	ListProxyConnections(ctx context.Context, proxyID uint) (*ProxyConnectionsData, error)
+	CloseProxyConnections(ctx context.Context, proxyID uint, connectionID uint64, clientIP string) (*CloseConnectionsData, error)
+
+	// HTTP proxy request inspector
+	ListProxyInspectedRequests(ctx context.Context, proxyID uint) (*InspectedRequestsData, error)
+	GetProxyInspectedRequest(ctx context.Context, proxyID uint, requestID uint64) (*proto.InspectedRequest, error)
+	ReplayProxyInspectedRequest(ctx context.Context, proxyID uint, requestID uint64) (*proto.InspectedRequest, error)
+
+	// ListProxyRuntimes returns the data-plane view of every proxy, used by
+	// the entry to start its listeners on boot.
+	ListProxyRuntimes(ctx context.Context) ([]*proto.Proxy, error)
 
 	RegisterProxyManager(proxyManager proto.ProxyManager)
 	RegisterFirewallManager(firewallManager proto.FirewallManager)
+	RegisterCertificateManager(certificateManager proto.CertificateManager)
 
 	// RestoreFirewallRules rehydrates the data-plane firewall allowlist from
 	// persisted rules. Call after the entry layer has finished starting
 	// its proxies.
 	RestoreFirewallRules()
+	// RestoreCertificates loads the stored certificates into the data plane.
+	RestoreCertificates()
 }
 
 func NewControlPlane(conf *config.Configuration, repo repo.Repo, frontierBound frontierbound.FrontierBound) (ControlPlane, error) {
@@ -56,6 +115,10 @@ func NewControlPlane(conf *config.Configuration, repo repo.Repo, frontierBound f
 		conf:          conf,
 		repo:          repo,
 		frontierBound: frontierBound,
+		targetHealth:  newTargetHealthStore(),
+	}
+	if frontierBound != nil {
+		frontierBound.RegisterTargetHealthHandler(cp)
 	}
 
 	// 初始化任务检查
@@ -68,8 +131,11 @@ type controlPlane struct {
 	conf          *config.Configuration
 	repo          repo.Repo
 	frontierBound frontierbound.FrontierBound
+	// latest health check results reported by edges, in memory only
+	targetHealth *targetHealthStore
 
 	// deps
-	proxyManager    proto.ProxyManager
-	firewallManager proto.FirewallManager
+	proxyManager       proto.ProxyManager
+	firewallManager    proto.FirewallManager
+	certificateManager proto.CertificateManager
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/liaisonio/liaison/pkg/liaison/manager/frontierbound"
 	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
 	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
 )
 
 func (cp *controlPlane) CreateEdge(_ context.Context, req *v1.CreateEdgeRequest) (*v1.CreateEdgeResponse, error) {
@@ -557,3 +558,10 @@ func generateSecretKey() string {
 func generateAccessKeyPair() (accessKey, secretKey string) {
 	return generateAccessKey(), generateSecretKey()
 }
+
+func (cp *controlPlane) EdgeProtocolVersion(edgeID uint64) int {
+	if cp.frontierBound == nil {
+		return proto.ProtocolLegacy
+	}
+	return cp.frontierBound.EdgeProtocolVersion(edgeID)
+}
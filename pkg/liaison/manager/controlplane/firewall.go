@@ -67,7 +67,7 @@ func (cp *controlPlane) UpsertProxyFirewall(ctx context.Context, proxyID uint, c
 
 	// Push to the data plane. Best-effort: if the proxy isn't running yet the
 	// rule will be re-applied when startProxyRuntime is next invoked.
-	if cp.firewallManager != nil && proxy.Port > 0 {
+	if cp.firewallManager != nil && proxy.Reachable() {
 		if err := cp.firewallManager.Allow(int(proxyID), cidrs); err != nil {
 			log.Warnf("firewall: Allow proxy=%d port=%d failed: %v", proxyID, proxy.Port, err)
 		}
@@ -90,7 +90,7 @@ func (cp *controlPlane) DeleteProxyFirewall(ctx context.Context, proxyID uint) e
 		return err
 	}
 
-	if cp.firewallManager != nil && proxy.Port > 0 {
+	if cp.firewallManager != nil && proxy.Reachable() {
 		cp.firewallManager.Revoke(int(proxyID))
 	}
 	return nil
@@ -0,0 +1,227 @@
+package controlplane
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 3 * time.Second
+	// a report older than this many intervals is ignored, e.g. when the
+	// edge that sent it went offline
+	healthReportStaleIntervals = 3
+)
+
+// Target health as shown in the API.
+const (
+	targetHealthUnknown   = "unknown"
+	targetHealthHealthy   = "healthy"
+	targetHealthUnhealthy = "unhealthy"
+)
+
+// targetHealthStore keeps the latest health check results reported by each
+// edge. A target is healthy if any edge that can reach it says so, unhealthy
+// if every fresh report says otherwise, and unknown without fresh reports.
+// Unknown targets are treated as healthy by the data plane.
+type targetHealthStore struct {
+	mu      sync.RWMutex
+	reports map[uint]map[string]map[uint64]healthReport // application -> target -> edge -> report
+}
+
+type healthReport struct {
+	healthy bool
+	err     string
+	at      time.Time
+}
+
+func newTargetHealthStore() *targetHealthStore {
+	return &targetHealthStore{reports: make(map[uint]map[string]map[uint64]healthReport)}
+}
+
+// record stores the results of one edge and returns the targets whose health
+// changed because of it.
+func (s *targetHealthStore) record(edgeID uint64, applicationID uint, results []proto.TargetHealth, staleAfter time.Duration) []string {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	targets, ok := s.reports[applicationID]
+	if !ok {
+		targets = make(map[string]map[uint64]healthReport)
+		s.reports[applicationID] = targets
+	}
+	changed := make([]string, 0)
+	for _, r := range results {
+		edges, ok := targets[r.Addr]
+		if !ok {
+			edges = make(map[uint64]healthReport)
+			targets[r.Addr] = edges
+		}
+		before, _ := evaluate(edges, now, staleAfter)
+		edges[edgeID] = healthReport{healthy: r.Healthy, err: r.Error, at: now}
+		if after, _ := evaluate(edges, now, staleAfter); after != before {
+			changed = append(changed, r.Addr)
+		}
+	}
+	return changed
+}
+
+// health returns the health of a target and the last error reported for it.
+func (s *targetHealthStore) health(applicationID uint, addr string, staleAfter time.Duration) (string, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return evaluate(s.reports[applicationID][addr], time.Now(), staleAfter)
+}
+
+// forget drops the results of an application, e.g. after its targets or
+// health check changed.
+func (s *targetHealthStore) forget(applicationID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.reports, applicationID)
+}
+
+func evaluate(edges map[uint64]healthReport, now time.Time, staleAfter time.Duration) (string, string) {
+	status, lastErr := targetHealthUnknown, ""
+	for _, r := range edges {
+		if now.Sub(r.at) > staleAfter {
+			continue
+		}
+		if r.healthy {
+			return targetHealthHealthy, ""
+		}
+		status, lastErr = targetHealthUnhealthy, r.err
+	}
+	return status, lastErr
+}
+
+func healthCheckInterval(hc model.HealthCheck) time.Duration {
+	if hc.IntervalSec > 0 {
+		return time.Duration(hc.IntervalSec) * time.Second
+	}
+	return defaultHealthCheckInterval
+}
+
+func healthStaleAfter(hc model.HealthCheck) time.Duration {
+	return healthReportStaleIntervals * healthCheckInterval(hc)
+}
+
+// protoTargets builds the data-plane view of the application's targets.
+func (cp *controlPlane) protoTargets(application *model.Application) []proto.Target {
+	targets := application.Targets
+	if len(targets) == 0 {
+		targets = model.TargetList{{IP: application.IP, Port: application.Port, Weight: 1}}
+	}
+	staleAfter := healthStaleAfter(application.HealthCheck)
+	result := make([]proto.Target, len(targets))
+	for i, t := range targets {
+		healthy := true
+		if application.HealthCheck.Type != "" {
+			status, _ := cp.targetHealth.health(application.ID, t.Addr(), staleAfter)
+			healthy = status != targetHealthUnhealthy
+		}
+		result[i] = proto.Target{Addr: t.Addr(), Weight: t.Weight, Healthy: healthy}
+	}
+	return result
+}
+
+// GetEdgeHealthChecks returns the health checks of every application that
+// the edge serves.
+func (cp *controlPlane) GetEdgeHealthChecks(edgeID uint64) ([]proto.HealthCheck, error) {
+	applications, err := cp.repo.ListApplications(&dao.ListApplicationsQuery{
+		Query: dao.Query{Page: -1, PageSize: -1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	checks := make([]proto.HealthCheck, 0)
+	for _, application := range applications {
+		hc := application.HealthCheck
+		if hc.Type == "" || !containsEdge(application.EdgeIDs, edgeID) {
+			continue
+		}
+		timeoutSec := hc.TimeoutSec
+		if timeoutSec <= 0 {
+			timeoutSec = int(defaultHealthCheckTimeout / time.Second)
+		}
+		checks = append(checks, proto.HealthCheck{
+			ApplicationID: application.ID,
+			Type:          hc.Type,
+			Path:          hc.Path,
+			IntervalSec:   int(healthCheckInterval(hc) / time.Second),
+			TimeoutSec:    timeoutSec,
+			Targets:       application.TargetAddrs(),
+		})
+	}
+	return checks, nil
+}
+
+// ReportTargetHealth records the results of an edge and pushes the new
+// health of the targets to running proxies when it changed.
+func (cp *controlPlane) ReportTargetHealth(report *proto.ReportTargetHealthRequest) error {
+	application, err := cp.repo.GetApplicationByID(report.ApplicationID)
+	if err != nil {
+		return err
+	}
+	if !containsEdge(application.EdgeIDs, report.EdgeID) {
+		return fmt.Errorf("edge %d does not serve application %d", report.EdgeID, report.ApplicationID)
+	}
+	changed := cp.targetHealth.record(report.EdgeID, application.ID, report.Targets, healthStaleAfter(application.HealthCheck))
+	if len(changed) == 0 {
+		return nil
+	}
+	for _, addr := range changed {
+		status, lastErr := cp.targetHealth.health(application.ID, addr, healthStaleAfter(application.HealthCheck))
+		log.Infof("application %d target %s is %s (reported by edge %d) %s", application.ID, addr, status, report.EdgeID, lastErr)
+	}
+	proxies, err := cp.repo.ListProxies(&dao.ListProxiesQuery{ApplicationIDs: []uint{application.ID}})
+	if err != nil {
+		return err
+	}
+	for _, proxy := range proxies {
+		if err := cp.updateProxyRuntime(proxy, application); err != nil {
+			log.Warnf("application %d: push target health to proxy=%d failed: %v", application.ID, proxy.ID, err)
+		}
+	}
+	cp.updateRoutingProxies(application.ID)
+	return nil
+}
+
+func containsEdge(edgeIDs model.UintSlice, edgeID uint64) bool {
+	for _, id := range edgeIDs {
+		if uint64(id) == edgeID {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTargets checks the targets and health check of an application.
+func validateTargets(targets model.TargetList, hc model.HealthCheck) error {
+	for _, t := range targets {
+		if t.IP == "" {
+			return fmt.Errorf("invalid target: ip is required")
+		}
+		if t.Port <= 0 || t.Port > 65535 {
+			return fmt.Errorf("invalid target port %d", t.Port)
+		}
+		if t.Weight < 0 {
+			return fmt.Errorf("invalid target weight %d", t.Weight)
+		}
+	}
+	switch hc.Type {
+	case "", proto.HealthCheckTCP, proto.HealthCheckHTTP:
+	default:
+		return fmt.Errorf("invalid health_check type %q, expect %s or %s", hc.Type, proto.HealthCheckTCP, proto.HealthCheckHTTP)
+	}
+	if hc.IntervalSec < 0 || hc.TimeoutSec < 0 {
+		return fmt.Errorf("invalid health_check: values must not be negative")
+	}
+	return nil
+}
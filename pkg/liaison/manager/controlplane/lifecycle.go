@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
 	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
 	"github.com/liaisonio/liaison/pkg/proto"
 )
@@ -22,7 +23,7 @@ func (cp *controlPlane) stopProxyRuntime(proxy *model.Proxy) error {
 	if err := cp.proxyManager.DeleteProxy(context.Background(), int(proxy.ID)); err != nil {
 		return err
 	}
-	if cp.firewallManager != nil && proxy.Port > 0 {
+	if cp.firewallManager != nil && proxy.Reachable() {
 		cp.firewallManager.Revoke(int(proxy.ID))
 	}
 	return nil
@@ -38,31 +39,99 @@ func (cp *controlPlane) startProxyRuntime(proxy *model.Proxy, application *model
 	if proxy.Status != model.ProxyStatusRunning {
 		return nil
 	}
-	useHTTPS := application.ApplicationType == model.ApplicationTypeHTTP
-	protoproxy := &proto.Proxy{
+	protoproxy := cp.newProtoProxy(proxy, application)
+	if err := cp.proxyManager.CreateProxy(context.Background(), protoproxy); err != nil {
+		return err
+	}
+	// A proxy that was only virtual-hosted gets a system-allocated port once
+	// its hostname is removed; persist it like CreateProxy does.
+	if proxy.Port == 0 && protoproxy.ProxyPort > 0 {
+		proxy.Port = protoproxy.ProxyPort
+		if err := cp.repo.UpdateProxy(proxy); err != nil {
+			log.Errorf("failed to update proxy port: %s", err)
+		}
+	}
+	cp.reapplyFirewall(proxy)
+	return nil
+}
+
+// newProtoProxy builds the data-plane view of proxy. Every place that
+// starts a listener goes through here so that options reach the entry
+// consistently.
+func (cp *controlPlane) newProtoProxy(proxy *model.Proxy, application *model.Application) *proto.Proxy {
+	return &proto.Proxy{
 		ID:              int(proxy.ID),
 		Name:            proxy.Name,
 		ProxyPort:       proxy.Port,
-		EdgeID:          uint64(application.EdgeIDs[0]),
+		Hostname:        proxy.Hostname,
+		EdgeIDs:         edgeIDsOf(application),
+		EdgePolicy:      application.EdgePolicy,
 		ApplicationID:   application.ID,
 		Dst:             fmt.Sprintf("%s:%d", application.IP, application.Port),
+		Targets:         cp.protoTargets(application),
 		ApplicationType: string(application.ApplicationType),
-		UseHTTPS:        useHTTPS,
+		// HTTP 应用默认使用 HTTPS
+		UseHTTPS:            application.ApplicationType == model.ApplicationTypeHTTP,
+		CertificateID:       proxy.CertificateID,
+		ProxyProtocol:       proxy.Options.ProxyProtocol,
+		ForwardedHeaders:    proxy.Options.ForwardedHeaders,
+		Compression:         proxy.Options.Compression,
+		H2C:                 proxy.Options.H2C,
+		BackendProtocol:     proxy.Options.BackendProtocol,
+		RateLimit:           proto.RateLimit(proxy.Options.RateLimit),
+		ConnLimit:           proto.ConnLimit(proxy.Options.ConnLimit),
+		Inspector:           proto.Inspector(proxy.Options.Inspector),
+		ResponseCompression: proto.ResponseCompression(proxy.Options.ResponseCompression),
+		ResponseCache:       proto.ResponseCache(proxy.Options.ResponseCache),
+		Auth: proto.ProxyAuth{
+			Required:     proxy.AuthRequired,
+			AllowedUsers: proxy.AuthAllowedUsers,
+		},
+		HeaderRules: protoHeaderRules(proxy.HeaderRules),
+		Routes:      cp.protoRoutes(proxy),
+		Middlewares: protoMiddlewares(application.Middlewares),
 	}
-	if err := cp.proxyManager.CreateProxy(context.Background(), protoproxy); err != nil {
-		return err
+}
+
+func protoHeaderRules(rules model.HeaderRules) []proto.HeaderRule {
+	if len(rules) == 0 {
+		return nil
 	}
-	cp.reapplyFirewall(proxy.ID, proxy.Port)
-	return nil
+	protoRules := make([]proto.HeaderRule, len(rules))
+	for i, rule := range rules {
+		protoRules[i] = proto.HeaderRule(rule)
+	}
+	return protoRules
+}
+
+func edgeIDsOf(application *model.Application) []uint64 {
+	edgeIDs := make([]uint64, len(application.EdgeIDs))
+	for i, id := range application.EdgeIDs {
+		edgeIDs[i] = uint64(id)
+	}
+	return edgeIDs
+}
+
+// updateProxyRuntime pushes the hot-reloadable part of proxy (rate and
+// connection limits, edges of the application) to its running listener. No-op for stopped proxies.
+func (cp *controlPlane) updateProxyRuntime(proxy *model.Proxy, application *model.Application) error {
+	if proxy == nil || application == nil || cp.proxyManager == nil {
+		return nil
+	}
+	if proxy.Status != model.ProxyStatusRunning || len(application.EdgeIDs) == 0 {
+		return nil
+	}
+	return cp.proxyManager.UpdateProxy(context.Background(), cp.newProtoProxy(proxy, application))
 }
 
 // reapplyFirewall reads the persisted allowlist for proxyID and pushes it to
 // the data plane. Called from startProxyRuntime and RestoreFirewallRules so
 // that kernel-side state stays in sync with DB-side state across restarts.
-func (cp *controlPlane) reapplyFirewall(proxyID uint, port int) {
-	if cp.firewallManager == nil || cp.repo == nil || port <= 0 {
+func (cp *controlPlane) reapplyFirewall(proxy *model.Proxy) {
+	if cp.firewallManager == nil || cp.repo == nil || !proxy.Reachable() {
 		return
 	}
+	proxyID := proxy.ID
 	rule, err := cp.repo.GetFirewallRuleByProxyID(proxyID)
 	if err != nil {
 		log.Warnf("firewall: lookup proxy=%d failed: %v", proxyID, err)
@@ -97,3 +166,43 @@ func (cp *controlPlane) RestoreFirewallRules() {
 	}
 	log.Infof("firewall: restored %d rule(s) from DB", len(rules))
 }
+
+// ListProxyRuntimes builds the data-plane view of every proxy. Proxies whose
+// application is missing or has no edge are skipped.
+func (cp *controlPlane) ListProxyRuntimes(_ context.Context) ([]*proto.Proxy, error) {
+	proxies, err := cp.repo.ListProxies(&dao.ListProxiesQuery{
+		Query: dao.Query{Page: -1, PageSize: -1, Order: "id"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(proxies) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, 0, len(proxies))
+	for _, proxy := range proxies {
+		ids = append(ids, proxy.ApplicationID)
+	}
+	applications, err := cp.repo.ListApplications(&dao.ListApplicationsQuery{
+		Query: dao.Query{Page: -1, PageSize: -1},
+		IDs:   ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	appMap := make(map[uint]*model.Application, len(applications))
+	for _, app := range applications {
+		appMap[app.ID] = app
+	}
+
+	protoproxies := make([]*proto.Proxy, 0, len(proxies))
+	for _, proxy := range proxies {
+		application, ok := appMap[proxy.ApplicationID]
+		if !ok || len(application.EdgeIDs) == 0 {
+			log.Warnf("proxy %d (name: %s) has no associated application or edge, skipping", proxy.ID, proxy.Name)
+			continue
+		}
+		protoproxies = append(protoproxies, cp.newProtoProxy(proxy, application))
+	}
+	return protoproxies, nil
+}
@@ -31,7 +31,12 @@ func (cp *controlPlane) CreateProxy(_ context.Context, req *v1.CreateProxyReques
 		return nil, err
 	}
 
-	// 如果端口为空或0，设置为0让系统自动分配
+	hostname := cp.resolveHostname(req.Hostname)
+	if err := cp.validateHostname(0, hostname, application); err != nil {
+		return nil, err
+	}
+
+	// 如果端口为空或0，设置为0让系统自动分配；设置了主机名时端口为0表示只通过共享监听访问
 	requestedPort := int(req.Port)
 	if requestedPort == 0 {
 		requestedPort = 0 // 明确设置为0，让系统自动分配
@@ -43,8 +48,15 @@ func (cp *controlPlane) CreateProxy(_ context.Context, req *v1.CreateProxyReques
 		Status:        model.ProxyStatusRunning,
 		Description:   req.Description,
 		Port:          requestedPort,
+		Hostname:      hostname,
 		ApplicationID: uint(req.ApplicationId),
 	}
+	if req.RateLimit != nil {
+		proxy.Options.RateLimit = fromV1RateLimit(req.RateLimit)
+		if err := validateRateLimit(&proxy.Options.RateLimit); err != nil {
+			return nil, err
+		}
+	}
 	err = cp.repo.CreateProxy(proxy)
 	if err != nil {
 		log.Warnf("failed to create proxy: %s", err)
@@ -52,22 +64,7 @@ func (cp *controlPlane) CreateProxy(_ context.Context, req *v1.CreateProxyReques
 	}
 
 	// 创建Proxy（如果端口为0，系统会自动分配）
-	// 如果是 HTTP 应用，默认使用 HTTPS
-	useHTTPS := false
-	if application.ApplicationType == model.ApplicationTypeHTTP {
-		useHTTPS = true
-	}
-
-	protoproxy := &proto.Proxy{
-		ID:              int(proxy.ID),
-		Name:            proxy.Name,
-		ProxyPort:       requestedPort, // 如果为0，系统会自动分配
-		EdgeID:          uint64(application.EdgeIDs[0]),
-		ApplicationID:   application.ID,
-		Dst:             fmt.Sprintf("%s:%d", application.IP, application.Port),
-		ApplicationType: string(application.ApplicationType),
-		UseHTTPS:        useHTTPS,
-	}
+	protoproxy := cp.newProtoProxy(proxy, application)
 	err = cp.proxyManager.CreateProxy(context.Background(), protoproxy)
 	if err != nil {
 		log.Errorf("failed to create proxy listener: %s", err)
@@ -193,6 +190,70 @@ func (cp *controlPlane) UpdateProxy(_ context.Context, req *v1.UpdateProxyReques
 		}
 	}
 
+	// 更新带宽限制，运行中且状态不变的代理热更新
+	rateLimitChanged := false
+	if req.RateLimit != nil {
+		rateLimit := fromV1RateLimit(req.RateLimit)
+		if err := validateRateLimit(&rateLimit); err != nil {
+			return nil, err
+		}
+		rateLimitChanged = rateLimit != proxy.Options.RateLimit
+		proxy.Options.RateLimit = rateLimit
+	}
+
+	// 更新主机名，运行中且状态不变的代理需要重建
+	hostnameChanged := false
+	if req.Hostname != nil {
+		hostname := cp.resolveHostname(*req.Hostname)
+		if hostname != proxy.Hostname {
+			application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+			if err != nil {
+				log.Warnf("application %d not found", proxy.ApplicationID)
+				return nil, err
+			}
+			if err := cp.validateHostname(proxy.ID, hostname, application); err != nil {
+				return nil, err
+			}
+			if err := cp.repo.UpdateProxyHostname(proxy.ID, hostname); err != nil {
+				return nil, err
+			}
+			hostnameChanged = true
+			proxy.Hostname = hostname
+		}
+	}
+
+	if rateLimitChanged {
+		if err := cp.repo.UpdateProxyOptions(proxy.ID, proxy.Options); err != nil {
+			return nil, err
+		}
+		if oldStatus == proxy.Status {
+			application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+			if err != nil {
+				log.Warnf("application %d not found", proxy.ApplicationID)
+				return nil, err
+			}
+			if err := cp.updateProxyRuntime(proxy, application); err != nil {
+				log.Errorf("failed to update proxy rate limit: %s", err)
+				return nil, err
+			}
+		}
+	}
+
+	if hostnameChanged && oldStatus == proxy.Status && proxy.Status == model.ProxyStatusRunning {
+		application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+		if err != nil {
+			log.Warnf("application %d not found", proxy.ApplicationID)
+			return nil, err
+		}
+		if err := cp.stopProxyRuntime(proxy); err != nil {
+			log.Warnf("failed to stop proxy %d for hostname change: %s", proxy.ID, err)
+		}
+		if err := cp.startProxyRuntime(proxy, application); err != nil {
+			log.Errorf("failed to restart proxy %d with new hostname: %s", proxy.ID, err)
+			return nil, err
+		}
+	}
+
 	// 如果状态发生变化，需要调用 ProxyManager
 	if oldStatus != proxy.Status {
 		// 获取 application 信息（用于启动代理时）
@@ -211,24 +272,16 @@ func (cp *controlPlane) UpdateProxy(_ context.Context, req *v1.UpdateProxyReques
 			}
 		} else if proxy.Status == model.ProxyStatusRunning {
 			// 启动代理：调用 CreateProxy
-			// 如果是 HTTP 应用，默认使用 HTTPS
-			useHTTPS := false
-			if application.ApplicationType == model.ApplicationTypeHTTP {
-				useHTTPS = true
-			}
-			err = cp.proxyManager.CreateProxy(context.Background(), &proto.Proxy{
-				ID:              int(proxy.ID),
-				Name:            proxy.Name,
-				ProxyPort:       proxy.Port,
-				EdgeID:          uint64(application.EdgeIDs[0]),
-				Dst:             fmt.Sprintf("%s:%d", application.IP, application.Port),
-				ApplicationType: string(application.ApplicationType),
-				UseHTTPS:        useHTTPS,
-			})
+			protoproxy := cp.newProtoProxy(proxy, application)
+			err = cp.proxyManager.CreateProxy(context.Background(), protoproxy)
 			if err != nil {
 				log.Errorf("failed to start proxy: %s", err)
 				return nil, err
 			}
+			// 去掉主机名的代理没有端口，启动时由系统分配，随下面的 UpdateProxy 保存
+			if proxy.Port == 0 {
+				proxy.Port = protoproxy.ProxyPort
+			}
 		}
 	}
 
@@ -287,7 +340,7 @@ func (cp *controlPlane) transformProxies(proxies []*model.Proxy) []*v1.Proxy {
 func (cp *controlPlane) transformProxy(proxy *model.Proxy) *v1.Proxy {
 	var application *v1.Application
 	if proxy.Application != nil {
-		application = transformApplication(proxy.Application)
+		application = cp.transformApplication(proxy.Application)
 	}
 
 	// 将 ProxyStatus 转换为字符串
@@ -301,10 +354,11 @@ func (cp *controlPlane) transformProxy(proxy *model.Proxy) *v1.Proxy {
 		status = "unknown"
 	}
 
-	// 生成访问地址 —— server_url 形如 https://<host>[:<manager_port>]，
+	// 生成访问地址 —— 设置了主机名时优先使用共享监听上的地址；
+	// 否则 server_url 形如 https://<host>[:<manager_port>]，
 	// 这里只需要 host 部分,再拼 entry 自己的端口。
-	var accessURL string
-	if proxy.Application != nil && proxy.Port > 0 {
+	accessURL := cp.vhostAccessURL(proxy.Hostname)
+	if accessURL == "" && proxy.Application != nil && proxy.Port > 0 {
 		serverURL := cp.conf.Manager.ServerURL
 		if serverURL != "" {
 			host := serverURL
@@ -319,21 +373,46 @@ func (cp *controlPlane) transformProxy(proxy *model.Proxy) *v1.Proxy {
 			}
 			if proxy.Application.ApplicationType == model.ApplicationTypeHTTP {
 				accessURL = fmt.Sprintf("https://%s:%d", host, proxy.Port)
+			} else if proxy.Application.ApplicationType == model.ApplicationTypeUDP {
+				accessURL = fmt.Sprintf("udp://%s:%d", host, proxy.Port)
 			} else {
 				accessURL = fmt.Sprintf("%s:%d", host, proxy.Port)
 			}
 		}
 	}
 
+	var rateLimit *v1.RateLimit
+	if proxy.Options.RateLimit != (model.RateLimit{}) {
+		rl := proxy.Options.RateLimit
+		rateLimit = &v1.RateLimit{
+			UploadBytesPerSec:            rl.UploadBytesPerSec,
+			DownloadBytesPerSec:          rl.DownloadBytesPerSec,
+			PerClientUploadBytesPerSec:   rl.PerClientUploadBytesPerSec,
+			PerClientDownloadBytesPerSec: rl.PerClientDownloadBytesPerSec,
+		}
+	}
+
 	return &v1.Proxy{
-		Id:          uint64(proxy.ID),
-		Name:        proxy.Name,
-		Port:        int32(proxy.Port),
-		Status:      status,
-		Application: application,
-		Description: proxy.Description,
-		CreatedAt:   proxy.CreatedAt.Format(time.DateTime),
-		UpdatedAt:   proxy.UpdatedAt.Format(time.DateTime),
-		AccessUrl:   accessURL,
+		Id:            uint64(proxy.ID),
+		Name:          proxy.Name,
+		Port:          int32(proxy.Port),
+		Status:        status,
+		Application:   application,
+		Description:   proxy.Description,
+		CreatedAt:     proxy.CreatedAt.Format(time.DateTime),
+		UpdatedAt:     proxy.UpdatedAt.Format(time.DateTime),
+		AccessUrl:     accessURL,
+		RateLimit:     rateLimit,
+		Hostname:      proxy.Hostname,
+		CertificateId: uint64(proxy.CertificateID),
+	}
+}
+
+func fromV1RateLimit(rl *v1.RateLimit) model.RateLimit {
+	return model.RateLimit{
+		UploadBytesPerSec:            rl.UploadBytesPerSec,
+		DownloadBytesPerSec:          rl.DownloadBytesPerSec,
+		PerClientUploadBytesPerSec:   rl.PerClientUploadBytesPerSec,
+		PerClientDownloadBytesPerSec: rl.PerClientDownloadBytesPerSec,
 	}
 }
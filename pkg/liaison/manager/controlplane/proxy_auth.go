@@ -0,0 +1,99 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+)
+
+// ProxyAuthParams is the body for configuring login protection of a proxy.
+type ProxyAuthParams struct {
+	Required bool `json:"required"`
+	// AllowedUsers are the emails of users allowed through; empty allows
+	// every active user.
+	AllowedUsers []string `json:"allowed_users"`
+}
+
+// ProxyAuthData is the login protection of a proxy.
+type ProxyAuthData struct {
+	ProxyID      uint     `json:"proxy_id"`
+	Required     bool     `json:"required"`
+	AllowedUsers []string `json:"allowed_users"`
+}
+
+// GetProxyAuth returns the login protection of a proxy.
+func (cp *controlPlane) GetProxyAuth(ctx context.Context, proxyID uint) (*ProxyAuthData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	return transformProxyAuth(proxy), nil
+}
+
+// UpdateProxyAuth replaces the login protection of an HTTP proxy. Visitors
+// of a protected proxy must sign in with a Liaison account; running proxies
+// pick up the change without a restart.
+func (cp *controlPlane) UpdateProxyAuth(ctx context.Context, proxyID uint, params *ProxyAuthParams) (*ProxyAuthData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.ApplicationType != model.ApplicationTypeHTTP {
+		return nil, fmt.Errorf("login protection is only supported on http proxies")
+	}
+	allowed, err := cp.normalizeAllowedUsers(params.AllowedUsers)
+	if err != nil {
+		return nil, err
+	}
+	if err := cp.repo.UpdateProxyAuth(proxyID, params.Required, allowed); err != nil {
+		return nil, err
+	}
+	proxy.AuthRequired, proxy.AuthAllowedUsers = params.Required, allowed
+	if err := cp.updateProxyRuntime(proxy, application); err != nil {
+		log.Errorf("proxy auth: update proxy=%d failed: %v", proxyID, err)
+		return nil, err
+	}
+	return transformProxyAuth(proxy), nil
+}
+
+// normalizeAllowedUsers trims and de-duplicates the allowed emails and checks
+// that each one belongs to an existing user.
+func (cp *controlPlane) normalizeAllowedUsers(emails []string) ([]string, error) {
+	allowed := make([]string, 0, len(emails))
+	seen := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+		if email == "" || seen[strings.ToLower(email)] {
+			continue
+		}
+		user, err := cp.repo.GetUserByEmail(email)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, fmt.Errorf("user %s not found", email)
+		}
+		seen[strings.ToLower(email)] = true
+		allowed = append(allowed, user.Email)
+	}
+	return allowed, nil
+}
+
+func transformProxyAuth(proxy *model.Proxy) *ProxyAuthData {
+	allowed := []string(proxy.AuthAllowedUsers)
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return &ProxyAuthData{
+		ProxyID:      proxy.ID,
+		Required:     proxy.AuthRequired,
+		AllowedUsers: allowed,
+	}
+}
@@ -0,0 +1,73 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// ProxyConnectionsData is the live connections of a proxy as seen by the
+// entry. TCP proxies list connections that reached an edge; UDP proxies
+// list one session per client address.
+type ProxyConnectionsData struct {
+	ProxyID     uint                   `json:"proxy_id"`
+	Running     bool                   `json:"running"`
+	Connections []proto.ConnectionInfo `json:"connections"`
+}
+
+// CloseConnectionsData reports how many connections a disconnect closed.
+type CloseConnectionsData struct {
+	Closed int `json:"closed"`
+}
+
+// ListProxyConnections returns the live client connections of a proxy.
+func (cp *controlPlane) ListProxyConnections(ctx context.Context, proxyID uint) (*ProxyConnectionsData, error) {
+	if _, err := cp.repo.GetProxyByID(proxyID); err != nil {
+		return nil, err
+	}
+	data := &ProxyConnectionsData{ProxyID: proxyID, Connections: []proto.ConnectionInfo{}}
+	if cp.proxyManager == nil {
+		return data, nil
+	}
+	conns, err := cp.proxyManager.ListConnections(ctx, int(proxyID))
+	if err != nil {
+		return nil, err
+	}
+	if conns != nil {
+		data.Running = true
+		data.Connections = conns
+	}
+	return data, nil
+}
+
+// CloseProxyConnections disconnects one connection of a proxy, or every
+// connection from clientIP. At least one of them must be given.
+func (cp *controlPlane) CloseProxyConnections(ctx context.Context, proxyID uint, connectionID uint64, clientIP string) (*CloseConnectionsData, error) {
+	if _, err := cp.repo.GetProxyByID(proxyID); err != nil {
+		return nil, err
+	}
+	filter := proto.ConnectionFilter{ID: connectionID}
+	if clientIP != "" {
+		ip := net.ParseIP(clientIP)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid client ip %q", clientIP)
+		}
+		filter.ClientIP = ip.String()
+	}
+	if filter.ID == 0 && filter.ClientIP == "" {
+		return nil, errors.New("connection id or client ip is required")
+	}
+	data := &CloseConnectionsData{}
+	if cp.proxyManager == nil {
+		return data, nil
+	}
+	closed, err := cp.proxyManager.CloseConnections(ctx, int(proxyID), filter)
+	if err != nil {
+		return nil, err
+	}
+	data.Closed = closed
+	return data, nil
+}
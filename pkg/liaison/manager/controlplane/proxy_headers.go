@@ -0,0 +1,88 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// maxHeaderRules caps the rewrite rules of a single proxy.
+const maxHeaderRules = 64
+
+// ProxyHeaderRulesData is the header rewrite rules of a proxy, applied in order.
+type ProxyHeaderRulesData struct {
+	ProxyID uint               `json:"proxy_id"`
+	Rules   []model.HeaderRule `json:"rules"`
+}
+
+// GetProxyHeaderRules returns the header rewrite rules of a proxy.
+func (cp *controlPlane) GetProxyHeaderRules(ctx context.Context, proxyID uint) (*ProxyHeaderRulesData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	return transformHeaderRules(proxy), nil
+}
+
+// UpdateProxyHeaderRules replaces the header rewrite rules of an HTTP proxy.
+// Running proxies apply them to subsequent requests without a restart.
+func (cp *controlPlane) UpdateProxyHeaderRules(ctx context.Context, proxyID uint, rules []model.HeaderRule) (*ProxyHeaderRulesData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.ApplicationType != model.ApplicationTypeHTTP {
+		return nil, fmt.Errorf("header rules are only supported on http proxies")
+	}
+	normalized, err := validateHeaderRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	if err := cp.repo.UpdateProxyHeaderRules(proxyID, normalized); err != nil {
+		return nil, err
+	}
+	proxy.HeaderRules = normalized
+	if err := cp.updateProxyRuntime(proxy, application); err != nil {
+		log.Errorf("header rules: update proxy=%d failed: %v", proxyID, err)
+		return nil, err
+	}
+	return transformHeaderRules(proxy), nil
+}
+
+// validateHeaderRules checks every rule and returns them with canonical
+// directions, actions and header names.
+func validateHeaderRules(rules []model.HeaderRule) (model.HeaderRules, error) {
+	if len(rules) > maxHeaderRules {
+		return nil, fmt.Errorf("too many header rules, at most %d", maxHeaderRules)
+	}
+	normalized := make(model.HeaderRules, 0, len(rules))
+	for i, rule := range rules {
+		protoRule := proto.HeaderRule{
+			Direction: strings.ToLower(strings.TrimSpace(rule.Direction)),
+			Action:    strings.ToLower(strings.TrimSpace(rule.Action)),
+			Name:      strings.TrimSpace(rule.Name),
+			Value:     rule.Value,
+		}
+		if err := proto.ValidateHeaderRule(&protoRule); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		normalized = append(normalized, model.HeaderRule(protoRule))
+	}
+	return normalized, nil
+}
+
+func transformHeaderRules(proxy *model.Proxy) *ProxyHeaderRulesData {
+	rules := []model.HeaderRule(proxy.HeaderRules)
+	if rules == nil {
+		rules = []model.HeaderRule{}
+	}
+	return &ProxyHeaderRulesData{ProxyID: proxy.ID, Rules: rules}
+}
@@ -0,0 +1,84 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// InspectedRequestsData is the requests the inspector of an HTTP proxy holds
+// in memory, newest first. Headers and bodies are left out; fetch a single
+// request for them.
+type InspectedRequestsData struct {
+	ProxyID  uint                     `json:"proxy_id"`
+	Enabled  bool                     `json:"enabled"`
+	Requests []proto.InspectedRequest `json:"requests"`
+}
+
+// ListProxyInspectedRequests returns the requests captured by the inspector
+// of a proxy. A stopped proxy or a disabled inspector has none.
+func (cp *controlPlane) ListProxyInspectedRequests(ctx context.Context, proxyID uint) (*InspectedRequestsData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	data := &InspectedRequestsData{
+		ProxyID:  proxyID,
+		Enabled:  proxy.Options.Inspector.Enabled,
+		Requests: []proto.InspectedRequest{},
+	}
+	if cp.proxyManager == nil {
+		return data, nil
+	}
+	requests, err := cp.proxyManager.ListInspectedRequests(ctx, int(proxyID))
+	if err != nil {
+		return nil, err
+	}
+	if requests != nil {
+		data.Requests = requests
+	}
+	return data, nil
+}
+
+// GetProxyInspectedRequest returns one captured request with its headers
+// and bodies.
+func (cp *controlPlane) GetProxyInspectedRequest(ctx context.Context, proxyID uint, requestID uint64) (*proto.InspectedRequest, error) {
+	if err := cp.checkInspectorProxy(proxyID); err != nil {
+		return nil, err
+	}
+	request, err := cp.proxyManager.GetInspectedRequest(ctx, int(proxyID), requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, fmt.Errorf("request %d not found", requestID)
+	}
+	return request, nil
+}
+
+// ReplayProxyInspectedRequest sends a captured request to the backend again
+// through the edge and returns the new capture. The replay is captured like
+// any other request, with replay_of pointing at the original.
+func (cp *controlPlane) ReplayProxyInspectedRequest(ctx context.Context, proxyID uint, requestID uint64) (*proto.InspectedRequest, error) {
+	if err := cp.checkInspectorProxy(proxyID); err != nil {
+		return nil, err
+	}
+	return cp.proxyManager.ReplayRequest(ctx, int(proxyID), requestID)
+}
+
+func (cp *controlPlane) checkInspectorProxy(proxyID uint) error {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return err
+	}
+	if !proxy.Options.Inspector.Enabled {
+		return fmt.Errorf("inspector of proxy %d is not enabled", proxyID)
+	}
+	if proxy.Status != model.ProxyStatusRunning || cp.proxyManager == nil {
+		return errors.New("proxy is not running")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/compress"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/liaisonio/liaison/pkg/proxyproto"
+)
+
+// ProxyOptionsData is the API-level representation of a proxy's options.
+type ProxyOptionsData struct {
+	ProxyID uint `json:"proxy_id"`
+	model.ProxyOptions
+}
+
+// GetProxyOptions returns the options of a proxy; unset options are zero.
+func (cp *controlPlane) GetProxyOptions(ctx context.Context, proxyID uint) (*ProxyOptionsData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyOptionsData{ProxyID: proxyID, ProxyOptions: proxy.Options}, nil
+}
+
+// UpdateProxyOptions replaces the options of a proxy. If only the rate or
+// connection limits, the inspector or response compression and caching
+// changed they are applied to the running listener in place; otherwise a running
+// proxy is restarted so the new options take effect on subsequent connections.
+func (cp *controlPlane) UpdateProxyOptions(ctx context.Context, proxyID uint, options model.ProxyOptions) (*ProxyOptionsData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateProxyOptions(&options); err != nil {
+		return nil, err
+	}
+	if err := cp.repo.UpdateProxyOptions(proxyID, options); err != nil {
+		return nil, err
+	}
+	old := proxy.Options
+	proxy.Options = options
+
+	if proxy.Status == model.ProxyStatusRunning {
+		application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+		if err != nil {
+			return nil, err
+		}
+		old.RateLimit, options.RateLimit = model.RateLimit{}, model.RateLimit{}
+		old.ConnLimit, options.ConnLimit = model.ConnLimit{}, model.ConnLimit{}
+		old.Inspector, options.Inspector = model.Inspector{}, model.Inspector{}
+		old.ResponseCompression, options.ResponseCompression = model.ResponseCompression{}, model.ResponseCompression{}
+		old.ResponseCache, options.ResponseCache = model.ResponseCache{}, model.ResponseCache{}
+		if old == options {
+			if err := cp.updateProxyRuntime(proxy, application); err != nil {
+				log.Errorf("proxy options: update proxy=%d failed: %v", proxyID, err)
+				return nil, err
+			}
+		} else {
+			if err := cp.stopProxyRuntime(proxy); err != nil {
+				log.Warnf("proxy options: stop proxy=%d failed: %v", proxyID, err)
+			}
+			if err := cp.startProxyRuntime(proxy, application); err != nil {
+				log.Errorf("proxy options: restart proxy=%d failed: %v", proxyID, err)
+				return nil, err
+			}
+		}
+	}
+	return &ProxyOptionsData{ProxyID: proxyID, ProxyOptions: proxy.Options}, nil
+}
+
+func validateProxyOptions(options *model.ProxyOptions) error {
+	if !proxyproto.ValidVersion(options.ProxyProtocol) {
+		return fmt.Errorf("invalid proxy_protocol %q, expect v1 or v2", options.ProxyProtocol)
+	}
+	if !compress.ValidAlgorithm(options.Compression) {
+		return fmt.Errorf("invalid compression %q, expect %s or %s", options.Compression, compress.Zstd, compress.Snappy)
+	}
+	if !proto.ValidBackendProtocol(options.BackendProtocol) {
+		return fmt.Errorf("invalid backend_protocol %q, expect %s or %s",
+			options.BackendProtocol, proto.BackendProtocolHTTP1, proto.BackendProtocolH2C)
+	}
+	if options.ConnLimit.MaxConnections < 0 || options.ConnLimit.MaxConnectionsPerIP < 0 {
+		return fmt.Errorf("invalid conn_limit: values must not be negative")
+	}
+	if options.Inspector.MaxBodyBytes < 0 || options.Inspector.MaxBodyBytes > proto.MaxInspectorBodyBytes {
+		return fmt.Errorf("invalid inspector max_body_bytes %d, expect 0 to %d", options.Inspector.MaxBodyBytes, proto.MaxInspectorBodyBytes)
+	}
+	if options.Inspector.MaxRequests < 0 || options.Inspector.MaxRequests > proto.MaxInspectorRequests {
+		return fmt.Errorf("invalid inspector max_requests %d, expect 0 to %d", options.Inspector.MaxRequests, proto.MaxInspectorRequests)
+	}
+	if options.ResponseCompression.MinBytes < 0 {
+		return fmt.Errorf("invalid response_compression min_bytes: must not be negative")
+	}
+	if cache := options.ResponseCache; cache.MaxBytes < 0 || cache.MaxBytes > proto.MaxCacheBytes || cache.MaxObjectBytes < 0 {
+		return fmt.Errorf("invalid response_cache: max_bytes expect 0 to %d, max_object_bytes must not be negative", proto.MaxCacheBytes)
+	}
+	return validateRateLimit(&options.RateLimit)
+}
+
+func validateRateLimit(rl *model.RateLimit) error {
+	if rl.UploadBytesPerSec < 0 || rl.DownloadBytesPerSec < 0 ||
+		rl.PerClientUploadBytesPerSec < 0 || rl.PerClientDownloadBytesPerSec < 0 {
+		return fmt.Errorf("invalid rate_limit: values must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// maxProxyRoutes caps the path routes of a single proxy.
+const maxProxyRoutes = 32
+
+// ProxyRoutesData is the path routes of a proxy, matched in order. Requests
+// matching no route go to the proxy's own application.
+type ProxyRoutesData struct {
+	ProxyID uint               `json:"proxy_id"`
+	Routes  []model.ProxyRoute `json:"routes"`
+}
+
+// GetProxyRoutes returns the path routes of a proxy.
+func (cp *controlPlane) GetProxyRoutes(ctx context.Context, proxyID uint) (*ProxyRoutesData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	return transformProxyRoutes(proxy), nil
+}
+
+// UpdateProxyRoutes replaces the path routes of an HTTP proxy. Every route
+// must point at an HTTP application; running proxies apply the routes to
+// subsequent requests without a restart.
+func (cp *controlPlane) UpdateProxyRoutes(ctx context.Context, proxyID uint, routes []model.ProxyRoute) (*ProxyRoutesData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.ApplicationType != model.ApplicationTypeHTTP {
+		return nil, fmt.Errorf("path routes are only supported on http proxies")
+	}
+	normalized, err := cp.validateProxyRoutes(routes)
+	if err != nil {
+		return nil, err
+	}
+	if err := cp.repo.UpdateProxyRoutes(proxyID, normalized); err != nil {
+		return nil, err
+	}
+	proxy.Routes = normalized
+	if err := cp.updateProxyRuntime(proxy, application); err != nil {
+		log.Errorf("proxy routes: update proxy=%d failed: %v", proxyID, err)
+		return nil, err
+	}
+	return transformProxyRoutes(proxy), nil
+}
+
+func (cp *controlPlane) validateProxyRoutes(routes []model.ProxyRoute) (model.ProxyRoutes, error) {
+	if len(routes) > maxProxyRoutes {
+		return nil, fmt.Errorf("too many routes, at most %d", maxProxyRoutes)
+	}
+	normalized := make(model.ProxyRoutes, 0, len(routes))
+	seen := make(map[string]bool, len(routes))
+	for i, route := range routes {
+		route.PathPrefix = strings.TrimSpace(route.PathPrefix)
+		if err := proto.ValidatePathPrefix(route.PathPrefix); err != nil {
+			return nil, fmt.Errorf("route %d: %w", i+1, err)
+		}
+		if seen[route.PathPrefix] {
+			return nil, fmt.Errorf("route %d: duplicate path prefix %s", i+1, route.PathPrefix)
+		}
+		seen[route.PathPrefix] = true
+		application, err := cp.repo.GetApplicationByID(route.ApplicationID)
+		if err != nil {
+			return nil, fmt.Errorf("route %d: application %d not found: %w", i+1, route.ApplicationID, err)
+		}
+		if application.ApplicationType != model.ApplicationTypeHTTP {
+			return nil, fmt.Errorf("route %d: application %d is not an http application", i+1, route.ApplicationID)
+		}
+		normalized = append(normalized, route)
+	}
+	return normalized, nil
+}
+
+// protoRoutes resolves the routes of proxy to the edges and targets of their
+// applications. Routes whose application is gone or has no edge are skipped,
+// so their requests fall through to later routes or the proxy's application.
+func (cp *controlPlane) protoRoutes(proxy *model.Proxy) []proto.Route {
+	if len(proxy.Routes) == 0 {
+		return nil
+	}
+	routes := make([]proto.Route, 0, len(proxy.Routes))
+	for _, route := range proxy.Routes {
+		application, err := cp.repo.GetApplicationByID(route.ApplicationID)
+		if err != nil || len(application.EdgeIDs) == 0 {
+			log.Warnf("proxy %d: route %s has no application or edge, skipping", proxy.ID, route.PathPrefix)
+			continue
+		}
+		routes = append(routes, proto.Route{
+			PathPrefix:    route.PathPrefix,
+			StripPrefix:   route.StripPrefix,
+			ApplicationID: application.ID,
+			EdgeIDs:       edgeIDsOf(application),
+			EdgePolicy:    application.EdgePolicy,
+			Dst:           fmt.Sprintf("%s:%d", application.IP, application.Port),
+			Targets:       cp.protoTargets(application),
+			Middlewares:   protoMiddlewares(application.Middlewares),
+		})
+	}
+	return routes
+}
+
+// updateRoutingProxies pushes a changed application to the running proxies
+// that route some paths to it but belong to another application.
+func (cp *controlPlane) updateRoutingProxies(applicationID uint) {
+	proxies, err := cp.repo.ListProxies(&dao.ListProxiesQuery{Query: dao.Query{Page: -1, PageSize: -1}})
+	if err != nil {
+		log.Warnf("application %d: list proxies failed: %v", applicationID, err)
+		return
+	}
+	for _, proxy := range proxies {
+		if proxy.ApplicationID == applicationID || !proxy.Routes.RoutesTo(applicationID) {
+			continue
+		}
+		application, err := cp.repo.GetApplicationByID(proxy.ApplicationID)
+		if err != nil {
+			log.Warnf("application %d: get application of proxy=%d failed: %v", applicationID, proxy.ID, err)
+			continue
+		}
+		if err := cp.updateProxyRuntime(proxy, application); err != nil {
+			log.Warnf("application %d: update routing proxy=%d failed: %v", applicationID, proxy.ID, err)
+		}
+	}
+}
+
+func transformProxyRoutes(proxy *model.Proxy) *ProxyRoutesData {
+	routes := []model.ProxyRoute(proxy.Routes)
+	if routes == nil {
+		routes = []model.ProxyRoute{}
+	}
+	return &ProxyRoutesData{ProxyID: proxy.ID, Routes: routes}
+}
@@ -0,0 +1,40 @@
+package controlplane
+
+import (
+	"context"
+
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// ProxyStatsData is the API-level representation of a proxy's live counters.
+// Counters are zero while the proxy is not running and reset whenever its
+// listener is recreated.
+type ProxyStatsData struct {
+	ProxyID uint `json:"proxy_id"`
+	Running bool `json:"running"`
+	proto.ProxyStats
+	ConnLimit model.ConnLimit `json:"conn_limit"`
+}
+
+// GetProxyStats returns the active connection count and limit rejections of a
+// proxy as seen by the entry.
+func (cp *controlPlane) GetProxyStats(ctx context.Context, proxyID uint) (*ProxyStatsData, error) {
+	proxy, err := cp.repo.GetProxyByID(proxyID)
+	if err != nil {
+		return nil, err
+	}
+	data := &ProxyStatsData{ProxyID: proxyID, ConnLimit: proxy.Options.ConnLimit}
+	if cp.proxyManager == nil {
+		return data, nil
+	}
+	stats, err := cp.proxyManager.GetProxyStats(ctx, int(proxyID))
+	if err != nil {
+		return nil, err
+	}
+	if stats != nil {
+		data.Running = true
+		data.ProxyStats = *stats
+	}
+	return data, nil
+}
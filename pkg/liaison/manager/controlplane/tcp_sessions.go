@@ -0,0 +1,143 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+const (
+	defaultSessionPageSize = 50
+	maxSessionPageSize     = 1000
+)
+
+// SessionFilter narrows the TCP sessions of a proxy or application. Empty
+// fields match everything. Start and End take the same formats as
+// AccessLogFilter and match sessions overlapping the window; CloseReason is
+// one of the proto.Session* reasons.
+type SessionFilter struct {
+	Start       string
+	End         string
+	ClientIP    string
+	CloseReason string
+}
+
+// SessionData is one connection accepted by a TCP proxy.
+type SessionData struct {
+	ID            uint      `json:"id"`
+	ProxyID       uint      `json:"proxy_id"`
+	ApplicationID uint      `json:"application_id"`
+	EdgeID        uint64    `json:"edge_id"`
+	ClientAddr    string    `json:"client_addr"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	DurationMs    int64     `json:"duration_ms"`
+	BytesIn       int64     `json:"bytes_in"`
+	BytesOut      int64     `json:"bytes_out"`
+	CloseReason   string    `json:"close_reason"`
+	Detail        string    `json:"detail,omitempty"`
+}
+
+// SessionsData is a page of TCP sessions, most recently closed first.
+type SessionsData struct {
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Sessions []*SessionData `json:"sessions"`
+}
+
+var sessionCloseReasons = map[string]bool{
+	proto.SessionClientClosed:     true,
+	proto.SessionEdgeClosed:       true,
+	proto.SessionEdgeDialFailed:   true,
+	proto.SessionFirewallRejected: true,
+	proto.SessionProxyStopped:     true,
+	proto.SessionDisconnected:     true,
+}
+
+// ListProxySessions returns a page of the TCP sessions of a proxy.
+func (cp *controlPlane) ListProxySessions(ctx context.Context, proxyID uint, filter *SessionFilter, page, pageSize int) (*SessionsData, error) {
+	if _, err := cp.repo.GetProxyByID(proxyID); err != nil {
+		return nil, err
+	}
+	return cp.listSessions(&dao.ListTCPSessionsQuery{ProxyID: proxyID}, filter, page, pageSize)
+}
+
+// ListApplicationSessions returns a page of the TCP sessions of every proxy
+// of an application.
+func (cp *controlPlane) ListApplicationSessions(ctx context.Context, applicationID uint, filter *SessionFilter, page, pageSize int) (*SessionsData, error) {
+	if _, err := cp.repo.GetApplicationByID(applicationID); err != nil {
+		return nil, err
+	}
+	return cp.listSessions(&dao.ListTCPSessionsQuery{ApplicationID: applicationID}, filter, page, pageSize)
+}
+
+func (cp *controlPlane) listSessions(query *dao.ListTCPSessionsQuery, filter *SessionFilter, page, pageSize int) (*SessionsData, error) {
+	if filter != nil {
+		var err error
+		if query.Start, err = parseFilterTime(filter.Start); err != nil {
+			return nil, fmt.Errorf("invalid start: %w", err)
+		}
+		if query.End, err = parseFilterTime(filter.End); err != nil {
+			return nil, fmt.Errorf("invalid end: %w", err)
+		}
+		if filter.ClientIP != "" {
+			ip := net.ParseIP(filter.ClientIP)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid client ip %q", filter.ClientIP)
+			}
+			query.ClientIP = ip.String()
+		}
+		if filter.CloseReason != "" {
+			if !sessionCloseReasons[filter.CloseReason] {
+				return nil, fmt.Errorf("invalid close reason %q", filter.CloseReason)
+			}
+			query.CloseReason = filter.CloseReason
+		}
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultSessionPageSize
+	}
+	if pageSize > maxSessionPageSize {
+		pageSize = maxSessionPageSize
+	}
+	total, err := cp.repo.CountTCPSessions(query)
+	if err != nil {
+		return nil, err
+	}
+	query.Page, query.PageSize = page, pageSize
+	sessions, err := cp.repo.ListTCPSessions(query)
+	if err != nil {
+		return nil, err
+	}
+	data := &SessionsData{Total: total, Page: page, PageSize: pageSize, Sessions: make([]*SessionData, len(sessions))}
+	for i, session := range sessions {
+		data.Sessions[i] = transformSession(session)
+	}
+	return data, nil
+}
+
+func transformSession(session *model.TCPSession) *SessionData {
+	return &SessionData{
+		ID:            session.ID,
+		ProxyID:       session.ProxyID,
+		ApplicationID: session.ApplicationID,
+		EdgeID:        session.EdgeID,
+		ClientAddr:    session.ClientAddr,
+		Start:         session.Start,
+		End:           session.End,
+		DurationMs:    session.DurationMs,
+		BytesIn:       session.BytesIn,
+		BytesOut:      session.BytesOut,
+		CloseReason:   session.CloseReason,
+		Detail:        session.Detail,
+	}
+}
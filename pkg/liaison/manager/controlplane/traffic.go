@@ -3,12 +3,14 @@ package controlplane
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
 	"github.com/jumboframes/armorigo/log"
 	v1 "github.com/liaisonio/liaison/api/v1"
 	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/proto"
 )
 
 // 本地时间格式（不带时区信息）
@@ -97,6 +99,9 @@ func (cp *controlPlane) ListTrafficMetrics(_ context.Context, req *v1.ListTraffi
 		Timestamp     time.Time
 		BytesIn       int64
 		BytesOut      int64
+		WireBytesIn   int64
+		WireBytesOut  int64
+		Cache         proto.CacheStats
 		Count         int
 	}
 	aggregated := make(map[string]*aggregatedMetric)
@@ -118,6 +123,12 @@ func (cp *controlPlane) ListTrafficMetrics(_ context.Context, req *v1.ListTraffi
 		if agg, exists := aggregated[key]; exists {
 			agg.BytesIn += metric.BytesIn
 			agg.BytesOut += metric.BytesOut
+			agg.WireBytesIn += metric.WireBytesIn
+			agg.WireBytesOut += metric.WireBytesOut
+			agg.Cache.Hits += metric.CacheHits
+			agg.Cache.Misses += metric.CacheMisses
+			agg.Cache.SavedBytes += metric.CacheSavedBytes
+			agg.Cache.CompressionSavedBytes += metric.CompressionSavedBytes
 			agg.Count++
 		} else {
 			aggregated[key] = &aggregatedMetric{
@@ -126,7 +137,15 @@ func (cp *controlPlane) ListTrafficMetrics(_ context.Context, req *v1.ListTraffi
 				Timestamp:     alignedTime,
 				BytesIn:       metric.BytesIn,
 				BytesOut:      metric.BytesOut,
-				Count:         1,
+				WireBytesIn:   metric.WireBytesIn,
+				WireBytesOut:  metric.WireBytesOut,
+				Cache: proto.CacheStats{
+					Hits:                  metric.CacheHits,
+					Misses:                metric.CacheMisses,
+					SavedBytes:            metric.CacheSavedBytes,
+					CompressionSavedBytes: metric.CompressionSavedBytes,
+				},
+				Count: 1,
 			}
 		}
 	}
@@ -149,6 +168,13 @@ func (cp *controlPlane) ListTrafficMetrics(_ context.Context, req *v1.ListTraffi
 		if avgBytesOut > 2147483647 {
 			bytesOut = 2147483647
 		}
+		wireBytesIn := clampInt32(agg.WireBytesIn / int64(agg.Count))
+		wireBytesOut := clampInt32(agg.WireBytesOut / int64(agg.Count))
+		// 命中率按请求数的合计计算
+		var hitRatio float64
+		if requests := agg.Cache.Hits + agg.Cache.Misses; requests > 0 {
+			hitRatio = float64(agg.Cache.Hits) / float64(requests)
+		}
 
 		// 直接使用数据库返回的时间戳，不进行格式化
 		// 使用time.Time的默认字符串表示（RFC3339格式）
@@ -159,6 +185,14 @@ func (cp *controlPlane) ListTrafficMetrics(_ context.Context, req *v1.ListTraffi
 			Timestamp:     agg.Timestamp.Format(time.RFC3339), // 使用RFC3339格式，这是time.Time的默认JSON序列化格式
 			BytesIn:       bytesIn,
 			BytesOut:      bytesOut,
+			WireBytesIn:   wireBytesIn,
+			WireBytesOut:  wireBytesOut,
+			// 与流量一样取平均值
+			CacheHits:             clampInt32(agg.Cache.Hits / int64(agg.Count)),
+			CacheMisses:           clampInt32(agg.Cache.Misses / int64(agg.Count)),
+			CacheHitRatio:         hitRatio,
+			CacheSavedBytes:       clampInt32(agg.Cache.SavedBytes / int64(agg.Count)),
+			CompressionSavedBytes: clampInt32(agg.Cache.CompressionSavedBytes / int64(agg.Count)),
 		})
 	}
 
@@ -184,3 +218,11 @@ func (cp *controlPlane) ListTrafficMetrics(_ context.Context, req *v1.ListTraffi
 		},
 	}, nil
 }
+
+// clampInt32 转换为 int32，超过最大值时取最大值
+func clampInt32(v int64) int32 {
+	if v > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(v)
+}
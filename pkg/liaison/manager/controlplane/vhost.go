@@ -0,0 +1,91 @@
+package controlplane
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+)
+
+// resolveHostname normalizes a requested proxy hostname. A bare label such as
+// "jellyfin" is expanded under the configured base domain.
+func (cp *controlPlane) resolveHostname(name string) string {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if name == "" {
+		return ""
+	}
+	if base := cp.conf.Manager.VirtualHost.BaseDomain; base != "" && !strings.Contains(name, ".") {
+		name = name + "." + strings.TrimSuffix(strings.ToLower(base), ".")
+	}
+	return name
+}
+
+// validateHostname checks that hostname can be routed to the proxy: only HTTP
+// applications are virtual-hosted and a hostname belongs to one proxy.
+func (cp *controlPlane) validateHostname(proxyID uint, hostname string, application *model.Application) error {
+	if hostname == "" {
+		return nil
+	}
+	if !cp.conf.Manager.VirtualHost.Enable {
+		return fmt.Errorf("virtual hosting is not enabled")
+	}
+	if application.ApplicationType != model.ApplicationTypeHTTP {
+		return fmt.Errorf("hostname is only supported for http applications")
+	}
+	if !validHostname(hostname) {
+		return fmt.Errorf("invalid hostname %q", hostname)
+	}
+	proxies, err := cp.repo.ListProxies(&dao.ListProxiesQuery{
+		Query:    dao.Query{Page: -1, PageSize: -1},
+		Hostname: hostname,
+	})
+	if err != nil {
+		return err
+	}
+	for _, proxy := range proxies {
+		if proxy.ID != proxyID {
+			return fmt.Errorf("hostname %s is already used by proxy %s", hostname, proxy.Name)
+		}
+	}
+	return nil
+}
+
+// validHostname reports whether name is a DNS name made of LDH labels.
+func validHostname(name string) bool {
+	if len(name) > 253 || net.ParseIP(name) != nil {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// vhostAccessURL returns the shared-listener URL of hostname, preferring HTTPS.
+// Empty when virtual hosting is disabled.
+func (cp *controlPlane) vhostAccessURL(hostname string) string {
+	vh := cp.conf.Manager.VirtualHost
+	if !vh.Enable || hostname == "" {
+		return ""
+	}
+	scheme, addr, defaultPort := "https", vh.HTTPSAddr, "443"
+	if addr == "" {
+		scheme, addr, defaultPort = "http", vh.HTTPAddr, "80"
+	}
+	if addr == "" {
+		return ""
+	}
+	if _, port, err := net.SplitHostPort(addr); err == nil && port != defaultPort {
+		return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(hostname, port))
+	}
+	return fmt.Sprintf("%s://%s", scheme, hostname)
+}
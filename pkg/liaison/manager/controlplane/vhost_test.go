@@ -0,0 +1,23 @@
+package controlplane
+
+import "testing"
+
+func TestValidHostname(t *testing.T) {
+	cases := map[string]bool{
+		"jellyfin.liaison.example.com": true,
+		"a-b.example.com":              true,
+		"localhost":                    true,
+		"":                             false,
+		"-bad.example.com":             false,
+		"bad-.example.com":             false,
+		"a..example.com":               false,
+		"under_score.example.com":      false,
+		"*.example.com":                false,
+		"10.0.0.1":                     false,
+	}
+	for name, want := range cases {
+		if got := validHostname(name); got != want {
+			t.Errorf("validHostname(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"math/rand"
 	"net"
+	"sync"
 
 	"github.com/jumboframes/armorigo/log"
 	"github.com/singchia/frontier/api/dataplane/v1/service"
@@ -15,6 +16,8 @@ import (
 
 type FrontierBound interface {
 	EmitScanApplications(ctx context.Context, taskID uint, edgeID uint64, net *Net) error
+	RegisterTargetHealthHandler(handler TargetHealthHandler)
+	EdgeProtocolVersion(edgeID uint64) int
 	Close() error
 }
 
@@ -22,12 +25,17 @@ type frontierBound struct {
 	repo             repo.Repo
 	svc              service.Service
 	trafficCollector interface {
-		RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64)
+		RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64)
 	}
+
+	mu            sync.RWMutex
+	healthHandler TargetHealthHandler
+	// edge ID -> edge 上报的 stream 协议版本
+	protocols map[uint64]int
 }
 
 func NewFrontierBound(conf *config.Configuration, repo repo.Repo, trafficCollector interface {
-	RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64)
+	RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64)
 }) (FrontierBound, error) {
 	dial := conf.Frontier.Dial
 	if len(dial.Addrs) == 0 {
@@ -36,6 +44,7 @@ func NewFrontierBound(conf *config.Configuration, repo repo.Repo, trafficCollect
 	fb := &frontierBound{
 		repo:             repo,
 		trafficCollector: trafficCollector,
+		protocols:        make(map[uint64]int),
 	}
 
 	dialer := func() (net.Conn, error) {
@@ -98,6 +107,16 @@ func NewFrontierBound(conf *config.Configuration, repo repo.Repo, trafficCollect
 		log.Errorf("register update device heartbeat error: %s", err)
 		return nil, err
 	}
+	err = svc.Register(context.Background(), "get_edge_health_checks", fb.getEdgeHealthChecks)
+	if err != nil {
+		log.Errorf("register get edge health checks error: %s", err)
+		return nil, err
+	}
+	err = svc.Register(context.Background(), "report_target_health", fb.reportTargetHealth)
+	if err != nil {
+		log.Errorf("register report target health error: %s", err)
+		return nil, err
+	}
 	// 流量统计已移到entry端，不再需要edge端上报
 
 	fb.svc = svc
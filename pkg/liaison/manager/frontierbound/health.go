@@ -0,0 +1,84 @@
+package frontierbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/proto"
+	"github.com/singchia/geminio"
+)
+
+// TargetHealthHandler 处理 edge 拉取健康检查任务和上报检查结果，由 controlplane 实现
+type TargetHealthHandler interface {
+	GetEdgeHealthChecks(edgeID uint64) ([]proto.HealthCheck, error)
+	ReportTargetHealth(report *proto.ReportTargetHealthRequest) error
+}
+
+var errNoTargetHealthHandler = errors.New("target health handler not registered")
+
+func (fb *frontierBound) RegisterTargetHealthHandler(handler TargetHealthHandler) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	fb.healthHandler = handler
+}
+
+func (fb *frontierBound) targetHealthHandler() TargetHealthHandler {
+	fb.mu.RLock()
+	defer fb.mu.RUnlock()
+	return fb.healthHandler
+}
+
+// 获取 edge 需要执行的健康检查
+func (fb *frontierBound) getEdgeHealthChecks(ctx context.Context, req geminio.Request, rsp geminio.Response) {
+	var request proto.GetEdgeHealthChecksRequest
+	if err := json.Unmarshal(req.Data(), &request); err != nil {
+		rsp.SetError(err)
+		return
+	}
+	// 以连接身份为准，edge 只能拉取自己的任务
+	if clientID := req.ClientID(); clientID > 0 {
+		request.EdgeID = clientID
+	}
+	handler := fb.targetHealthHandler()
+	if handler == nil {
+		rsp.SetError(errNoTargetHealthHandler)
+		return
+	}
+	checks, err := handler.GetEdgeHealthChecks(request.EdgeID)
+	if err != nil {
+		log.Errorf("get edge health checks error: %s, edge_id: %d", err, request.EdgeID)
+		rsp.SetError(err)
+		return
+	}
+	data, err := json.Marshal(proto.GetEdgeHealthChecksResponse{Checks: checks})
+	if err != nil {
+		rsp.SetError(err)
+		return
+	}
+	rsp.SetData(data)
+}
+
+// 上报后端健康状态
+func (fb *frontierBound) reportTargetHealth(ctx context.Context, req geminio.Request, rsp geminio.Response) {
+	var request proto.ReportTargetHealthRequest
+	if err := json.Unmarshal(req.Data(), &request); err != nil {
+		rsp.SetError(err)
+		return
+	}
+	// 以连接身份为准，edge 不能冒充其他 edge 上报
+	if clientID := req.ClientID(); clientID > 0 {
+		request.EdgeID = clientID
+	}
+	handler := fb.targetHealthHandler()
+	if handler == nil {
+		rsp.SetError(errNoTargetHealthHandler)
+		return
+	}
+	if err := handler.ReportTargetHealth(&request); err != nil {
+		log.Errorf("report target health error: %s, edge_id: %d, application_id: %d", err, request.EdgeID, request.ApplicationID)
+		rsp.SetError(err)
+		return
+	}
+}
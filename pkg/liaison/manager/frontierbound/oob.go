@@ -44,6 +44,10 @@ func (fb *frontierBound) online(edgeID uint64, meta []byte, addr net.Addr) error
 	if err != nil {
 		return err
 	}
+	var m proto.Meta
+	if err := json.Unmarshal(meta, &m); err == nil {
+		fb.updateEdgeProtocol(edgeID, m.ProtocolVersion)
+	}
 	// 更新心跳时间
 	fb.updateEdgeHeartbeat(edgeID)
 	return nil
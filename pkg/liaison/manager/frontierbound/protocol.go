@@ -0,0 +1,41 @@
+package frontierbound
+
+import (
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/proto"
+)
+
+// EdgeProtocolVersion edge 的 stream 协议版本，entry 按它和 edge 握手。edge 上线时更新，
+// manager 重启之后从数据库读取；查不到时按老版本处理，老版本 edge 不支持的功能不可用，
+// 但不会等待它不写的 DialResult
+func (fb *frontierBound) EdgeProtocolVersion(edgeID uint64) int {
+	fb.mu.RLock()
+	version, ok := fb.protocols[edgeID]
+	fb.mu.RUnlock()
+	if ok {
+		return version
+	}
+	edge, err := fb.repo.GetEdge(edgeID)
+	if err != nil {
+		log.Warnf("get protocol version of edge %d err: %s", edgeID, err)
+		return proto.ProtocolLegacy
+	}
+	fb.mu.Lock()
+	fb.protocols[edgeID] = edge.ProtocolVersion
+	fb.mu.Unlock()
+	return edge.ProtocolVersion
+}
+
+// updateEdgeProtocol 记录 edge 上线时上报的协议版本
+func (fb *frontierBound) updateEdgeProtocol(edgeID uint64, version int) {
+	fb.mu.Lock()
+	fb.protocols[edgeID] = version
+	fb.mu.Unlock()
+	if version < proto.ProtocolVersion {
+		log.Warnf("edge %d uses stream protocol version %d (current %d), UDP, PROXY protocol, compression "+
+			"and dial errors are unavailable until it is upgraded", edgeID, version, proto.ProtocolVersion)
+	}
+	if err := fb.repo.UpdateEdgeProtocolVersion(edgeID, version); err != nil {
+		log.Errorf("update protocol version of edge %d err: %s", edgeID, err)
+	}
+}
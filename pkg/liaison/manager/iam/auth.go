@@ -71,8 +71,51 @@ type User struct {
 
 // Login 用户登录
 func (s *IAMService) Login(req *LoginRequest, loginIP string) (*LoginResponse, error) {
+	user, err := s.verifyCredentials(req.Email, req.Password, loginIP)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成JWT token
+	token, err := utils.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		Token: token,
+		User:  user,
+	}, nil
+}
+
+// Authenticate 校验登录保护代理的访问者，不签发 token，会话由数据面维护
+func (s *IAMService) Authenticate(email, password, clientIP string) (uint, string, error) {
+	user, err := s.verifyCredentials(email, password, clientIP)
+	if err != nil {
+		return 0, "", err
+	}
+	return user.ID, user.Email, nil
+}
+
+// UserSession 用户是否存在且处于启用状态，以及当前的会话代数。登录保护的代理用来让禁用、
+// 删除或者退出登录的用户的会话失效
+func (s *IAMService) UserSession(userID uint) (uint, bool) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil || user == nil {
+		return 0, false
+	}
+	return user.SessionGeneration, user.Status == model.UserStatusActive
+}
+
+// RevokeUserSessions 递增用户的会话代数，之前签发的登录保护代理会话全部失效
+func (s *IAMService) RevokeUserSessions(userID uint) error {
+	return s.repo.IncrUserSessionGeneration(userID)
+}
+
+// verifyCredentials 校验邮箱、密码和用户状态，成功后记录登录时间和IP
+func (s *IAMService) verifyCredentials(email, password, loginIP string) (*model.User, error) {
 	// 获取用户
-	user, err := s.repo.GetUserByEmail(req.Email)
+	user, err := s.repo.GetUserByEmail(email)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +129,7 @@ func (s *IAMService) Login(req *LoginRequest, loginIP string) (*LoginResponse, e
 	}
 
 	// 验证密码
-	valid, err := utils.VerifyPassword(req.Password, user.Password)
+	valid, err := utils.VerifyPassword(password, user.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -98,17 +141,7 @@ func (s *IAMService) Login(req *LoginRequest, loginIP string) (*LoginResponse, e
 	if err := s.repo.UpdateUserLastLoginAndIP(user.ID, loginIP); err != nil {
 		log.Errorf("Failed to update last login time and IP: %v", err)
 	}
-
-	// 生成JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		return nil, err
-	}
-
-	return &LoginResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	return user, nil
 }
 
 // CreateDefaultUser 创建默认用户账户
@@ -8,6 +8,7 @@ import (
 	"github.com/jumboframes/armorigo/log"
 	"github.com/liaisonio/liaison/pkg/liaison/repo/dao"
 	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/proto"
 )
 
 // TrafficCollector 流量统计收集器
@@ -25,6 +26,9 @@ type trafficStats struct {
 	ApplicationID uint
 	BytesIn       int64
 	BytesOut      int64
+	WireBytesIn   int64
+	WireBytesOut  int64
+	Cache         proto.CacheStats
 }
 
 // NewTrafficCollector 创建流量统计收集器
@@ -42,7 +46,8 @@ func NewTrafficCollector(repo dao.Dao) *TrafficCollector {
 }
 
 // RecordTraffic 记录流量（线程安全）
-func (tc *TrafficCollector) RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut int64) {
+// bytesIn/bytesOut 为客户端与后端之间的流量，wireBytesIn/wireBytesOut 为 entry 与 edge 之间压缩后实际传输的流量
+func (tc *TrafficCollector) RecordTraffic(proxyID, applicationID uint, bytesIn, bytesOut, wireBytesIn, wireBytesOut int64) {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
@@ -58,6 +63,29 @@ func (tc *TrafficCollector) RecordTraffic(proxyID, applicationID uint, bytesIn,
 
 	stats.BytesIn += bytesIn
 	stats.BytesOut += bytesOut
+	stats.WireBytesIn += wireBytesIn
+	stats.WireBytesOut += wireBytesOut
+}
+
+// RecordCacheStats 记录 HTTP 响应缓存和压缩的效果（线程安全），与流量一起落盘
+func (tc *TrafficCollector) RecordCacheStats(proxyID, applicationID uint, cache proto.CacheStats) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	key := trafficKey(proxyID, applicationID)
+	stats, exists := tc.stats[key]
+	if !exists {
+		stats = &trafficStats{
+			ProxyID:       proxyID,
+			ApplicationID: applicationID,
+		}
+		tc.stats[key] = stats
+	}
+
+	stats.Cache.Hits += cache.Hits
+	stats.Cache.Misses += cache.Misses
+	stats.Cache.SavedBytes += cache.SavedBytes
+	stats.Cache.CompressionSavedBytes += cache.CompressionSavedBytes
 }
 
 // flushLoop 每分钟落盘一次
@@ -93,6 +121,9 @@ func (tc *TrafficCollector) flush() {
 			ApplicationID: stats.ApplicationID,
 			BytesIn:       stats.BytesIn,
 			BytesOut:      stats.BytesOut,
+			WireBytesIn:   stats.WireBytesIn,
+			WireBytesOut:  stats.WireBytesOut,
+			Cache:         stats.Cache,
 		})
 	}
 
@@ -106,11 +137,17 @@ func (tc *TrafficCollector) flush() {
 
 	for _, stats := range statsToFlush {
 		metric := &model.TrafficMetric{
-			ApplicationID: stats.ApplicationID,
-			ProxyID:       stats.ProxyID,
-			Timestamp:     now,
-			BytesIn:       stats.BytesIn,
-			BytesOut:      stats.BytesOut,
+			ApplicationID:         stats.ApplicationID,
+			ProxyID:               stats.ProxyID,
+			Timestamp:             now,
+			BytesIn:               stats.BytesIn,
+			BytesOut:              stats.BytesOut,
+			WireBytesIn:           stats.WireBytesIn,
+			WireBytesOut:          stats.WireBytesOut,
+			CacheHits:             stats.Cache.Hits,
+			CacheMisses:           stats.Cache.Misses,
+			CacheSavedBytes:       stats.Cache.SavedBytes,
+			CompressionSavedBytes: stats.Cache.CompressionSavedBytes,
 		}
 
 		if err := tc.repo.CreateTrafficMetric(metric); err != nil {
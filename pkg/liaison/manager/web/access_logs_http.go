@@ -0,0 +1,114 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jumboframes/armorigo/log"
+	"github.com/liaisonio/liaison/pkg/liaison/manager/controlplane"
+)
+
+// accessLogFilter reads the filter of the access log endpoints from the
+// query string: start, end, status (e.g. 5xx), client_ip and path_prefix.
+func accessLogFilter(r *http.Request) *controlplane.AccessLogFilter {
+	q := r.URL.Query()
+	return &controlplane.AccessLogFilter{
+		Start:      q.Get("start"),
+		End:        q.Get("end"),
+		Status:     q.Get("status"),
+		ClientIP:   q.Get("client_ip"),
+		PathPrefix: q.Get("path_prefix"),
+	}
+}
+
+// handleProxyAccessLogsHTTP serves GET /api/v1/proxies/{id}/access_logs,
+// a page of the access logs of an HTTP proxy, newest first.
+func (web *web) handleProxyAccessLogsHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, err := parseProxySubresourceID(r, "/access_logs")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid proxy id"})
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	data, err := web.controlPlane.ListProxyAccessLogs(ctx, proxyID, accessLogFilter(r), page, pageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
+
+// handleProxyAccessLogsExportHTTP serves GET
+// /api/v1/proxies/{id}/access_logs/export, every access log matching the
+// filter as JSON Lines, newest first. The body is streamed, so an error after
+// the first line can only be reported by cutting the response short.
+func (web *web) handleProxyAccessLogsExportHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, err := parseProxySubresourceID(r, "/access_logs/export")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid proxy id"})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	// 响应头在第一条日志之前写出，在此之前的错误（参数、代理不存在）仍然以 JSON 返回
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	started := false
+	start := func() {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="proxy-%d-access-logs.jsonl"`, proxyID))
+		w.WriteHeader(http.StatusOK)
+		started = true
+	}
+	err = web.controlPlane.ExportProxyAccessLogs(ctx, proxyID, accessLogFilter(r), func(data *controlplane.AccessLogData) error {
+		if !started {
+			start()
+		}
+		return encoder.Encode(data)
+	})
+	if err != nil {
+		if !started {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+			return
+		}
+		log.Warnf("export access logs of proxy %d interrupted: %s", proxyID, err)
+		return
+	}
+	if !started {
+		start()
+	}
+	if err := bw.Flush(); err != nil {
+		log.Debugf("export access logs of proxy %d: %s", proxyID, err)
+	}
+}
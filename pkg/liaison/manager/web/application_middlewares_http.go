@@ -0,0 +1,73 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/liaisonio/liaison/pkg/liaison/manager/controlplane"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+)
+
+type updateApplicationMiddlewaresRequest struct {
+	Middlewares []model.ApplicationMiddleware `json:"middlewares"`
+}
+
+// handleMiddlewaresHTTP serves GET /api/v1/middlewares, the middleware
+// plugins that applications can enable.
+func (web *web) handleMiddlewaresHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, err := web.authenticateHTTP(r); err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": web.controlPlane.ListMiddlewares(r.Context())})
+}
+
+// handleApplicationMiddlewaresHTTP dispatches GET/PUT on
+// /api/v1/applications/{id}/middlewares, the middlewares enabled on an HTTP
+// application. PUT replaces the whole list.
+func (web *web) handleApplicationMiddlewaresHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	applicationID, err := parseApplicationSubresourceID(r, "/middlewares")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid application id"})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	var data *controlplane.ApplicationMiddlewaresData
+	switch r.Method {
+	case http.MethodGet:
+		data, err = web.controlPlane.GetApplicationMiddlewares(ctx, applicationID)
+	case http.MethodPut:
+		var req updateApplicationMiddlewaresRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid request body"})
+			return
+		}
+		data, err = web.controlPlane.UpdateApplicationMiddlewares(ctx, applicationID, req.Middlewares)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
@@ -0,0 +1,144 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/liaisonio/liaison/pkg/liaison/manager/controlplane"
+)
+
+// bindCertificateRequest is the body of PUT /api/v1/proxies/{id}/certificate.
+type bindCertificateRequest struct {
+	CertificateID uint `json:"certificate_id"`
+}
+
+// handleCertificatesHTTP dispatches GET (list) and POST (upload) on /api/v1/certificates.
+func (web *web) handleCertificatesHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	switch r.Method {
+	case http.MethodGet:
+		data, err := web.controlPlane.ListCertificates(ctx)
+		writeCertificateResult(w, map[string]any{"certificates": data}, err)
+	case http.MethodPost:
+		var req controlplane.CertificateParams
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid request body"})
+			return
+		}
+		data, err := web.controlPlane.CreateCertificate(ctx, &req)
+		writeCertificateResult(w, data, err)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+	}
+}
+
+// handleCertificateByIDHTTP dispatches GET/PUT (rename or rotate)/DELETE on
+// /api/v1/certificates/{id}.
+func (web *web) handleCertificateByIDHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	id, err := parseCertificateID(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid certificate id"})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	switch r.Method {
+	case http.MethodGet:
+		data, err := web.controlPlane.GetCertificate(ctx, id)
+		writeCertificateResult(w, data, err)
+	case http.MethodPut:
+		var req controlplane.CertificateParams
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid request body"})
+			return
+		}
+		data, err := web.controlPlane.UpdateCertificate(ctx, id, &req)
+		writeCertificateResult(w, data, err)
+	case http.MethodDelete:
+		err := web.controlPlane.DeleteCertificate(ctx, id)
+		writeCertificateResult(w, nil, err)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+	}
+}
+
+// handleProxyCertificateHTTP dispatches GET/PUT/DELETE on
+// /api/v1/proxies/{id}/certificate. DELETE removes the binding.
+func (web *web) handleProxyCertificateHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, err := parseProxySubresourceID(r, "/certificate")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid proxy id"})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	switch r.Method {
+	case http.MethodGet:
+		data, err := web.controlPlane.GetProxyCertificate(ctx, proxyID)
+		writeCertificateResult(w, data, err)
+	case http.MethodPut:
+		var req bindCertificateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid request body"})
+			return
+		}
+		data, err := web.controlPlane.BindProxyCertificate(ctx, proxyID, req.CertificateID)
+		writeCertificateResult(w, data, err)
+	case http.MethodDelete:
+		data, err := web.controlPlane.BindProxyCertificate(ctx, proxyID, 0)
+		writeCertificateResult(w, data, err)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+	}
+}
+
+func writeCertificateResult(w http.ResponseWriter, data any, err error) {
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	body := map[string]any{"code": 200, "message": "success"}
+	if data != nil {
+		body["data"] = data
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// parseCertificateID extracts {id} from /api/v1/certificates/{id}.
+func parseCertificateID(r *http.Request) (uint, error) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/certificates/")
+	id, err := strconv.ParseUint(path, 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid certificate id")
+	}
+	return uint(id), nil
+}
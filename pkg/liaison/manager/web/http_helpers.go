@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/liaisonio/liaison/pkg/liaison/manager/iam"
@@ -67,3 +68,25 @@ func writeUnauthorized(w http.ResponseWriter) {
 		"message": "unauthorized",
 	})
 }
+
+// parseProxySubresourceID extracts {id} from /api/v1/proxies/{id}<suffix>.
+func parseProxySubresourceID(r *http.Request, suffix string) (uint, error) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/proxies/")
+	path = strings.TrimSuffix(path, suffix)
+	id, err := strconv.ParseUint(path, 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid proxy id")
+	}
+	return uint(id), nil
+}
+
+// parseApplicationSubresourceID extracts {id} from /api/v1/applications/{id}<suffix>.
+func parseApplicationSubresourceID(r *http.Request, suffix string) (uint, error) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/applications/")
+	path = strings.TrimSuffix(path, suffix)
+	id, err := strconv.ParseUint(path, 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid application id")
+	}
+	return uint(id), nil
+}
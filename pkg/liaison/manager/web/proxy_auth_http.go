@@ -0,0 +1,49 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/liaisonio/liaison/pkg/liaison/manager/controlplane"
+)
+
+// handleProxyAuthHTTP dispatches GET/PUT on /api/v1/proxies/{id}/auth, the
+// login protection of an HTTP proxy.
+func (web *web) handleProxyAuthHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, err := parseProxySubresourceID(r, "/auth")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid proxy id"})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	var data *controlplane.ProxyAuthData
+	switch r.Method {
+	case http.MethodGet:
+		data, err = web.controlPlane.GetProxyAuth(ctx, proxyID)
+	case http.MethodPut:
+		var req controlplane.ProxyAuthParams
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid request body"})
+			return
+		}
+		data, err = web.controlPlane.UpdateProxyAuth(ctx, proxyID, &req)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
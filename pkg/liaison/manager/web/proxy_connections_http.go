@@ -0,0 +1,52 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// handleProxyConnectionsHTTP dispatches GET/DELETE on
+// /api/v1/proxies/{id}/connections, the live client connections of a proxy.
+// DELETE disconnects the connection given by ?id= or every connection from
+// ?client_ip=.
+func (web *web) handleProxyConnectionsHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, err := parseProxySubresourceID(r, "/connections")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid proxy id"})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	var data any
+	switch r.Method {
+	case http.MethodGet:
+		data, err = web.controlPlane.ListProxyConnections(ctx, proxyID)
+	case http.MethodDelete:
+		var connectionID uint64
+		if id := r.URL.Query().Get("id"); id != "" {
+			connectionID, err = strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid connection id"})
+				return
+			}
+		}
+		data, err = web.controlPlane.CloseProxyConnections(ctx, proxyID, connectionID, r.URL.Query().Get("client_ip"))
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
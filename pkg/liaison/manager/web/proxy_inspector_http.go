@@ -0,0 +1,122 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// handleProxyInspectedRequestsHTTP serves GET
+// /api/v1/proxies/{id}/inspector/requests, the requests captured by the
+// inspector of an HTTP proxy, newest first, without headers and bodies.
+func (web *web) handleProxyInspectedRequestsHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, err := parseProxySubresourceID(r, "/inspector/requests")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid proxy id"})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	data, err := web.controlPlane.ListProxyInspectedRequests(ctx, proxyID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
+
+// handleProxyInspectedRequestHTTP serves GET
+// /api/v1/proxies/{id}/inspector/requests/{request_id}, one captured request
+// with its headers and bodies. Bodies are base64 encoded.
+func (web *web) handleProxyInspectedRequestHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, requestID, err := parseInspectedRequestPath(r, "")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	data, err := web.controlPlane.GetProxyInspectedRequest(ctx, proxyID, requestID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
+
+// handleProxyInspectedRequestReplayHTTP serves POST
+// /api/v1/proxies/{id}/inspector/requests/{request_id}/replay, which sends
+// the captured request to the backend again and returns the new capture.
+func (web *web) handleProxyInspectedRequestReplayHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, requestID, err := parseInspectedRequestPath(r, "/replay")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	data, err := web.controlPlane.ReplayProxyInspectedRequest(ctx, proxyID, requestID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
+
+// parseInspectedRequestPath extracts {id} and {request_id} from
+// /api/v1/proxies/{id}/inspector/requests/{request_id}<suffix>.
+func parseInspectedRequestPath(r *http.Request, suffix string) (uint, uint64, error) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/proxies/")
+	path = strings.TrimSuffix(path, suffix)
+	proxy, request, ok := strings.Cut(path, "/inspector/requests/")
+	if !ok {
+		return 0, 0, errors.New("invalid request path")
+	}
+	proxyID, err := strconv.ParseUint(proxy, 10, 32)
+	if err != nil || proxyID == 0 {
+		return 0, 0, errors.New("invalid proxy id")
+	}
+	requestID, err := strconv.ParseUint(request, 10, 64)
+	if err != nil || requestID == 0 {
+		return 0, 0, errors.New("invalid request id")
+	}
+	return uint(proxyID), requestID, nil
+}
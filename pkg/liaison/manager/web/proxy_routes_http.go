@@ -0,0 +1,54 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/liaisonio/liaison/pkg/liaison/manager/controlplane"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+)
+
+type updateProxyRoutesRequest struct {
+	Routes []model.ProxyRoute `json:"routes"`
+}
+
+// handleProxyRoutesHTTP dispatches GET/PUT on /api/v1/proxies/{id}/routes,
+// the path routes of an HTTP proxy. PUT replaces the whole list.
+func (web *web) handleProxyRoutesHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	proxyID, err := parseProxySubresourceID(r, "/routes")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid proxy id"})
+		return
+	}
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	var data *controlplane.ProxyRoutesData
+	switch r.Method {
+	case http.MethodGet:
+		data, err = web.controlPlane.GetProxyRoutes(ctx, proxyID)
+	case http.MethodPut:
+		var req updateProxyRoutesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid request body"})
+			return
+		}
+		data, err = web.controlPlane.UpdateProxyRoutes(ctx, proxyID, req.Routes)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/liaisonio/liaison/pkg/liaison/manager/controlplane"
+)
+
+// sessionFilter reads the filter of the session endpoints from the query
+// string: start, end, client_ip and close_reason.
+func sessionFilter(r *http.Request) *controlplane.SessionFilter {
+	q := r.URL.Query()
+	return &controlplane.SessionFilter{
+		Start:       q.Get("start"),
+		End:         q.Get("end"),
+		ClientIP:    q.Get("client_ip"),
+		CloseReason: q.Get("close_reason"),
+	}
+}
+
+// handleProxySessionsHTTP serves GET /api/v1/proxies/{id}/sessions, a page
+// of the session records of a TCP proxy.
+func (web *web) handleProxySessionsHTTP(w http.ResponseWriter, r *http.Request) {
+	web.handleSessionsHTTP(w, r, "proxy", parseProxySubresourceID, web.controlPlane.ListProxySessions)
+}
+
+// handleApplicationSessionsHTTP serves GET /api/v1/applications/{id}/sessions,
+// a page of the session records of every TCP proxy of an application.
+func (web *web) handleApplicationSessionsHTTP(w http.ResponseWriter, r *http.Request) {
+	web.handleSessionsHTTP(w, r, "application", parseApplicationSubresourceID, web.controlPlane.ListApplicationSessions)
+}
+
+func (web *web) handleSessionsHTTP(w http.ResponseWriter, r *http.Request, kind string,
+	parseID func(*http.Request, string) (uint, error),
+	list func(context.Context, uint, *controlplane.SessionFilter, int, int) (*controlplane.SessionsData, error)) {
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	user, err := web.authenticateHTTP(r)
+	if err != nil {
+		writeUnauthorized(w)
+		return
+	}
+	id, err := parseID(r, "/sessions")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": "invalid " + kind + " id"})
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	ctx := context.WithValue(r.Context(), "user_id", user.ID)
+	data, err := list(ctx, id, sessionFilter(r), page, pageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": 200, "message": "success", "data": data})
+}
@@ -67,6 +67,34 @@ func NewWebServer(conf *config.Configuration, controlPlane controlplane.ControlP
 
 	// 代理防火墙
 	srv.HandleFunc("/api/v1/proxies/{id}/firewall", web.handleFirewallHTTP)
+	// 代理可选配置（PROXY protocol 等）
+	srv.HandleFunc("/api/v1/proxies/{id}/options", web.handleProxyOptionsHTTP)
+	srv.HandleFunc("/api/v1/proxies/{id}/stats", web.handleProxyStatsHTTP)
+	// 证书管理与代理绑定
+	srv.HandleFunc("/api/v1/certificates", web.handleCertificatesHTTP)
+	srv.HandleFunc("/api/v1/certificates/{id}", web.handleCertificateByIDHTTP)
+	srv.HandleFunc("/api/v1/proxies/{id}/certificate", web.handleProxyCertificateHTTP)
+	// 代理登录保护
+	srv.HandleFunc("/api/v1/proxies/{id}/auth", web.handleProxyAuthHTTP)
+	// 代理 HTTP 头改写
+	srv.HandleFunc("/api/v1/proxies/{id}/headers", web.handleProxyHeadersHTTP)
+	// 代理按路径路由到多个应用
+	srv.HandleFunc("/api/v1/proxies/{id}/routes", web.handleProxyRoutesHTTP)
+	// HTTP 代理访问日志
+	srv.HandleFunc("/api/v1/proxies/{id}/access_logs", web.handleProxyAccessLogsHTTP)
+	srv.HandleFunc("/api/v1/proxies/{id}/access_logs/export", web.handleProxyAccessLogsExportHTTP)
+	// TCP 代理会话记录
+	srv.HandleFunc("/api/v1/proxies/{id}/sessions", web.handleProxySessionsHTTP)
+	srv.HandleFunc("/api/v1/applications/{id}/sessions", web.handleApplicationSessionsHTTP)
+	// 代理的活跃连接，可以断开
+	srv.HandleFunc("/api/v1/proxies/{id}/connections", web.handleProxyConnectionsHTTP)
+	// HTTP 代理的请求检查器
+	srv.HandleFunc("/api/v1/proxies/{id}/inspector/requests", web.handleProxyInspectedRequestsHTTP)
+	srv.HandleFunc("/api/v1/proxies/{id}/inspector/requests/{request_id}", web.handleProxyInspectedRequestHTTP)
+	srv.HandleFunc("/api/v1/proxies/{id}/inspector/requests/{request_id}/replay", web.handleProxyInspectedRequestReplayHTTP)
+	// 应用的 HTTP 中间件
+	srv.HandleFunc("/api/v1/middlewares", web.handleMiddlewaresHTTP)
+	srv.HandleFunc("/api/v1/applications/{id}/middlewares", web.handleApplicationMiddlewaresHTTP)
 
 	// 文件服务
 	err = web.serveFiles(conf, srv)
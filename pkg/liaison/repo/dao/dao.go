@@ -28,6 +28,7 @@ type Dao interface {
 	UpdateEdge(edge *model.Edge) error
 	UpdateEdgeOnlineStatus(edgeID uint64, onlineStatus model.EdgeOnlineStatus) error
 	UpdateEdgeHeartbeatAt(edgeID uint64, heartbeatAt time.Time) error
+	UpdateEdgeProtocolVersion(edgeID uint64, version int) error
 	UpdateEdgeDeviceID(edgeID uint64, deviceID uint) error
 	DeleteEdge(id uint64) error
 
@@ -67,6 +68,7 @@ type Dao interface {
 	ListApplications(query *ListApplicationsQuery) ([]*model.Application, error)
 	CountApplications(query *ListApplicationsQuery) (int64, error)
 	UpdateApplication(application *model.Application) error
+	UpdateApplicationMiddlewares(id uint, middlewares model.ApplicationMiddlewares) error
 	DeleteApplication(id uint) error
 
 	// Proxy 相关方法
@@ -75,6 +77,11 @@ type Dao interface {
 	ListProxies(query *ListProxiesQuery) ([]*model.Proxy, error)
 	CountProxies(query *ListProxiesQuery) (int64, error)
 	UpdateProxy(proxy *model.Proxy) error
+	UpdateProxyOptions(id uint, options model.ProxyOptions) error
+	UpdateProxyHostname(id uint, hostname string) error
+	UpdateProxyAuth(id uint, required bool, allowedUsers []string) error
+	UpdateProxyHeaderRules(id uint, rules model.HeaderRules) error
+	UpdateProxyRoutes(id uint, routes model.ProxyRoutes) error
 	DeleteProxy(id uint) error
 
 	// Task 相关方法
@@ -93,6 +100,7 @@ type Dao interface {
 	UpdateUser(user *model.User) error
 	UpdateUserLastLogin(userID uint) error
 	UpdateUserLastLoginAndIP(userID uint, loginIP string) error
+	IncrUserSessionGeneration(userID uint) error
 	ListUsers(offset, limit int) ([]*model.User, int64, error)
 	DeleteUser(id uint) error
 	CheckUserExists(email string) (bool, error)
@@ -102,6 +110,20 @@ type Dao interface {
 	ListTrafficMetrics(query *ListTrafficMetricsQuery) ([]*model.TrafficMetric, error)
 	GetTrafficMetricsByTimeRange(startTime, endTime time.Time, applicationIDs []uint) ([]*model.TrafficMetric, error)
 
+	// AccessLog 相关方法
+	CreateAccessLogs(logs []*model.AccessLog) error
+	ListAccessLogs(query *ListAccessLogsQuery) ([]*model.AccessLog, error)
+	CountAccessLogs(query *ListAccessLogsQuery) (int64, error)
+	DeleteAccessLogsBefore(before time.Time) (int64, error)
+	TrimAccessLogs(keep int) (int64, error)
+
+	// TCPSession 相关方法
+	CreateTCPSessions(sessions []*model.TCPSession) error
+	ListTCPSessions(query *ListTCPSessionsQuery) ([]*model.TCPSession, error)
+	CountTCPSessions(query *ListTCPSessionsQuery) (int64, error)
+	DeleteTCPSessionsBefore(before time.Time) (int64, error)
+	TrimTCPSessions(keep int) (int64, error)
+
 	// UserAPIToken (PAT) 相关方法
 	CreateUserAPIToken(tok *model.UserAPIToken) error
 	ListUserAPITokens(userID uint) ([]*model.UserAPIToken, error)
@@ -117,6 +139,14 @@ type Dao interface {
 	ListFirewallRulesByUserID(userID uint) ([]*model.ProxyFirewallRule, error)
 	ListAllFirewallRules() ([]*model.ProxyFirewallRule, error)
 
+	// Certificate 相关方法
+	CreateCertificate(cert *model.Certificate) error
+	GetCertificateByID(id uint) (*model.Certificate, error)
+	ListCertificates() ([]*model.Certificate, error)
+	UpdateCertificate(cert *model.Certificate) error
+	DeleteCertificate(id uint) error
+	UpdateProxyCertificate(proxyID, certificateID uint) error
+
 	// 资源清理
 	Close() error
 }
@@ -161,7 +191,10 @@ func NewDao(config *config.Configuration) (Dao, error) {
 }
 
 func (d *dao) initDB() error {
-	return d.db.AutoMigrate(
+	// 升级前的 HTTP 应用固定执行 Jellyfin 转码改写，新增 middlewares 列时为它们启用对应的中间件
+	migrateMiddlewares := d.db.Migrator().HasTable(&model.Application{}) &&
+		!d.db.Migrator().HasColumn(&model.Application{}, "middlewares")
+	err := d.db.AutoMigrate(
 		&model.Edge{},
 		&model.AccessKey{},
 		&model.Device{},
@@ -172,9 +205,19 @@ func (d *dao) initDB() error {
 		&model.Task{},
 		&model.User{},
 		&model.TrafficMetric{},
+		&model.AccessLog{},
+		&model.TCPSession{},
 		&model.UserAPIToken{},
 		&model.ProxyFirewallRule{},
+		&model.Certificate{},
 	)
+	if err != nil {
+		return err
+	}
+	if migrateMiddlewares {
+		return d.enableLegacyMiddlewares()
+	}
+	return nil
 }
 
 // Begin 开始事务 - 返回新的事务 DAO 实例
@@ -282,6 +325,12 @@ func (d *dao) UpdateUserLastLoginAndIP(userID uint, loginIP string) error {
 	}).Error
 }
 
+// IncrUserSessionGeneration 递增用户的会话代数，使登录保护代理上已有的会话失效
+func (d *dao) IncrUserSessionGeneration(userID uint) error {
+	return d.getDB().Model(&model.User{}).Where("id = ?", userID).
+		Update("session_generation", gorm.Expr("session_generation + 1")).Error
+}
+
 func (d *dao) ListUsers(offset, limit int) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"strings"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"gorm.io/gorm"
+)
+
+// CreateAccessLogs 批量写入访问日志
+func (d *dao) CreateAccessLogs(logs []*model.AccessLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return d.getDB().CreateInBatches(logs, 200).Error
+}
+
+// ListAccessLogs 按时间倒序查询访问日志，BeforeID 非 0 时只返回更早写入的记录，用于分批导出
+func (d *dao) ListAccessLogs(query *ListAccessLogsQuery) ([]*model.AccessLog, error) {
+	db := d.accessLogsWhere(query)
+	if query.BeforeID != 0 {
+		db = db.Where("id < ?", query.BeforeID)
+	}
+	if query.Page > 0 && query.PageSize > 0 {
+		db = db.Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize)
+	} else if query.PageSize > 0 {
+		db = db.Limit(query.PageSize)
+	}
+	var logs []*model.AccessLog
+	err := db.Order("id DESC").Find(&logs).Error
+	return logs, err
+}
+
+// CountAccessLogs 统计符合条件的访问日志数量
+func (d *dao) CountAccessLogs(query *ListAccessLogsQuery) (int64, error) {
+	var count int64
+	err := d.accessLogsWhere(query).Model(&model.AccessLog{}).Count(&count).Error
+	return count, err
+}
+
+func (d *dao) accessLogsWhere(query *ListAccessLogsQuery) *gorm.DB {
+	db := d.getDB()
+	if query.ProxyID != 0 {
+		db = db.Where("proxy_id = ?", query.ProxyID)
+	}
+	if query.Start != nil {
+		db = db.Where("time >= ?", *query.Start)
+	}
+	if query.End != nil {
+		db = db.Where("time <= ?", *query.End)
+	}
+	if query.StatusClass != 0 {
+		db = db.Where("status >= ? AND status < ?", query.StatusClass*100, (query.StatusClass+1)*100)
+	}
+	if query.ClientIP != "" {
+		db = db.Where("client_ip = ?", query.ClientIP)
+	}
+	if query.PathPrefix != "" {
+		db = db.Where(`path LIKE ? ESCAPE '\'`, escapeLike(query.PathPrefix)+"%")
+	}
+	return db
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// DeleteAccessLogsBefore 删除早于 before 的访问日志
+func (d *dao) DeleteAccessLogsBefore(before time.Time) (int64, error) {
+	result := d.getDB().Where("time < ?", before).Delete(&model.AccessLog{})
+	return result.RowsAffected, result.Error
+}
+
+// TrimAccessLogs 只保留最新写入的 keep 条访问日志
+func (d *dao) TrimAccessLogs(keep int) (int64, error) {
+	var ids []uint
+	err := d.getDB().Model(&model.AccessLog{}).Order("id DESC").Offset(keep).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	result := d.getDB().Where("id <= ?", ids[0]).Delete(&model.AccessLog{})
+	return result.RowsAffected, result.Error
+}
@@ -1,6 +1,9 @@
 package dao
 
-import "github.com/liaisonio/liaison/pkg/liaison/repo/model"
+import (
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/middleware"
+)
 
 func (d *dao) CreateApplication(application *model.Application) error {
 	return d.getDB().Create(application).Error
@@ -22,6 +25,19 @@ func (d *dao) UpdateApplication(application *model.Application) error {
 	return d.getDB().Save(application).Error
 }
 
+// UpdateApplicationMiddlewares 整体替换应用启用的 HTTP 中间件
+func (d *dao) UpdateApplicationMiddlewares(id uint, middlewares model.ApplicationMiddlewares) error {
+	return d.getDB().Model(&model.Application{}).Where("id = ?", id).Update("middlewares", middlewares).Error
+}
+
+// enableLegacyMiddlewares 为已有的 HTTP 应用启用 jellyfin_transcode，保持升级前的行为
+func (d *dao) enableLegacyMiddlewares() error {
+	middlewares := model.ApplicationMiddlewares{{Name: middleware.JellyfinTranscode}}
+	return d.getDB().Model(&model.Application{}).
+		Where("application_type = ?", model.ApplicationTypeHTTP).
+		Update("middlewares", middlewares).Error
+}
+
 func (d *dao) CountApplications(query *ListApplicationsQuery) (int64, error) {
 	db := d.getDB()
 	if len(query.DeviceIDs) > 0 {
@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+)
+
+func (d *dao) CreateCertificate(cert *model.Certificate) error {
+	return d.getDB().Create(cert).Error
+}
+
+func (d *dao) GetCertificateByID(id uint) (*model.Certificate, error) {
+	var cert model.Certificate
+	if err := d.getDB().Where("id = ?", id).First(&cert).Error; err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+func (d *dao) ListCertificates() ([]*model.Certificate, error) {
+	var certs []*model.Certificate
+	if err := d.getDB().Order("id ASC").Find(&certs).Error; err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+// UpdateCertificate 更新名称、证书和私钥，轮换证书时整体替换
+func (d *dao) UpdateCertificate(cert *model.Certificate) error {
+	return d.getDB().Model(&model.Certificate{}).Where("id = ?", cert.ID).Updates(map[string]interface{}{
+		"name":       cert.Name,
+		"cert_pem":   cert.CertPEM,
+		"key_pem":    cert.KeyPEM,
+		"domains":    cert.Domains,
+		"not_before": cert.NotBefore,
+		"not_after":  cert.NotAfter,
+	}).Error
+}
+
+func (d *dao) DeleteCertificate(id uint) error {
+	return d.getDB().Delete(&model.Certificate{}, id).Error
+}
+
+// UpdateProxyCertificate 绑定代理的证书，0 表示解除绑定
+func (d *dao) UpdateProxyCertificate(proxyID, certificateID uint) error {
+	return d.getDB().Model(&model.Proxy{}).Where("id = ?", proxyID).Update("certificate_id", certificateID).Error
+}
@@ -121,6 +121,10 @@ func (d *dao) UpdateEdgeHeartbeatAt(edgeID uint64, heartbeatAt time.Time) error
 	return d.getDB().Model(&model.Edge{}).Where("id = ?", edgeID).Update("heartbeat_at", heartbeatAt).Error
 }
 
+func (d *dao) UpdateEdgeProtocolVersion(edgeID uint64, version int) error {
+	return d.getDB().Model(&model.Edge{}).Where("id = ?", edgeID).Update("protocol_version", version).Error
+}
+
 func (d *dao) UpdateEdgeDeviceID(edgeID uint64, deviceID uint) error {
 	return d.getDB().Model(&model.Edge{}).Where("id = ?", edgeID).Update("device_id", deviceID).Error
 }
@@ -24,6 +24,12 @@ func (d *dao) CountProxies(query *ListProxiesQuery) (int64, error) {
 		if len(query.ApplicationIDs) > 0 {
 			db = db.Where("application_id IN ?", query.ApplicationIDs)
 		}
+		if query.Hostname != "" {
+			db = db.Where("hostname = ?", query.Hostname)
+		}
+		if query.CertificateID != 0 {
+			db = db.Where("certificate_id = ?", query.CertificateID)
+		}
 	}
 	if err := db.Model(&model.Proxy{}).Count(&count).Error; err != nil {
 		return 0, err
@@ -46,6 +52,12 @@ func (d *dao) ListProxies(query *ListProxiesQuery) ([]*model.Proxy, error) {
 	if query.Name != "" {
 		db = db.Where("name LIKE ?", "%"+query.Name+"%")
 	}
+	if query.Hostname != "" {
+		db = db.Where("hostname = ?", query.Hostname)
+	}
+	if query.CertificateID != 0 {
+		db = db.Where("certificate_id = ?", query.CertificateID)
+	}
 	// 应用排序
 	if query.Order != "" {
 		if query.Desc {
@@ -78,6 +90,34 @@ func (d *dao) UpdateProxy(proxy *model.Proxy) error {
 	return d.getDB().Model(&model.Proxy{}).Where("id = ?", proxy.ID).Updates(updates).Error
 }
 
+// UpdateProxyHostname 设置代理的主机名，空字符串表示清除
+func (d *dao) UpdateProxyHostname(id uint, hostname string) error {
+	return d.getDB().Model(&model.Proxy{}).Where("id = ?", id).Update("hostname", hostname).Error
+}
+
+// UpdateProxyOptions 整体替换代理的可选配置
+func (d *dao) UpdateProxyOptions(id uint, options model.ProxyOptions) error {
+	return d.getDB().Model(&model.Proxy{}).Where("id = ?", id).Update("options", options).Error
+}
+
+// UpdateProxyAuth 设置代理的登录保护和允许访问的用户
+func (d *dao) UpdateProxyAuth(id uint, required bool, allowedUsers []string) error {
+	return d.getDB().Model(&model.Proxy{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"auth_required":      required,
+		"auth_allowed_users": model.StringSlice(allowedUsers),
+	}).Error
+}
+
+// UpdateProxyHeaderRules 整体替换代理的 HTTP 头改写规则
+func (d *dao) UpdateProxyHeaderRules(id uint, rules model.HeaderRules) error {
+	return d.getDB().Model(&model.Proxy{}).Where("id = ?", id).Update("header_rules", rules).Error
+}
+
+// UpdateProxyRoutes 整体替换代理的路由规则
+func (d *dao) UpdateProxyRoutes(id uint, routes model.ProxyRoutes) error {
+	return d.getDB().Model(&model.Proxy{}).Where("id = ?", id).Update("routes", routes).Error
+}
+
 func (d *dao) DeleteProxy(id uint) error {
 	return d.getDB().Delete(&model.Proxy{}, id).Error
 }
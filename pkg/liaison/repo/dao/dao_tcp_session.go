@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"gorm.io/gorm"
+)
+
+// CreateTCPSessions 批量写入 TCP 会话记录
+func (d *dao) CreateTCPSessions(sessions []*model.TCPSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	return d.getDB().CreateInBatches(sessions, 200).Error
+}
+
+// ListTCPSessions 按写入时间倒序查询会话记录
+func (d *dao) ListTCPSessions(query *ListTCPSessionsQuery) ([]*model.TCPSession, error) {
+	db := d.tcpSessionsWhere(query)
+	if query.Page > 0 && query.PageSize > 0 {
+		db = db.Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize)
+	} else if query.PageSize > 0 {
+		db = db.Limit(query.PageSize)
+	}
+	var sessions []*model.TCPSession
+	err := db.Order("id DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// CountTCPSessions 统计符合条件的会话记录数量
+func (d *dao) CountTCPSessions(query *ListTCPSessionsQuery) (int64, error) {
+	var count int64
+	err := d.tcpSessionsWhere(query).Model(&model.TCPSession{}).Count(&count).Error
+	return count, err
+}
+
+func (d *dao) tcpSessionsWhere(query *ListTCPSessionsQuery) *gorm.DB {
+	db := d.getDB()
+	if query.ProxyID != 0 {
+		db = db.Where("proxy_id = ?", query.ProxyID)
+	}
+	if query.ApplicationID != 0 {
+		db = db.Where("application_id = ?", query.ApplicationID)
+	}
+	// 与时间窗口有重叠的会话
+	if query.Start != nil {
+		db = db.Where("end_time >= ?", *query.Start)
+	}
+	if query.End != nil {
+		db = db.Where("start_time <= ?", *query.End)
+	}
+	if query.ClientIP != "" {
+		db = db.Where("client_ip = ?", query.ClientIP)
+	}
+	if query.CloseReason != "" {
+		db = db.Where("close_reason = ?", query.CloseReason)
+	}
+	return db
+}
+
+// DeleteTCPSessionsBefore 删除在 before 之前结束的会话记录
+func (d *dao) DeleteTCPSessionsBefore(before time.Time) (int64, error) {
+	result := d.getDB().Where("end_time < ?", before).Delete(&model.TCPSession{})
+	return result.RowsAffected, result.Error
+}
+
+// TrimTCPSessions 只保留最新写入的 keep 条会话记录
+func (d *dao) TrimTCPSessions(keep int) (int64, error) {
+	var ids []uint
+	err := d.getDB().Model(&model.TCPSession{}).Order("id DESC").Offset(keep).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	result := d.getDB().Where("id <= ?", ids[0]).Delete(&model.TCPSession{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,72 @@
+package dao
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liaisonio/liaison/pkg/liaison/config"
+	"github.com/liaisonio/liaison/pkg/liaison/repo/model"
+	"github.com/liaisonio/liaison/pkg/middleware"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// 从没有 middlewares 列的数据库升级时，已有的 HTTP 应用继续执行 Jellyfin 转码改写
+func TestMigrateApplicationMiddlewares(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "liaison.db")
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Application{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrator().DropColumn(&model.Application{}, "middlewares"); err != nil {
+		t.Fatal(err)
+	}
+	for _, app := range []*model.Application{
+		{Name: "jellyfin", IP: "10.0.0.1", Port: 8096, HeartbeatAt: time.Now(), ApplicationType: model.ApplicationTypeHTTP},
+		{Name: "ssh", IP: "10.0.0.1", Port: 22, HeartbeatAt: time.Now(), ApplicationType: model.ApplicationTypeTCP},
+	} {
+		if err := db.Omit("Middlewares").Create(app).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+
+	conf := &config.Configuration{Manager: config.Manager{DB: path}}
+	d, err := NewDao(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	middlewares := func(id uint) model.ApplicationMiddlewares {
+		t.Helper()
+		app, err := d.GetApplicationByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return app.Middlewares
+	}
+	if got := middlewares(1); len(got) != 1 || got[0].Name != middleware.JellyfinTranscode {
+		t.Errorf("http application middlewares = %+v", got)
+	}
+	if got := middlewares(2); len(got) != 0 {
+		t.Errorf("tcp application middlewares = %+v", got)
+	}
+
+	// 只在新增列时迁移一次，之后关闭的中间件不会被重新启用
+	if err := d.UpdateApplicationMiddlewares(1, nil); err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+	d, err = NewDao(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if got := middlewares(1); len(got) != 0 {
+		t.Errorf("middlewares re-enabled on restart: %+v", got)
+	}
+}
@@ -54,7 +54,8 @@ func (d *dao) GetTrafficMetricsByTimeRange(startTime, endTime time.Time, applica
 	}
 
 	// 按应用ID和时间分组，聚合流量
-	err := db.Select("application_id, proxy_id, MIN(timestamp) as timestamp, SUM(bytes_in) as bytes_in, SUM(bytes_out) as bytes_out").
+	err := db.Select("application_id, proxy_id, MIN(timestamp) as timestamp, SUM(bytes_in) as bytes_in, SUM(bytes_out) as bytes_out, SUM(wire_bytes_in) as wire_bytes_in, SUM(wire_bytes_out) as wire_bytes_out, " +
+		"SUM(cache_hits) as cache_hits, SUM(cache_misses) as cache_misses, SUM(cache_saved_bytes) as cache_saved_bytes, SUM(compression_saved_bytes) as compression_saved_bytes").
 		Group("application_id, timestamp").
 		Order("timestamp ASC").
 		Find(&metrics).Error
@@ -43,6 +43,8 @@ type ListProxiesQuery struct {
 	IDs            []uint
 	ApplicationIDs []uint
 	Name           string
+	Hostname       string // 精确匹配
+	CertificateID  uint
 }
 
 type ListEdgesQuery struct {
@@ -52,6 +54,28 @@ type ListEdgesQuery struct {
 	Name      string
 }
 
+// ListAccessLogsQuery 访问日志查询条件，零值表示不限
+type ListAccessLogsQuery struct {
+	Page, PageSize int
+	ProxyID        uint
+	Start, End     *time.Time
+	// 状态码的类别，2 表示 2xx
+	StatusClass int
+	ClientIP    string
+	PathPrefix  string
+	BeforeID    uint
+}
+
+// ListTCPSessionsQuery TCP 会话记录查询条件，零值表示不限。Start、End 匹配与时间窗口有重叠的会话
+type ListTCPSessionsQuery struct {
+	Page, PageSize int
+	ProxyID        uint
+	ApplicationID  uint
+	Start, End     *time.Time
+	ClientIP       string
+	CloseReason    string
+}
+
 type ListTrafficMetricsQuery struct {
 	ApplicationIDs []uint
 	ProxyIDs       []uint
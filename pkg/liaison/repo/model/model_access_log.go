@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// AccessLog HTTP 代理的访问日志，每个请求一条。数量大且只按保留策略整批删除，
+// 所以不用 gorm.Model，删除即真正删除
+type AccessLog struct {
+	ID            uint      `gorm:"primarykey"`
+	ProxyID       uint      `gorm:"column:proxy_id;type:int;not null;index:idx_access_logs_proxy_time"`
+	ApplicationID uint      `gorm:"column:application_id;type:int;not null"`
+	Time          time.Time `gorm:"column:time;type:datetime;not null;index:idx_access_logs_proxy_time;index"` // 收到请求的时间
+	DurationMs    int64     `gorm:"column:duration_ms;type:bigint;not null;default:0"`
+	ClientIP      string    `gorm:"column:client_ip;type:varchar(64);not null;default:''"`
+	User          string    `gorm:"column:user;type:varchar(255);not null;default:''"` // 登录保护代理上的登录用户
+	Method        string    `gorm:"column:method;type:varchar(16);not null;default:''"`
+	Host          string    `gorm:"column:host;type:varchar(255);not null;default:''"`
+	Path          string    `gorm:"column:path;type:text;not null"` // 不含查询参数
+	Proto         string    `gorm:"column:proto;type:varchar(16);not null;default:''"`
+	Status        int       `gorm:"column:status;type:int;not null;default:0"`
+	BytesIn       int64     `gorm:"column:bytes_in;type:bigint;not null;default:0"`
+	BytesOut      int64     `gorm:"column:bytes_out;type:bigint;not null;default:0"`
+	UserAgent     string    `gorm:"column:user_agent;type:text;not null"`
+}
+
+func (AccessLog) TableName() string {
+	return "access_logs"
+}
@@ -3,6 +3,8 @@ package model
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"net"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,6 +15,7 @@ type ApplicationType string
 const (
 	ApplicationTypeTCP  ApplicationType = "tcp"  // TCP 应用
 	ApplicationTypeHTTP ApplicationType = "http" // HTTP 应用
+	ApplicationTypeUDP  ApplicationType = "udp"  // UDP 应用（DNS、WireGuard、游戏服务器、syslog 等）
 )
 
 // UintSlice 为 []uint 实现 Scanner 和 Valuer 接口，用于 SQLite JSON 字段处理
@@ -48,7 +51,8 @@ func (u UintSlice) Value() (driver.Value, error) {
 // Application is a software running on a device that edge-proxy can proxy to.
 type Application struct {
 	gorm.Model
-	EdgeIDs         UintSlice       `gorm:"column:edge_ids;type:json;not null"` // 关联的edge id
+	EdgeIDs         UintSlice       `gorm:"column:edge_ids;type:json;not null"`             // 关联的edge id，按优先级排列
+	EdgePolicy      string          `gorm:"column:edge_policy;type:varchar(32);default:''"` // 多个 edge 的选择策略，空为 failover
 	DeviceID        uint            `gorm:"column:device_id;type:int;not null"`
 	Name            string          `gorm:"column:name;type:varchar(255);not null"`
 	Description     string          `gorm:"column:description;type:varchar(255);default:''"` // 描述
@@ -56,6 +60,12 @@ type Application struct {
 	Port            int             `gorm:"column:port;type:int;not null"`
 	HeartbeatAt     time.Time       `gorm:"column:heartbeat_at;type:datetime;not null"`
 	ApplicationType ApplicationType `gorm:"column:application_type;type:varchar(255);not null"`
+	// 多个后端时的全部目标，为空表示只有 IP:Port 一个目标
+	Targets TargetList `gorm:"column:targets;type:text"`
+	// 由 edge 执行的主动健康检查，Type 为空表示不检查
+	HealthCheck HealthCheck `gorm:"column:health_check;type:text"`
+	// 启用的 HTTP 中间件，按顺序执行，见 pkg/middleware
+	Middlewares ApplicationMiddlewares `gorm:"column:middlewares;type:text"`
 	// 以下用于中间使用
 	Device *Device `gorm:"-"`
 	Proxy  *Proxy  `gorm:"-"`
@@ -64,3 +74,131 @@ type Application struct {
 func (Application) TableName() string {
 	return "applications"
 }
+
+// TargetAddrs 返回应用的全部后端地址（ip:port），没有配置 Targets 时就是 IP:Port
+func (a *Application) TargetAddrs() []string {
+	if len(a.Targets) == 0 {
+		return []string{net.JoinHostPort(a.IP, strconv.Itoa(a.Port))}
+	}
+	addrs := make([]string, len(a.Targets))
+	for i, t := range a.Targets {
+		addrs[i] = t.Addr()
+	}
+	return addrs
+}
+
+// Target 应用的一个后端
+type Target struct {
+	IP     string `json:"ip"`
+	Port   int    `json:"port"`
+	Weight int    `json:"weight,omitempty"` // 0 按 1 处理
+}
+
+func (t Target) Addr() string {
+	return net.JoinHostPort(t.IP, strconv.Itoa(t.Port))
+}
+
+// TargetList 为 []Target 实现 Scanner 和 Valuer，JSON 存储
+type TargetList []Target
+
+func (l *TargetList) Scan(value interface{}) error {
+	*l = nil
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 0 {
+			return nil
+		}
+		return json.Unmarshal(v, l)
+	case string:
+		if v == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return nil
+	}
+}
+
+func (l TargetList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// HealthCheck 应用后端的主动健康检查配置
+type HealthCheck struct {
+	Type        string `json:"type,omitempty"`         // tcp 或 http，见 proto.HealthCheckTCP/HealthCheckHTTP
+	Path        string `json:"path,omitempty"`         // 仅 http，默认 /
+	IntervalSec int    `json:"interval_sec,omitempty"` // 默认 10
+	TimeoutSec  int    `json:"timeout_sec,omitempty"`  // 默认 3
+}
+
+func (h *HealthCheck) Scan(value interface{}) error {
+	*h = HealthCheck{}
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 0 {
+			return nil
+		}
+		return json.Unmarshal(v, h)
+	case string:
+		if v == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(v), h)
+	default:
+		return nil
+	}
+}
+
+func (h HealthCheck) Value() (driver.Value, error) {
+	b, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// ApplicationMiddleware 应用启用的一个 HTTP 中间件
+type ApplicationMiddleware struct {
+	Name string `json:"name"`
+	// 中间件的 JSON 配置，为空时使用默认配置
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// ApplicationMiddlewares 应用启用的 HTTP 中间件，JSON 存储
+type ApplicationMiddlewares []ApplicationMiddleware
+
+func (m *ApplicationMiddlewares) Scan(value interface{}) error {
+	*m = ApplicationMiddlewares{}
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 0 {
+			return nil
+		}
+		return json.Unmarshal(v, m)
+	case string:
+		if v == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(v), m)
+	default:
+		return nil
+	}
+}
+
+func (m ApplicationMiddlewares) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Certificate 用户上传的证书和私钥（PEM），绑定到 HTTP 代理或按 SNI 匹配使用。
+// Domains、NotBefore、NotAfter 在上传时从证书中解析，便于列表展示到期时间
+type Certificate struct {
+	gorm.Model
+	Name      string      `gorm:"column:name;type:varchar(255);not null"`
+	CertPEM   string      `gorm:"column:cert_pem;type:text;not null"`
+	KeyPEM    string      `gorm:"column:key_pem;type:text;not null"`
+	Domains   StringSlice `gorm:"column:domains;type:text"`
+	NotBefore time.Time   `gorm:"column:not_before"`
+	NotAfter  time.Time   `gorm:"column:not_after"`
+}
+
+func (Certificate) TableName() string {
+	return "certificates"
+}
@@ -27,7 +27,9 @@ type Edge struct {
 	Online      EdgeOnlineStatus `gorm:"column:online;type:int;not null"`
 	HeartbeatAt time.Time        `gorm:"column:heartbeat_at;type:datetime;not null"`
 	Description string           `gorm:"column:description;type:varchar(255);not null"`
-	Device      *Device          `gorm:"-"` // 通过 EdgeDevice 关系表关联的设备（Host 类型）
+	// ProtocolVersion edge 最近一次上线时上报的 stream 协议版本，见 proto.ProtocolVersion
+	ProtocolVersion int     `gorm:"column:protocol_version;type:int;not null;default:0"`
+	Device          *Device `gorm:"-"` // 通过 EdgeDevice 关系表关联的设备（Host 类型）
 }
 
 func (Edge) TableName() string {
@@ -1,6 +1,11 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
 
 type ProxyStatus int
 
@@ -16,6 +21,20 @@ type Proxy struct {
 	Port          int         `gorm:"column:port;type:int;not null"`
 	Status        ProxyStatus `gorm:"column:status;type:int;not null"`
 	Description   string      `gorm:"column:description;type:varchar(255);not null"`
+	// 共享 80/443 监听上使用的主机名（仅 HTTP 应用），为空只通过独立端口访问
+	Hostname string `gorm:"column:hostname;type:varchar(255);default:''"`
+	// 绑定的证书，0 表示使用按 SNI 匹配的证书
+	CertificateID uint `gorm:"column:certificate_id;type:int;not null;default:0"`
+	// 是否要求 Liaison 用户登录后才能访问（仅 HTTP 应用）
+	AuthRequired bool `gorm:"column:auth_required;not null;default:false"`
+	// 允许访问的用户邮箱，为空表示所有启用的用户
+	AuthAllowedUsers StringSlice `gorm:"column:auth_allowed_users;type:text"`
+	// HTTP 头改写规则，按顺序执行（仅 HTTP 应用）
+	HeaderRules HeaderRules `gorm:"column:header_rules;type:text"`
+	// 按路径转发到其他应用的路由规则，按顺序匹配，都不匹配时转发到本代理的应用（仅 HTTP 应用）
+	Routes ProxyRoutes `gorm:"column:routes;type:text"`
+	// 可选行为，JSON 存储，老数据为空时取零值
+	Options ProxyOptions `gorm:"column:options;type:text"`
 	// 以下用于中间使用
 	Application *Application `gorm:"-"`
 	Device      *Device      `gorm:"-"`
@@ -24,3 +43,190 @@ type Proxy struct {
 func (Proxy) TableName() string {
 	return "proxies"
 }
+
+// Reachable 代理在数据面上是否有入口：独立端口或虚拟主机名
+func (p *Proxy) Reachable() bool {
+	return p.Port > 0 || p.Hostname != ""
+}
+
+// ProxyOptions 代理的可选行为，零值即默认行为
+type ProxyOptions struct {
+	// 向后端发送 PROXY protocol 头的版本："v1"、"v2"，为空不发送
+	ProxyProtocol string `json:"proxy_protocol,omitempty"`
+	// 向 HTTP 后端注入 X-Forwarded-For / X-Real-IP
+	ForwardedHeaders bool `json:"forwarded_headers,omitempty"`
+	// entry 与 edge 之间 stream 的压缩算法："zstd"、"snappy"，为空不压缩
+	Compression string `json:"compression,omitempty"`
+	// 独立端口的 HTTP 监听接受 h2c（不经 TLS 的 HTTP/2，prior knowledge）
+	H2C bool `json:"h2c,omitempty"`
+	// 与 HTTP 后端之间的协议："http1"、"h2c"（如 gRPC 后端），为空即 http1
+	BackendProtocol string `json:"backend_protocol,omitempty"`
+	// 带宽限制，可热更新
+	RateLimit RateLimit `json:"rate_limit"`
+	// 并发连接限制
+	ConnLimit ConnLimit `json:"conn_limit"`
+	// 请求检查器（仅 HTTP 应用），可热更新
+	Inspector Inspector `json:"inspector"`
+	// 响应压缩和响应缓存（仅 HTTP 应用），可热更新
+	ResponseCompression ResponseCompression `json:"response_compression"`
+	ResponseCache       ResponseCache       `json:"response_cache"`
+}
+
+// RateLimit 带宽限制，单位字节/秒，0 表示不限
+type RateLimit struct {
+	UploadBytesPerSec            int64 `json:"upload_bytes_per_sec,omitempty"`
+	DownloadBytesPerSec          int64 `json:"download_bytes_per_sec,omitempty"`
+	PerClientUploadBytesPerSec   int64 `json:"per_client_upload_bytes_per_sec,omitempty"`
+	PerClientDownloadBytesPerSec int64 `json:"per_client_download_bytes_per_sec,omitempty"`
+}
+
+// ConnLimit 并发连接限制，0 表示不限。UDP 代理按会话计数
+type ConnLimit struct {
+	MaxConnections      int `json:"max_connections,omitempty"`
+	MaxConnectionsPerIP int `json:"max_connections_per_ip,omitempty"`
+}
+
+// Inspector 请求检查器，在内存中保留最近的请求和响应，见 proto.Inspector
+type Inspector struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// 请求体和响应体各保留的字节数，0 表示默认值
+	MaxBodyBytes int `json:"max_body_bytes,omitempty"`
+	// 保留的请求数，0 表示默认值
+	MaxRequests int `json:"max_requests,omitempty"`
+}
+
+// ResponseCompression 响应压缩，见 proto.ResponseCompression
+type ResponseCompression struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// 已知长度小于该值的响应不压缩，0 表示默认值
+	MinBytes int `json:"min_bytes,omitempty"`
+}
+
+// ResponseCache 内存中的响应缓存，见 proto.ResponseCache
+type ResponseCache struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// 缓存占用的上限，0 表示默认值
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+	// 单个响应体的上限，0 表示默认值
+	MaxObjectBytes int64 `json:"max_object_bytes,omitempty"`
+}
+
+func (o *ProxyOptions) Scan(value interface{}) error {
+	*o = ProxyOptions{}
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 0 {
+			return nil
+		}
+		return json.Unmarshal(v, o)
+	case string:
+		if v == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(v), o)
+	default:
+		return nil
+	}
+}
+
+func (o ProxyOptions) Value() (driver.Value, error) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// HeaderRule HTTP 头改写规则，见 proto.HeaderRule
+type HeaderRule struct {
+	// "request"：转发给后端的请求；"response"：返回给客户端的响应
+	Direction string `json:"direction"`
+	// "add"、"set"、"remove"
+	Action string `json:"action"`
+	Name   string `json:"name"`
+	// 可以使用 {client_ip}、{proxy_name}、{host}、{scheme} 变量
+	Value string `json:"value,omitempty"`
+}
+
+// HeaderRules 代理的 HTTP 头改写规则，JSON 存储
+type HeaderRules []HeaderRule
+
+func (r *HeaderRules) Scan(value interface{}) error {
+	*r = HeaderRules{}
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 0 {
+			return nil
+		}
+		return json.Unmarshal(v, r)
+	case string:
+		if v == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return nil
+	}
+}
+
+func (r HeaderRules) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// ProxyRoute 按路径前缀转发到其他应用的路由规则，见 proto.Route
+type ProxyRoute struct {
+	// 以 / 开头，/api 匹配 /api 和 /api/ 下的路径
+	PathPrefix string `json:"path_prefix"`
+	// 转发前去掉路径中的前缀
+	StripPrefix   bool `json:"strip_prefix,omitempty"`
+	ApplicationID uint `json:"application_id"`
+}
+
+// ProxyRoutes 代理的路由规则，JSON 存储
+type ProxyRoutes []ProxyRoute
+
+func (r *ProxyRoutes) Scan(value interface{}) error {
+	*r = ProxyRoutes{}
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 0 {
+			return nil
+		}
+		return json.Unmarshal(v, r)
+	case string:
+		if v == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return nil
+	}
+}
+
+func (r ProxyRoutes) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// RoutesTo 路由规则中是否有转发到该应用的
+func (r ProxyRoutes) RoutesTo(applicationID uint) bool {
+	for _, route := range r {
+		if route.ApplicationID == applicationID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// TCPSession TCP 代理的会话记录，每个接受的连接一条。与 AccessLog 一样按保留策略整批删除，不用 gorm.Model
+type TCPSession struct {
+	ID            uint      `gorm:"primarykey"`
+	ProxyID       uint      `gorm:"column:proxy_id;type:int;not null;index:idx_tcp_sessions_proxy_start"`
+	ApplicationID uint      `gorm:"column:application_id;type:int;not null;index"`
+	EdgeID        uint64    `gorm:"column:edge_id;type:bigint;not null;default:0"` // 没有拨号到 edge 时为 0
+	ClientAddr    string    `gorm:"column:client_addr;type:varchar(128);not null;default:''"`
+	ClientIP      string    `gorm:"column:client_ip;type:varchar(64);not null;default:''"`
+	Start         time.Time `gorm:"column:start_time;type:datetime;not null;index:idx_tcp_sessions_proxy_start;index"`
+	End           time.Time `gorm:"column:end_time;type:datetime;not null"`
+	DurationMs    int64     `gorm:"column:duration_ms;type:bigint;not null;default:0"`
+	BytesIn       int64     `gorm:"column:bytes_in;type:bigint;not null;default:0"`
+	BytesOut      int64     `gorm:"column:bytes_out;type:bigint;not null;default:0"`
+	CloseReason   string    `gorm:"column:close_reason;type:varchar(32);not null;default:''"`
+	Detail        string    `gorm:"column:detail;type:text;not null"` // 拨号失败的原因
+}
+
+func (TCPSession) TableName() string {
+	return "tcp_sessions"
+}
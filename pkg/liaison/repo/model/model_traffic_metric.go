@@ -15,6 +15,15 @@ type TrafficMetric struct {
 	Timestamp     time.Time `gorm:"column:timestamp;type:datetime;not null;index"` // 时间戳（分钟级别）
 	BytesIn       int64     `gorm:"column:bytes_in;type:bigint;not null;default:0"` // 入站流量（字节）
 	BytesOut      int64     `gorm:"column:bytes_out;type:bigint;not null;default:0"` // 出站流量（字节）
+	// entry 与 edge 之间实际传输的流量（字节），开启压缩时小于 BytesIn/BytesOut
+	WireBytesIn  int64 `gorm:"column:wire_bytes_in;type:bigint;not null;default:0"`
+	WireBytesOut int64 `gorm:"column:wire_bytes_out;type:bigint;not null;default:0"`
+	// HTTP 响应缓存命中和可以缓存但没有命中的请求数
+	CacheHits   int64 `gorm:"column:cache_hits;type:bigint;not null;default:0"`
+	CacheMisses int64 `gorm:"column:cache_misses;type:bigint;not null;default:0"`
+	// 由缓存提供、不经 edge 传输的字节数和响应压缩减少的字节数
+	CacheSavedBytes       int64 `gorm:"column:cache_saved_bytes;type:bigint;not null;default:0"`
+	CompressionSavedBytes int64 `gorm:"column:compression_saved_bytes;type:bigint;not null;default:0"`
 	// 以下用于中间使用
 	Application *Application `gorm:"-"`
 	Proxy       *Proxy       `gorm:"-"`
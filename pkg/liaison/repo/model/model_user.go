@@ -14,6 +14,8 @@ type User struct {
 	Status    UserStatus `gorm:"column:status;type:varchar(50);not null;default:'active'" json:"status"`
 	LastLogin *time.Time `gorm:"column:last_login;type:datetime" json:"last_login"`
 	LoginIP   string     `gorm:"column:login_ip;type:varchar(45)" json:"login_ip"` // IPv6最长45字符
+	// SessionGeneration 登录保护代理的会话代数，递增后之前签发的会话全部失效
+	SessionGeneration uint `gorm:"column:session_generation;not null;default:0" json:"-"`
 }
 
 // UserStatus 用户状态
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jumboframes/armorigo/log"
+)
+
+// JellyfinTranscode 强制 Jellyfin 转码的插件名
+const JellyfinTranscode = "jellyfin_transcode"
+
+func init() {
+	Register(Plugin{
+		Name:        JellyfinTranscode,
+		Description: "Rewrite Jellyfin PlaybackInfo requests so the server transcodes to AAC stereo instead of direct play",
+		New: func(config json.RawMessage) (Middleware, error) {
+			return jellyfinTranscode{}, nil
+		},
+	})
+}
+
+// jellyfinTranscode 改写 Jellyfin 的 PlaybackInfo 请求：声明客户端只支持 AAC 立体声并清空
+// DirectPlayProfiles，让服务端转码而不是直接播放原始文件，降低经隧道传输的码率
+type jellyfinTranscode struct{}
+
+func (jellyfinTranscode) ModifyRequest(req *http.Request) error {
+	// 检查路径是否包含 "PlaybackInfo"
+	if !strings.Contains(strings.ToLower(req.URL.Path), "playbackinfo") {
+		return nil
+	}
+	// 只处理 POST 和 PUT 请求
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		return nil
+	}
+	// 检查 Content-Type 是否为 JSON
+	if !strings.Contains(strings.ToLower(req.Header.Get("Content-Type")), "application/json") {
+		return nil
+	}
+
+	// 读取请求体，超过上限的请求体不改写
+	data, body, ok, err := readBody(req.Body, req.ContentLength)
+	req.Body = body
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	if !ok || len(data) == 0 {
+		return nil
+	}
+
+	// 不是有效的 JSON 时原样转发
+	var jsonData map[string]interface{}
+	if err := json.Unmarshal(data, &jsonData); err != nil {
+		return nil
+	}
+	deviceProfile, ok := jsonData["DeviceProfile"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	// 强制声明只支持 AAC，并限制为立体声
+	deviceProfile["SupportedAudioCodecs"] = []interface{}{"aac"}
+	deviceProfile["MaxAudioChannels"] = 2
+	if _, exists := deviceProfile["DirectPlayProfiles"]; exists {
+		deviceProfile["DirectPlayProfiles"] = []interface{}{}
+		deviceProfile["DirectStreamProfiles"] = []interface{}{}
+		log.Debugf("cleared DeviceProfile.DirectPlayProfiles in PlaybackInfo request: %s", req.URL.Path)
+	}
+
+	modified, err := json.Marshal(jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal modified JSON: %w", err)
+	}
+	setRequestBody(req, modified)
+	return nil
+}
+
+func (jellyfinTranscode) ModifyResponse(resp *http.Response) error {
+	return nil
+}
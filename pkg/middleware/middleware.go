@@ -0,0 +1,147 @@
+// Package middleware 实现 HTTP 代理可选的请求/响应改写插件。插件在 init 中按名字注册，
+// 每个应用通过管理端 API 选择启用哪些插件及其配置，entry 按顺序对转发到该应用的请求和响应执行。
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// maxBodyBytes 需要改写的请求体、响应体最多读入内存的大小，超过的原样流式转发
+const maxBodyBytes = 1 << 20
+
+// Middleware 请求/响应改写插件。ModifyRequest 在请求转发给后端之前执行，
+// ModifyResponse 在响应写给客户端之前执行。返回错误时消息必须仍然可以原样转发，
+// entry 记录日志后继续转发
+type Middleware interface {
+	ModifyRequest(req *http.Request) error
+	ModifyResponse(resp *http.Response) error
+}
+
+// Factory 按 JSON 配置创建插件实例，config 为空时使用默认配置
+type Factory func(config json.RawMessage) (Middleware, error)
+
+// Plugin 一个注册的插件
+type Plugin struct {
+	Name        string
+	Description string
+	New         Factory
+}
+
+var (
+	mu      sync.RWMutex
+	plugins = make(map[string]Plugin)
+)
+
+// Register 注册插件，名字重复时 panic
+func Register(plugin Plugin) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := plugins[plugin.Name]; ok {
+		panic("middleware: duplicate plugin " + plugin.Name)
+	}
+	plugins[plugin.Name] = plugin
+}
+
+// Lookup 按名字查找插件
+func Lookup(name string) (Plugin, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	plugin, ok := plugins[name]
+	return plugin, ok
+}
+
+// List 返回全部插件，按名字排序
+func List() []Plugin {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		list = append(list, plugin)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// New 创建名为 name 的插件实例，也用于校验配置
+func New(name string, config json.RawMessage) (Middleware, error) {
+	plugin, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown middleware %q", name)
+	}
+	m, err := plugin.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("middleware %s: %w", name, err)
+	}
+	return m, nil
+}
+
+// Chain 按顺序执行的一组插件，nil 表示没有插件
+type Chain []Middleware
+
+// ModifyRequest 依次执行插件的请求改写，一个插件出错不影响后面的插件，返回第一个错误
+func (c Chain) ModifyRequest(req *http.Request) error {
+	var first error
+	for _, m := range c {
+		if err := m.ModifyRequest(req); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ModifyResponse 依次执行插件的响应改写，返回第一个错误
+func (c Chain) ModifyResponse(resp *http.Response) error {
+	var first error
+	for _, m := range c {
+		if err := m.ModifyResponse(resp); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// readBody 读入不超过 maxBodyBytes 的消息体，ok 为 false 时消息体超过上限或读取失败，
+// 已读出的部分接回 body，消息仍然可以原样转发
+func readBody(body io.ReadCloser, contentLength int64) (data []byte, restored io.ReadCloser, ok bool, err error) {
+	if body == nil || body == http.NoBody {
+		return nil, body, true, nil
+	}
+	if contentLength > maxBodyBytes {
+		return nil, body, false, nil
+	}
+	data, err = io.ReadAll(io.LimitReader(body, maxBodyBytes+1))
+	if err != nil || len(data) > maxBodyBytes {
+		return nil, &readCloser{Reader: io.MultiReader(bytes.NewReader(data), body), Closer: body}, false, err
+	}
+	body.Close()
+	return data, io.NopCloser(bytes.NewReader(data)), true, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// setRequestBody 替换请求体并更新长度
+func setRequestBody(req *http.Request, data []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.ContentLength = int64(len(data))
+	req.TransferEncoding = nil
+	req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+}
+
+// setResponseBody 替换响应体并更新长度
+func setResponseBody(resp *http.Response, data []byte) {
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	resp.TransferEncoding = nil
+	resp.Header.Del("Transfer-Encoding")
+	resp.Header.Set("Content-Length", strconv.Itoa(len(data)))
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestJellyfinTranscode(t *testing.T) {
+	m, err := New(JellyfinTranscode, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"DeviceProfile":{"MaxAudioChannels":6,"DirectPlayProfiles":[{"Type":"Video"}]}}`
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/Items/1/PlaybackInfo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if err := m.ModifyRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(req.Body)
+	if req.ContentLength != int64(len(data)) {
+		t.Errorf("content length %d, body %d bytes", req.ContentLength, len(data))
+	}
+	var got struct {
+		DeviceProfile struct {
+			MaxAudioChannels     int
+			SupportedAudioCodecs []string
+			DirectPlayProfiles   []any
+		}
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	profile := got.DeviceProfile
+	if profile.MaxAudioChannels != 2 || len(profile.SupportedAudioCodecs) != 1 || len(profile.DirectPlayProfiles) != 0 {
+		t.Errorf("profile not rewritten: %s", data)
+	}
+
+	// 其他请求原样转发
+	req, _ = http.NewRequest(http.MethodPost, "http://example.com/Users/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if err := m.ModifyRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(req.Body); string(data) != body {
+		t.Errorf("unrelated request modified: %s", data)
+	}
+}
+
+func TestBodyReplace(t *testing.T) {
+	if _, err := New(BodyReplace, nil); err == nil {
+		t.Error("empty replacements accepted")
+	}
+	if _, err := New("unknown", nil); err == nil {
+		t.Error("unknown middleware accepted")
+	}
+	m, err := New(BodyReplace, json.RawMessage(`{"replacements":[{"from":"http://10.0.0.1:8080","to":"https://app.example.com"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := func(contentType, encoding string) *http.Response {
+		resp := &http.Response{Header: make(http.Header), ContentLength: -1,
+			Body: io.NopCloser(strings.NewReader(`<a href="http://10.0.0.1:8080/x">`))}
+		resp.Header.Set("Content-Type", contentType)
+		if encoding != "" {
+			resp.Header.Set("Content-Encoding", encoding)
+		}
+		return resp
+	}
+	cases := []struct {
+		contentType, encoding, want string
+	}{
+		{"text/html; charset=utf-8", "", `<a href="https://app.example.com/x">`},
+		{"application/json", "", `<a href="http://10.0.0.1:8080/x">`},
+		{"text/html", "gzip", `<a href="http://10.0.0.1:8080/x">`},
+	}
+	for _, c := range cases {
+		resp := response(c.contentType, c.encoding)
+		if err := m.ModifyResponse(resp); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := io.ReadAll(resp.Body); string(data) != c.want {
+			t.Errorf("%s %s: body %s", c.contentType, c.encoding, data)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// BodyReplace 响应体字符串替换的插件名
+const BodyReplace = "body_replace"
+
+// maxReplacements 一个 body_replace 插件最多的替换规则数
+const maxReplacements = 32
+
+func init() {
+	Register(Plugin{
+		Name:        BodyReplace,
+		Description: "Replace strings in uncompressed response bodies up to 1 MiB, e.g. absolute backend URLs in HTML",
+		New:         newBodyReplace,
+	})
+}
+
+// Replacement 一条替换规则，From 在响应体中的全部出现替换为 To
+type Replacement struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// BodyReplaceConfig body_replace 插件的配置
+type BodyReplaceConfig struct {
+	Replacements []Replacement `json:"replacements"`
+	// 需要替换的响应的媒体类型，为空时只替换 text/html
+	ContentTypes []string `json:"content_types,omitempty"`
+}
+
+type bodyReplace struct {
+	pairs        [][]byte
+	contentTypes map[string]bool
+}
+
+func newBodyReplace(config json.RawMessage) (Middleware, error) {
+	var conf BodyReplaceConfig
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &conf); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	if len(conf.Replacements) == 0 {
+		return nil, errors.New("at least one replacement is required")
+	}
+	if len(conf.Replacements) > maxReplacements {
+		return nil, fmt.Errorf("too many replacements, at most %d", maxReplacements)
+	}
+	m := &bodyReplace{contentTypes: make(map[string]bool)}
+	for i, r := range conf.Replacements {
+		if r.From == "" {
+			return nil, fmt.Errorf("replacement %d: from is required", i+1)
+		}
+		m.pairs = append(m.pairs, []byte(r.From), []byte(r.To))
+	}
+	if len(conf.ContentTypes) == 0 {
+		conf.ContentTypes = []string{"text/html"}
+	}
+	for _, ct := range conf.ContentTypes {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content type %q", ct)
+		}
+		m.contentTypes[mediaType] = true
+	}
+	return m, nil
+}
+
+func (m *bodyReplace) ModifyRequest(req *http.Request) error {
+	return nil
+}
+
+func (m *bodyReplace) ModifyResponse(resp *http.Response) error {
+	// 压缩过的响应体无法按字符串替换
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !m.contentTypes[mediaType] {
+		return nil
+	}
+	data, body, ok, err := readBody(resp.Body, resp.ContentLength)
+	resp.Body = body
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if !ok || len(data) == 0 {
+		return nil
+	}
+	replaced := data
+	for i := 0; i < len(m.pairs); i += 2 {
+		replaced = bytes.ReplaceAll(replaced, m.pairs[i], m.pairs[i+1])
+	}
+	setResponseBody(resp, replaced)
+	return nil
+}
@@ -0,0 +1,30 @@
+package proto
+
+import "time"
+
+// AccessLogEntry HTTP 代理上的一个请求，响应写完（或者转发失败）之后由 entry 上报
+type AccessLogEntry struct {
+	ProxyID uint
+	// 请求转发到的应用，按路径路由时为路由的应用
+	ApplicationID uint
+	// 收到请求的时间和处理耗时
+	Time     time.Time
+	Duration time.Duration
+	ClientIP string
+	// 登录保护代理上的登录用户，其余为空
+	User   string
+	Method string
+	Host   string
+	// 客户端请求的路径，不含查询参数，查询参数中常有令牌之类的敏感信息
+	Path      string
+	Proto     string
+	Status    int
+	BytesIn   int64
+	BytesOut  int64
+	UserAgent string
+}
+
+// AccessLogger 接收 HTTP 代理的访问日志，在请求处理的 goroutine 中调用，不能阻塞
+type AccessLogger interface {
+	RecordAccess(entry *AccessLogEntry)
+}
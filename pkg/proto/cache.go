@@ -0,0 +1,44 @@
+package proto
+
+// 响应缓存的默认值和上限
+const (
+	DefaultCacheBytes       = 64 << 20
+	DefaultCacheObjectBytes = 8 << 20
+	MaxCacheBytes           = 1 << 30
+	// 小于该大小的响应不压缩
+	DefaultCompressMinBytes = 1024
+)
+
+// ResponseCompression HTTP 代理对响应的压缩：按客户端的 Accept-Encoding 选择 br 或者 gzip，
+// 只压缩后端没有压缩过的文本类响应
+type ResponseCompression struct {
+	Enabled bool
+	// 已知长度小于该值的响应不压缩，0 取 DefaultCompressMinBytes
+	MinBytes int
+}
+
+// ResponseCache HTTP 代理的响应缓存，在内存中按 Cache-Control、Expires 和 Vary 缓存 GET 响应，
+// 过期之后用 ETag、Last-Modified 向后端重新验证
+type ResponseCache struct {
+	Enabled bool
+	// 缓存占用的上限，0 取 DefaultCacheBytes
+	MaxBytes int64
+	// 单个响应体的上限，更大的响应不缓存，0 取 DefaultCacheObjectBytes
+	MaxObjectBytes int64
+}
+
+// CacheStats 一段时间内响应缓存和压缩的效果
+type CacheStats struct {
+	// 命中缓存（包括重新验证后使用缓存）和可以缓存但没有命中的请求数
+	Hits   int64
+	Misses int64
+	// 由缓存提供、不需要经 edge 传输的响应体字节数
+	SavedBytes int64
+	// 压缩减少的发给客户端的字节数
+	CompressionSavedBytes int64
+}
+
+// IsZero 是否没有任何记录
+func (s CacheStats) IsZero() bool {
+	return s == CacheStats{}
+}
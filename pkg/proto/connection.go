@@ -0,0 +1,33 @@
+package proto
+
+import "time"
+
+// 活跃连接的类型
+const (
+	ConnectionTCP       = "tcp"
+	ConnectionHTTP      = "http"
+	ConnectionWebSocket = "websocket"
+	ConnectionUDP       = "udp" // UDP 代理上一个客户端地址的会话
+)
+
+// ConnectionInfo 数据面上的一个活跃客户端连接
+type ConnectionInfo struct {
+	ID         uint64    `json:"id"` // 在 entry 进程内唯一，重启后重新编号
+	Kind       string    `json:"kind"`
+	ClientAddr string    `json:"client_addr"`
+	ClientIP   string    `json:"client_ip"`
+	Start      time.Time `json:"start"`
+	// 客户端 -> 后端和后端 -> 客户端到目前为止的字节数，HTTPS 为解密后的字节
+	BytesIn  int64 `json:"bytes_in"`
+	BytesOut int64 `json:"bytes_out"`
+	// 最近一次打开 stream 的 edge，还没有打开时为 0
+	EdgeID uint64 `json:"edge_id"`
+	// 最近一次收发数据的时间
+	LastActive time.Time `json:"last_active"`
+}
+
+// ConnectionFilter 选择要断开的连接：ID 非 0 时只匹配该连接，ClientIP 非空时匹配该来源的所有连接
+type ConnectionFilter struct {
+	ID       uint64
+	ClientIP string
+}
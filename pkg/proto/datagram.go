@@ -0,0 +1,47 @@
+package proto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// 网络类型，写在 Dst.Network 中，决定 edge 侧如何拨号
+const (
+	NetworkTCP = "tcp"
+	NetworkUDP = "udp"
+)
+
+// MaxDatagramSize 单个 UDP 数据报的最大长度（2 字节长度前缀能表达的上限）
+const MaxDatagramSize = 65535
+
+// WriteDatagram 在流上写入一个数据报：2 字节大端长度 + 数据。
+// geminio stream 是字节流，需要靠长度前缀保留 UDP 的报文边界。
+func WriteDatagram(w io.Writer, b []byte) error {
+	if len(b) > MaxDatagramSize {
+		return fmt.Errorf("datagram too large: %d", len(b))
+	}
+	// 合并成一次 Write，避免长度和数据被拆成两个帧
+	frame := make([]byte, 2+len(b))
+	binary.BigEndian.PutUint16(frame, uint16(len(b)))
+	copy(frame[2:], b)
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadDatagram 从流上读取一个数据报到 buf，返回数据报长度。
+// buf 至少要有 MaxDatagramSize 大小，否则过长的数据报会返回错误。
+func ReadDatagram(r io.Reader, buf []byte) (int, error) {
+	var lengthBuf [2]byte
+	if _, err := io.ReadFull(r, lengthBuf[:]); err != nil {
+		return 0, err
+	}
+	length := int(binary.BigEndian.Uint16(lengthBuf[:]))
+	if length > len(buf) {
+		return 0, fmt.Errorf("datagram too large: %d > %d", length, len(buf))
+	}
+	if _, err := io.ReadFull(r, buf[:length]); err != nil {
+		return 0, err
+	}
+	return length, nil
+}
@@ -0,0 +1,45 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDatagramRoundTrip(t *testing.T) {
+	var stream bytes.Buffer
+	datagrams := [][]byte{
+		[]byte("dns query"),
+		{},
+		bytes.Repeat([]byte{0xab}, MaxDatagramSize),
+	}
+	for _, d := range datagrams {
+		if err := WriteDatagram(&stream, d); err != nil {
+			t.Fatalf("WriteDatagram error: %s", err)
+		}
+	}
+
+	buf := make([]byte, MaxDatagramSize)
+	for i, want := range datagrams {
+		n, err := ReadDatagram(&stream, buf)
+		if err != nil {
+			t.Fatalf("ReadDatagram[%d] error: %s", i, err)
+		}
+		if !bytes.Equal(buf[:n], want) {
+			t.Fatalf("datagram[%d] mismatch: got %d bytes, want %d", i, n, len(want))
+		}
+	}
+}
+
+func TestDatagramTooLarge(t *testing.T) {
+	var stream bytes.Buffer
+	if err := WriteDatagram(&stream, make([]byte, MaxDatagramSize+1)); err == nil {
+		t.Fatalf("expected error for oversized datagram")
+	}
+
+	if err := WriteDatagram(&stream, make([]byte, 16)); err != nil {
+		t.Fatalf("WriteDatagram error: %s", err)
+	}
+	if _, err := ReadDatagram(&stream, make([]byte, 8)); err == nil {
+		t.Fatalf("expected error when buffer is smaller than datagram")
+	}
+}
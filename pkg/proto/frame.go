@@ -0,0 +1,47 @@
+package proto
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxFrameSize 控制帧（Dst、DialResult）的长度上限，防止异常长度撑爆内存
+const maxFrameSize = 1 << 20
+
+// WriteFrame 写入一个 4 字节大端长度前缀的 JSON 帧。
+// entry 与 edge 之间 stream 开头的控制信息（Dst、DialResult）都使用这种格式。
+func WriteFrame(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal frame: %w", err)
+	}
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+	if _, err := w.Write(frame); err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+	return nil
+}
+
+// ReadFrame 读取一个 WriteFrame 写入的帧并反序列化到 v
+func ReadFrame(r io.Reader, v interface{}) error {
+	lengthBuf := make([]byte, 4)
+	if _, err := io.ReadFull(r, lengthBuf); err != nil {
+		return fmt.Errorf("failed to read frame length: %w", err)
+	}
+	length := binary.BigEndian.Uint32(lengthBuf)
+	if length > maxFrameSize {
+		return fmt.Errorf("frame too large: %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("failed to read frame data: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal frame: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package proto
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// DialError edge 返回的非成功 DialResult
+type DialError struct {
+	Result DialResult
+}
+
+func (e *DialError) Error() string {
+	switch e.Result.Code {
+	case DialCodeDenied:
+		return fmt.Sprintf("edge denied destination: %s", e.Result.Reason)
+	default:
+		return fmt.Sprintf("edge dial failed (%s): %s", e.Result.Code, e.Result.Reason)
+	}
+}
+
+// entry 与 edge 之间 stream 协议的版本。edge 连接时在 Meta 中上报自己的版本，
+// entry 按 edge 的版本握手并写在 Dst.Version 中
+const (
+	// ProtocolLegacy 老版本 edge：读取 Dst 之后直接按 TCP 拨号并转发，不写回 DialResult
+	ProtocolLegacy = 0
+	// ProtocolDialResult edge 读取 Dst 之后写回 DialResult
+	ProtocolDialResult = 1
+	// ProtocolVersion 当前版本
+	ProtocolVersion = ProtocolDialResult
+)
+
+// Handshake entry 侧打开 stream 后的第一步：写入 Dst，并等待 edge 写回的 DialResult。
+// 成功之后 stream 上承载的就是后端数据（按 DialResult.Compression 压缩）；edge 拒绝或拨号失败时返回 *DialError。
+// Dst.Version 低于 ProtocolDialResult 时按老版本 edge 处理：只写入 Dst，不等待结果，
+// 老版本 edge 不支持的 UDP 和 PROXY protocol 直接返回错误
+func Handshake(conn net.Conn, dst *Dst, timeout time.Duration) (*DialResult, error) {
+	if dst.Version < ProtocolDialResult {
+		return legacyHandshake(conn, dst)
+	}
+	if err := WriteFrame(conn, dst); err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(timeout))
+		defer conn.SetReadDeadline(time.Time{})
+	}
+	var result DialResult
+	if err := ReadFrame(conn, &result); err != nil {
+		return nil, fmt.Errorf("failed to read dial result: %w", err)
+	}
+	if result.Code != DialCodeOK {
+		return nil, &DialError{Result: result}
+	}
+	return &result, nil
+}
+
+func legacyHandshake(conn net.Conn, dst *Dst) (*DialResult, error) {
+	switch {
+	case dst.Network == NetworkUDP:
+		return nil, fmt.Errorf("edge protocol version %d does not support udp, upgrade the edge", dst.Version)
+	case dst.ProxyProtocol != "":
+		return nil, fmt.Errorf("edge protocol version %d does not support proxy protocol, upgrade the edge", dst.Version)
+	}
+	if err := WriteFrame(conn, dst); err != nil {
+		return nil, err
+	}
+	return &DialResult{Code: DialCodeOK}, nil
+}
@@ -0,0 +1,53 @@
+package proto
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestHandshake(t *testing.T) {
+	entry, edge := net.Pipe()
+	defer entry.Close()
+	defer edge.Close()
+	go func() {
+		var dst Dst
+		if ReadFrame(edge, &dst) != nil {
+			return
+		}
+		WriteFrame(edge, &DialResult{Code: DialCodeDenied, Reason: dst.Addr + " not allowed"})
+	}()
+	_, err := Handshake(entry, &Dst{Addr: "10.0.0.1:22", Version: ProtocolVersion}, time.Second)
+	if dialErr, ok := err.(*DialError); !ok || dialErr.Result.Code != DialCodeDenied {
+		t.Fatalf("err = %v, want denied", err)
+	}
+}
+
+func TestLegacyHandshake(t *testing.T) {
+	entry, edge := net.Pipe()
+	defer entry.Close()
+	defer edge.Close()
+	received := make(chan Dst, 1)
+	go func() {
+		var dst Dst
+		if ReadFrame(edge, &dst) == nil {
+			received <- dst
+		}
+	}()
+	// 老版本 edge 不写回结果，写入 Dst 之后立即返回
+	result, err := Handshake(entry, &Dst{Addr: "10.0.0.1:22", Compression: "zstd"}, time.Second)
+	if err != nil || result.Code != DialCodeOK || result.Compression != "" {
+		t.Fatalf("legacy handshake = %+v, %v", result, err)
+	}
+	if dst := <-received; dst.Addr != "10.0.0.1:22" {
+		t.Errorf("edge received %+v", dst)
+	}
+	for _, dst := range []*Dst{
+		{Addr: "10.0.0.1:53", Network: NetworkUDP},
+		{Addr: "10.0.0.1:22", ProxyProtocol: "v1"},
+	} {
+		if _, err := Handshake(entry, dst, time.Second); err == nil {
+			t.Errorf("legacy handshake accepted %+v", dst)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package proto
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// HeaderRule HTTP 头改写规则。Value 中可以使用 HeaderVar* 变量，转发时替换为本次请求的值
+type HeaderRule struct {
+	Direction string // HeaderDirection*
+	Action    string // HeaderAction*
+	Name      string
+	Value     string // remove 时忽略
+}
+
+// 改写的方向
+const (
+	HeaderDirectionRequest  = "request"  // 转发给后端的请求
+	HeaderDirectionResponse = "response" // 返回给客户端的响应
+)
+
+// 改写的动作
+const (
+	HeaderActionAdd    = "add"    // 追加一个值，保留已有的值
+	HeaderActionSet    = "set"    // 替换已有的值
+	HeaderActionRemove = "remove" // 删除
+)
+
+// 规则的值中可以使用的变量
+const (
+	HeaderVarClientIP  = "{client_ip}"  // 客户端 IP
+	HeaderVarProxyName = "{proxy_name}" // 代理名称
+	HeaderVarHost      = "{host}"       // 客户端请求的原始 Host
+	HeaderVarScheme    = "{scheme}"     // 客户端使用的协议，http 或 https
+)
+
+// protectedHeaders 决定消息边界或者连接行为的头，由代理自己维护，不允许改写
+var protectedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Upgrade":           true,
+	"Te":                true,
+	"Trailer":           true,
+}
+
+var headerVarPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// ValidateHeaderRule 检查改写规则，Name 规范化为标准的大小写
+func ValidateHeaderRule(rule *HeaderRule) error {
+	switch rule.Direction {
+	case HeaderDirectionRequest, HeaderDirectionResponse:
+	default:
+		return fmt.Errorf("invalid direction %q, expect %s or %s", rule.Direction, HeaderDirectionRequest, HeaderDirectionResponse)
+	}
+	switch rule.Action {
+	case HeaderActionAdd, HeaderActionSet, HeaderActionRemove:
+	default:
+		return fmt.Errorf("invalid action %q, expect %s, %s or %s", rule.Action, HeaderActionAdd, HeaderActionSet, HeaderActionRemove)
+	}
+	if !httpguts.ValidHeaderFieldName(rule.Name) {
+		return fmt.Errorf("invalid header name %q", rule.Name)
+	}
+	rule.Name = http.CanonicalHeaderKey(rule.Name)
+	if protectedHeaders[rule.Name] {
+		return fmt.Errorf("header %s can not be rewritten", rule.Name)
+	}
+	if rule.Action == HeaderActionRemove {
+		rule.Value = ""
+		return nil
+	}
+	if !httpguts.ValidHeaderFieldValue(rule.Value) {
+		return fmt.Errorf("invalid value for header %s", rule.Name)
+	}
+	for _, v := range headerVarPattern.FindAllString(rule.Value, -1) {
+		switch v {
+		case HeaderVarClientIP, HeaderVarProxyName, HeaderVarHost, HeaderVarScheme:
+		default:
+			return fmt.Errorf("unknown variable %s in header %s, expect one of %s", v, rule.Name,
+				strings.Join([]string{HeaderVarClientIP, HeaderVarProxyName, HeaderVarHost, HeaderVarScheme}, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package proto
+
+import "testing"
+
+func TestValidateHeaderRule(t *testing.T) {
+	cases := []struct {
+		rule HeaderRule
+		ok   bool
+	}{
+		{HeaderRule{Direction: "request", Action: "set", Name: "x-forwarded-proto", Value: "{scheme}"}, true},
+		{HeaderRule{Direction: "response", Action: "remove", Name: "Server"}, true},
+		{HeaderRule{Direction: "response", Action: "add", Name: "Strict-Transport-Security", Value: "max-age=31536000"}, true},
+		{HeaderRule{Direction: "both", Action: "set", Name: "X-A"}, false},
+		{HeaderRule{Direction: "request", Action: "append", Name: "X-A"}, false},
+		{HeaderRule{Direction: "request", Action: "set", Name: "Bad Name"}, false},
+		{HeaderRule{Direction: "request", Action: "set", Name: "content-length", Value: "0"}, false},
+		{HeaderRule{Direction: "request", Action: "set", Name: "X-A", Value: "a\r\nb"}, false},
+		{HeaderRule{Direction: "request", Action: "set", Name: "X-A", Value: "{client_addr}"}, false},
+	}
+	for _, c := range cases {
+		rule := c.rule
+		err := ValidateHeaderRule(&rule)
+		if (err == nil) != c.ok {
+			t.Errorf("ValidateHeaderRule(%+v) = %v, want ok %v", c.rule, err, c.ok)
+		}
+	}
+	rule := HeaderRule{Direction: "request", Action: "set", Name: "x-forwarded-proto"}
+	if err := ValidateHeaderRule(&rule); err != nil || rule.Name != "X-Forwarded-Proto" {
+		t.Errorf("name = %q, err = %v", rule.Name, err)
+	}
+}
@@ -0,0 +1,51 @@
+package proto
+
+import (
+	"net/http"
+	"time"
+)
+
+// 请求检查器的默认值和上限
+const (
+	DefaultInspectorBodyBytes = 64 << 10
+	DefaultInspectorRequests  = 100
+	MaxInspectorBodyBytes     = 1 << 20
+	MaxInspectorRequests      = 1000
+)
+
+// Inspector HTTP 代理的请求检查器：在内存中保留最近的请求和响应，用于调试 webhook 等请求。
+// 关闭时不做任何记录
+type Inspector struct {
+	Enabled bool
+	// 请求体和响应体各保留的字节数，0 取 DefaultInspectorBodyBytes
+	MaxBodyBytes int
+	// 保留的请求数，0 取 DefaultInspectorRequests
+	MaxRequests int
+}
+
+// InspectedRequest 检查器记录的一个请求。请求为转发给后端的样子，即经过路由、中间件和头改写之后；
+// 响应为后端返回的样子
+type InspectedRequest struct {
+	ID            uint64    `json:"id"`
+	ApplicationID uint      `json:"application_id"`
+	Time          time.Time `json:"time"`
+	DurationMs    int64     `json:"duration_ms"`
+	ClientAddr    string    `json:"client_addr"`
+	// 非 0 表示是重放该记录产生的请求
+	ReplayOf uint64 `json:"replay_of,omitempty"`
+
+	Method               string      `json:"method"`
+	Host                 string      `json:"host"`
+	URL                  string      `json:"url"` // 路径和查询参数
+	Proto                string      `json:"proto"`
+	RequestHeader        http.Header `json:"request_header,omitempty"`
+	RequestBody          []byte      `json:"request_body,omitempty"`
+	RequestBodyTruncated bool        `json:"request_body_truncated,omitempty"`
+
+	Status                int         `json:"status,omitempty"`
+	ResponseHeader        http.Header `json:"response_header,omitempty"`
+	ResponseBody          []byte      `json:"response_body,omitempty"`
+	ResponseBodyTruncated bool        `json:"response_body_truncated,omitempty"`
+	// 转发失败的原因
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,8 @@
+package proto
+
+// Middleware 应用启用的一个 HTTP 中间件，实现见 pkg/middleware
+type Middleware struct {
+	Name string
+	// 中间件的 JSON 配置，为空时使用默认配置
+	Config []byte
+}
@@ -1,6 +1,10 @@
 package proto
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 // manager <-> entry
 // 一个Proxy是三元组
@@ -9,21 +13,180 @@ type Proxy struct {
 	Name string
 	// 代理端口
 	ProxyPort int
-	// 边缘ID
-	EdgeID uint64
+	// 共享监听上路由到本代理的主机名（仅对 HTTP 应用有效），为空不参与虚拟主机
+	Hostname string
+	// 可以到达应用的 edge，按优先级排列
+	EdgeIDs []uint64
+	// 多个 edge 之间的选择策略，见 EdgePolicy*
+	EdgePolicy string
 	// 应用ID（用于流量统计）
 	ApplicationID uint
-	// 目的地址
+	// 目的地址，只有一个后端时使用；Targets 非空时以 Targets 为准
 	Dst string
-	// 应用类型（用于决定使用 HTTP 还是 TCP 代理）
+	// 应用的全部后端及其健康状态
+	Targets []Target
+	// 应用类型（用于决定使用 HTTP、TCP 还是 UDP 代理）
 	ApplicationType string
 	// 是否使用 HTTPS（仅对 HTTP 应用有效）
 	UseHTTPS bool
+	// 绑定的证书 ID（见 CertificateManager），0 表示按 SNI 选择
+	CertificateID uint
+	// 向后端发送的 PROXY protocol 版本（v1/v2），为空不发送
+	ProxyProtocol string
+	// 是否向后端注入 X-Forwarded-For / X-Real-IP（仅对 HTTP 应用有效）
+	ForwardedHeaders bool
+	// entry 与 edge 之间 stream 的压缩算法（zstd/snappy），为空不压缩；UDP 代理不压缩
+	Compression string
+	// 独立端口的 HTTP 监听是否接受 h2c（仅对 HTTP 应用有效）
+	H2C bool
+	// 与 HTTP 后端之间的协议，见 BackendProtocol*，为空即 HTTP/1.1
+	BackendProtocol string
+	// 登录保护（仅对 HTTP 应用有效），可热更新
+	Auth ProxyAuth
+	// HTTP 头改写规则，按顺序执行（仅对 HTTP 应用有效），可热更新
+	HeaderRules []HeaderRule
+	// 按路径转发到其他应用的路由规则，按顺序匹配，都不匹配时转发到本代理的应用（仅对 HTTP 应用有效），可热更新
+	Routes []Route
+	// 应用启用的 HTTP 中间件，按顺序执行（仅对 HTTP 应用有效），可热更新
+	Middlewares []Middleware
+	// 带宽限制
+	RateLimit RateLimit
+	// 并发连接限制
+	ConnLimit ConnLimit
+	// 请求检查器（仅对 HTTP 应用有效），可热更新
+	Inspector Inspector
+	// 响应压缩和缓存（仅对 HTTP 应用有效），可热更新
+	ResponseCompression ResponseCompression
+	ResponseCache       ResponseCache
+}
+
+// RateLimit 代理带宽限制，单位字节/秒，0 表示不限。
+// 上行指客户端 -> 后端，下行指后端 -> 客户端
+type RateLimit struct {
+	UploadBytesPerSec            int64
+	DownloadBytesPerSec          int64
+	PerClientUploadBytesPerSec   int64
+	PerClientDownloadBytesPerSec int64
+}
+
+// IsZero 是否完全不限速
+func (r RateLimit) IsZero() bool {
+	return r == RateLimit{}
+}
+
+// Target 应用的一个后端
+type Target struct {
+	Addr    string // ip:port
+	Weight  int
+	Healthy bool // 没有健康检查或还没有检查结果时为 true
+}
+
+// TargetList 返回代理的全部后端，Targets 为空时退化为 Dst 一个后端
+func (p *Proxy) TargetList() []Target {
+	return targetList(p.Dst, p.Targets)
+}
+
+func targetList(dst string, targets []Target) []Target {
+	if len(targets) == 0 && dst != "" {
+		return []Target{{Addr: dst, Weight: 1, Healthy: true}}
+	}
+	return targets
+}
+
+// ProxyAuth 要求 Liaison 用户登录后才能访问代理
+type ProxyAuth struct {
+	Required bool
+	// 允许访问的用户邮箱，为空表示所有启用的用户
+	AllowedUsers []string
+}
+
+// Allows 用户是否在允许访问的列表中，邮箱不区分大小写
+func (a ProxyAuth) Allows(email string) bool {
+	if len(a.AllowedUsers) == 0 {
+		return true
+	}
+	for _, allowed := range a.AllowedUsers {
+		if strings.EqualFold(allowed, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// UserAuthenticator 校验登录保护代理的访问者，由管理端的 IAM 实现
+type UserAuthenticator interface {
+	// Authenticate 校验邮箱和密码，返回用户 ID 和邮箱
+	Authenticate(email, password, clientIP string) (uint, string, error)
+	// UserSession 用户是否存在且处于启用状态，以及用户当前的会话代数，
+	// 代数不同的会话已经被注销
+	UserSession(userID uint) (generation uint, active bool)
+	// RevokeUserSessions 注销用户在所有登录保护代理上的会话
+	RevokeUserSessions(userID uint) error
+}
+
+// HTTP 后端协议
+const (
+	BackendProtocolHTTP1 = "http1" // HTTP/1.1
+	BackendProtocolH2C   = "h2c"   // 不经 TLS 的 HTTP/2，gRPC 后端需要
+)
+
+// ValidBackendProtocol 是否是支持的后端协议，空值表示默认的 HTTP/1.1
+func ValidBackendProtocol(protocol string) bool {
+	switch protocol {
+	case "", BackendProtocolHTTP1, BackendProtocolH2C:
+		return true
+	}
+	return false
+}
+
+// 多 edge 选择策略
+const (
+	EdgePolicyFailover   = "failover"    // 优先使用排在前面的 edge，打开 stream 失败再换下一个
+	EdgePolicyRoundRobin = "round_robin" // 在各 edge 之间轮转
+)
+
+// ConnLimit 并发连接限制，0 表示不限。UDP 代理按会话计数
+type ConnLimit struct {
+	MaxConnections      int
+	MaxConnectionsPerIP int
+}
+
+// ProxyStats 数据面上代理的运行时计数，监听重建后清零
+type ProxyStats struct {
+	ActiveConnections    int64 `json:"active_connections"`
+	RejectedByProxyLimit int64 `json:"rejected_by_proxy_limit"` // 因代理总连接数超限被拒绝
+	RejectedByIPLimit    int64 `json:"rejected_by_ip_limit"`    // 因单个来源 IP 连接数超限被拒绝
+	// 各 edge 承载的流量，顺序与配置一致
+	Edges []EdgeStats `json:"edges"`
+}
+
+// EdgeStats 代理在单个 edge 上打开 stream 的计数
+type EdgeStats struct {
+	EdgeID     uint64    `json:"edge_id"`
+	Streams    int64     `json:"streams"`  // 成功打开的 stream 数（TCP 连接、UDP 会话、HTTP 请求）
+	Failures   int64     `json:"failures"` // 打开 stream 失败次数
+	LastUsedAt time.Time `json:"last_used_at"`
+	Healthy    bool      `json:"healthy"` // 为 false 表示最近失败过，正在冷却
 }
 
 type ProxyManager interface {
 	CreateProxy(ctx context.Context, proxy *Proxy) error
+	// UpdateProxy 在不重建监听的情况下更新运行中代理的可热更新配置（带宽、连接数限制）
+	UpdateProxy(ctx context.Context, proxy *Proxy) error
 	DeleteProxy(ctx context.Context, id int) error
+	// GetProxyStats 返回运行中代理的计数，代理未运行时返回 nil
+	GetProxyStats(ctx context.Context, id int) (*ProxyStats, error)
+	// ListInspectedRequests 返回 HTTP 代理检查器记录的请求，从新到旧，不含请求体和响应体；
+	// 代理未运行或没有开启检查器时返回 nil
+	ListInspectedRequests(ctx context.Context, id int) ([]InspectedRequest, error)
+	// GetInspectedRequest 返回一条完整的记录，不存在时返回 nil
+	GetInspectedRequest(ctx context.Context, id int, requestID uint64) (*InspectedRequest, error)
+	// ReplayRequest 经 edge 把记录的请求重新发给后端，返回重放的记录
+	ReplayRequest(ctx context.Context, id int, requestID uint64) (*InspectedRequest, error)
+	// ListConnections 返回运行中代理的活跃连接，代理未运行时返回 nil
+	ListConnections(ctx context.Context, id int) ([]ConnectionInfo, error)
+	// CloseConnections 断开代理上匹配 filter 的连接，返回断开的数量
+	CloseConnections(ctx context.Context, id int, filter ConnectionFilter) (int, error)
 }
 
 // FirewallManager pushes per-proxy source-IP allowlists to the data plane.
@@ -33,10 +196,20 @@ type FirewallManager interface {
 	Revoke(proxyID int)
 }
 
+// CertificateManager 把管理端保存的证书下发给 HTTP 数据面。替换证书不影响已建立的连接
+type CertificateManager interface {
+	SetCertificate(id uint, certPEM, keyPEM []byte) error
+	RemoveCertificate(id uint)
+	// LoadedCertificates 返回数据面已加载的证书及其到期时间
+	LoadedCertificates() map[uint]time.Time
+}
+
 // manager <-> edge
 type Meta struct {
 	AccessKey string `json:"access_key"`
 	SecretKey string `json:"secret_key"`
+	// edge 支持的 stream 协议版本，老版本 edge 不上报，为 ProtocolLegacy
+	ProtocolVersion int `json:"protocol_version,omitempty"`
 }
 
 // device
@@ -94,8 +267,59 @@ type ScanApplicationTaskResult struct {
 
 type Dst struct {
 	Addr          string `json:"addr"`
+	Network       string `json:"network,omitempty"`        // 传输层协议（tcp/udp），为空表示 tcp
 	ApplicationID uint   `json:"application_id,omitempty"` // 应用ID（用于流量统计）
 	ProxyID       uint   `json:"proxy_id,omitempty"`       // 代理ID（用于流量统计）
+	// 客户端真实地址和它连接的 entry 地址（ip:port），用于 PROXY protocol 和日志
+	ClientAddr string `json:"client_addr,omitempty"`
+	EntryAddr  string `json:"entry_addr,omitempty"`
+	// 非空时 edge 拨号成功后先向后端写入该版本（v1/v2）的 PROXY protocol 头
+	ProxyProtocol string `json:"proxy_protocol,omitempty"`
+	// 请求的压缩算法（zstd/snappy），edge 在 DialResult 中确认后才生效
+	Compression string `json:"compression,omitempty"`
+	// entry 按哪个协议版本握手，低于 ProtocolDialResult 时 edge 不写回 DialResult
+	Version int `json:"version,omitempty"`
+}
+
+// DialCode edge 处理 Dst 的结果码
+type DialCode int
+
+const (
+	DialCodeOK          DialCode = iota // 拨号成功，随后是后端数据
+	DialCodeDenied                      // 目的地址不在 edge 本地的 allowed_destinations 中
+	DialCodeFailed                      // 其他拨号失败
+	DialCodeRefused                     // 后端拒绝连接（端口未监听）
+	DialCodeTimeout                     // 拨号超时
+	DialCodeDNSFailure                  // 域名解析失败
+	DialCodeUnreachable                 // 网络或主机不可达
+)
+
+func (c DialCode) String() string {
+	switch c {
+	case DialCodeOK:
+		return "ok"
+	case DialCodeDenied:
+		return "denied"
+	case DialCodeRefused:
+		return "refused"
+	case DialCodeTimeout:
+		return "timeout"
+	case DialCodeDNSFailure:
+		return "dns_failure"
+	case DialCodeUnreachable:
+		return "unreachable"
+	default:
+		return "failed"
+	}
+}
+
+// DialResult edge 在读取 Dst 并尝试拨号之后写回 entry 的结果帧。
+// 只有 Code 为 DialCodeOK 时，stream 上才会继续承载后端数据。
+type DialResult struct {
+	Code   DialCode `json:"code"`
+	Reason string   `json:"reason,omitempty"`
+	// edge 接受的压缩算法，为空表示不压缩（包括不支持压缩的老版本 edge）
+	Compression string `json:"compression,omitempty"`
 }
 
 type PullTaskScanApplicationRequest struct {
@@ -127,6 +351,43 @@ type UpdateDeviceHeartbeatRequest struct {
 	DeviceID uint64 `json:"device_id"`
 }
 
+// health check
+const (
+	HealthCheckTCP  = "tcp"  // 能建立 TCP 连接即健康
+	HealthCheckHTTP = "http" // GET Path 返回 2xx/3xx 即健康
+)
+
+type GetEdgeHealthChecksRequest struct {
+	EdgeID uint64 `json:"edge_id"`
+}
+
+// HealthCheck 下发给 edge 的一个应用的健康检查任务
+type HealthCheck struct {
+	ApplicationID uint     `json:"application_id"`
+	Type          string   `json:"type"`
+	Path          string   `json:"path,omitempty"`
+	IntervalSec   int      `json:"interval_sec"`
+	TimeoutSec    int      `json:"timeout_sec"`
+	Targets       []string `json:"targets"` // ip:port
+}
+
+type GetEdgeHealthChecksResponse struct {
+	Checks []HealthCheck `json:"checks"`
+}
+
+type ReportTargetHealthRequest struct {
+	EdgeID        uint64         `json:"edge_id"`
+	ApplicationID uint           `json:"application_id"`
+	Targets       []TargetHealth `json:"targets"`
+}
+
+// TargetHealth edge 对一个后端的检查结论
+type TargetHealth struct {
+	Addr    string `json:"addr"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"` // 最近一次失败的原因
+}
+
 // traffic metric
 type ReportTrafficMetricRequest struct {
 	ProxyID       uint  `json:"proxy_id"`
@@ -0,0 +1,59 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Route HTTP 代理的路由规则：路径匹配 PathPrefix 的请求转发到另一个应用
+type Route struct {
+	PathPrefix string
+	// 转发前去掉路径中的 PathPrefix
+	StripPrefix bool
+	// 匹配的应用，以下字段的含义与 Proxy 中的同名字段一致
+	ApplicationID uint
+	EdgeIDs       []uint64
+	EdgePolicy    string
+	Dst           string
+	Targets       []Target
+	Middlewares   []Middleware
+}
+
+// TargetList 返回路由的全部后端，Targets 为空时退化为 Dst 一个后端
+func (r *Route) TargetList() []Target {
+	return targetList(r.Dst, r.Targets)
+}
+
+// MatchPath 路径是否匹配前缀。前缀按路径段匹配：/api 匹配 /api 和 /api/users，不匹配 /apis；
+// 以 / 结尾的前缀按字符串前缀匹配
+func (r *Route) MatchPath(path string) bool {
+	if !strings.HasPrefix(path, r.PathPrefix) {
+		return false
+	}
+	return len(path) == len(r.PathPrefix) || strings.HasSuffix(r.PathPrefix, "/") || path[len(r.PathPrefix)] == '/'
+}
+
+// StrippedPath 去掉前缀之后的路径，结果总是以 / 开头
+func (r *Route) StrippedPath(path string) string {
+	stripped := strings.TrimPrefix(path, strings.TrimSuffix(r.PathPrefix, "/"))
+	if !strings.HasPrefix(stripped, "/") {
+		stripped = "/" + stripped
+	}
+	return stripped
+}
+
+// ValidatePathPrefix 检查路由的路径前缀：以 / 开头，只包含不需要转义的字符
+func ValidatePathPrefix(prefix string) error {
+	if !strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("path prefix %q must start with /", prefix)
+	}
+	for _, c := range prefix {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.ContainsRune("/-._~!$&'()*+,;=:@", c):
+		default:
+			return fmt.Errorf("path prefix %q contains invalid character %q", prefix, c)
+		}
+	}
+	return nil
+}
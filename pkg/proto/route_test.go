@@ -0,0 +1,30 @@
+package proto
+
+import "testing"
+
+func TestRouteMatchPath(t *testing.T) {
+	cases := []struct {
+		prefix, path string
+		match        bool
+		stripped     string
+	}{
+		{"/api", "/api", true, "/"},
+		{"/api", "/api/users", true, "/users"},
+		{"/api", "/apis", false, ""},
+		{"/api/", "/api/users", true, "/users"},
+		{"/api/", "/api", false, ""},
+		{"/", "/index.html", true, "/index.html"},
+	}
+	for _, c := range cases {
+		route := &Route{PathPrefix: c.prefix}
+		if got := route.MatchPath(c.path); got != c.match {
+			t.Errorf("%s match %s = %v, want %v", c.prefix, c.path, got, c.match)
+			continue
+		}
+		if c.match {
+			if got := route.StrippedPath(c.path); got != c.stripped {
+				t.Errorf("%s strip %s = %q, want %q", c.prefix, c.path, got, c.stripped)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package proto
+
+import "time"
+
+// TCP 会话的结束原因
+const (
+	SessionClientClosed     = "client_closed"
+	SessionEdgeClosed       = "edge_closed"
+	SessionEdgeDialFailed   = "edge_dial_failed"
+	SessionFirewallRejected = "firewall_rejected"
+	// 代理被停止或删除时仍在进行的会话
+	SessionProxyStopped = "proxy_stopped"
+	// 通过管理 API 断开的会话
+	SessionDisconnected = "disconnected"
+)
+
+// SessionRecord TCP 代理上的一个客户端连接，连接结束后由 entry 上报
+type SessionRecord struct {
+	ProxyID       uint
+	ApplicationID uint
+	// 承载连接的 edge，没有打开 stream 时为 0
+	EdgeID     uint64
+	ClientAddr string
+	Start      time.Time
+	End        time.Time
+	// 客户端 -> 后端和后端 -> 客户端的字节数
+	BytesIn  int64
+	BytesOut int64
+	// 见 Session*
+	CloseReason string
+	// 失败的具体原因，如 edge 返回的拨号错误
+	Detail string
+}
+
+// SessionRecorder 接收 TCP 会话记录，在连接处理的 goroutine 中调用，不能阻塞
+type SessionRecorder interface {
+	RecordSession(record *SessionRecord)
+}
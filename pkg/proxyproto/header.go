@@ -0,0 +1,142 @@
+// Package proxyproto 实现 HAProxy PROXY protocol（v1 文本格式、v2 二进制格式）：
+// edge 用它把客户端真实地址传递给后端，entry 用它从前置负载均衡器获取客户端真实地址。
+// 规范见 https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt
+package proxyproto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+)
+
+// 版本，与代理配置中的取值一致
+const (
+	Version1 = "v1"
+	Version2 = "v2"
+)
+
+// v2Signature v2 头部固定的 12 字节签名
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// Header 一个 PROXY protocol 头携带的地址信息。
+// Src、Dst 任一无效时按规范写成 UNKNOWN（v1）或 LOCAL（v2），后端会使用连接本身的地址。
+type Header struct {
+	Src netip.AddrPort // 客户端地址
+	Dst netip.AddrPort // 客户端连接的代理地址
+}
+
+// ValidVersion 检查版本取值是否合法，空字符串表示不发送
+func ValidVersion(version string) bool {
+	return version == "" || version == Version1 || version == Version2
+}
+
+// HeaderFromAddrs 从 "ip:port" 形式的地址构造头部，无法解析的地址留空
+func HeaderFromAddrs(src, dst string) Header {
+	var h Header
+	if ap, err := netip.ParseAddrPort(src); err == nil {
+		h.Src = ap
+	}
+	if ap, err := netip.ParseAddrPort(dst); err == nil {
+		h.Dst = ap
+	}
+	return h
+}
+
+// HeaderFromNetAddrs 从 net.Addr 构造头部，非 TCP/UDP 地址留空
+func HeaderFromNetAddrs(src, dst net.Addr) Header {
+	return Header{Src: addrPortOf(src), Dst: addrPortOf(dst)}
+}
+
+func addrPortOf(addr net.Addr) netip.AddrPort {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.AddrPort()
+	case *net.UDPAddr:
+		return a.AddrPort()
+	}
+	return netip.AddrPort{}
+}
+
+// known 两端地址都有效
+func (h Header) known() bool {
+	return h.Src.IsValid() && h.Dst.IsValid()
+}
+
+// addrs 返回同一地址族的两端地址；族不一致时统一成 IPv6（v4-mapped）
+func (h Header) addrs() (src, dst netip.Addr, v4 bool) {
+	src, dst = h.Src.Addr().Unmap(), h.Dst.Addr().Unmap()
+	if src.Is4() && dst.Is4() {
+		return src, dst, true
+	}
+	if src.Is4() {
+		src = netip.AddrFrom16(src.As16())
+	}
+	if dst.Is4() {
+		dst = netip.AddrFrom16(dst.As16())
+	}
+	return src, dst, false
+}
+
+// Format 按指定版本编码头部
+func (h Header) Format(version string) ([]byte, error) {
+	switch version {
+	case Version1:
+		return h.formatV1(), nil
+	case Version2:
+		return h.formatV2(), nil
+	}
+	return nil, fmt.Errorf("unsupported proxy protocol version %q", version)
+}
+
+func (h Header) formatV1() []byte {
+	if !h.known() {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+	src, dst, v4 := h.addrs()
+	family := "TCP6"
+	if v4 {
+		family = "TCP4"
+	}
+	return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n",
+		family, src, dst, h.Src.Port(), h.Dst.Port()))
+}
+
+func (h Header) formatV2() []byte {
+	buf := make([]byte, 16, 16+36)
+	copy(buf, v2Signature)
+	if !h.known() {
+		// LOCAL 命令，不带地址
+		buf[12] = 0x20
+		buf[13] = 0x00
+		return buf
+	}
+	buf[12] = 0x21 // 版本 2，PROXY 命令
+	src, dst, v4 := h.addrs()
+	if v4 {
+		buf[13] = 0x11 // AF_INET + STREAM
+		s, d := src.As4(), dst.As4()
+		buf = append(buf, s[:]...)
+		buf = append(buf, d[:]...)
+	} else {
+		buf[13] = 0x21 // AF_INET6 + STREAM
+		s, d := src.As16(), dst.As16()
+		buf = append(buf, s[:]...)
+		buf = append(buf, d[:]...)
+	}
+	buf = binary.BigEndian.AppendUint16(buf, h.Src.Port())
+	buf = binary.BigEndian.AppendUint16(buf, h.Dst.Port())
+	binary.BigEndian.PutUint16(buf[14:16], uint16(len(buf)-16))
+	return buf
+}
+
+// WriteHeader 在连接开头写入 PROXY protocol 头
+func WriteHeader(w io.Writer, version string, h Header) error {
+	data, err := h.Format(version)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
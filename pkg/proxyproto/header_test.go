@@ -0,0 +1,170 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestFormatV1(t *testing.T) {
+	cases := []struct {
+		src, dst string
+		want     string
+	}{
+		{"192.0.2.1:51234", "198.51.100.7:443", "PROXY TCP4 192.0.2.1 198.51.100.7 51234 443\r\n"},
+		{"[2001:db8::1]:51234", "[2001:db8::2]:443", "PROXY TCP6 2001:db8::1 2001:db8::2 51234 443\r\n"},
+		{"192.0.2.1:51234", "[2001:db8::2]:443", "PROXY TCP6 ::ffff:192.0.2.1 2001:db8::2 51234 443\r\n"},
+		{"[::ffff:192.0.2.1]:51234", "198.51.100.7:443", "PROXY TCP4 192.0.2.1 198.51.100.7 51234 443\r\n"},
+		{"", "198.51.100.7:443", "PROXY UNKNOWN\r\n"},
+	}
+	for _, c := range cases {
+		got, err := HeaderFromAddrs(c.src, c.dst).Format(Version1)
+		if err != nil {
+			t.Fatalf("Format(%s, %s) error: %s", c.src, c.dst, err)
+		}
+		if string(got) != c.want {
+			t.Errorf("Format(%s, %s) = %q, want %q", c.src, c.dst, got, c.want)
+		}
+	}
+}
+
+func TestFormatV2(t *testing.T) {
+	got, err := HeaderFromAddrs("192.0.2.1:51234", "198.51.100.7:443").Format(Version2)
+	if err != nil {
+		t.Fatalf("Format error: %s", err)
+	}
+	want, _ := hex.DecodeString("0d0a0d0a000d0a515549540a" + "2111000c" +
+		"c0000201" + "c6336407" + "c822" + "01bb")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Format = %x, want %x", got, want)
+	}
+
+	got, err = HeaderFromAddrs("[2001:db8::1]:1", "[2001:db8::2]:2").Format(Version2)
+	if err != nil {
+		t.Fatalf("Format error: %s", err)
+	}
+	if len(got) != 16+36 || got[13] != 0x21 || got[15] != 36 {
+		t.Fatalf("unexpected v6 header %x", got)
+	}
+
+	got, err = Header{}.Format(Version2)
+	if err != nil {
+		t.Fatalf("Format error: %s", err)
+	}
+	if len(got) != 16 || got[12] != 0x20 {
+		t.Fatalf("unexpected LOCAL header %x", got)
+	}
+}
+
+func TestFormatUnsupportedVersion(t *testing.T) {
+	if _, err := (Header{}).Format("v3"); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
+
+func TestReadHeaderRoundTrip(t *testing.T) {
+	addrs := [][2]string{
+		{"192.0.2.1:51234", "198.51.100.7:443"},
+		{"[2001:db8::1]:51234", "[2001:db8::2]:443"},
+	}
+	for _, version := range []string{Version1, Version2} {
+		for _, a := range addrs {
+			want := HeaderFromAddrs(a[0], a[1])
+			data, err := want.Format(version)
+			if err != nil {
+				t.Fatalf("Format error: %s", err)
+			}
+			r := bufio.NewReader(bytes.NewReader(append(data, "payload"...)))
+			got, err := ReadHeader(r)
+			if err != nil {
+				t.Fatalf("%s ReadHeader(%s) error: %s", version, a[0], err)
+			}
+			if got != want {
+				t.Errorf("%s ReadHeader = %+v, want %+v", version, got, want)
+			}
+			rest, _ := io.ReadAll(r)
+			if string(rest) != "payload" {
+				t.Errorf("%s payload after header = %q", version, rest)
+			}
+		}
+	}
+}
+
+func TestReadHeaderUnknownAndInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("PROXY UNKNOWN\r\n"),
+		mustFormat(t, Header{}, Version2),
+	} {
+		h, err := ReadHeader(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			t.Fatalf("ReadHeader(%q) error: %s", data, err)
+		}
+		if h.known() {
+			t.Errorf("ReadHeader(%q) = %+v, want unknown", data, h)
+		}
+	}
+
+	for _, data := range []string{
+		"GET / HTTP/1.1\r\n\r\n",
+		"PROXY TCP4 192.0.2.1 198.51.100.7 51234\r\n",
+		"PROXY TCP4 192.0.2.1 198.51.100.7 51234 443\n",
+		"PROXY TCP4 " + strings.Repeat("1", 200) + "\r\n",
+	} {
+		if _, err := ReadHeader(bufio.NewReader(strings.NewReader(data))); err == nil {
+			t.Errorf("ReadHeader(%q) expected error", data)
+		}
+	}
+}
+
+func TestListenerTrusted(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted, _ := ParseTrusted([]string{"127.0.0.1"})
+	pl := NewListener(ln, trusted)
+	defer pl.Close()
+
+	// 一个迟迟不发送头部的可信连接不应阻塞后续连接
+	slow, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	header := mustFormat(t, HeaderFromAddrs("203.0.113.9:4000", "198.51.100.7:443"), Version2)
+	if _, err := c.Write(append(header, "hello"...)); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if got := conn.RemoteAddr().String(); got != "203.0.113.9:4000" {
+		t.Fatalf("RemoteAddr = %s", got)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("read payload = %q, %v", buf, err)
+	}
+}
+
+func mustFormat(t *testing.T, h Header, version string) []byte {
+	t.Helper()
+	data, err := h.Format(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
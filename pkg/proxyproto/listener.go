@@ -0,0 +1,151 @@
+package proxyproto
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jumboframes/armorigo/log"
+)
+
+// HeaderTimeout 可信来源建连后发送 PROXY protocol 头的最长时间
+const HeaderTimeout = 5 * time.Second
+
+// Listener 包装监听器：来自可信来源（负载均衡器）的连接必须以 PROXY protocol 头开头，
+// Accept 返回的连接 RemoteAddr/LocalAddr 替换为头部携带的地址；其他来源的连接原样返回。
+//
+// 头部在独立的 goroutine 里读取，慢的或恶意的可信来源连接不会阻塞 Accept。
+type Listener struct {
+	net.Listener
+	trusted []*net.IPNet
+
+	conns     chan net.Conn
+	errs      chan error
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewListener 在 trusted 非空时包装 ln，否则原样返回
+func NewListener(ln net.Listener, trusted []*net.IPNet) net.Listener {
+	if len(trusted) == 0 {
+		return ln
+	}
+	l := &Listener{
+		Listener: ln,
+		trusted:  trusted,
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		closed:   make(chan struct{}),
+	}
+	go l.acceptLoop()
+	return l
+}
+
+func (l *Listener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			select {
+			case l.errs <- err:
+			case <-l.closed:
+				return
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		if !l.isTrusted(conn.RemoteAddr()) {
+			l.deliver(conn)
+			continue
+		}
+		go l.readHeader(conn)
+	}
+}
+
+func (l *Listener) readHeader(conn net.Conn) {
+	_ = conn.SetReadDeadline(time.Now().Add(HeaderTimeout))
+	r := bufio.NewReader(conn)
+	h, err := ReadHeader(r)
+	if err != nil {
+		log.Warnf("proxy protocol: read header from trusted %s err: %s", conn.RemoteAddr(), err)
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	pc := &Conn{Conn: conn, r: r}
+	if h.known() {
+		pc.remote = net.TCPAddrFromAddrPort(h.Src)
+		pc.local = net.TCPAddrFromAddrPort(h.Dst)
+	}
+	l.deliver(pc)
+}
+
+func (l *Listener) deliver(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.closed:
+		_ = conn.Close()
+	}
+}
+
+func (l *Listener) isTrusted(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range l.trusted {
+		if n.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// Accept 返回下一个就绪的连接
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *Listener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+	return l.Listener.Close()
+}
+
+// Conn 已经读掉 PROXY protocol 头的连接，地址为头部携带的客户端地址
+type Conn struct {
+	net.Conn
+	r      *bufio.Reader
+	remote net.Addr
+	local  net.Addr
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	if c.local != nil {
+		return c.local
+	}
+	return c.Conn.LocalAddr()
+}
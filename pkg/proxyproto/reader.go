@@ -0,0 +1,157 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// v1MaxLength v1 头部（含 CRLF）的最大长度
+const v1MaxLength = 107
+
+// ErrNoHeader 连接开头不是 PROXY protocol 头
+var ErrNoHeader = errors.New("proxy protocol header not found")
+
+// ReadHeader 从连接开头读取一个 v1 或 v2 头部。
+// UNKNOWN（v1）、LOCAL（v2）以及非 IP 地址族返回零值 Header，调用方应继续使用连接本身的地址。
+func ReadHeader(r *bufio.Reader) (Header, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return Header{}, err
+	}
+	switch first[0] {
+	case v2Signature[0]:
+		return readV2(r)
+	case 'P':
+		return readV1(r)
+	}
+	return Header{}, ErrNoHeader
+}
+
+func readV1(r *bufio.Reader) (Header, error) {
+	var line []byte
+	for len(line) < v1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return Header{}, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return Header{}, fmt.Errorf("invalid proxy protocol v1 header: missing CRLF")
+	}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if fields[0] != "PROXY" {
+		return Header{}, ErrNoHeader
+	}
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return Header{}, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return Header{}, fmt.Errorf("invalid proxy protocol v1 header %q", line)
+	}
+	src, err := parseV1Addr(fields[2], fields[4])
+	if err != nil {
+		return Header{}, err
+	}
+	dst, err := parseV1Addr(fields[3], fields[5])
+	if err != nil {
+		return Header{}, err
+	}
+	return Header{Src: src, Dst: dst}, nil
+}
+
+func parseV1Addr(ip, port string) (netip.AddrPort, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("invalid proxy protocol v1 address %q", ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("invalid proxy protocol v1 port %q", port)
+	}
+	return netip.AddrPortFrom(addr, uint16(p)), nil
+}
+
+func readV2(r *bufio.Reader) (Header, error) {
+	var fixed [16]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return Header{}, err
+	}
+	if !bytes.Equal(fixed[:12], v2Signature) {
+		return Header{}, ErrNoHeader
+	}
+	if fixed[12]>>4 != 2 {
+		return Header{}, fmt.Errorf("unsupported proxy protocol v2 version %d", fixed[12]>>4)
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(fixed[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return Header{}, err
+	}
+	// LOCAL 命令：负载均衡器自己发起的连接（如健康检查）
+	if fixed[12]&0x0f == 0x00 {
+		return Header{}, nil
+	}
+	if fixed[12]&0x0f != 0x01 {
+		return Header{}, fmt.Errorf("unsupported proxy protocol v2 command %d", fixed[12]&0x0f)
+	}
+	switch fixed[13] >> 4 {
+	case 0x1: // AF_INET
+		if len(payload) < 12 {
+			return Header{}, fmt.Errorf("short proxy protocol v2 ipv4 address block")
+		}
+		src := netip.AddrFrom4([4]byte(payload[0:4]))
+		dst := netip.AddrFrom4([4]byte(payload[4:8]))
+		return Header{
+			Src: netip.AddrPortFrom(src, binary.BigEndian.Uint16(payload[8:10])),
+			Dst: netip.AddrPortFrom(dst, binary.BigEndian.Uint16(payload[10:12])),
+		}, nil
+	case 0x2: // AF_INET6
+		if len(payload) < 36 {
+			return Header{}, fmt.Errorf("short proxy protocol v2 ipv6 address block")
+		}
+		src := netip.AddrFrom16([16]byte(payload[0:16]))
+		dst := netip.AddrFrom16([16]byte(payload[16:32]))
+		return Header{
+			Src: netip.AddrPortFrom(src, binary.BigEndian.Uint16(payload[32:34])),
+			Dst: netip.AddrPortFrom(dst, binary.BigEndian.Uint16(payload[34:36])),
+		}, nil
+	}
+	// AF_UNSPEC、AF_UNIX：没有可用的 IP 地址
+	return Header{}, nil
+}
+
+// ParseTrusted 解析可信来源列表，支持 CIDR 和单个 IP
+func ParseTrusted(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		_, ipnet, err := net.ParseCIDR(c)
+		if err != nil {
+			ip := net.ParseIP(c)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", c, err)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 128
+			}
+			ipnet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		}
+		nets = append(nets, ipnet)
+	}
+	return nets, nil
+}
@@ -334,6 +334,7 @@ const AppPage: React.FC = () => {
           options={[
             { label: 'HTTP', value: 'http' },
             { label: 'TCP', value: 'tcp' },
+            { label: 'UDP', value: 'udp' },
             { label: 'SSH', value: 'ssh' },
             { label: 'RDP', value: 'rdp' },
             { label: 'MySQL', value: 'mysql' },
@@ -262,3 +262,215 @@ export async function deleteProxyFirewall(proxyId: number) {
     method: 'DELETE',
   });
 }
+
+/** 获取代理可选配置 GET /v1/proxies/:id/options */
+export async function getProxyOptions(proxyId: number) {
+  return request<API.Response<API.ProxyOptions>>(`/api/v1/proxies/${proxyId}/options`, {
+    method: 'GET',
+  });
+}
+
+/** 设置代理可选配置 PUT /v1/proxies/:id/options —— 整体替换，运行中的代理会重启生效 */
+export async function updateProxyOptions(proxyId: number, data: API.ProxyOptionsParams) {
+  return request<API.Response<API.ProxyOptions>>(`/api/v1/proxies/${proxyId}/options`, {
+    method: 'PUT',
+    data,
+  });
+}
+
+/** 获取代理运行时统计 GET /v1/proxies/:id/stats —— 活跃连接数与连接限制拒绝计数 */
+export async function getProxyStats(proxyId: number) {
+  return request<API.Response<API.ProxyStats>>(`/api/v1/proxies/${proxyId}/stats`, {
+    method: 'GET',
+  });
+}
+
+/** 证书列表 GET /v1/certificates */
+export async function getCertificateList() {
+  return request<API.Response<API.CertificateListResult>>('/api/v1/certificates', {
+    method: 'GET',
+  });
+}
+
+/** 上传证书 POST /v1/certificates */
+export async function createCertificate(data: API.CertificateParams) {
+  return request<API.Response<API.Certificate>>('/api/v1/certificates', {
+    method: 'POST',
+    data,
+  });
+}
+
+/** 重命名或轮换证书 PUT /v1/certificates/:id —— 已建立的连接不受影响 */
+export async function updateCertificate(id: number, data: API.CertificateParams) {
+  return request<API.Response<API.Certificate>>(`/api/v1/certificates/${id}`, {
+    method: 'PUT',
+    data,
+  });
+}
+
+/** 删除证书 DELETE /v1/certificates/:id —— 仍有代理绑定时失败 */
+export async function deleteCertificate(id: number) {
+  return request<API.Response>(`/api/v1/certificates/${id}`, {
+    method: 'DELETE',
+  });
+}
+
+/** 获取代理绑定的证书 GET /v1/proxies/:id/certificate */
+export async function getProxyCertificate(proxyId: number) {
+  return request<API.Response<API.ProxyCertificate>>(`/api/v1/proxies/${proxyId}/certificate`, {
+    method: 'GET',
+  });
+}
+
+/** 绑定代理证书 PUT /v1/proxies/:id/certificate */
+export async function bindProxyCertificate(proxyId: number, certificateId: number) {
+  return request<API.Response<API.ProxyCertificate>>(`/api/v1/proxies/${proxyId}/certificate`, {
+    method: 'PUT',
+    data: { certificate_id: certificateId },
+  });
+}
+
+/** 解除代理证书绑定 DELETE /v1/proxies/:id/certificate —— 恢复按 SNI 选择 */
+export async function unbindProxyCertificate(proxyId: number) {
+  return request<API.Response<API.ProxyCertificate>>(`/api/v1/proxies/${proxyId}/certificate`, {
+    method: 'DELETE',
+  });
+}
+
+/** 获取代理登录保护 GET /v1/proxies/:id/auth */
+export async function getProxyAuth(proxyId: number) {
+  return request<API.Response<API.ProxyAuth>>(`/api/v1/proxies/${proxyId}/auth`, {
+    method: 'GET',
+  });
+}
+
+/** 更新代理登录保护 PUT /v1/proxies/:id/auth */
+export async function updateProxyAuth(proxyId: number, data: API.ProxyAuthParams) {
+  return request<API.Response<API.ProxyAuth>>(`/api/v1/proxies/${proxyId}/auth`, {
+    method: 'PUT',
+    data,
+  });
+}
+
+/** 获取代理 HTTP 头改写规则 GET /v1/proxies/:id/headers */
+export async function getProxyHeaderRules(proxyId: number) {
+  return request<API.Response<API.ProxyHeaderRules>>(`/api/v1/proxies/${proxyId}/headers`, {
+    method: 'GET',
+  });
+}
+
+/** 替换代理 HTTP 头改写规则 PUT /v1/proxies/:id/headers */
+export async function updateProxyHeaderRules(proxyId: number, rules: API.HeaderRule[]) {
+  return request<API.Response<API.ProxyHeaderRules>>(`/api/v1/proxies/${proxyId}/headers`, {
+    method: 'PUT',
+    data: { rules },
+  });
+}
+
+/** 获取代理路由规则 GET /v1/proxies/:id/routes */
+export async function getProxyRoutes(proxyId: number) {
+  return request<API.Response<API.ProxyRoutes>>(`/api/v1/proxies/${proxyId}/routes`, {
+    method: 'GET',
+  });
+}
+
+/** 替换代理路由规则 PUT /v1/proxies/:id/routes */
+export async function updateProxyRoutes(proxyId: number, routes: API.ProxyRoute[]) {
+  return request<API.Response<API.ProxyRoutes>>(`/api/v1/proxies/${proxyId}/routes`, {
+    method: 'PUT',
+    data: { routes },
+  });
+}
+
+/** 获取可用的 HTTP 中间件 GET /v1/middlewares */
+export async function getMiddlewares() {
+  return request<API.Response<API.Middleware[]>>('/api/v1/middlewares', {
+    method: 'GET',
+  });
+}
+
+/** 获取应用启用的中间件 GET /v1/applications/:id/middlewares */
+export async function getApplicationMiddlewares(applicationId: number) {
+  return request<API.Response<API.ApplicationMiddlewares>>(`/api/v1/applications/${applicationId}/middlewares`, {
+    method: 'GET',
+  });
+}
+
+/** 替换应用启用的中间件 PUT /v1/applications/:id/middlewares */
+export async function updateApplicationMiddlewares(applicationId: number, middlewares: API.ApplicationMiddleware[]) {
+  return request<API.Response<API.ApplicationMiddlewares>>(`/api/v1/applications/${applicationId}/middlewares`, {
+    method: 'PUT',
+    data: { middlewares },
+  });
+}
+
+/** 查询代理访问日志 GET /v1/proxies/:id/access_logs */
+export async function getProxyAccessLogs(proxyId: number, params?: API.AccessLogParams) {
+  return request<API.Response<API.AccessLogs>>(`/api/v1/proxies/${proxyId}/access_logs`, {
+    method: 'GET',
+    params,
+  });
+}
+
+/** 导出代理访问日志（JSON Lines）GET /v1/proxies/:id/access_logs/export */
+export async function exportProxyAccessLogs(proxyId: number, params?: Omit<API.AccessLogParams, 'page' | 'page_size'>) {
+  return request<Blob>(`/api/v1/proxies/${proxyId}/access_logs/export`, {
+    method: 'GET',
+    params,
+    responseType: 'blob',
+  });
+}
+
+/** 查询 TCP 代理会话记录 GET /v1/proxies/:id/sessions */
+export async function getProxySessions(proxyId: number, params?: API.SessionParams) {
+  return request<API.Response<API.TCPSessions>>(`/api/v1/proxies/${proxyId}/sessions`, {
+    method: 'GET',
+    params,
+  });
+}
+
+/** 查询应用下所有 TCP 代理的会话记录 GET /v1/applications/:id/sessions */
+export async function getApplicationSessions(applicationId: number, params?: API.SessionParams) {
+  return request<API.Response<API.TCPSessions>>(`/api/v1/applications/${applicationId}/sessions`, {
+    method: 'GET',
+    params,
+  });
+}
+
+/** 查询代理的活跃连接 GET /v1/proxies/:id/connections */
+export async function getProxyConnections(proxyId: number) {
+  return request<API.Response<API.ProxyConnections>>(`/api/v1/proxies/${proxyId}/connections`, {
+    method: 'GET',
+  });
+}
+
+/** 断开代理的指定连接或者某个来源 IP 的所有连接 DELETE /v1/proxies/:id/connections */
+export async function closeProxyConnections(proxyId: number, params: API.CloseConnectionsParams) {
+  return request<API.Response<{ closed: number }>>(`/api/v1/proxies/${proxyId}/connections`, {
+    method: 'DELETE',
+    params,
+  });
+}
+
+/** 查询 HTTP 代理请求检查器记录的请求 GET /v1/proxies/:id/inspector/requests —— 从新到旧，不含头和 body */
+export async function getProxyInspectedRequests(proxyId: number) {
+  return request<API.Response<API.InspectedRequests>>(`/api/v1/proxies/${proxyId}/inspector/requests`, {
+    method: 'GET',
+  });
+}
+
+/** 获取一条请求的完整记录 GET /v1/proxies/:id/inspector/requests/:request_id */
+export async function getProxyInspectedRequest(proxyId: number, requestId: number) {
+  return request<API.Response<API.InspectedRequest>>(
+    `/api/v1/proxies/${proxyId}/inspector/requests/${requestId}`,
+    { method: 'GET' },
+  );
+}
+
+/** 重放记录的请求 POST /v1/proxies/:id/inspector/requests/:request_id/replay —— 返回新的记录 */
+export async function replayProxyInspectedRequest(proxyId: number, requestId: number) {
+  return request<API.Response<API.InspectedRequest>>(
+    `/api/v1/proxies/${proxyId}/inspector/requests/${requestId}/replay`,
+    { method: 'POST' },
+  );
+}
@@ -47,7 +47,11 @@ declare namespace API {
     application_type: string;
     ip: string;
     port: number;
-    edge_id: number;
+    edge_id: number; // 优先级最高的 edge，即 edge_ids[0]
+    edge_ids?: number[];
+    edge_policy?: EdgePolicy;
+    targets?: ApplicationTarget[];
+    health_check?: ApplicationHealthCheck;
     device?: Device;
     proxy?: Proxy; // 已关联访问
     created_at: string;
@@ -72,13 +76,41 @@ declare namespace API {
     ip: string;
     port: number;
     edge_id: number;
+    edge_ids?: number[]; // 多个 edge，按优先级排列；非空时忽略 edge_id
+    edge_policy?: EdgePolicy;
+    targets?: ApplicationTarget[]; // 多个后端，非空时 ip/port 取第一个后端
+    health_check?: ApplicationHealthCheck;
     device_id?: number;
   }
 
   interface ApplicationUpdateParams {
     name?: string;
+    edge_ids?: number[]; // 运行中的代理立即生效
+    edge_policy?: EdgePolicy;
+    targets?: ApplicationTarget[];
+    health_check?: ApplicationHealthCheck;
   }
 
+  // 应用后端，health/error 为只读的健康检查结果
+  interface ApplicationTarget {
+    ip: string;
+    port: number;
+    weight?: number;
+    health?: 'unknown' | 'healthy' | 'unhealthy';
+    error?: string;
+  }
+
+  // 由 edge 执行的主动健康检查，type 为空表示不检查
+  interface ApplicationHealthCheck {
+    type?: '' | 'tcp' | 'http';
+    path?: string; // http 检查的路径
+    interval_sec?: number;
+    timeout_sec?: number;
+  }
+
+  // 多个 edge 的选择策略：failover 优先使用排在前面的 edge，round_robin 轮转
+  type EdgePolicy = 'failover' | 'round_robin';
+
   // ========== 设备 (Device) ==========
   interface Device {
     id: number;
@@ -183,6 +215,17 @@ declare namespace API {
     created_at: string;
     updated_at: string;
     access_url?: string;
+    rate_limit?: RateLimit;
+    hostname?: string; // 虚拟主机名，为空只通过端口访问
+    certificate_id?: number; // 绑定的证书，0 表示按 SNI 选择
+  }
+
+  // 带宽限制，单位字节/秒，0 或不传表示不限
+  interface RateLimit {
+    upload_bytes_per_sec?: number;
+    download_bytes_per_sec?: number;
+    per_client_upload_bytes_per_sec?: number;
+    per_client_download_bytes_per_sec?: number;
   }
 
   interface ProxyListResult {
@@ -199,6 +242,8 @@ declare namespace API {
     description?: string;
     port?: number;
     application_id: number;
+    rate_limit?: RateLimit;
+    hostname?: string; // 仅 HTTP 应用；完整域名或基础域名下的一级名字，不填端口时只通过共享监听访问
   }
 
   interface ProxyUpdateParams {
@@ -206,6 +251,8 @@ declare namespace API {
     description?: string;
     port?: number;
     status?: string;
+    rate_limit?: RateLimit; // 不传表示不修改，运行中的代理立即生效
+    hostname?: string; // 不传表示不修改，空字符串表示清除
   }
 
   // ========== 流量监控 (Traffic Metric) ==========
@@ -216,6 +263,13 @@ declare namespace API {
     timestamp: string; // ISO 8601 格式
     bytes_in: number; // 入站流量（字节）
     bytes_out: number; // 出站流量（字节）
+    wire_bytes_in?: number; // entry 与 edge 之间实际传输的入站流量（压缩后）
+    wire_bytes_out?: number; // entry 与 edge 之间实际传输的出站流量（压缩后）
+    cache_hits?: number; // HTTP 响应缓存命中的请求数
+    cache_misses?: number;
+    cache_hit_ratio?: number; // 0 到 1
+    cache_saved_bytes?: number; // 由缓存提供、不经 edge 传输的字节数
+    compression_saved_bytes?: number; // 响应压缩减少的字节数
   }
 
   interface TrafficMetricsListParams {
@@ -266,4 +320,267 @@ declare namespace API {
   interface ProxyFirewallUpsertParams {
     allowed_cidrs: string[];
   }
+
+  // ========== 代理可选配置 (Options) ==========
+  interface ProxyOptionsParams {
+    proxy_protocol?: '' | 'v1' | 'v2'; // 向后端发送 PROXY protocol 头，空为不发送
+    forwarded_headers?: boolean; // HTTP 应用注入 X-Forwarded-For / X-Real-IP
+    compression?: '' | 'zstd' | 'snappy'; // entry 与 edge 之间的压缩，UDP 代理不压缩
+    h2c?: boolean; // 独立端口的 HTTP 监听接受 h2c（prior knowledge）
+    backend_protocol?: '' | 'http1' | 'h2c'; // 与 HTTP 后端之间的协议，gRPC 后端使用 h2c
+    rate_limit?: RateLimit;
+    conn_limit?: ConnLimit;
+    inspector?: InspectorOptions; // 仅 HTTP 应用
+    response_compression?: ResponseCompression; // 仅 HTTP 应用
+    response_cache?: ResponseCache; // 仅 HTTP 应用
+  }
+
+  interface ProxyOptions extends ProxyOptionsParams {
+    proxy_id: number;
+  }
+
+  // 并发连接限制，0 或不传表示不限；UDP 代理按会话计数
+  interface ResponseCompression {
+    enabled?: boolean; // 按客户端的 Accept-Encoding 压缩为 br 或 gzip
+    min_bytes?: number; // 小于该长度的响应不压缩，0 为默认 1KiB
+  }
+
+  interface ResponseCache {
+    enabled?: boolean; // 按 Cache-Control / ETag 在内存中缓存 GET 响应
+    max_bytes?: number; // 缓存占用上限，0 为默认 64MiB，最大 1GiB
+    max_object_bytes?: number; // 单个响应体上限，0 为默认 8MiB
+  }
+
+  interface InspectorOptions {
+    enabled?: boolean;
+    max_body_bytes?: number; // 请求体和响应体各保留的字节数，0 为默认 64KiB，最大 1MiB
+    max_requests?: number; // 保留的请求数，0 为默认 100，最大 1000
+  }
+
+  interface ConnLimit {
+    max_connections?: number;
+    max_connections_per_ip?: number;
+  }
+
+  // ========== 代理运行时统计 (Stats) ==========
+  interface ProxyStats {
+    proxy_id: number;
+    running: boolean;
+    active_connections: number;
+    rejected_by_proxy_limit: number;
+    rejected_by_ip_limit: number;
+    conn_limit: ConnLimit;
+    edges?: EdgeStats[]; // 各 edge 承载的流量，代理未运行时为空
+  }
+
+  interface EdgeStats {
+    edge_id: number;
+    streams: number; // 成功打开的 stream 数（TCP 连接、UDP 会话、HTTP 请求）
+    failures: number;
+    last_used_at: string;
+    healthy: boolean; // false 表示最近打开 stream 失败，正在冷却
+  }
+
+  // ========== 证书 (Certificate) ==========
+  interface Certificate {
+    id: number;
+    name: string;
+    domains: string[];
+    not_before: string;
+    not_after: string;
+    expires_in_days: number; // 已过期时为负数
+    proxy_ids: number[]; // 绑定了该证书的代理
+    loaded: boolean; // 数据面是否已加载
+    created_at: string;
+    updated_at: string;
+  }
+
+  interface CertificateListResult {
+    certificates: Certificate[];
+  }
+
+  // 上传时 cert、key 必填；更新时只改名称可以不传 cert、key，轮换证书需要同时传
+  interface CertificateParams {
+    name?: string;
+    cert?: string; // PEM，可以包含证书链
+    key?: string; // PEM
+  }
+
+  interface ProxyCertificate {
+    proxy_id: number;
+    certificate_id: number; // 0 表示按 SNI 选择
+  }
+
+  interface ProxyAuthParams {
+    required: boolean; // 要求 Liaison 用户登录后才能访问
+    allowed_users?: string[]; // 允许访问的用户邮箱，为空表示所有启用的用户
+  }
+
+  interface ProxyAuth {
+    proxy_id: number;
+    required: boolean;
+    allowed_users: string[];
+  }
+
+  interface HeaderRule {
+    direction: 'request' | 'response';
+    action: 'add' | 'set' | 'remove';
+    name: string;
+    value?: string; // 可以使用 {client_ip}、{proxy_name}、{host}、{scheme}
+  }
+
+  interface ProxyHeaderRules {
+    proxy_id: number;
+    rules: HeaderRule[];
+  }
+
+  interface ProxyRoute {
+    path_prefix: string; // 以 / 开头，/api 匹配 /api 和 /api/ 下的路径
+    strip_prefix?: boolean; // 转发前去掉路径前缀
+    application_id: number;
+  }
+
+  interface ProxyRoutes {
+    proxy_id: number;
+    routes: ProxyRoute[]; // 按顺序匹配，都不匹配时转发到代理自己的应用
+  }
+
+  interface Middleware {
+    name: string;
+    description: string;
+  }
+
+  interface ApplicationMiddleware {
+    name: string; // 见 GET /v1/middlewares，如 jellyfin_transcode、body_replace
+    config?: Record<string, any>; // 中间件的配置，为空时使用默认配置
+  }
+
+  interface ApplicationMiddlewares {
+    application_id: number;
+    middlewares: ApplicationMiddleware[]; // 按顺序执行
+  }
+
+  interface AccessLogParams {
+    start?: string; // RFC3339 或本地时间 2006-01-02T15:04:05
+    end?: string;
+    status?: string; // 状态码类别，如 2xx、5xx
+    client_ip?: string;
+    path_prefix?: string;
+    page?: number;
+    page_size?: number;
+  }
+
+  interface AccessLog {
+    id: number;
+    proxy_id: number;
+    application_id: number;
+    time: string;
+    duration_ms: number;
+    client_ip: string;
+    user?: string; // 登录保护代理上的登录用户
+    method: string;
+    host: string;
+    path: string; // 不含查询参数
+    proto: string;
+    status: number;
+    bytes_in: number;
+    bytes_out: number;
+    user_agent?: string;
+  }
+
+  interface AccessLogs {
+    total: number;
+    page: number;
+    page_size: number;
+    logs: AccessLog[]; // 按时间倒序
+  }
+
+  type SessionCloseReason =
+    | 'client_closed'
+    | 'edge_closed'
+    | 'edge_dial_failed'
+    | 'firewall_rejected'
+    | 'proxy_stopped'
+    | 'disconnected'; // 通过管理 API 断开
+
+  interface SessionParams {
+    start?: string; // 与时间窗口有重叠的会话
+    end?: string;
+    client_ip?: string;
+    close_reason?: SessionCloseReason;
+    page?: number;
+    page_size?: number;
+  }
+
+  interface TCPSession {
+    id: number;
+    proxy_id: number;
+    application_id: number;
+    edge_id: number; // 没有拨号到 edge 时为 0
+    client_addr: string;
+    start: string;
+    end: string;
+    duration_ms: number;
+    bytes_in: number;
+    bytes_out: number;
+    close_reason: SessionCloseReason;
+    detail?: string; // 拨号失败的原因
+  }
+
+  interface TCPSessions {
+    total: number;
+    page: number;
+    page_size: number;
+    sessions: TCPSession[];
+  }
+
+  interface ProxyConnection {
+    id: number;
+    kind: 'tcp' | 'http' | 'websocket' | 'udp';
+    client_addr: string;
+    client_ip: string;
+    start: string;
+    bytes_in: number; // 到目前为止的字节数
+    bytes_out: number;
+    edge_id: number; // 还没有打开 stream 时为 0
+    last_active: string; // 最近一次收发数据的时间
+  }
+
+  interface ProxyConnections {
+    proxy_id: number;
+    running: boolean;
+    connections: ProxyConnection[];
+  }
+
+  interface CloseConnectionsParams {
+    id?: number; // 与 client_ip 至少指定一个
+    client_ip?: string;
+  }
+
+  interface InspectedRequest {
+    id: number;
+    application_id: number;
+    time: string;
+    duration_ms: number;
+    client_addr: string;
+    replay_of?: number; // 重放产生的请求指向原请求
+    method: string;
+    host: string;
+    url: string;
+    proto: string;
+    request_header?: Record<string, string[]>; // 列表中不返回头和 body
+    request_body?: string; // base64
+    request_body_truncated?: boolean;
+    status?: number;
+    response_header?: Record<string, string[]>;
+    response_body?: string; // base64
+    response_body_truncated?: boolean;
+    error?: string;
+  }
+
+  interface InspectedRequests {
+    proxy_id: number;
+    enabled: boolean;
+    requests: InspectedRequest[];
+  }
 }